@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AdGuardConfig 通过 AdGuard Home 的 DNS 重写（Filters → DNS rewrites）维护内部记录
+type AdGuardConfig struct {
+	// URL AdGuard Home 管理界面地址，如 http://192.168.1.2:3000
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// adguardRewrite 一条 DNS 重写
+type adguardRewrite struct {
+	Domain string `json:"domain"`
+	Answer string `json:"answer"`
+}
+
+func (c *AdGuardConfig) String() string {
+	return "adguard " + c.URL
+}
+
+// do 发送请求；body 不为空时以 JSON 发送，out 不为空时解析响应
+func (c *AdGuardConfig) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf(tr("序列化请求失败: %v"), err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Username != "" || c.Password != "" {
+		registerSecret(c.Password)
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: newHTTPTransport("adguard")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf(tr("连接 AdGuard Home 失败: %v"), redactError(err))
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return classify(ErrAuth, fmt.Errorf(tr("AdGuard Home 认证失败 (状态码: %d)"), resp.StatusCode))
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf(tr("AdGuard Home 返回错误 (状态码: %d)"), resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf(tr("解析响应失败: %v"), err)
+		}
+	}
+	return nil
+}
+
+// setAddress 删除各名称指向其他同类型地址的重写，再添加指向 ip 的重写；指向域名的重写（CNAME）保持不变
+func (c *AdGuardConfig) setAddress(names []string, recordType, ip string, ttl int) error {
+	var rewrites []adguardRewrite
+	if err := c.do("GET", "/control/rewrite/list", nil, &rewrites); err != nil {
+		return err
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		exists := false
+		for _, rewrite := range rewrites {
+			if !strings.EqualFold(rewrite.Domain, name) {
+				continue
+			}
+			if rewrite.Answer == ip {
+				exists = true
+				continue
+			}
+			if answer := net.ParseIP(rewrite.Answer); answer != nil && (answer.To4() == nil) == (recordType == "AAAA") {
+				if err := c.do("POST", "/control/rewrite/delete", rewrite, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if !exists {
+			if err := c.do("POST", "/control/rewrite/add", adguardRewrite{Domain: name, Answer: ip}, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
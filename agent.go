@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// AgentConfig 控制器侧的代理配置：每个代理使用独立令牌，只能更新分配给它的记录
+type AgentConfig struct {
+	Name       string `json:"name"`
+	Token      string `json:"token"`
+	Record     string `json:"record"`
+	RecordType string `json:"record_type,omitempty"` // 默认 A
+}
+
+// ControllerConfig 代理侧配置：控制器地址与本代理的令牌
+type ControllerConfig struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// agentHeartbeat IP未变化时代理重复上报的间隔，控制器据此判断代理是否在线
+const agentHeartbeat = time.Minute
+
+// AgentReport 代理上报内容；IP 为空时控制器使用请求的来源地址
+type AgentReport struct {
+	IP       string `json:"ip,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// AgentStatus 控制器记录的代理状态
+type AgentStatus struct {
+	Name      string    `json:"name"`
+	Record    string    `json:"record"`
+	Hostname  string    `json:"hostname,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	LastSeen  time.Time `json:"last_seen"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// agentReportRequest 代理上报请求，由守护进程主循环执行，避免与本机检测并发修改记录
+type agentReportRequest struct {
+	agent    AgentConfig
+	ip       string
+	hostname string
+	reply    chan apiUpdateResult
+}
+
+var (
+	agentReportChan = make(chan agentReportRequest)
+
+	agentStatuses   = make(map[string]*AgentStatus)
+	agentStatusesMu sync.Mutex
+)
+
+// recordType 返回代理记录类型，未配置时为 A
+func (a AgentConfig) recordType() string {
+	if a.RecordType == "" {
+		return "A"
+	}
+	return strings.ToUpper(a.RecordType)
+}
+
+// registerAgentSecrets 注册代理令牌，避免出现在日志中
+func registerAgentSecrets(agents []AgentConfig) {
+	for _, agent := range agents {
+		registerSecret(agent.Token)
+	}
+}
+
+// findAgentByToken 按令牌查找代理配置
+func findAgentByToken(token string) (AgentConfig, bool) {
+	config := app.Config()
+	if token == "" {
+		return AgentConfig{}, false
+	}
+	for _, agent := range config.Agents {
+		if agent.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(agent.Token)) == 1 {
+			return agent, true
+		}
+	}
+	return AgentConfig{}, false
+}
+
+// handleAgentReport 接收代理上报的IP（使用代理令牌认证）
+func handleAgentReport(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	agent, ok := findAgentByToken(token)
+	if auth == token || !ok {
+		writeAPIError(w, http.StatusUnauthorized, tr("未授权"))
+		return
+	}
+	if r.Method != "POST" {
+		writeAPIError(w, http.StatusMethodNotAllowed, tr("不支持的请求方法"))
+		return
+	}
+
+	var report AgentReport
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&report); err != nil && err != io.EOF {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf(tr("解析请求失败: %v"), err))
+		return
+	}
+
+	ip := strings.TrimSpace(report.IP)
+	if ip == "" {
+		ip, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	if err := validateRecordContent(agent.recordType(), ip); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := agentReportRequest{
+		agent:    agent,
+		ip:       ip,
+		hostname: report.Hostname,
+		reply:    make(chan apiUpdateResult, 1),
+	}
+
+	select {
+	case agentReportChan <- req:
+	case <-time.After(10 * time.Second):
+		writeAPIError(w, http.StatusServiceUnavailable, tr("守护进程繁忙，请稍后重试"))
+		return
+	}
+
+	result := <-req.reply
+	status := http.StatusOK
+	if result.Error != "" {
+		status = http.StatusBadGateway
+	}
+	writeAPIJSON(w, status, result)
+}
+
+func handleAPIAgents(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, getAgentStatuses())
+}
+
+// getAgentStatuses 返回已配置代理的状态（按配置顺序）
+func getAgentStatuses() []AgentStatus {
+	config := app.Config()
+	agentStatusesMu.Lock()
+	defer agentStatusesMu.Unlock()
+
+	result := make([]AgentStatus, 0, len(config.Agents))
+	for _, agent := range config.Agents {
+		status := AgentStatus{Name: agent.Name, Record: agent.Record}
+		if s, ok := agentStatuses[agent.Name]; ok {
+			status = *s
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// reconcileAgent 将代理上报的IP同步到其记录（在守护进程主循环中执行）
+func reconcileAgent(req agentReportRequest) apiUpdateResult {
+	agent := req.agent
+
+	agentStatusesMu.Lock()
+	status, ok := agentStatuses[agent.Name]
+	if !ok {
+		status = &AgentStatus{Name: agent.Name}
+		agentStatuses[agent.Name] = status
+	}
+	oldIP := status.IP
+	status.Record = agent.Record
+	status.Hostname = req.hostname
+	status.LastSeen = time.Now()
+	agentStatusesMu.Unlock()
+
+	result := apiUpdateResult{IP: req.ip}
+	if req.ip == oldIP {
+		return result
+	}
+
+	logInfo("代理 %s 上报IP变化 (%s -> %s)，正在更新记录 %s", agent.Name, oldIP, req.ip, agent.Record)
+	publishEvent(StreamEvent{Type: StreamIPChanged, Record: agent.Record, IP: req.ip, OldIP: oldIP, Source: agent.Name})
+
+	r := newReconciler()
+	r.Name = agent.Record
+	r.Type = agent.recordType()
+	// 记录已更新后立即返回，不在主循环中等待传播验证
+	r.VerifyPropagation = false
+	// 代理的记录不指向本机IP，恢复TTL时不会处理，因此不降低TTL
+	r.TTLStrategy = nil
+
+	// 每个代理独占自己的记录：oldIP 只保存在控制器内存中，控制器重启后为空，
+	// 此时修改已有的记录而不是新增一条，避免留下指向旧IP的记录
+	desired := r.Desired(req.ip, oldIP)
+	desired.Exclusive = true
+	plan, err := r.Plan(desired)
+	if err == nil && plan.Action == planNone {
+		// 记录已指向上报的IP（如控制器重启后首次上报且IP未变化）
+		setAgentResult(agent.Name, req.ip, nil)
+		return result
+	}
+	if err == nil {
+		err = r.Apply(plan, fmt.Sprintf(tr("代理: %s"), agent.Name))
+	}
+	setAgentResult(agent.Name, req.ip, err)
+	if err != nil {
+		logError("代理 %s 的记录更新失败: %v", agent.Name, err)
+		appendHistory(HistoryEntry{Type: "error", IP: req.ip, Message: agent.Name + ": " + redactSecrets(err.Error()), Category: errorCategory(err), Requests: apiResponseMetas(err)})
+		publishEvent(StreamEvent{Type: StreamError, Record: agent.Record, IP: req.ip, Source: agent.Name, Message: err.Error()})
+		result.Error = redactSecrets(err.Error())
+		return result
+	}
+
+	logInfo("代理 %s 的记录已更新: %s -> %s", agent.Name, agent.Record, req.ip)
+	appendHistory(HistoryEntry{Type: "updated", IP: req.ip, Message: agent.Name + ": " + oldIP + " -> " + req.ip})
+	publishEvent(StreamEvent{Type: StreamDNSUpdated, Record: agent.Record, IP: req.ip, OldIP: oldIP, Source: agent.Name})
+	notify(NotifyEvent{
+		Type:   EventDNSUpdated,
+		Title:  tr("DNS记录已更新"),
+		Record: agent.Record,
+		OldIP:  oldIP,
+		NewIP:  req.ip,
+	})
+	result.Updated = true
+	return result
+}
+
+// setAgentResult 记录代理最近一次同步结果；失败时不记录IP，下次上报会重试
+func setAgentResult(name, ip string, err error) {
+	agentStatusesMu.Lock()
+	defer agentStatusesMu.Unlock()
+
+	status := agentStatuses[name]
+	if err != nil {
+		status.LastError = redactSecrets(err.Error())
+		return
+	}
+	status.IP = ip
+	status.LastError = ""
+}
+
+// runAgent 代理模式：检测本机公网IP并上报给控制器，本机无需持有 Cloudflare 令牌
+func runAgent(controller ControllerConfig) {
+	ipChecker := app.IPChecker()
+	registerSecret(controller.Token)
+	logInfo("代理已启动，控制器: %s", controller.URL)
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: newHTTPTransport("controller"),
+	}
+	hostname, _ := os.Hostname()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	var lastReported string
+	var lastReport time.Time
+	report := func() {
+		ip, service, err := ipChecker.GetPublicIPWithService()
+		if err != nil {
+			logError("获取公网IP失败: %v", err)
+			return
+		}
+		if ip == lastReported && time.Since(lastReport) < agentHeartbeat {
+			return
+		}
+
+		result, err := sendAgentReport(client, controller, AgentReport{IP: ip, Hostname: hostname})
+		if err != nil {
+			logError("上报IP失败: %v", err)
+			return
+		}
+		if ip != lastReported {
+			logInfo("已上报IP: %s (来源: %s)", ip, service)
+		}
+		if result.Updated {
+			logInfo("控制器已更新DNS记录: %s", ip)
+		}
+		lastReported = ip
+		lastReport = time.Now()
+	}
+
+	report()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report()
+		case <-sigChan:
+			logInfo("收到停止信号，正在退出...")
+			return
+		}
+	}
+}
+
+// sendAgentReport 向控制器发送一次上报
+func sendAgentReport(client *http.Client, controller ControllerConfig, report AgentReport) (apiUpdateResult, error) {
+	var result apiUpdateResult
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return result, fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	url := strings.TrimRight(controller.URL, "/") + "/agent/report"
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Authorization", "Bearer "+controller.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, redactError(err)
+	}
+	defer resp.Body.Close()
+
+	// 错误响应同样为 JSON，error 字段包含原因
+	decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf(tr("控制器返回错误 (状态码: %d): %s"), resp.StatusCode, result.Error)
+	}
+	if decodeErr != nil {
+		return result, fmt.Errorf(tr("解析响应失败: %v"), decodeErr)
+	}
+	return result, nil
+}
+
+// validateControllerConfig 检查代理模式所需的控制器配置
+func validateControllerConfig(c *ControllerConfig) error {
+	if c == nil || c.URL == "" || c.Token == "" {
+		return errors.New(tr("代理模式需要在配置文件中设置 controller.url 和 controller.token"))
+	}
+	return nil
+}
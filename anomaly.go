@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnomalyConfig 新IP的国家或 ASN 与以往接受过的IP都不同时告警，可选在确认之前不写入记录，
+// 防止把 VPN 或被劫持的代理出口写入公网DNS
+type AnomalyConfig struct {
+	// Compare 比较的字段: any（默认，国家或 ASN 不同）/ country / asn
+	Compare string `json:"compare,omitempty"`
+	// Confirm 为 true 时暂缓更新，直到执行 approve 命令或调用 POST /approve
+	Confirm bool `json:"confirm,omitempty"`
+}
+
+// 比较的字段
+const (
+	anomalyAny     = "any"
+	anomalyCountry = "country"
+	anomalyASN     = "asn"
+)
+
+func (c *AnomalyConfig) compare() string {
+	if c.Compare == "" {
+		return anomalyAny
+	}
+	return strings.ToLower(c.Compare)
+}
+
+// validate 检查配置
+func (c *AnomalyConfig) validate() error {
+	switch c.compare() {
+	case anomalyAny, anomalyCountry, anomalyASN:
+		return nil
+	}
+	return fmt.Errorf(tr("geo.anomaly.compare 无效: %s（可选 any、country 或 asn）"), c.Compare)
+}
+
+// pendingChange 等待确认的IP变化
+type pendingChange struct {
+	IP     string    `json:"ip"`
+	Geo    *GeoInfo  `json:"geo,omitempty"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+}
+
+// geoProfile 已接受的IP的归属统计（国家 / ASN -> 次数）与待确认的变化，持久化到数据目录，
+// once 模式的多次运行与守护进程重启后仍然有效
+type geoProfile struct {
+	Countries map[string]int `json:"countries,omitempty"`
+	ASNs      map[string]int `json:"asns,omitempty"`
+	Pending   *pendingChange `json:"pending,omitempty"`
+	// Approved 已确认、尚未同步完成的IP
+	Approved string `json:"approved,omitempty"`
+}
+
+// deviation 返回新IP与历史不符的说明，没有历史（第一次接受IP）或一致时返回空字符串
+func (p *geoProfile) deviation(info *GeoInfo, compare string) string {
+	var reasons []string
+	if compare != anomalyASN && info.Country != "" && len(p.Countries) > 0 && p.Countries[info.Country] == 0 {
+		reasons = append(reasons, fmt.Sprintf(tr("国家 %s 不在以往的 %s 中"), info.Country, strings.Join(sortedKeys(p.Countries), ", ")))
+	}
+	if compare != anomalyCountry && info.ASN != "" && len(p.ASNs) > 0 && p.ASNs[info.ASN] == 0 {
+		reasons = append(reasons, fmt.Sprintf(tr("ASN %s 不在以往的 %s 中"), info.ASN, strings.Join(sortedKeys(p.ASNs), ", ")))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// learn 把已接受的IP的归属计入历史
+func (p *geoProfile) learn(info *GeoInfo) {
+	if info.Country != "" {
+		if p.Countries == nil {
+			p.Countries = make(map[string]int)
+		}
+		p.Countries[info.Country]++
+	}
+	if info.ASN != "" {
+		if p.ASNs == nil {
+			p.ASNs = make(map[string]int)
+		}
+		p.ASNs[info.ASN]++
+	}
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// anomalyGuard 检查新IP的归属是否异常
+type anomalyGuard struct {
+	mu      sync.Mutex
+	profile *geoProfile // 第一次使用时从文件加载
+}
+
+var anomaly = &anomalyGuard{}
+
+// getGeoProfilePath 返回归属统计文件路径
+func getGeoProfilePath() string {
+	return filepath.Join(getDataDir(), "geo_profile.json")
+}
+
+// load 读取归属统计，文件不存在时从空的历史开始；调用方需持有锁
+func (a *anomalyGuard) load() *geoProfile {
+	if a.profile != nil {
+		return a.profile
+	}
+	a.profile = &geoProfile{}
+	data, err := readFileChecked(getGeoProfilePath())
+	if err == nil {
+		err = json.Unmarshal(data, a.profile)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		logError("读取IP归属统计失败，从空的历史开始: %v", err)
+		a.profile = &geoProfile{}
+	}
+	return a.profile
+}
+
+// save 写入归属统计；调用方需持有锁
+func (a *anomalyGuard) save() {
+	data, err := json.MarshalIndent(a.profile, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(getGeoProfilePath(), data, 0644, true)
+	}
+	if err != nil {
+		logError("写入IP归属统计失败: %v", err)
+	}
+}
+
+// hold 检查新IP的归属，异常时告警；需要确认且尚未确认时返回 true，本周期不写入记录。
+// 未配置或没有归属信息（查询失败、geo 配置无效）时不检查
+func (a *anomalyGuard) hold(config *Config, ip string, info *GeoInfo) bool {
+	if config.Geo == nil || config.Geo.Anomaly == nil || info == nil {
+		return false
+	}
+	settings := config.Geo.Anomaly
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	profile := a.load()
+	reason := profile.deviation(info, settings.compare())
+	if reason == "" {
+		return false
+	}
+	if profile.Approved == ip {
+		logInfo("新IP %s 的归属异常已确认，继续更新", ip)
+		return false
+	}
+
+	// 已告警过的IP继续等待确认，不重复通知
+	if settings.Confirm && profile.Pending != nil && profile.Pending.IP == ip {
+		logInfo("新IP %s 等待确认，暂不更新DNS记录（执行 approve 命令确认）", ip)
+		return true
+	}
+
+	logError("新IP %s 的归属与以往不同: %s", ip, reason)
+	message := fmt.Sprintf(tr("新IP %s 的归属 %s 与以往不同: %s"), ip, info.String(), reason)
+	if settings.Confirm {
+		message += tr("；确认前不会更新DNS记录，使用 approve 命令确认")
+	}
+	notify(NotifyEvent{
+		Type:    EventAnomaly,
+		Title:   tr("新IP的归属异常"),
+		Message: message,
+		Record:  config.RecordName,
+		OldIP:   app.CurrentIP(),
+		NewIP:   ip,
+		Geo:     info,
+	})
+	if !settings.Confirm {
+		return false
+	}
+	profile.Pending = &pendingChange{IP: ip, Geo: info, Reason: reason, Since: time.Now()}
+	a.save()
+	logInfo("新IP %s 等待确认，暂不更新DNS记录（执行 approve 命令确认）", ip)
+	return true
+}
+
+// accepted IP已同步完成：计入历史并清除待确认的变化
+func (a *anomalyGuard) accepted(config *Config, ip string, info *GeoInfo) {
+	if config.Geo == nil || config.Geo.Anomaly == nil || info == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	profile := a.load()
+	profile.learn(info)
+	profile.Pending = nil
+	profile.Approved = ""
+	a.save()
+}
+
+// approve 确认待确认的IP变化；ip 非空时必须与待确认的IP一致，避免确认了之后又变化的IP
+func (a *anomalyGuard) approve(ip string) (*pendingChange, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	profile := a.load()
+	pending := profile.Pending
+	if pending == nil {
+		return nil, errors.New(tr("没有等待确认的IP变化"))
+	}
+	if ip != "" && ip != pending.IP {
+		return nil, fmt.Errorf(tr("等待确认的IP是 %s，不是 %s"), pending.IP, ip)
+	}
+	profile.Approved = pending.IP
+	profile.Pending = nil
+	a.save()
+	return pending, nil
+}
+
+// pending 返回等待确认的IP变化
+func (a *anomalyGuard) pending() *pendingChange {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.load().Pending
+}
+
+func cmdApproveMain(args []string) int {
+	fs, common := newFlagSet("approve")
+	ip := fs.String("ip", "", tr("只在等待确认的IP与之一致时确认"))
+	parseFlags(fs, common, args)
+
+	var pending *pendingChange
+	if _, err := os.Stat(getControlSocketPath()); err == nil {
+		resp, err := sendControl(controlRequest{Command: "approve", IP: *ip})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		pending = resp.Pending
+	} else {
+		// 守护进程未运行（once 模式）：直接写入归属统计，下次运行时更新
+		var err error
+		if pending, err = anomaly.approve(*ip); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	fmt.Printf(tr("已确认新IP %s，将更新DNS记录\n"), pending.IP)
+	return 0
+}
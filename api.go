@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// apiUpdateRequest 通过管理 API 触发的更新请求，由守护进程主循环执行
+type apiUpdateRequest struct {
+	force bool
+	// summary 附带本次周期的结构化结果（once 命令交给守护进程执行时）
+	summary bool
+	reply   chan apiUpdateResult
+}
+
+type apiUpdateResult struct {
+	Updated bool         `json:"updated"`
+	IP      string       `json:"ip,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Summary *OnceSummary `json:"summary,omitempty"`
+}
+
+// apiUpdateChan 管理 API 与守护进程主循环之间的更新请求通道
+var apiUpdateChan = make(chan apiUpdateRequest)
+
+// startAPIServer 启动管理 API（需配置 api_token）
+func startAPIServer(addr, token string) {
+	if token == "" {
+		logError("管理 API 未启动: 必须配置 api_auth_token")
+		return
+	}
+	registerSecret(token)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz)
+	mux.Handle("/status", apiAuth(token, "GET", handleAPIStatus))
+	mux.Handle("/records", apiAuth(token, "GET", handleAPIRecords))
+	mux.Handle("/update", apiAuth(token, "POST", handleAPIUpdate))
+	mux.Handle("/reload", apiAuth(token, "POST", handleAPIReload))
+	mux.Handle("/approve", apiAuth(token, "POST", handleAPIApprove))
+	mux.Handle("/history", apiAuth(token, "GET", handleAPIHistory))
+	mux.Handle("/agents", apiAuth(token, "GET", handleAPIAgents))
+	mux.Handle("/events", streamAuth(token, handleAPIEvents))
+	mux.HandleFunc("/agent/report", handleAgentReport)
+	if app.Config().APIPprof {
+		registerPprof(mux, token)
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logInfo("管理 API 已启动: http://%s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logError("管理 API 启动失败: %v", err)
+		}
+	}()
+}
+
+// apiAuth 校验 Bearer 令牌与请求方法
+func apiAuth(token, method string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		provided := strings.TrimPrefix(auth, "Bearer ")
+		if auth == provided || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, tr("未授权"))
+			return
+		}
+		if r.Method != method {
+			writeAPIError(w, http.StatusMethodNotAllowed, tr("不支持的请求方法"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+func handleAPIStatus(w http.ResponseWriter, r *http.Request) {
+	config := app.Config()
+	ready, reason := health.ready()
+
+	status := map[string]interface{}{
+		"pid":         os.Getpid(),
+		"ready":       ready,
+		"reason":      reason,
+		"zone_id":     config.ZoneID,
+		"record_name": config.RecordName,
+		"record_type": config.RecordType,
+	}
+
+	if len(config.Agents) > 0 {
+		status["agents"] = getAgentStatuses()
+	}
+
+	if offlineStatus := offline.status(); offlineStatus != nil {
+		status["offline"] = offlineStatus
+	}
+
+	if config.ServiceCheck != nil {
+		status["membership"] = membership.status()
+	}
+
+	status["maintenance"] = maintenance.status()
+	if config.Geo != nil && config.Geo.Anomaly != nil {
+		status["pending_ip"] = anomaly.pending()
+	}
+	status["runtime"] = runtimeStats()
+
+	daemonStateMu.Lock()
+	if daemonState != nil {
+		state := *daemonState
+		status["uptime"] = time.Since(state.StartTime).Round(time.Second).String()
+		status["state"] = state
+	}
+	daemonStateMu.Unlock()
+
+	writeAPIJSON(w, http.StatusOK, status)
+}
+
+func handleAPIRecords(w http.ResponseWriter, r *http.Request) {
+	config := app.Config()
+	cfClient := app.Client()
+	records, err := cfClient.GetAllDNSRecords(config.ZoneID, config.RecordName, config.RecordType)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if records == nil {
+		records = []DNSRecord{}
+	}
+	writeAPIJSON(w, http.StatusOK, records)
+}
+
+// handleAPIUpdate 立即执行一次检测更新；?force=true 时忽略已记录的IP，强制核对DNS记录
+func handleAPIUpdate(w http.ResponseWriter, r *http.Request) {
+	req := apiUpdateRequest{
+		force: r.URL.Query().Get("force") == "true",
+		reply: make(chan apiUpdateResult, 1),
+	}
+
+	select {
+	case apiUpdateChan <- req:
+	case <-time.After(10 * time.Second):
+		writeAPIError(w, http.StatusServiceUnavailable, tr("守护进程繁忙，请稍后重试"))
+		return
+	}
+
+	result := <-req.reply
+	status := http.StatusOK
+	if result.Error != "" {
+		status = http.StatusBadGateway
+	}
+	writeAPIJSON(w, status, result)
+}
+
+func handleAPIReload(w http.ResponseWriter, r *http.Request) {
+	select {
+	case reloadChan <- true:
+	default:
+		// 已有待处理的重载请求
+	}
+	writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "reloading"})
+}
+
+// handleAPIApprove 确认归属异常、等待确认的新IP；?ip= 非空时必须与等待确认的IP一致
+func handleAPIApprove(w http.ResponseWriter, r *http.Request) {
+	pending, err := anomaly.approve(r.URL.Query().Get("ip"))
+	if err != nil {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+	logInfo("已通过管理 API 确认新IP %s", pending.IP)
+	requestSync(resumeChan)
+	writeAPIJSON(w, http.StatusOK, pending)
+}
+
+func handleAPIHistory(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, getHistory())
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": redactSecrets(message)})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// App 运行时状态：配置、Cloudflare 客户端、IP检测器与最近确认的公网IP。
+// 检测周期、配置重载、管理 API 与通知协程会并发访问这些状态，所有读写都经过互斥锁；
+// Config 加载后视为只读，重载时整体替换，读取方拿到的指针始终是一份完整的配置
+type App struct {
+	mu        sync.RWMutex
+	config    *Config
+	cfClient  *CloudflareClient
+	ipChecker *IPChecker
+	currentIP string
+	running   bool
+
+	// cycleMu 保证检测周期与配置重载互斥，重载不会在周期执行到一半时替换配置和客户端
+	cycleMu sync.Mutex
+}
+
+// app 进程内唯一的运行时状态
+var app = &App{config: &Config{RecordType: "A"}}
+
+// Config 返回当前配置
+func (a *App) Config() *Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
+// Client 返回当前 Cloudflare 客户端（尚未配置时为 nil）
+func (a *App) Client() *CloudflareClient {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfClient
+}
+
+// IPChecker 返回IP检测器
+func (a *App) IPChecker() *IPChecker {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.ipChecker
+}
+
+// CurrentIP 返回最近确认的公网IP
+func (a *App) CurrentIP() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.currentIP
+}
+
+// SetCurrentIP 记录最近确认的公网IP
+func (a *App) SetCurrentIP(ip string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.currentIP = ip
+}
+
+// SetConfig 替换配置，不改变客户端（用于尚未完成配置的阶段）
+func (a *App) SetConfig(cfg *Config) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = cfg
+}
+
+// SetIPChecker 设置IP检测器
+func (a *App) SetIPChecker(checker *IPChecker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ipChecker = checker
+}
+
+// Apply 同时替换配置与客户端；客户端为 nil 时不做任何修改，避免留下不可用的客户端
+func (a *App) Apply(cfg *Config, client *CloudflareClient) error {
+	if cfg == nil || client == nil {
+		return errors.New(tr("配置或客户端无效，保持使用旧配置"))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = cfg
+	a.cfClient = client
+	return nil
+}
+
+// ApplyConfig 按新配置创建客户端，成功后同时替换配置与客户端；令牌与 HTTP 设置未变化时复用现有客户端
+func (a *App) ApplyConfig(cfg *Config) error {
+	client := a.Client()
+	if client == nil || cfg.APIToken != a.Config().APIToken || !reflect.DeepEqual(cfg.HTTP, a.Config().HTTP) {
+		if _, err := cfg.HTTP.tlsConfig(); err != nil {
+			return err
+		}
+		var err error
+		client, err = NewCloudflareClient(cfg.APIToken)
+		if err != nil {
+			return err
+		}
+		client.client = newCloudflareHTTPClient(cfg.HTTP)
+		client.listCache = newRecordListCache(defaultListCacheTTL)
+	}
+	return a.Apply(cfg, client)
+}
+
+// ClearConfig 清空配置与客户端（重新配置前）
+func (a *App) ClearConfig() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = &Config{RecordType: "A"}
+	a.cfClient = nil
+}
+
+// startRunning 标记前台监控已开始；已在运行时返回 false
+func (a *App) startRunning() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return false
+	}
+	a.running = true
+	return true
+}
+
+// stopRunning 标记前台监控已停止
+func (a *App) stopRunning() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running = false
+}
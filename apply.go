@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ApplyFile apply 子命令读取的期望状态文件（YAML 或 JSON）
+type ApplyFile struct {
+	// ZoneID 为空时使用配置中的区域
+	ZoneID  string        `json:"zone_id,omitempty"`
+	Records []ApplyRecord `json:"records"`
+}
+
+// ApplyRecord 文件中声明的一条记录
+type ApplyRecord struct {
+	// Name 完整域名；@ 表示区域根域名，不含点的标签相对于区域根域名
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	// TTL 为 0 时为自动（1）
+	TTL      int    `json:"ttl,omitempty"`
+	Proxied  bool   `json:"proxied,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// managedCommentPrefix 自动发现的记录（docker、kubernetes）的备注前缀
+const managedCommentPrefix = "managed by dns_manager"
+
+// loadApplyFile 读取并解析期望状态文件
+func loadApplyFile(path string) (*ApplyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取文件失败: %v"), err)
+	}
+	data, err = yamlToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf(tr("解析 %s 失败: %v"), path, err)
+	}
+	var spec ApplyFile
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf(tr("解析 %s 失败: %v"), path, err)
+	}
+	return &spec, nil
+}
+
+// desiredRecords 校验文件中的记录并转换为完整名称
+func (f *ApplyFile) desiredRecords(client *CloudflareClient, zoneID string) ([]DNSRecord, error) {
+	var records []DNSRecord
+	seen := make(map[string]bool)
+	for i, r := range f.Records {
+		recordType := strings.ToUpper(strings.TrimSpace(r.Type))
+		if r.Name == "" || recordType == "" {
+			return nil, fmt.Errorf(tr("第 %d 条记录缺少 name 或 type"), i+1)
+		}
+		if err := validateRecordContent(recordType, r.Content); err != nil {
+			return nil, fmt.Errorf("%s %s: %v", r.Name, recordType, err)
+		}
+		ttl := r.TTL
+		if ttl == 0 {
+			ttl = 1
+		}
+		if ttl != 1 && (ttl < 60 || ttl > 86400) {
+			return nil, fmt.Errorf(tr("%s %s: 无效的 TTL %d（1 表示自动，或 60-86400 秒）"), r.Name, recordType, r.TTL)
+		}
+		if r.Proxied && !proxiableTypes[recordType] {
+			return nil, fmt.Errorf(tr("%s %s: 该类型的记录不能开启代理"), r.Name, recordType)
+		}
+		priority := r.Priority
+		if recordType == "MX" && priority == nil {
+			def := 10
+			priority = &def
+		}
+
+		name := r.Name
+		if name != apexName && !strings.Contains(name, ".") {
+			name += "." + apexName
+		}
+		name, err := client.recordName(zoneID, strings.TrimSuffix(name, "."))
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(name) + "/" + recordType + "/" + r.Content
+		if seen[key] {
+			return nil, fmt.Errorf(tr("重复的记录: %s %s %s"), name, recordType, r.Content)
+		}
+		seen[key] = true
+
+		records = append(records, DNSRecord{
+			Type:     recordType,
+			Name:     strings.ToLower(name),
+			Content:  r.Content,
+			TTL:      ttl,
+			Proxied:  r.Proxied,
+			Priority: priority,
+			Comment:  r.Comment,
+		})
+	}
+	return records, nil
+}
+
+// managedRecordSets 返回区域内由本程序维护、apply 不能声明也不会删除的记录集（类型/名称）：
+// 跟随公网IP的记录（包括使用独立IP来源的记录）、子域名 CNAME 与租约等内部 TXT 记录
+func managedRecordSets(config *Config, client *CloudflareClient, zoneID string) (map[string]bool, error) {
+	sets := make(map[string]bool)
+	add := func(recordType, name string) error {
+		name, err := client.recordName(zoneID, name)
+		if err != nil {
+			return err
+		}
+		sets[recordType+"/"+strings.ToLower(name)] = true
+		return nil
+	}
+	for _, target := range append(config.ipTargets(), config.pinnedTargets()...) {
+		if target.ZoneID == zoneID {
+			if err := add(target.Type, target.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if zoneID == config.ZoneID {
+		_, aliases := config.subdomainTargets()
+		for _, alias := range aliases {
+			if err := add("CNAME", alias); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sets, nil
+}
+
+// isManagedRecord 判断记录是否由本程序维护
+func isManagedRecord(sets map[string]bool, record DNSRecord) bool {
+	return sets[record.Type+"/"+strings.ToLower(record.Name)] ||
+		strings.HasPrefix(strings.ToLower(record.Name), "_dns_manager_") ||
+		strings.HasPrefix(record.Comment, managedCommentPrefix)
+}
+
+// planApply 比较期望记录与区域内的当前记录：同名同类型的记录集中先按内容匹配，
+// 其余按顺序修改内容，不足时新建；prune 时删除文件中未声明的记录（本程序维护的记录除外）
+func planApply(desired, current []DNSRecord, prune bool, managed map[string]bool) []restoreAction {
+	setKey := func(r DNSRecord) string { return r.Type + "/" + strings.ToLower(r.Name) }
+
+	var keys []string
+	wants := make(map[string][]DNSRecord)
+	for _, record := range desired {
+		key := setKey(record)
+		if _, ok := wants[key]; !ok {
+			keys = append(keys, key)
+		}
+		wants[key] = append(wants[key], record)
+	}
+	haves := make(map[string][]DNSRecord)
+	for _, record := range current {
+		if !isManagedRecord(managed, record) {
+			haves[setKey(record)] = append(haves[setKey(record)], record)
+		}
+	}
+
+	var actions []restoreAction
+	for _, key := range keys {
+		have := haves[key]
+		delete(haves, key)
+
+		var unmatched []DNSRecord
+		for _, want := range wants[key] {
+			matched := false
+			for i := range have {
+				if have[i].Content == want.Content {
+					actions = append(actions, updateIfChanged(have[i], want)...)
+					have = append(have[:i], have[i+1:]...)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				unmatched = append(unmatched, want)
+			}
+		}
+		for _, want := range unmatched {
+			if len(have) > 0 {
+				actions = append(actions, updateIfChanged(have[0], want)...)
+				have = have[1:]
+				continue
+			}
+			actions = append(actions, restoreAction{Action: "create", Record: want})
+		}
+		if prune {
+			for _, extra := range have {
+				actions = append(actions, restoreAction{Action: "delete", Record: extra})
+			}
+		}
+	}
+
+	if prune {
+		var rest []string
+		for key := range haves {
+			rest = append(rest, key)
+		}
+		sort.Strings(rest)
+		for _, key := range rest {
+			for _, extra := range haves[key] {
+				actions = append(actions, restoreAction{Action: "delete", Record: extra})
+			}
+		}
+	}
+	return actions
+}
+
+// updateIfChanged 记录与期望不同时返回修改操作；文件中未填写备注时保留原备注
+func updateIfChanged(have, want DNSRecord) []restoreAction {
+	if want.Comment == "" {
+		want.Comment = have.Comment
+	}
+	if sameRecord(have, want) && have.Comment == want.Comment {
+		return nil
+	}
+	want.ID = have.ID
+	old := have
+	return []restoreAction{{Action: "update", Record: want, Old: &old}}
+}
+
+func cmdApplyMain(args []string) int {
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+
+	fs, common := newFlagSet("apply")
+	diffOnly := fs.Bool("diff", false, tr("只显示与区域中记录的差异，不修改记录"))
+	prune := fs.Bool("prune", false, tr("删除文件中未声明的记录（本程序维护的记录除外）"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	parseFlags(fs, common, args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager apply <file> [--diff] [--prune] [--yes]"))
+		return 2
+	}
+
+	spec, err := loadApplyFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := initRuntime(runtimeOptions{console: true, debugHTTP: *common.debugHTTP}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer globalLogger.Close()
+
+	config := app.Config()
+	client := app.Client()
+	zoneID := spec.ZoneID
+	if zoneID == "" {
+		zoneID = config.ZoneID
+	}
+
+	actions, err := planApplyFile(spec, config, client, zoneID, *prune)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitCodeFor(err)
+	}
+	if len(actions) == 0 {
+		fmt.Println(tr("区域中的记录与文件一致，无需修改"))
+		return 0
+	}
+	printRestorePlan(actions)
+	counts := map[string]int{}
+	for _, action := range actions {
+		counts[action.Action]++
+	}
+	fmt.Printf(tr("计划: 新建 %d 条，修改 %d 条，删除 %d 条\n"), counts["create"], counts["update"], counts["delete"])
+
+	if *diffOnly {
+		return 0
+	}
+	if !*yes && !confirm(tr("确认应用？")) {
+		return 1
+	}
+
+	client.SetAuditSource(tr("应用文件: ") + filepath.Base(file))
+	if err := applyRestore(zoneID, actions); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitCodeFor(err)
+	}
+	fmt.Println(tr("✓ 已应用"))
+	return 0
+}
+
+// planApplyFile 读取区域内的当前记录并生成 apply 计划
+func planApplyFile(spec *ApplyFile, config *Config, client *CloudflareClient, zoneID string, prune bool) ([]restoreAction, error) {
+	if zoneID == "" {
+		return nil, errors.New(tr("未指定区域：请在文件中填写 zone_id 或先完成配置"))
+	}
+	desired, err := spec.desiredRecords(client, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := managedRecordSets(config, client, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range desired {
+		if isManagedRecord(managed, record) {
+			return nil, fmt.Errorf(tr("%s %s 由本程序自动维护，不能在文件中声明"), record.Name, record.Type)
+		}
+	}
+	current, err := client.ListZoneDNSRecords(zoneID)
+	if err != nil {
+		return nil, fmt.Errorf(tr("获取DNS记录失败: %w"), err)
+	}
+	return planApply(desired, current, prune, managed), nil
+}
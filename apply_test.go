@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"mapping", "a: 1\nb: text # comment\nc: \"x # y\"\n", `{"a":1,"b":"text","c":"x # y"}`},
+		{"sequence aligned with key", "records:\n- name: www\n  ttl: 300\n- name: '@'\n", `{"records":[{"name":"www","ttl":300},{"name":"@"}]}`},
+		{"nested sequence", "records:\n  - name: a\n    proxied: true\n  - b\n", `{"records":[{"name":"a","proxied":true},"b"]}`},
+		{"scalars", "---\nip: 192.0.2.1\nempty:\nnull: ~\nquoted: 'it''s'\n", `{"empty":null,"ip":"192.0.2.1","null":null,"quoted":"it's"}`},
+		{"json", `{"a": [1]}`, `{"a": [1]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("yamlToJSON: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("yamlToJSON = %s; want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, doc := range []string{"a: 1\n  b: 2\n", "a: [1, 2]\n", "a: 1\na: 2\n", "just text\n", "a: |\n  x\n"} {
+		if got, err := yamlToJSON([]byte(doc)); err == nil {
+			t.Errorf("yamlToJSON(%q) = %s; want error", doc, got)
+		}
+	}
+}
+
+func TestApplyFile(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 1})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "vpn.example.com", Content: "198.51.100.1", TTL: 1})
+	cf.addRecord(testZoneID, DNSRecord{Type: "CNAME", Name: "www.example.com", Content: "old.example.net", TTL: 1})
+	cf.addRecord(testZoneID, DNSRecord{Type: "TXT", Name: "example.com", Content: "v=spf1 -all", TTL: 300})
+	cf.addRecord(testZoneID, DNSRecord{Type: "TXT", Name: "stale.example.com", Content: "remove me", TTL: 1})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "app.example.com", Content: "192.0.2.9", TTL: 1, Comment: dockerRecordComment})
+
+	path := filepath.Join(t.TempDir(), "records.yaml")
+	doc := `records:
+  - name: www              # 相对于区域根域名
+    type: CNAME
+    content: example.com
+    proxied: true
+  - name: "@"
+    type: TXT
+    content: "v=spf1 -all"
+    ttl: 300
+  - name: "@"
+    type: MX
+    content: mail.example.com
+`
+	if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := loadApplyFile(path)
+	if err != nil {
+		t.Fatalf("loadApplyFile: %v", err)
+	}
+
+	actions, err := planApplyFile(spec, config, app.Client(), testZoneID, true)
+	if err != nil {
+		t.Fatalf("planApplyFile: %v", err)
+	}
+	var got []string
+	for _, action := range actions {
+		got = append(got, action.Action+" "+action.Record.Type+" "+action.Record.Name)
+	}
+	want := []string{"update CNAME www.example.com", "create MX example.com", "delete TXT stale.example.com"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("plan = %v; want %v", got, want)
+	}
+
+	if err := applyRestore(testZoneID, actions); err != nil {
+		t.Fatalf("applyRestore: %v", err)
+	}
+	if actions, err := planApplyFile(spec, config, app.Client(), testZoneID, true); err != nil || len(actions) != 0 {
+		t.Fatalf("second plan = %+v, %v; want no changes", actions, err)
+	}
+	// 本程序维护的记录不受影响
+	for _, name := range []string{testRecord, "vpn.example.com", "app.example.com"} {
+		if got := cf.find(testZoneID, name, "A"); len(got) != 1 {
+			t.Errorf("%s = %v; want kept", name, got)
+		}
+	}
+	if mx := cf.find(testZoneID, "example.com", "MX"); len(mx) != 1 || mx[0].Priority == nil || *mx[0].Priority != 10 {
+		data, _ := json.Marshal(mx)
+		t.Errorf("MX records = %s; want one with priority 10", data)
+	}
+
+	// 不能声明本程序维护的记录
+	spec.Records = append(spec.Records, ApplyRecord{Name: "vpn.example.com", Type: "A", Content: "192.0.2.1"})
+	if _, err := planApplyFile(spec, config, app.Client(), testZoneID, false); err == nil {
+		t.Error("planApplyFile accepted a record maintained by the daemon")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// checksumPrefix 校验和头部前缀，头部独占文件第一行：# dns_manager sha256=<十六进制>
+const checksumPrefix = "# dns_manager sha256="
+
+// errChecksumMismatch 文件内容与校验和头部不一致（写入中断或被手动修改）
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// writeFileAtomic 原子写入文件：先写入同目录下的临时文件并 fsync，再重命名覆盖目标文件，
+// 最后 fsync 目录，保证崩溃或磁盘写满时目标文件要么是旧内容，要么是完整的新内容。
+// withChecksum 为 true 时在内容前添加 sha256 校验和头部
+func writeFileAtomic(path string, data []byte, perm os.FileMode, withChecksum bool) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if withChecksum {
+		sum := sha256.Sum256(data)
+		header := checksumPrefix + hex.EncodeToString(sum[:]) + "\n"
+		data = append([]byte(header), data...)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// 出错时清理临时文件；重命名成功后删除操作不会生效
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// 同步目录，确保重命名本身已持久化
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// readFileChecked 读取文件并校验头部中的校验和，返回去掉头部的内容。
+// 没有校验和头部的文件（旧版本写入或手动创建）原样返回；
+// 校验和不一致时同时返回内容和 errChecksumMismatch，由调用方决定如何处理
+func readFileChecked(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(checksumPrefix)) {
+		return data, nil
+	}
+
+	newline := bytes.IndexByte(data, '\n')
+	if newline < 0 {
+		return nil, fmt.Errorf("%s: %w", path, errChecksumMismatch)
+	}
+	expected := string(data[len(checksumPrefix):newline])
+	body := data[newline+1:]
+
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != expected {
+		return body, fmt.Errorf("%s: %w", path, errChecksumMismatch)
+	}
+	return body, nil
+}
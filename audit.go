@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry DNS记录变更审计条目（每行一个 JSON）
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"` // create / update / delete
+	ZoneID   string    `json:"zone_id"`
+	Record   string    `json:"record"`
+	Type     string    `json:"type"`
+	OldValue string    `json:"old_value,omitempty"`
+	NewValue string    `json:"new_value,omitempty"`
+	RecordID string    `json:"record_id,omitempty"` // Cloudflare 返回的记录ID
+	Source   string    `json:"source,omitempty"`    // 触发变更的IP来源
+	PID      int       `json:"pid"`
+}
+
+var auditMu sync.Mutex
+
+// getAuditLogPath 返回审计日志路径（位于日志目录之外，不参与日志轮转）
+func getAuditLogPath() string {
+	return filepath.Join(getDataDir(), "audit.log")
+}
+
+// writeAudit 追加一条审计记录，失败时只记录错误不影响主流程
+func writeAudit(entry AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.PID = os.Getpid()
+
+	if err := appendAudit(entry); err != nil {
+		logError("写入审计日志失败: %v", err)
+	}
+}
+
+func appendAudit(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf(tr("序列化审计记录失败: %v"), err)
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	auditPath := getAuditLogPath()
+	if err := os.MkdirAll(filepath.Dir(auditPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(auditPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
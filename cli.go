@@ -0,0 +1,981 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// subcommand 子命令定义
+type subcommand struct {
+	name    string
+	usage   string // 参数说明，如 "[--detach]"
+	summary string
+	run     func(args []string) int
+}
+
+// subcommands 返回所有子命令（按帮助中显示的顺序）
+func subcommands() []*subcommand {
+	return []*subcommand{
+		{name: "run", usage: "[--detach] [--user USER] [--group GROUP]", summary: tr("运行守护进程（默认前台运行，适合 systemd；--detach 转为后台）"), run: cmdRunMain},
+		{name: "agent", summary: tr("代理模式：检测本机公网IP并上报给控制器（本机无需 Cloudflare 令牌）"), run: cmdAgentMain},
+		{name: "once", usage: "[--dry-run] [--user USER] [--group GROUP]", summary: tr("执行一次更新后退出（适合 cron）"), run: cmdOnceMain},
+		{name: "status", summary: tr("查看守护进程状态"), run: cmdStatusMain},
+		{name: "stop", usage: "[--force]", summary: tr("停止守护进程（--force 强制终止）"), run: cmdStopMain},
+		{name: "pause", usage: "[--for 2h]", summary: tr("进入维护模式：守护进程继续检测，但暂停写入DNS记录（--for 指定时长后自动恢复）"), run: cmdPauseMain},
+		{name: "resume", summary: tr("退出维护模式，恢复写入DNS记录"), run: cmdResumeMain},
+		{name: "approve", usage: "[--ip IP]", summary: tr("确认归属异常、等待确认的新IP（geo.anomaly.confirm）"), run: cmdApproveMain},
+		{name: "info", summary: tr("查看守护进程详细信息"), run: cmdInfoMain},
+		{name: "list", summary: tr("列出所有dns_manager进程"), run: cmdListMain},
+		{name: "cleanup", summary: tr("清理无效的PID文件"), run: cmdCleanupMain},
+		{name: "logs", usage: "[-f] [-n 100]", summary: tr("查看日志文件（配合 -f 持续跟踪，-n 指定行数）"), run: cmdLogsMain},
+		{name: "manage", summary: tr("进入守护进程管理菜单"), run: cmdManageMain},
+		{name: "records", usage: "list [--type A] [--name GLOB] [--content-contains TEXT] [--sort name] [--output json] | create | delete | import", summary: tr("DNS记录管理（list 列出；create、delete 使用已配置的令牌直接创建或删除区域内的记录；import 从 CSV 或区域文件批量导入）"), run: cmdRecordsMain},
+		{name: "update", usage: "[--ip ADDR [--yes]] [--dry-run] [--output json]", summary: tr("立即检测公网IP并更新DNS记录"), run: cmdUpdateMain},
+		{name: "resolve", usage: "[record] [--type A] [--expect IP] [--public] [--output json]", summary: tr("查询权威名称服务器（及公共解析器），检查记录的传播情况"), run: cmdResolveMain},
+		{name: "audit", usage: "[--repair] [--output json]", summary: tr("比较跟随公网IP的全部记录与期望状态，报告（--repair 时修复）缺失或指向其他地址的记录"), run: cmdAuditMain},
+		{name: "dedupe", usage: "[record] [--type A] [--dry-run] [--yes] [--output json]", summary: tr("删除名称、类型与内容都相同的重复记录，每组保留一条（默认检查跟随公网IP的记录）"), run: cmdDedupeMain},
+		{name: "watch", usage: "[record] [--type A] [--interval 10s] [--count N]", summary: tr("持续显示记录的值、在权威与公共解析器上的传播情况以及本机检测到的IP（调试迁移时使用）"), run: cmdWatchMain},
+		{name: "fleet", usage: "[--output json]", summary: tr("列出成员登记中的集群成员"), run: cmdFleetMain},
+		{name: "restore-snapshot", usage: "[file] [--yes]", summary: tr("将修改前快照中的记录恢复到 Cloudflare（不指定文件时列出快照）"), run: cmdRestoreSnapshotMain},
+		{name: "import", usage: "--zone example.com [--type A] [--all] [--yes]", summary: tr("从区域中已有的 A/AAAA 记录选择要自动维护的记录，生成多记录配置"), run: cmdImportMain},
+		{name: "apply", usage: "<file> [--diff] [--prune] [--yes]", summary: tr("按 YAML 文件声明的期望状态同步区域中的记录（新建缺少的、修改不一致的，--prune 删除未声明的）"), run: cmdApplyMain},
+		{name: "notify", usage: "test [--output json]", summary: tr("向所有已配置的通知渠道发送测试通知"), run: cmdNotifyMain},
+		{name: "config", usage: "show|path|edit", summary: tr("配置管理（查看、路径、编辑）"), run: cmdConfigMain},
+		{name: "version", usage: "[--output json]", summary: tr("显示版本与构建信息"), run: cmdVersionMain},
+		{name: "help", usage: "[command]", summary: tr("显示帮助信息"), run: cmdHelpMain},
+	}
+}
+
+// findSubcommand 按名称查找子命令
+func findSubcommand(name string) *subcommand {
+	for _, cmd := range subcommands() {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// runSubcommand 执行子命令并返回退出码
+func runSubcommand(name string, args []string) int {
+	cmd := findSubcommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, tr("未知命令: %s\n\n"), name)
+		printUsage()
+		return 2
+	}
+	return cmd.run(args)
+}
+
+// printUsage 打印总体帮助
+func printUsage() {
+	fmt.Println(tr("用法: dns_manager [命令] [参数]"))
+	fmt.Println()
+	fmt.Println(tr("不带命令运行时进入交互式菜单。可用命令:"))
+	for _, cmd := range subcommands() {
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Println()
+	fmt.Println(tr("使用 'dns_manager <命令> --help' 查看命令参数。"))
+	fmt.Println(tr("旧的参数形式（--daemon、--once、--status 等）仍然可用。"))
+}
+
+// newFlagSet 创建子命令的参数集，包含通用参数 --lang 与 --debug-http
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	common := &commonFlags{
+		lang:      fs.String("lang", "", tr("输出语言: en 或 zh（默认根据 LANG 环境变量判断）")),
+		debugHTTP: fs.Bool("debug-http", false, tr("记录所有 HTTP 请求的追踪信息（方法、URL、状态码、耗时、Cf-Ray，出错时记录响应内容）")),
+	}
+	fs.Usage = func() {
+		cmd := findSubcommand(name)
+		usage := name
+		if cmd != nil {
+			usage = strings.TrimSpace(name + " " + cmd.usage)
+			fmt.Fprintf(fs.Output(), tr("用法: dns_manager %s\n\n%s\n\n参数:\n"), usage, cmd.summary)
+		}
+		fs.PrintDefaults()
+	}
+	return fs, common
+}
+
+// commonFlags 所有子命令共用的参数
+type commonFlags struct {
+	lang      *string
+	debugHTTP *bool
+}
+
+// parseFlags 解析参数并应用通用参数
+func parseFlags(fs *flag.FlagSet, common *commonFlags, args []string) {
+	fs.Parse(args)
+	initLang(*common.lang)
+}
+
+// 输出格式
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// addOutputFlag 为子命令添加 --output 参数
+func addOutputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", outputText, tr("输出格式: text 或 json（json 便于脚本处理）"))
+}
+
+// validOutput 检查输出格式是否有效
+func validOutput(output string) bool {
+	if output == outputText || output == outputJSON {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, tr("无效的输出格式: %s（可选 text 或 json）\n"), output)
+	return false
+}
+
+// printJSON 以缩进格式输出 JSON 到标准输出
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// failOutput 按输出格式报告错误并返回退出码 1；json 格式下错误同样以 JSON 输出到标准输出
+func failOutput(output string, err error) int {
+	if output == outputJSON {
+		printJSON(map[string]interface{}{
+			"success": false,
+			"error":   redactSecrets(err.Error()),
+		})
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return 1
+}
+
+// runtimeOptions 运行时初始化选项
+type runtimeOptions struct {
+	fileLog     bool
+	console     bool
+	debugHTTP   bool
+	interactive bool // 未配置时是否进入配置向导（标准输入不是终端时不进入）
+}
+
+// initRuntime 加载配置、初始化日志和客户端
+func initRuntime(opts runtimeOptions) error {
+	// 加载配置
+	config := LoadConfig()
+	app.SetConfig(config)
+
+	// 初始化日志
+	rotation := LogRotation{
+		MaxSizeMB:  config.LogMaxSizeMB,
+		MaxFiles:   config.LogMaxFiles,
+		MaxAgeDays: config.LogMaxAgeDays,
+	}
+	if err := setLogTimezone(config.LogTimezone); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if err := initLogger(opts.fileLog, opts.console, rotation); err != nil {
+		return fmt.Errorf(tr("初始化日志失败: %v"), err)
+	}
+	configureLogOutputs(config)
+
+	// HTTP 追踪需在创建客户端之前启用
+	debugHTTP = opts.debugHTTP
+	globalLogger.debug = opts.debugHTTP
+	registerAgentSecrets(config.Agents)
+
+	// 初始化重载通道
+	reloadChan = make(chan bool, 1)
+
+	if !config.isComplete() {
+		// 没有终端时配置向导会一直等待输入，直接报错退出
+		if !opts.interactive || config.NonInteractive || !stdinIsTerminal() {
+			return classify(ErrConfig, fmt.Errorf(tr("配置不完整（缺少 %s），请先运行 'dns_manager config edit' 进行配置，或编辑 %s"),
+				strings.Join(config.missingFields(), ", "), getConfigPath()))
+		}
+		logInfo("检测到未配置，请先进行配置...")
+		interactiveConfig()
+		config = LoadConfig()
+	}
+
+	// 初始化客户端
+	if err := app.ApplyConfig(config); err != nil {
+		return fmt.Errorf(tr("初始化 Cloudflare 客户端失败: %v"), err)
+	}
+
+	app.SetIPChecker(newIPCheckerFor(config))
+	initNotifiers(config.Notifications, config.notifyPolicy().EscalateTo, config.HTTP)
+	initTracing(config.Tracing)
+	initNotifyPolicy(config.notifyPolicy())
+	return nil
+}
+
+// applyLogTimezoneFromConfig 查看日志与详细信息时需要按配置的日志时区定位日志文件
+func applyLogTimezoneFromConfig() {
+	if err := setLogTimezone(LoadConfig().LogTimezone); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func cmdRunMain(args []string) int {
+	fs, common := newFlagSet("run")
+	detach := fs.Bool("detach", false, tr("转为后台守护进程运行"))
+	nonInteractive := fs.Bool("non-interactive", false, tr("配置不完整时直接报错退出，不进入配置向导"))
+	runUser := fs.String("user", "", tr("以 root 启动时，打开日志和PID文件后切换到该用户运行"))
+	runGroup := fs.String("group", "", tr("以 root 启动时切换到该用户组运行（默认使用 --user 的主用户组）"))
+	simulate := addSimulateFlag(fs)
+	parseFlags(fs, common, args)
+	if *simulate != "" && *detach {
+		fmt.Fprintln(os.Stderr, tr("--simulate-ip 只能在前台运行时使用"))
+		return 2
+	}
+	if err := applySimulateFlag(*simulate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	opts := runtimeOptions{
+		fileLog:     true,
+		console:     !*detach,
+		debugHTTP:   *common.debugHTTP,
+		interactive: !*detach && !*nonInteractive,
+	}
+	return cmdRun(opts, *detach, *runUser, *runGroup)
+}
+
+// cmdRun 运行守护进程；detach 为 true 时先转为后台进程
+func cmdRun(opts runtimeOptions, detach bool, runUser, runGroup string) int {
+	if runUser != "" {
+		useSystemDataDir()
+	}
+	if err := initRuntime(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitCodeFor(err)
+	}
+	defer globalLogger.Close()
+
+	// 由旧版本重新执行而来时已经是守护进程并已降权
+	reexeced := resumeAfterReexec()
+
+	// 如果不是守护进程，先转换为守护进程
+	if detach && os.Getppid() != 1 && !reexeced {
+		if err := daemonize(); err != nil {
+			fmt.Fprintf(os.Stderr, tr("守护进程化失败: %v\n"), err)
+			return 1
+		}
+		// daemonize 会退出父进程，这里不会执行到
+		return 0
+	}
+
+	// 降权后运行
+	if !reexeced {
+		if err := dropPrivileges(runUser, runGroup); err != nil {
+			logError("%v", err)
+			return 1
+		}
+	}
+	runDaemon()
+	return 0
+}
+
+func cmdAgentMain(args []string) int {
+	fs, common := newFlagSet("agent")
+	parseFlags(fs, common, args)
+
+	config := LoadConfig()
+	app.SetConfig(config)
+	if err := validateControllerConfig(config.Controller); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	rotation := LogRotation{
+		MaxSizeMB:  config.LogMaxSizeMB,
+		MaxFiles:   config.LogMaxFiles,
+		MaxAgeDays: config.LogMaxAgeDays,
+	}
+	if err := setLogTimezone(config.LogTimezone); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if err := initLogger(true, true, rotation); err != nil {
+		fmt.Fprintf(os.Stderr, tr("初始化日志失败: %v")+"\n", err)
+		return 1
+	}
+	configureLogOutputs(config)
+	defer globalLogger.Close()
+
+	debugHTTP = *common.debugHTTP
+	globalLogger.debug = *common.debugHTTP
+
+	app.SetIPChecker(newIPCheckerFor(config))
+	runAgent(*config.Controller)
+	return 0
+}
+
+func cmdOnceMain(args []string) int {
+	fs, common := newFlagSet("once")
+	logFile := fs.Bool("log-file", false, tr("启用日志文件（daemon 模式默认启用）"))
+	runUser := fs.String("user", "", tr("以 root 启动时，打开日志和PID文件后切换到该用户运行"))
+	runGroup := fs.String("group", "", tr("以 root 启动时切换到该用户组运行（默认使用 --user 的主用户组）"))
+	fs.BoolVar(&dryRun, "dry-run", false, tr("只显示将要执行的更改，不修改DNS记录"))
+	simulate := addSimulateFlag(fs)
+	output := fs.String("output", outputText, tr("输出格式: text 或 json（json 时不输出日志，结束后输出结构化结果）"))
+	summaryFile := fs.String("summary-file", "", tr("结束后将结构化结果（JSON）写入该文件"))
+	nonInteractive := fs.Bool("non-interactive", false, tr("配置不完整时直接报错退出，不进入配置向导"))
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if err := applySimulateFlag(*simulate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	opts := runtimeOptions{
+		fileLog:     *logFile,
+		console:     *output != outputJSON,
+		debugHTTP:   *common.debugHTTP,
+		interactive: !*nonInteractive && *output != outputJSON,
+	}
+	return cmdOnce(opts, *runUser, *runGroup, onceReport{output: *output, summaryFile: *summaryFile})
+}
+
+// onceReport once 模式结构化结果的输出方式
+type onceReport struct {
+	output      string
+	summaryFile string
+}
+
+// cmdOnce 执行一次更新后退出
+func cmdOnce(opts runtimeOptions, runUser, runGroup string, report onceReport) int {
+	start := time.Now()
+	if runUser != "" {
+		useSystemDataDir()
+	}
+	if err := initRuntime(opts); err != nil {
+		if report.output == outputJSON {
+			printJSON(lastCycle.summary(nil, start, err))
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return exitCodeFor(err)
+	}
+	defer globalLogger.Close()
+
+	var summary OnceSummary
+	if err := dropPrivileges(runUser, runGroup); err != nil {
+		logError("%v", err)
+		summary = lastCycle.summary(app.Config(), start, err)
+	} else if delegated, ok := delegateOnce(); ok {
+		summary = *delegated
+		if summary.Error != "" {
+			logError("更新失败: %s", summary.Error)
+		}
+	} else {
+		err := runOnce()
+		summary = lastCycle.summary(app.Config(), start, err)
+	}
+	// 按错误类别返回退出码，便于 cron / systemd 区分认证失败与暂时性网络错误
+	code := summary.ExitCode
+	if report.output != outputJSON && report.summaryFile == "" {
+		return code
+	}
+
+	if report.output == outputJSON {
+		printJSON(summary)
+	}
+	if report.summaryFile != "" {
+		if err := writeFileAtomic(report.summaryFile, marshalOnceSummary(summary), 0644, false); err != nil {
+			logError("写入结果文件失败: %v", err)
+			if code == 0 {
+				return 1
+			}
+		}
+	}
+	return code
+}
+
+func cmdStatusMain(args []string) int {
+	fs, common := newFlagSet("status")
+	parseFlags(fs, common, args)
+	return cmdStatus()
+}
+
+// cmdStatus 查看守护进程状态
+func cmdStatus() int {
+	pid, err := getPID()
+	if err != nil {
+		fmt.Println(tr("守护进程未运行（未找到PID文件）"))
+		return 0
+	}
+	if isProcessRunning(pid) {
+		fmt.Printf(tr("守护进程正在运行，PID: %d\n"), pid)
+		printFailureStreak(pid)
+	} else {
+		fmt.Printf(tr("守护进程未运行（PID文件存在但进程不存在，PID: %d）\n"), pid)
+		fmt.Println(tr("提示: 使用 cleanup 命令清理无效的PID文件"))
+	}
+	return 0
+}
+
+// printFailureStreak 显示守护进程连续失败的次数与降级状态（状态文件属于该进程时）
+func printFailureStreak(pid int) {
+	state, err := loadDaemonState()
+	if err != nil || state.PID != pid || state.ConsecutiveFailures == 0 {
+		return
+	}
+	fmt.Printf(tr("连续失败 %d 次（自 %s 起）: %s\n"), state.ConsecutiveFailures, state.FailingSince.In(logLocation).Format(logTimeFormat), state.LastError)
+	if !state.DegradedSince.IsZero() {
+		fmt.Printf(tr("已进入降级模式（自 %s 起），按较长间隔检测\n"), state.DegradedSince.In(logLocation).Format(logTimeFormat))
+	}
+}
+
+func cmdStopMain(args []string) int {
+	fs, common := newFlagSet("stop")
+	force := fs.Bool("force", false, tr("强制终止守护进程"))
+	parseFlags(fs, common, args)
+	if *force {
+		return cmdKill()
+	}
+	return cmdStop()
+}
+
+// cmdStop 停止守护进程
+func cmdStop() int {
+	if err := stopDaemon(); err != nil {
+		fmt.Fprintf(os.Stderr, tr("停止守护进程失败: %v\n"), err)
+		return 1
+	}
+	return 0
+}
+
+// cmdKill 强制终止守护进程
+func cmdKill() int {
+	if err := killDaemon(); err != nil {
+		fmt.Fprintf(os.Stderr, tr("强制终止守护进程失败: %v\n"), err)
+		return 1
+	}
+	return 0
+}
+
+func cmdPauseMain(args []string) int {
+	fs, common := newFlagSet("pause")
+	duration := fs.String("for", "", tr("维护模式时长（如 2h），为空时直到执行 resume"))
+	parseFlags(fs, common, args)
+
+	resp, err := sendControl(controlRequest{Command: "pause", Duration: *duration})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if resp.Maintenance.Until != nil {
+		fmt.Printf(tr("已进入维护模式，暂停写入DNS记录，将于 %s 自动恢复\n"), resp.Maintenance.Until.In(logLocation).Format(logTimeFormat))
+	} else {
+		fmt.Println(tr("已进入维护模式，暂停写入DNS记录，使用 resume 命令恢复"))
+	}
+	return 0
+}
+
+func cmdResumeMain(args []string) int {
+	fs, common := newFlagSet("resume")
+	parseFlags(fs, common, args)
+
+	if _, err := sendControl(controlRequest{Command: "resume"}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(tr("已退出维护模式，恢复写入DNS记录"))
+	return 0
+}
+
+func cmdInfoMain(args []string) int {
+	fs, common := newFlagSet("info")
+	parseFlags(fs, common, args)
+	return cmdInfo()
+}
+
+// cmdInfo 查看详细信息
+func cmdInfo() int {
+	applyLogTimezoneFromConfig()
+	info, err := getDaemonInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("获取信息失败: %v\n"), err)
+		return 1
+	}
+	printDaemonInfo(info)
+	return 0
+}
+
+func cmdListMain(args []string) int {
+	fs, common := newFlagSet("list")
+	parseFlags(fs, common, args)
+	return cmdList()
+}
+
+// cmdList 列出所有进程
+func cmdList() int {
+	processes, err := listDaemonProcesses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("列出进程失败: %v\n"), err)
+		return 1
+	}
+	if len(processes) == 0 {
+		fmt.Println(tr("未找到运行中的 dns_manager 进程"))
+	} else {
+		fmt.Printf(tr("找到 %d 个 dns_manager 进程:\n"), len(processes))
+		fmt.Println(strings.Repeat("-", 80))
+		for _, proc := range processes {
+			fmt.Printf("PID: %d\n%s\n", proc.PID, proc.Command)
+			fmt.Println(strings.Repeat("-", 80))
+		}
+	}
+	return 0
+}
+
+func cmdCleanupMain(args []string) int {
+	fs, common := newFlagSet("cleanup")
+	parseFlags(fs, common, args)
+	return cmdCleanup()
+}
+
+// cmdCleanup 清理PID文件
+func cmdCleanup() int {
+	if err := cleanupPIDFile(); err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(tr("PID文件检查完成，无需清理"))
+	}
+	return 0
+}
+
+func cmdLogsMain(args []string) int {
+	fs, common := newFlagSet("logs")
+	follow := fs.Bool("f", false, tr("持续跟踪日志输出"))
+	lines := fs.Int("n", 100, tr("显示的日志行数"))
+	parseFlags(fs, common, args)
+	return cmdLogs(*lines, *follow)
+}
+
+// cmdLogs 查看日志
+func cmdLogs(lines int, follow bool) int {
+	applyLogTimezoneFromConfig()
+	if err := tailLogs(lines, follow); err != nil {
+		fmt.Fprintf(os.Stderr, tr("查看日志失败: %v\n"), err)
+		return 1
+	}
+	return 0
+}
+
+func cmdManageMain(args []string) int {
+	fs, common := newFlagSet("manage")
+	parseFlags(fs, common, args)
+	manageDaemonMenu()
+	return 0
+}
+
+func cmdRecordsMain(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager records list|create|delete|import"))
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		fs, common := newFlagSet("records")
+		output := addOutputFlag(fs)
+		var filter recordFilter
+		fs.StringVar(&filter.Type, "type", "", tr("只列出该类型的记录"))
+		fs.StringVar(&filter.ContentContains, "content-contains", "", tr("只列出内容包含该文本的记录（不区分大小写）"))
+		fs.StringVar(&filter.Name, "name", "", tr("只列出名称匹配该通配符的记录（如 \"*.example.com\"）"))
+		fs.StringVar(&filter.Sort, "sort", "name", tr("排序字段: name、type、content 或 ttl"))
+		parseFlags(fs, common, args[1:])
+		if !validOutput(*output) {
+			return 2
+		}
+		if err := filter.validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+		if err := initRuntime(opts); err != nil {
+			return failOutput(*output, err)
+		}
+		if *output == outputJSON {
+			records, err := listFilteredRecords(filter)
+			if err != nil {
+				return failOutput(*output, err)
+			}
+			if records == nil {
+				records = []DNSRecord{}
+			}
+			printJSON(records)
+			return 0
+		}
+		if err := viewDNSRecords(filter); err != nil {
+			return 1
+		}
+		return 0
+	case "create":
+		return cmdRecordsCreate(args[1:])
+	case "delete":
+		return cmdRecordsDelete(args[1:])
+	case "import":
+		return cmdRecordsImport(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, tr("未知命令: %s\n\n"), "records "+args[0])
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager records list|create|delete|import"))
+		return 2
+	}
+}
+
+func cmdUpdateMain(args []string) int {
+	fs, common := newFlagSet("update")
+	output := addOutputFlag(fs)
+	fs.BoolVar(&dryRun, "dry-run", false, tr("只显示将要执行的更改，不修改DNS记录"))
+	manualIP := fs.String("ip", "", tr("跳过检测，将记录更新为指定的IP（检测结果有误或预先切换到计划中的新地址时使用）"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	simulate := addSimulateFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if err := applySimulateFlag(*simulate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *manualIP != "" && *simulate != "" {
+		fmt.Fprintln(os.Stderr, tr("--ip 与 --simulate-ip 不能同时使用"))
+		return 2
+	}
+	// 手动指定的IP未经检测确认，写入前要求确认；JSON 输出无法交互，需要 --yes
+	confirmChanges := *manualIP != "" && !*yes && !dryRun
+	if confirmChanges && *output == outputJSON {
+		return failOutput(*output, errors.New(tr("--ip 与 --output json 一起使用时需要 --yes")))
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+	if *manualIP != "" {
+		if err := validateRecordContent(app.Config().RecordType, *manualIP); err != nil {
+			return failOutput(*output, err)
+		}
+	}
+	if *output == outputJSON {
+		result := updateDNSResult(*manualIP)
+		printJSON(result)
+		if !result.Success {
+			return 1
+		}
+		return 0
+	}
+	if err := updateDNSNow(confirmChanges, *manualIP); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func cmdResolveMain(args []string) int {
+	// 记录名称可以写在参数之前
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	fs, common := newFlagSet("resolve")
+	recordType := fs.String("type", "", tr("记录类型（默认使用配置中的记录类型）"))
+	expect := fs.String("expect", "", tr("期望的解析结果，多个值用逗号分隔（默认使用 Cloudflare 中的记录值）"))
+	public := fs.Bool("public", false, tr("同时查询公共解析器 1.1.1.1 与 8.8.8.8"))
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+	if name == "" {
+		name = app.Config().RecordName
+	}
+	if client := app.Client(); client != nil {
+		resolved, err := client.recordName(app.Config().ZoneID, name)
+		if err != nil {
+			return failOutput(*output, err)
+		}
+		name = resolved
+	}
+	if *recordType == "" {
+		*recordType = app.Config().RecordType
+	}
+	*recordType = strings.ToUpper(*recordType)
+
+	var expected []string
+	proxied := false
+	if *expect != "" {
+		for _, ip := range strings.Split(*expect, ",") {
+			expected = append(expected, strings.TrimSpace(ip))
+		}
+		sort.Strings(expected)
+	} else {
+		var err error
+		expected, proxied, err = expectedAnswers(name, *recordType)
+		if err != nil {
+			return failOutput(*output, err)
+		}
+	}
+
+	results, err := resolveRecord(name, *recordType, expected, proxied, *public)
+	if err != nil {
+		return failOutput(*output, err)
+	}
+
+	propagated := true
+	for _, result := range results {
+		if result.Authoritative && result.Status != propagationOK && result.Status != propagationProxied {
+			propagated = false
+		}
+	}
+
+	if *output == outputJSON {
+		printJSON(map[string]interface{}{
+			"record":     name,
+			"type":       *recordType,
+			"expected":   expected,
+			"proxied":    proxied,
+			"propagated": propagated,
+			"resolvers":  results,
+		})
+	} else {
+		printResolverResults(name, *recordType, expected, results)
+		if proxied {
+			fmt.Println(tr("提示: 记录已开启 Cloudflare 代理，解析结果为 Cloudflare 的地址"))
+		}
+		if propagated {
+			fmt.Println(tr("✓ 所有权威名称服务器均已返回期望值"))
+		} else {
+			fmt.Println(tr("✗ 部分权威名称服务器尚未返回期望值"))
+		}
+	}
+
+	if !propagated {
+		return 1
+	}
+	return 0
+}
+
+func cmdFleetMain(args []string) int {
+	fs, common := newFlagSet("fleet")
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+	config := app.Config()
+	if config.Fleet == nil {
+		return failOutput(*output, errors.New(tr("未配置成员登记（fleet）")))
+	}
+
+	members, err := listFleetMembers(config, app.Client())
+	if err != nil {
+		return failOutput(*output, err)
+	}
+	if *output == outputJSON {
+		printJSON(members)
+		return 0
+	}
+	printFleetMembers(members, config.fleetNode())
+	return 0
+}
+
+func cmdRestoreSnapshotMain(args []string) int {
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+
+	fs, common := newFlagSet("restore-snapshot")
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	parseFlags(fs, common, args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+
+	if file == "" {
+		files, err := listSnapshots()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, tr("读取快照失败: %v")+"\n", err)
+			return 1
+		}
+		if len(files) == 0 {
+			fmt.Println(tr("没有可用的快照"))
+			return 0
+		}
+		fmt.Printf(tr("快照目录: %s\n"), getSnapshotDir())
+		for _, f := range files {
+			fmt.Println("  " + f)
+		}
+		return 0
+	}
+
+	snapshot, err := loadSnapshot(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := initRuntime(runtimeOptions{console: true, debugHTTP: *common.debugHTTP}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer globalLogger.Close()
+
+	current, err := app.Client().ListDNSRecords(snapshot.ZoneID, snapshot.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ 获取失败: %v\n"), err)
+		return 1
+	}
+
+	actions := planRestore(snapshot.Records, current)
+	fmt.Printf(tr("快照: %s（%s，%d 条记录）\n"), snapshot.Name, snapshot.Time.Format(logTimeFormat), len(snapshot.Records))
+	if len(actions) == 0 {
+		fmt.Println(tr("当前记录与快照一致，无需恢复"))
+		return 0
+	}
+	printRestorePlan(actions)
+
+	if !*yes && !confirm(tr("确认恢复？")) {
+		return 1
+	}
+
+	app.Client().SetAuditSource(tr("恢复快照: ") + filepath.Base(file))
+	if err := applyRestore(snapshot.ZoneID, actions); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Println(tr("✓ 快照已恢复"))
+	return 0
+}
+
+func cmdNotifyMain(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			fmt.Fprintf(os.Stderr, tr("未知命令: %s\n\n"), "notify "+args[0])
+		}
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager notify test"))
+		return 2
+	}
+
+	fs, common := newFlagSet("notify")
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args[1:])
+	if !validOutput(*output) {
+		return 2
+	}
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+
+	config := app.Config()
+	if *output == outputJSON {
+		results := testNotifications(config.Notifications)
+		printJSON(results)
+		for _, result := range results {
+			if result.Error != "" {
+				return 1
+			}
+		}
+		return 0
+	}
+	if !runNotifyTest(config.Notifications) {
+		return 1
+	}
+	return 0
+}
+
+// runNotifyTest 发送测试通知并逐个显示结果，全部成功时返回 true
+func runNotifyTest(cfgs []NotificationConfig) bool {
+	if len(cfgs) == 0 {
+		fmt.Println(tr("未配置通知渠道（配置文件中的 notifications）"))
+		return false
+	}
+	fmt.Printf(tr("正在向 %d 个通知渠道发送测试通知...\n"), len(cfgs))
+	ok := true
+	for _, result := range testNotifications(cfgs) {
+		if result.Error != "" {
+			ok = false
+			fmt.Printf("❌ %s (%s): %s\n", result.Channel, result.Type, result.Error)
+		} else {
+			fmt.Printf("✓ %s (%s)\n", result.Channel, result.Type)
+		}
+	}
+	return ok
+}
+
+func cmdConfigMain(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager config show|path|edit"))
+		return 2
+	}
+
+	fs, common := newFlagSet("config")
+	parseFlags(fs, common, args[1:])
+
+	switch args[0] {
+	case "path":
+		fmt.Println(getConfigPath())
+		return 0
+	case "show":
+		return cmdConfigShow()
+	case "edit":
+		interactiveConfig()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, tr("未知命令: %s\n\n"), "config "+args[0])
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager config show|path|edit"))
+		return 2
+	}
+}
+
+// cmdConfigShow 输出当前配置（敏感值已屏蔽）
+func cmdConfigShow() int {
+	cfg := LoadConfig()
+	data, err := json.Marshal(cfg)
+	if err == nil {
+		data, err = redactJSON(data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("序列化配置失败: %v")+"\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+func cmdHelpMain(args []string) int {
+	if len(args) > 0 {
+		if cmd := findSubcommand(args[0]); cmd != nil && cmd.name != "help" {
+			return cmd.run([]string{"--help"})
+		}
+	}
+	printUsage()
+	return 0
+}
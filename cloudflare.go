@@ -3,25 +3,44 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"strings"
+	"sync"
 )
 
 type CloudflareClient struct {
 	apiToken string
 	client   *http.Client
 	baseURL  string
+
+	// auditSource 记录在审计日志中的变更来源（如IP检测服务）；多个记录并行同步时共用，读写需加锁
+	auditMu     sync.Mutex
+	auditSource string
+
+	// snapshotted 本进程中已保存修改前快照的记录名称
+	snapshotMu  sync.Mutex
+	snapshotted map[string]bool
+
+	// zoneNames 区域ID到区域名称的缓存，用于转换 "@"
+	zoneMu    sync.Mutex
+	zoneNames map[string]string
+
+	// listCache 记录列表的短时缓存，为空则不缓存
+	listCache *recordListCache
 }
 
 type DNSRecord struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	TTL     int    `json:"ttl"`
-	Proxied bool   `json:"proxied"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Proxied  bool   `json:"proxied"`
+	Priority *int   `json:"priority,omitempty"`
+	Comment  string `json:"comment,omitempty"`
 }
 
 type DNSRecordResponse struct {
@@ -31,6 +50,10 @@ type DNSRecordResponse struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
 	} `json:"errors"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
 }
 
 type DNSRecordUpdateRequest struct {
@@ -41,27 +64,33 @@ type DNSRecordUpdateRequest struct {
 }
 
 type DNSRecordCreateRequest struct {
-	Type    string `json:"type"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	TTL     int    `json:"ttl"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Proxied  bool   `json:"proxied,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+	Comment  string `json:"comment,omitempty"`
 }
 
 func NewCloudflareClient(apiToken string) (*CloudflareClient, error) {
 	if apiToken == "" {
-		return nil, fmt.Errorf("API Token 不能为空")
+		return nil, errors.New(tr("API Token 不能为空"))
 	}
 
+	// 注册令牌，确保其不会出现在日志和错误信息中
+	registerSecret(apiToken)
+
 	return &CloudflareClient{
 		apiToken: apiToken,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: "https://api.cloudflare.com/client/v4",
+		client:   newCloudflareHTTPClient(nil),
+		baseURL:  apiBaseURL(),
 	}, nil
 }
 
 func (c *CloudflareClient) makeRequest(method, endpoint string, body io.Reader) (*http.Response, error) {
+	c.listCache.invalidateForRequest(method, endpoint)
+
 	url := c.baseURL + endpoint
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
@@ -73,50 +102,108 @@ func (c *CloudflareClient) makeRequest(method, endpoint string, body io.Reader)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
+		err = classify(ErrNetwork, redactError(err))
+		health.markAPIResult(err)
 		return nil, err
 	}
 
+	// 记录API调用结果，供就绪检查使用
+	if resp.StatusCode >= 400 {
+		health.markAPIResult(fmt.Errorf(tr("状态码: %d"), resp.StatusCode))
+	} else {
+		health.markAPIResult(nil)
+	}
+
 	return resp, nil
 }
 
-func (c *CloudflareClient) ListDNSRecords(zoneID, recordName string) ([]DNSRecord, error) {
-	endpoint := fmt.Sprintf("/zones/%s/dns_records?name=%s", zoneID, recordName)
-	resp, err := c.makeRequest("GET", endpoint, nil)
+// SetAuditSource 设置后续变更在审计日志中记录的触发来源
+func (c *CloudflareClient) SetAuditSource(source string) {
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+	c.auditSource = source
+}
+
+// getAuditSource 返回当前的变更来源
+func (c *CloudflareClient) getAuditSource() string {
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+	return c.auditSource
+}
+
+// apiStatusError 根据非 200 响应构造错误，响应内容中的敏感信息会被屏蔽；
+// 错误附带 Cf-Ray 与限流响应头，便于向 Cloudflare 支持引用具体请求
+func apiStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	err := fmt.Errorf(tr("API 返回错误 (状态码: %d): %s"), resp.StatusCode, redactSecrets(string(body)))
+	return withResponseMeta(resp, classify(statusKind(resp.StatusCode), err))
+}
+
+// apexName 记录名称中表示区域根域名的写法
+const apexName = "@"
+
+// recordName 将 "@" 转换为区域名称："@.xxx" 形式的后缀同样转换（如 _dns_manager_lease.@）。
+// Cloudflare 按完整名称查询记录，"@" 不会匹配任何记录；区域名称按区域缓存
+func (c *CloudflareClient) recordName(zoneID, name string) (string, error) {
+	if name != apexName && !strings.HasSuffix(name, "."+apexName) {
+		return name, nil
+	}
+
+	zoneName, err := c.zoneName(zoneID)
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	return strings.TrimSuffix(name, apexName) + zoneName, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API 返回错误 (状态码: %d): %s", resp.StatusCode, string(body))
+// zoneName 返回区域名称，结果按区域ID缓存
+func (c *CloudflareClient) zoneName(zoneID string) (string, error) {
+	c.zoneMu.Lock()
+	zoneName := c.zoneNames[zoneID]
+	c.zoneMu.Unlock()
+	if zoneName != "" {
+		return zoneName, nil
 	}
 
-	var result DNSRecordResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+	zone, err := c.GetZone(zoneID)
+	if err != nil {
+		return "", fmt.Errorf(tr("获取区域名称失败: %w"), err)
 	}
+	c.zoneMu.Lock()
+	if c.zoneNames == nil {
+		c.zoneNames = make(map[string]string)
+	}
+	c.zoneNames[zoneID] = zone.Name
+	c.zoneMu.Unlock()
+	return zone.Name, nil
+}
 
-	if !result.Success {
-		var errorMsg string
-		for _, e := range result.Errors {
-			errorMsg += fmt.Sprintf("Code %d: %s; ", e.Code, e.Message)
-		}
-		return nil, fmt.Errorf("API 错误: %s", errorMsg)
+func (c *CloudflareClient) ListDNSRecords(zoneID, recordName string) ([]DNSRecord, error) {
+	recordName, err := c.recordName(zoneID, recordName)
+	if err != nil {
+		return nil, err
 	}
 
-	return result.Result, nil
+	if records, ok := c.listCache.get(zoneID, recordName); ok {
+		return records, nil
+	}
+	records, err := c.listDNSRecordPages(fmt.Sprintf("/zones/%s/dns_records?name=%s", zoneID, recordName))
+	if err != nil {
+		return nil, err
+	}
+	c.listCache.put(zoneID, recordName, records)
+	return records, nil
 }
 
 func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, content string) error {
 	// 首先查找现有的记录
 	records, err := c.ListDNSRecords(zoneID, recordName)
 	if err != nil {
-		return fmt.Errorf("查找DNS记录失败: %v", err)
+		return fmt.Errorf(tr("查找DNS记录失败: %w"), err)
 	}
 
 	if len(records) == 0 {
-		return fmt.Errorf("未找到匹配的DNS记录: %s", recordName)
+		return classify(ErrRecordNotFound, fmt.Errorf(tr("未找到匹配的DNS记录: %s"), recordName))
 	}
 
 	// 查找匹配类型的记录
@@ -129,7 +216,7 @@ func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, conte
 	}
 
 	if targetRecord == nil {
-		return fmt.Errorf("未找到类型为 %s 的DNS记录", recordType)
+		return classify(ErrRecordNotFound, fmt.Errorf(tr("未找到类型为 %s 的DNS记录"), recordType))
 	}
 
 	// 如果内容相同，跳过更新
@@ -137,6 +224,10 @@ func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, conte
 		return nil
 	}
 
+	if err := c.snapshotBeforeMutation(zoneID, recordName); err != nil {
+		return err
+	}
+
 	// 更新记录（使用乐观锁：先读取再更新）
 	endpoint := fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, targetRecord.ID)
 	
@@ -149,18 +240,17 @@ func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, conte
 
 	jsonData, err := json.Marshal(updateReq)
 	if err != nil {
-		return fmt.Errorf("序列化请求失败: %v", err)
+		return fmt.Errorf(tr("序列化请求失败: %v"), err)
 	}
 
 	resp, err := c.makeRequest("PUT", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("请求失败: %v", err)
+		return fmt.Errorf(tr("请求失败: %w"), err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API 返回错误 (状态码: %d): %s", resp.StatusCode, string(body))
+		return apiStatusError(resp)
 	}
 
 	var result struct {
@@ -178,20 +268,27 @@ func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, conte
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("解析响应失败: %v", err)
+		return fmt.Errorf(tr("解析响应失败: %v"), err)
 	}
 
 	if !result.Success {
-		var errorMsg string
-		for _, e := range result.Errors {
-			errorMsg += fmt.Sprintf("Code %d: %s; ", e.Code, e.Message)
-		}
-		return fmt.Errorf("API 错误: %s", errorMsg)
+		return apiResultError(result.Errors)
 	}
 
+	writeAudit(AuditEntry{
+		Action:   "update",
+		ZoneID:   zoneID,
+		Record:   recordName,
+		Type:     recordType,
+		OldValue: targetRecord.Content,
+		NewValue: result.Result.Content,
+		RecordID: result.Result.ID,
+		Source:   c.getAuditSource(),
+	})
+
 	// 验证更新后的值是否正确
 	if result.Result.Content != content {
-		return fmt.Errorf("DNS记录更新后内容不匹配: 期望 %s，实际 %s", content, result.Result.Content)
+		return fmt.Errorf(tr("DNS记录更新后内容不匹配: 期望 %s，实际 %s"), content, result.Result.Content)
 	}
 
 	return nil
@@ -201,7 +298,7 @@ func (c *CloudflareClient) UpdateDNSRecord(zoneID, recordName, recordType, conte
 func (c *CloudflareClient) GetCurrentDNSRecord(zoneID, recordName, recordType string) (string, error) {
 	records, err := c.ListDNSRecords(zoneID, recordName)
 	if err != nil {
-		return "", fmt.Errorf("查找DNS记录失败: %v", err)
+		return "", fmt.Errorf(tr("查找DNS记录失败: %w"), err)
 	}
 
 	for _, record := range records {
@@ -210,14 +307,14 @@ func (c *CloudflareClient) GetCurrentDNSRecord(zoneID, recordName, recordType st
 		}
 	}
 
-	return "", fmt.Errorf("未找到类型为 %s 的DNS记录", recordType)
+	return "", classify(ErrRecordNotFound, fmt.Errorf(tr("未找到类型为 %s 的DNS记录"), recordType))
 }
 
 // GetAllDNSRecords 获取所有匹配的DNS记录
 func (c *CloudflareClient) GetAllDNSRecords(zoneID, recordName, recordType string) ([]DNSRecord, error) {
 	records, err := c.ListDNSRecords(zoneID, recordName)
 	if err != nil {
-		return nil, fmt.Errorf("查找DNS记录失败: %v", err)
+		return nil, fmt.Errorf(tr("查找DNS记录失败: %w"), err)
 	}
 
 	var matchedRecords []DNSRecord
@@ -254,29 +351,41 @@ func (c *CloudflareClient) FindOrCreateDNSRecord(zoneID, recordName, recordType,
 
 // CreateDNSRecord 创建新的DNS记录
 func (c *CloudflareClient) CreateDNSRecord(zoneID, recordName, recordType, content string, ttl int) (*DNSRecord, error) {
-	endpoint := fmt.Sprintf("/zones/%s/dns_records", zoneID)
-	
-	createReq := DNSRecordCreateRequest{
+	return c.CreateDNSRecordWithOptions(zoneID, DNSRecordCreateRequest{
 		Type:    recordType,
 		Name:    recordName,
 		Content: content,
 		TTL:     ttl,
+	})
+}
+
+// CreateDNSRecordWithOptions 按完整参数创建DNS记录（支持代理状态与优先级）
+func (c *CloudflareClient) CreateDNSRecordWithOptions(zoneID string, createReq DNSRecordCreateRequest) (*DNSRecord, error) {
+	name, err := c.recordName(zoneID, createReq.Name)
+	if err != nil {
+		return nil, err
 	}
+	createReq.Name = name
+
+	if err := c.snapshotBeforeMutation(zoneID, createReq.Name); err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/zones/%s/dns_records", zoneID)
 
 	jsonData, err := json.Marshal(createReq)
 	if err != nil {
-		return nil, fmt.Errorf("序列化请求失败: %v", err)
+		return nil, fmt.Errorf(tr("序列化请求失败: %v"), err)
 	}
 
 	resp, err := c.makeRequest("POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return nil, fmt.Errorf(tr("请求失败: %w"), err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API 返回错误 (状态码: %d): %s", resp.StatusCode, string(body))
+		return nil, apiStatusError(resp)
 	}
 
 	var result struct {
@@ -289,51 +398,225 @@ func (c *CloudflareClient) CreateDNSRecord(zoneID, recordName, recordType, conte
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
 	}
 
 	if !result.Success {
-		var errorMsg string
-		for _, e := range result.Errors {
-			errorMsg += fmt.Sprintf("Code %d: %s; ", e.Code, e.Message)
+		return nil, apiResultError(result.Errors)
+	}
+
+	writeAudit(AuditEntry{
+		Action:   "create",
+		ZoneID:   zoneID,
+		Record:   createReq.Name,
+		Type:     createReq.Type,
+		NewValue: result.Result.Content,
+		RecordID: result.Result.ID,
+		Source:   c.getAuditSource(),
+	})
+
+	return &result.Result, nil
+}
+
+// ListZoneDNSRecords 获取区域内的全部DNS记录（自动翻页）
+func (c *CloudflareClient) ListZoneDNSRecords(zoneID string) ([]DNSRecord, error) {
+	return c.listDNSRecordPages(fmt.Sprintf("/zones/%s/dns_records?", zoneID))
+}
+
+// listDNSRecordPages 按页读取记录列表直到最后一页；endpoint 为带查询参数的列表地址
+func (c *CloudflareClient) listDNSRecordPages(endpoint string) ([]DNSRecord, error) {
+	if !strings.HasSuffix(endpoint, "?") {
+		endpoint += "&"
+	}
+	var all []DNSRecord
+	for page := 1; ; page++ {
+		resp, err := c.makeRequest("GET", fmt.Sprintf("%sper_page=100&page=%d", endpoint, page), nil)
+		if err != nil {
+			return nil, fmt.Errorf(tr("请求失败: %w"), err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := apiStatusError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result DNSRecordResponse
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+		}
+
+		if !result.Success {
+			return nil, apiResultError(result.Errors)
+		}
+
+		all = append(all, result.Result...)
+		if page >= result.ResultInfo.TotalPages {
+			return all, nil
 		}
-		return nil, fmt.Errorf("API 错误: %s", errorMsg)
 	}
+}
+
+// EditDNSRecord 按记录ID修改记录内容、TTL、代理状态与优先级
+func (c *CloudflareClient) EditDNSRecord(zoneID string, old DNSRecord, updated DNSRecordCreateRequest) (*DNSRecord, error) {
+	if err := c.snapshotBeforeMutation(zoneID, old.Name); err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, old.ID)
+
+	jsonData, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	resp, err := c.makeRequest("PUT", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %w"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool      `json:"success"`
+		Result  DNSRecord `json:"result"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+
+	if !result.Success {
+		return nil, apiResultError(result.Errors)
+	}
+
+	writeAudit(AuditEntry{
+		Action:   "update",
+		ZoneID:   zoneID,
+		Record:   result.Result.Name,
+		Type:     result.Result.Type,
+		OldValue: old.Content,
+		NewValue: result.Result.Content,
+		RecordID: result.Result.ID,
+		Source:   c.getAuditSource(),
+	})
 
 	return &result.Result, nil
 }
 
-// UpdateOrCreateDNSRecord 更新或创建DNS记录（支持多机器场景）
-// 优先查找指向本机IP的记录，如果不存在则创建新记录
-// oldIP: 旧的IP地址，如果提供，会尝试更新指向旧IP的记录
-func (c *CloudflareClient) UpdateOrCreateDNSRecord(zoneID, recordName, recordType, content string, ttl int, oldIP string) error {
-	// 获取所有匹配的记录
-	records, err := c.GetAllDNSRecords(zoneID, recordName, recordType)
-	if err != nil || len(records) == 0 {
-		// 如果没有找到任何记录，创建新记录
-		_, err := c.CreateDNSRecord(zoneID, recordName, recordType, content, ttl)
+// DeleteDNSRecord 删除DNS记录
+func (c *CloudflareClient) DeleteDNSRecord(zoneID string, record DNSRecord) error {
+	if err := c.snapshotBeforeMutation(zoneID, record.Name); err != nil {
 		return err
 	}
 
-	// 查找是否已有指向本机IP的记录
-	for _, record := range records {
-		if record.Content == content {
-			// 找到指向本机IP的记录，内容已匹配，无需更新
-			return nil
-		}
+	endpoint := fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, record.ID)
+	resp, err := c.makeRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %w"), err)
 	}
+	defer resp.Body.Close()
 
-	// 如果没有找到指向本机IP的记录，但有旧IP，尝试更新指向旧IP的记录
-	if oldIP != "" {
-		for _, record := range records {
-			if record.Content == oldIP {
-				// 找到指向旧IP的记录，更新它
-				return c.UpdateDNSRecord(zoneID, recordName, recordType, content)
-			}
-		}
+	if resp.StatusCode != http.StatusOK {
+		return apiStatusError(resp)
 	}
 
-	// 没有找到指向本机IP或旧IP的记录，创建新记录（支持多机器）
-	_, err = c.CreateDNSRecord(zoneID, recordName, recordType, content, ttl)
-	return err
+	writeAudit(AuditEntry{
+		Action:   "delete",
+		ZoneID:   zoneID,
+		Record:   record.Name,
+		Type:     record.Type,
+		OldValue: record.Content,
+		RecordID: record.ID,
+		Source:   c.getAuditSource(),
+	})
+
+	return nil
+}
+
+// Zone 区域信息
+type Zone struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	NameServers []string `json:"name_servers"`
+}
+
+// GetZone 获取区域信息（域名与分配的权威名称服务器）
+func (c *CloudflareClient) GetZone(zoneID string) (*Zone, error) {
+	resp, err := c.makeRequest("GET", "/zones/"+zoneID, nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %w"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+		Result  Zone `json:"result"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+
+	if !result.Success {
+		return nil, apiResultError(result.Errors)
+	}
+
+	return &result.Result, nil
+}
+
+// TokenStatus 令牌校验结果
+type TokenStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	ExpiresOn string `json:"expires_on,omitempty"`
+}
+
+// VerifyToken 校验 API 令牌是否有效（不检查权限范围）
+func (c *CloudflareClient) VerifyToken() (*TokenStatus, error) {
+	resp, err := c.makeRequest("GET", "/user/tokens/verify", nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %w"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool        `json:"success"`
+		Result  TokenStatus `json:"result"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+
+	if !result.Success {
+		return nil, apiResultError(result.Errors)
+	}
+
+	return &result.Result, nil
 }
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeToken 模拟服务器接受的 API 令牌
+const fakeToken = "test-token"
+
+// fakeCloudflare 基于 httptest 的 Cloudflare API 模拟服务器：
+// 支持区域查询、记录列表（分页）、创建、修改、删除与批量请求，并可注入错误与限流
+type fakeCloudflare struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	zones    map[string]string      // 区域ID -> 区域名称
+	records  map[string][]DNSRecord // 区域ID -> 记录
+	nextID   int
+	pageSize int // 每页最多返回的记录数，模拟 per_page 上限
+	failures []*fakeFailure
+	requests []string // 收到的请求，格式 "METHOD /path"
+}
+
+// fakeFailure 注入的错误：匹配 method 与路径片段的请求返回 status，共 times 次
+type fakeFailure struct {
+	method string
+	path   string
+	status int
+	code   int
+	times  int
+}
+
+// cfError Cloudflare 错误响应中的一项
+type cfError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newFakeCloudflare 启动模拟服务器，测试结束时自动关闭
+func newFakeCloudflare(t *testing.T) *fakeCloudflare {
+	t.Helper()
+	f := &fakeCloudflare{
+		t:        t,
+		zones:    map[string]string{},
+		records:  map[string][]DNSRecord{},
+		pageSize: 100,
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client 返回指向模拟服务器的客户端
+func (f *fakeCloudflare) client() *CloudflareClient {
+	f.t.Helper()
+	c, err := NewCloudflareClient(fakeToken)
+	if err != nil {
+		f.t.Fatalf("NewCloudflareClient: %v", err)
+	}
+	c.baseURL = f.server.URL
+	return c
+}
+
+// addZone 添加区域
+func (f *fakeCloudflare) addZone(id, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.zones[id] = name
+}
+
+// addRecord 直接写入一条记录（不经过 API），返回带ID的记录
+func (f *fakeCloudflare) addRecord(zoneID string, record DNSRecord) DNSRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	record.ID = fmt.Sprintf("rec%03d", f.nextID)
+	if record.TTL == 0 {
+		record.TTL = 1
+	}
+	f.records[zoneID] = append(f.records[zoneID], record)
+	return record
+}
+
+// find 返回区域中名称与类型匹配的记录（按ID排序）
+func (f *fakeCloudflare) find(zoneID, name, recordType string) []DNSRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []DNSRecord
+	for _, r := range f.records[zoneID] {
+		if strings.EqualFold(r.Name, name) && (recordType == "" || r.Type == recordType) {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// contents 返回匹配记录的内容（排序后）
+func (f *fakeCloudflare) contents(zoneID, name, recordType string) []string {
+	var result []string
+	for _, r := range f.find(zoneID, name, recordType) {
+		result = append(result, r.Content)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// failNext 让接下来 times 个匹配 method 与路径片段的请求返回 status
+func (f *fakeCloudflare) failNext(method, path string, status, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, &fakeFailure{method: method, path: path, status: status, code: 10000 + status, times: times})
+}
+
+// rateLimit 让接下来 times 个请求返回 429（Cloudflare 错误码 971）
+func (f *fakeCloudflare) rateLimit(times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, &fakeFailure{status: http.StatusTooManyRequests, code: 971, times: times})
+}
+
+// count 返回收到的 method 请求数量
+func (f *fakeCloudflare) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, method+" ") {
+			n++
+		}
+	}
+	return n
+}
+
+// writes 返回收到的修改请求数量
+func (f *fakeCloudflare) writes() int {
+	return f.count("POST") + f.count("PUT") + f.count("PATCH") + f.count("DELETE")
+}
+
+func (f *fakeCloudflare) reply(w http.ResponseWriter, status int, result interface{}, extra map[string]interface{}, errs ...cfError) {
+	body := map[string]interface{}{
+		"success":  len(errs) == 0,
+		"errors":   errs,
+		"messages": []string{},
+		"result":   result,
+	}
+	if errs == nil {
+		body["errors"] = []cfError{}
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeCloudflare) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	// 与真实 API 一样为每个请求返回唯一的 Cf-Ray
+	w.Header().Set("Cf-Ray", fmt.Sprintf("%016x-TST", len(f.requests)))
+	var injected *fakeFailure
+	for _, failure := range f.failures {
+		if failure.times > 0 && (failure.method == "" || failure.method == r.Method) && strings.Contains(r.URL.Path, failure.path) {
+			failure.times--
+			injected = failure
+			break
+		}
+	}
+	f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+		f.reply(w, http.StatusForbidden, nil, nil, cfError{Code: 10000, Message: "Authentication error"})
+		return
+	}
+	if injected != nil {
+		if injected.status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Ratelimit", `"default";r=0;t=1`)
+		}
+		f.reply(w, injected.status, nil, nil, cfError{Code: injected.code, Message: http.StatusText(injected.status)})
+		return
+	}
+
+	// /zones/{zone}[/dns_records[/{id}|/batch]]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.URL.Path == "/user/tokens/verify" && r.Method == "GET" {
+		f.reply(w, http.StatusOK, TokenStatus{ID: "token1", Status: "active"}, nil)
+		return
+	}
+	if len(parts) == 1 && parts[0] == "zones" && r.Method == "GET" {
+		f.handleZones(w, r)
+		return
+	}
+	if len(parts) < 2 || parts[0] != "zones" {
+		f.reply(w, http.StatusNotFound, nil, nil, cfError{Code: 7003, Message: "Could not route to " + r.URL.Path})
+		return
+	}
+	zoneID := parts[1]
+
+	f.mu.Lock()
+	zoneName, ok := f.zones[zoneID]
+	f.mu.Unlock()
+	if !ok {
+		f.reply(w, http.StatusNotFound, nil, nil, cfError{Code: 1001, Message: "Invalid zone identifier"})
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == "GET":
+		f.reply(w, http.StatusOK, Zone{ID: zoneID, Name: zoneName, NameServers: []string{"ns1.example.net", "ns2.example.net"}}, nil)
+	case len(parts) == 3 && parts[2] == "dns_records" && r.Method == "GET":
+		f.handleList(w, r, zoneID)
+	case len(parts) == 3 && parts[2] == "dns_records" && r.Method == "POST":
+		f.handleCreate(w, r, zoneID)
+	case len(parts) == 4 && parts[3] == "batch" && r.Method == "POST":
+		f.handleBatch(w, r, zoneID)
+	case len(parts) == 4 && r.Method == "PUT":
+		f.handleUpdate(w, r, zoneID, parts[3])
+	case len(parts) == 4 && r.Method == "DELETE":
+		f.handleDelete(w, zoneID, parts[3])
+	default:
+		f.reply(w, http.StatusMethodNotAllowed, nil, nil, cfError{Code: 10405, Message: "Method not allowed"})
+	}
+}
+
+// handleZones 按 name 参数查找区域
+func (f *fakeCloudflare) handleZones(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	f.mu.Lock()
+	result := []Zone{}
+	for id, zoneName := range f.zones {
+		if name == "" || strings.EqualFold(zoneName, name) {
+			result = append(result, Zone{ID: id, Name: zoneName})
+		}
+	}
+	f.mu.Unlock()
+	f.reply(w, http.StatusOK, result, nil)
+}
+
+func (f *fakeCloudflare) handleList(w http.ResponseWriter, r *http.Request, zoneID string) {
+	query := r.URL.Query()
+	name, recordType := query.Get("name"), query.Get("type")
+
+	f.mu.Lock()
+	var matched []DNSRecord
+	for _, record := range f.records[zoneID] {
+		if (name == "" || strings.EqualFold(record.Name, name)) && (recordType == "" || record.Type == recordType) {
+			matched = append(matched, record)
+		}
+	}
+	pageSize := f.pageSize
+	f.mu.Unlock()
+
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if perPage <= 0 || perPage > pageSize {
+		perPage = pageSize
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	totalPages := (len(matched) + perPage - 1) / perPage
+
+	start := (page - 1) * perPage
+	end := start + perPage
+	if start > len(matched) {
+		start = len(matched)
+	}
+	if end > len(matched) {
+		end = len(matched)
+	}
+	result := matched[start:end]
+	if result == nil {
+		result = []DNSRecord{}
+	}
+	f.reply(w, http.StatusOK, result, map[string]interface{}{
+		"result_info": map[string]int{"page": page, "per_page": perPage, "count": len(result), "total_count": len(matched), "total_pages": totalPages},
+	})
+}
+
+// validate 检查记录参数，返回 Cloudflare 的错误
+func (f *fakeCloudflare) validate(zoneID string, req DNSRecordCreateRequest, skipID string) *cfError {
+	if req.Type == "" || req.Name == "" || req.Content == "" {
+		return &cfError{Code: 9005, Message: "Content for record is invalid"}
+	}
+	for _, record := range f.records[zoneID] {
+		if record.ID != skipID && strings.EqualFold(record.Name, req.Name) && record.Type == req.Type && record.Content == req.Content {
+			return &cfError{Code: 81058, Message: "An identical record already exists."}
+		}
+	}
+	return nil
+}
+
+func recordFromRequest(id string, req DNSRecordCreateRequest) DNSRecord {
+	return DNSRecord{
+		ID:       id,
+		Type:     req.Type,
+		Name:     req.Name,
+		Content:  req.Content,
+		TTL:      req.TTL,
+		Proxied:  req.Proxied,
+		Priority: req.Priority,
+		Comment:  req.Comment,
+	}
+}
+
+func (f *fakeCloudflare) handleCreate(w http.ResponseWriter, r *http.Request, zoneID string) {
+	var req DNSRecordCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.reply(w, http.StatusBadRequest, nil, nil, cfError{Code: 9207, Message: "Request body is invalid"})
+		return
+	}
+
+	f.mu.Lock()
+	if e := f.validate(zoneID, req, ""); e != nil {
+		f.mu.Unlock()
+		f.reply(w, http.StatusBadRequest, nil, nil, *e)
+		return
+	}
+	f.nextID++
+	record := recordFromRequest(fmt.Sprintf("rec%03d", f.nextID), req)
+	f.records[zoneID] = append(f.records[zoneID], record)
+	f.mu.Unlock()
+
+	f.reply(w, http.StatusOK, record, nil)
+}
+
+func (f *fakeCloudflare) handleUpdate(w http.ResponseWriter, r *http.Request, zoneID, id string) {
+	var req DNSRecordCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.reply(w, http.StatusBadRequest, nil, nil, cfError{Code: 9207, Message: "Request body is invalid"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, record := range f.records[zoneID] {
+		if record.ID != id {
+			continue
+		}
+		if e := f.validate(zoneID, req, id); e != nil {
+			f.reply(w, http.StatusBadRequest, nil, nil, *e)
+			return
+		}
+		f.records[zoneID][i] = recordFromRequest(id, req)
+		f.reply(w, http.StatusOK, f.records[zoneID][i], nil)
+		return
+	}
+	f.reply(w, http.StatusNotFound, nil, nil, cfError{Code: 81044, Message: "Record does not exist."})
+}
+
+func (f *fakeCloudflare) handleDelete(w http.ResponseWriter, zoneID, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	records := f.records[zoneID]
+	for i, record := range records {
+		if record.ID == id {
+			f.records[zoneID] = append(records[:i:i], records[i+1:]...)
+			f.reply(w, http.StatusOK, map[string]string{"id": id}, nil)
+			return
+		}
+	}
+	f.reply(w, http.StatusNotFound, nil, nil, cfError{Code: 81044, Message: "Record does not exist."})
+}
+
+// handleBatch 批量请求：先检查全部操作，任一失败时不做任何修改
+func (f *fakeCloudflare) handleBatch(w http.ResponseWriter, r *http.Request, zoneID string) {
+	var body struct {
+		Posts []DNSRecordCreateRequest `json:"posts"`
+		Puts  []struct {
+			ID string `json:"id"`
+			DNSRecordCreateRequest
+		} `json:"puts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		f.reply(w, http.StatusBadRequest, nil, nil, cfError{Code: 9207, Message: "Request body is invalid"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// 在副本上执行，全部成功后再替换
+	records := append([]DNSRecord(nil), f.records[zoneID]...)
+	nextID := f.nextID
+	var puts, posts []DNSRecord
+	for _, put := range body.Puts {
+		found := false
+		for i := range records {
+			if records[i].ID == put.ID {
+				records[i] = recordFromRequest(put.ID, put.DNSRecordCreateRequest)
+				puts = append(puts, records[i])
+				found = true
+			}
+		}
+		if !found {
+			f.reply(w, http.StatusBadRequest, nil, nil, cfError{Code: 81044, Message: "Record does not exist."})
+			return
+		}
+	}
+	for _, post := range body.Posts {
+		nextID++
+		record := recordFromRequest(fmt.Sprintf("rec%03d", nextID), post)
+		records = append(records, record)
+		posts = append(posts, record)
+	}
+
+	f.records[zoneID] = records
+	f.nextID = nextID
+	f.reply(w, http.StatusOK, map[string]interface{}{"posts": posts, "puts": puts}, nil)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListDNSRecordsPaginates(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.pageSize = 2
+	for i := 1; i <= 5; i++ {
+		cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: fmt.Sprintf("198.51.100.%d", i)})
+	}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "other.example.com", Content: "198.51.100.9"})
+
+	records, err := cf.client().ListDNSRecords(testZoneID, testRecord)
+	if err != nil {
+		t.Fatalf("ListDNSRecords: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records; want 5 across 3 pages", len(records))
+	}
+	if n := cf.count("GET"); n != 3 {
+		t.Fatalf("GET requests = %d; want 3", n)
+	}
+}
+
+func TestListZoneDNSRecordsPaginates(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.pageSize = 3
+	for i := 1; i <= 7; i++ {
+		cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: fmt.Sprintf("h%d.example.com", i), Content: "198.51.100.1"})
+	}
+
+	records, err := cf.client().ListZoneDNSRecords(testZoneID)
+	if err != nil {
+		t.Fatalf("ListZoneDNSRecords: %v", err)
+	}
+	if len(records) != 7 {
+		t.Fatalf("got %d records; want 7", len(records))
+	}
+}
+
+func TestGetAllDNSRecordsFiltersType(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "AAAA", Name: testRecord, Content: "2001:db8::1"})
+
+	records, err := cf.client().GetAllDNSRecords(testZoneID, testRecord, "AAAA")
+	if err != nil {
+		t.Fatalf("GetAllDNSRecords: %v", err)
+	}
+	if len(records) != 1 || records[0].Content != "2001:db8::1" {
+		t.Fatalf("records = %+v; want only the AAAA record", records)
+	}
+}
+
+func TestListDNSRecordsCache(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	client := cf.client()
+	client.listCache = newRecordListCache(time.Minute)
+
+	records, err := client.GetAllDNSRecords(testZoneID, testRecord, "A")
+	if err != nil {
+		t.Fatalf("GetAllDNSRecords: %v", err)
+	}
+	records[0].Content = "mutated"
+	if again, _ := client.ListDNSRecords(testZoneID, strings.ToUpper(testRecord)); len(again) != 1 || again[0].Content != "198.51.100.1" {
+		t.Fatalf("cached records = %+v; want an unmodified copy", again)
+	}
+	if n := cf.count("GET"); n != 1 {
+		t.Fatalf("GET requests = %d; want 1 with the second read cached", n)
+	}
+
+	// 修改区域内任何记录后重新读取
+	if _, err := client.CreateDNSRecordWithOptions(testZoneID, DNSRecordCreateRequest{Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 1}); err != nil {
+		t.Fatalf("CreateDNSRecordWithOptions: %v", err)
+	}
+	if records, _ := client.ListDNSRecords(testZoneID, testRecord); len(records) != 2 {
+		t.Fatalf("records after create = %+v; want 2", records)
+	}
+	if n := cf.count("GET"); n != 2 {
+		t.Fatalf("GET requests = %d; want 2 after the write invalidated the cache", n)
+	}
+}
+
+func TestCreateEditDeleteDNSRecord(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	client := cf.client()
+
+	created, err := client.CreateDNSRecordWithOptions(testZoneID, DNSRecordCreateRequest{
+		Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300, Comment: "test",
+	})
+	if err != nil {
+		t.Fatalf("CreateDNSRecordWithOptions: %v", err)
+	}
+	if created.ID == "" || created.Comment != "test" {
+		t.Fatalf("created = %+v; want ID and comment", created)
+	}
+
+	edited, err := client.EditDNSRecord(testZoneID, *created, DNSRecordCreateRequest{
+		Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 300,
+	})
+	if err != nil {
+		t.Fatalf("EditDNSRecord: %v", err)
+	}
+	if edited.ID != created.ID || edited.Content != "198.51.100.2" {
+		t.Fatalf("edited = %+v; want same ID with new content", edited)
+	}
+
+	if err := client.DeleteDNSRecord(testZoneID, *edited); err != nil {
+		t.Fatalf("DeleteDNSRecord: %v", err)
+	}
+	if got := cf.find(testZoneID, testRecord, ""); len(got) != 0 {
+		t.Fatalf("records after delete = %+v; want none", got)
+	}
+}
+
+func TestCreateApexRecordUsesZoneName(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+
+	client := cf.client()
+	if _, err := client.CreateDNSRecord(testZoneID, apexName, "A", "198.51.100.1", 1); err != nil {
+		t.Fatalf("CreateDNSRecord: %v", err)
+	}
+	records, err := client.GetAllDNSRecords(testZoneID, apexName, "A")
+	if err != nil {
+		t.Fatalf("GetAllDNSRecords: %v", err)
+	}
+	if len(records) != 1 || records[0].Name != testZoneName {
+		t.Fatalf("records = %+v; want one record named %s", records, testZoneName)
+	}
+}
+
+func TestAPIErrorsIncludeStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func(cf *fakeCloudflare)
+		want   string
+		kind   error
+		exit   int
+	}{
+		{"server error", func(cf *fakeCloudflare) { cf.failNext("GET", "/dns_records", http.StatusInternalServerError, 1) }, "500", ErrNetwork, exitNetwork},
+		{"rate limited", func(cf *fakeCloudflare) { cf.rateLimit(1) }, "429", ErrRateLimited, exitRateLimited},
+		{"unknown zone", func(cf *fakeCloudflare) { cf.zones = map[string]string{} }, "404", ErrRecordNotFound, exitNotFound},
+		{"bad token", func(cf *fakeCloudflare) { cf.failNext("GET", "/dns_records", http.StatusForbidden, 1) }, "403", ErrAuth, exitAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := newFakeCloudflare(t)
+			cf.addZone(testZoneID, testZoneName)
+			tt.inject(cf)
+
+			_, err := cf.client().ListDNSRecords(testZoneID, testRecord)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v; want error mentioning %s", err, tt.want)
+			}
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.kind)
+			}
+			if code := exitCodeFor(err); code != tt.exit {
+				t.Fatalf("exit code = %d; want %d", code, tt.exit)
+			}
+		})
+	}
+}
+
+func TestAPIErrorRedactsToken(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	client := cf.client()
+	client.apiToken = "wrong-token"
+	registerSecret("wrong-token")
+
+	_, err := client.ListDNSRecords(testZoneID, testRecord)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("err = %v; want 403", err)
+	}
+	if strings.Contains(err.Error(), "wrong-token") {
+		t.Fatalf("error leaks the token: %v", err)
+	}
+}
+
+func TestBatchDNSRecordsIsAtomic(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	existing := cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	client := cf.client()
+
+	missing := existing
+	missing.ID = "does-not-exist"
+	err := client.BatchDNSRecords(testZoneID,
+		[]DNSRecordCreateRequest{{Type: "A", Name: "nas.example.com", Content: "198.51.100.2", TTL: 1}},
+		[]batchUpdate{{Old: missing, Request: DNSRecordCreateRequest{Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 1}}})
+	if err == nil {
+		t.Fatal("BatchDNSRecords succeeded; want error for a missing record")
+	}
+	if got := cf.find(testZoneID, "nas.example.com", "A"); len(got) != 0 {
+		t.Fatalf("failed batch created %+v", got)
+	}
+
+	err = client.BatchDNSRecords(testZoneID,
+		[]DNSRecordCreateRequest{{Type: "A", Name: "nas.example.com", Content: "198.51.100.2", TTL: 1}},
+		[]batchUpdate{{Old: existing, Request: DNSRecordCreateRequest{Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 1}}})
+	if err != nil {
+		t.Fatalf("BatchDNSRecords: %v", err)
+	}
+	for _, name := range []string{testRecord, "nas.example.com"} {
+		if got := cf.contents(testZoneID, name, "A"); len(got) != 1 || got[0] != "198.51.100.2" {
+			t.Errorf("%s = %v; want [198.51.100.2]", name, got)
+		}
+	}
+}
+
+func TestAPIErrorRecordsResponseMeta(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.rateLimit(1)
+
+	_, err := cf.client().ListDNSRecords(testZoneID, testRecord)
+	metas := apiResponseMetas(err)
+	if len(metas) != 1 {
+		t.Fatalf("metas = %+v; want one failed request", metas)
+	}
+	meta := metas[0]
+	if meta.Status != http.StatusTooManyRequests || meta.Method != "GET" || meta.CfRay == "" || meta.RetryAfter != "1" || meta.RateLimit == "" {
+		t.Fatalf("meta = %+v; want 429 GET with Cf-Ray and rate-limit headers", meta)
+	}
+	if !strings.Contains(err.Error(), "cf-ray: "+meta.CfRay) {
+		t.Fatalf("err = %v; want Cf-Ray in the message", err)
+	}
+	if errorCategory(err) != "rate_limited" {
+		t.Fatalf("category = %q; want rate_limited", errorCategory(err))
+	}
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Config struct {
@@ -12,40 +16,355 @@ type Config struct {
 	ZoneID     string `json:"zone_id"`
 	RecordName string `json:"record_name"`
 	RecordType string `json:"record_type"`
+
+	// DualStack 启动时本机没有 record_type 对应的地址族而另一地址族可用时，改为维护另一类型的记录（只在运行时生效，不写回配置）
+	DualStack bool `json:"dual_stack,omitempty"`
+
+	// Records 与主记录使用同一个公网IP同步的其他记录（可位于其他区域）
+	Records []RecordConfig `json:"records,omitempty"`
+
+	// Subdomains 跟随主记录的子域名（与主记录同一区域），IP变化时与主记录在同一个批量请求中原子更新
+	Subdomains []SubdomainConfig `json:"subdomains,omitempty"`
+
+	// CachePurge 开启代理的记录更新后清除 Cloudflare 缓存，为空则不清除
+	CachePurge *CachePurgeConfig `json:"cache_purge,omitempty"`
+
+	// Firewalls 公网IP变化后更新的防火墙白名单
+	Firewalls []FirewallConfig `json:"firewalls,omitempty"`
+
+	// TTLStrategy 写入变化前先降低TTL，一段时间后再恢复，为空则不启用
+	TTLStrategy *TTLStrategyConfig `json:"ttl_strategy,omitempty"`
+
+	// PTR 公网IP变化后通过服务商 API 设置新IP的反向解析，为空则不启用
+	PTR *PTRConfig `json:"ptr,omitempty"`
+
+	// Consul 公网IP变化后注册到 Consul 服务或写入 KV 键，为空则不启用
+	Consul *ConsulConfig `json:"consul,omitempty"`
+
+	// SplitHorizon 公网IP变化后同时更新局域网内的DNS服务器，为空则不启用
+	SplitHorizon *SplitHorizonConfig `json:"split_horizon,omitempty"`
+
+	// Docker 根据容器标签维护DNS记录（仅守护进程），为空则不启用
+	Docker *DockerConfig `json:"docker,omitempty"`
+
+	// Kubernetes 根据带注解的 Service / Ingress 维护DNS记录（仅守护进程），为空则不启用
+	Kubernetes *KubernetesConfig `json:"kubernetes,omitempty"`
+
+	// ReconcileWorkers 并行同步记录的最大数量（默认 4）
+	ReconcileWorkers int `json:"reconcile_workers,omitempty"`
+
+	// StartupReport 守护进程启动时检查记录的实际状态，为空时只写入日志，存在问题时通知
+	StartupReport *StartupReportConfig `json:"startup_report,omitempty"`
+
+	// Drift 检测记录被外部修改及处理策略，为空则不检测
+	Drift *DriftConfig `json:"drift,omitempty"`
+	// RecordAudit 按间隔比较全部记录与期望状态并修复，为空则不审计
+	RecordAudit *RecordAuditConfig `json:"record_audit,omitempty"`
+	// Geo IP变化时查询新地址的 ASN 与地理位置，为空则不查询
+	Geo *GeoConfig `json:"geo,omitempty"`
+
+	// DoHPrecheck 同步前先通过 DNS-over-HTTPS 确认记录是否已指向检测到的IP，为空则不启用
+	DoHPrecheck *DoHPrecheckConfig `json:"doh_precheck,omitempty"`
+
+	// RetryPolicy 写入失败后的重试次数、间隔与IP变化的确认次数，为空则使用默认值；records 中可以单独设置
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// Degraded 连续失败达到阈值后改为按较长间隔检测并通知，为空则不启用（只按 max_check_interval 退避）
+	Degraded *DegradedConfig `json:"degraded,omitempty"`
+
+	// MinUpdateInterval 两次写入DNS记录的最小间隔（如 "10m"），期间IP再次变化时暂缓更新并通知，为空则不限制
+	MinUpdateInterval string `json:"min_update_interval,omitempty"`
+
+	// Lease 写入租约（TXT 记录），为空则不启用；用于误在多台机器上管理同一条记录时只让一个实例写入
+	Lease *LeaseConfig `json:"lease,omitempty"`
+
+	// Weight 本机在多机器记录集中的权重（0–100，默认 100）；0 表示撤下本机记录。
+	// 普通记录无法表示其他权重，按权重分配流量需要配置 LoadBalancerPool
+	Weight *int `json:"weight,omitempty"`
+
+	// LoadBalancerPool Cloudflare 负载均衡源站池，配置后按权重维护本机源站
+	LoadBalancerPool *LoadBalancerPoolConfig `json:"load_balancer_pool,omitempty"`
+
+	// Fleet 成员登记（TXT 记录），为空则不启用
+	Fleet *FleetConfig `json:"fleet,omitempty"`
+
+	// ServiceCheck 本机服务健康检查，为空则不启用；服务持续不可用时从多机器记录集中撤下本机记录
+	ServiceCheck *ServiceCheckConfig `json:"service_check,omitempty"`
+
+	// IPInterface 从本机网络接口读取IP（不查询外部服务），为空则使用IP检测服务；
+	// AAAA 记录默认跳过 RFC 4941 临时地址，避免记录每隔几小时变化
+	IPInterface *InterfaceSourceConfig `json:"ip_interface,omitempty"`
+
+	// IPRaceDelay 主检测服务先行的时间，超过后同时请求第一个备用服务，取先返回的结果（默认 500ms，"0" 表示依次尝试）
+	IPRaceDelay string `json:"ip_race_delay,omitempty"`
+
+	// HTTP Cloudflare API 与IP检测服务的超时和连接池设置，为空时使用默认值
+	HTTP *HTTPConfig `json:"http,omitempty"`
+
+	// Tracing 检测周期的链路追踪（OTLP 导出），为空时仅在设置了 OTEL_EXPORTER_OTLP_ENDPOINT 环境变量时启用
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+
+	// VerifyDNS 更新后查询权威名称服务器，确认新值已生效才视为成功；VerifyDNSTimeout 为等待上限（默认 60s）
+	VerifyDNS        bool   `json:"verify_dns,omitempty"`
+	VerifyDNSTimeout string `json:"verify_dns_timeout,omitempty"`
+
+	// DedupeRecords 同步前删除记录集中名称、类型与内容都相同的重复记录（每组保留一条）
+	DedupeRecords bool `json:"dedupe_records,omitempty"`
+	// ObserveOnly 观察模式：只比较DNS记录与公网IP，不一致时告警，不修改任何记录
+	ObserveOnly bool `json:"observe_only,omitempty"`
+	// NonInteractive 配置不完整时直接报错退出，不进入配置向导（与 --non-interactive 相同）
+	NonInteractive bool `json:"non_interactive,omitempty"`
+
+	// MaxCheckInterval IP长期未变化时检测间隔逐步拉长的上限（如 "5m"），为空时固定每5秒检测；
+	// 检测连续失败时同样按指数退避，上限为该值（未配置时为 1 分钟）
+	MaxCheckInterval string `json:"max_check_interval,omitempty"`
+
+	// HealthListen 健康检查服务监听地址（如 127.0.0.1:8053），为空则不启用
+	HealthListen string `json:"health_listen,omitempty"`
+
+	// APIListen 管理 API 监听地址，为空则不启用；APIAuthToken 为访问令牌（必填）
+	APIListen    string `json:"api_listen,omitempty"`
+	APIAuthToken string `json:"api_auth_token,omitempty"`
+	// APIPprof 在管理 API 上启用 /debug/pprof/（仅限本机访问）
+	APIPprof bool `json:"api_pprof,omitempty"`
+
+	// 日志轮转：单文件大小上限（MB）、保留文件数、保留天数，0 表示使用默认值
+	LogMaxSizeMB  int `json:"log_max_size_mb,omitempty"`
+	LogMaxFiles   int `json:"log_max_files,omitempty"`
+	LogMaxAgeDays int `json:"log_max_age_days,omitempty"`
+
+	// LogTimezone 日志时间戳时区：UTC（默认）、Local 或 IANA 时区名称
+	LogTimezone string `json:"log_timezone,omitempty"`
+
+	// LogDedupWindow 相同错误日志的合并窗口（默认 10m），"0" 表示不合并
+	LogDedupWindow string `json:"log_dedup_window,omitempty"`
+
+	// EventLog 将错误日志同时写入 Windows 事件日志，仅 Windows 可用
+	EventLog *EventLogConfig `json:"event_log,omitempty"`
+
+	// Notifications 通知渠道列表
+	Notifications []NotificationConfig `json:"notifications,omitempty"`
+
+	// NotifyPolicy 失败通知策略（阈值、升级、恢复通知）
+	NotifyPolicy *NotifyPolicy `json:"notify_policy,omitempty"`
+
+	// Hooks 守护进程启动/停止钩子
+	Hooks *HooksConfig `json:"hooks,omitempty"`
+
+	// Agents 控制器模式下允许通过管理 API 上报IP的代理
+	Agents []AgentConfig `json:"agents,omitempty"`
+
+	// Controller 代理模式下连接的控制器
+	Controller *ControllerConfig `json:"controller,omitempty"`
 }
 
-func getConfigPath() string {
+// RecordConfig 额外同步的记录，记录类型与主记录相同
+type RecordConfig struct {
+	Name string `json:"name"`
+	// ZoneID 为空时使用主配置的 zone_id
+	ZoneID string `json:"zone_id,omitempty"`
+	// IPSource 为空时指向主记录检测到的IP；设置后单独检测并同步（见 syncPinnedRecords）
+	IPSource *IPSourceConfig `json:"ip_source,omitempty"`
+	// RetryPolicy 该记录的重试与确认策略，未设置的项沿用顶层的 retry_policy
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+}
+
+// defaultReconcileWorkers 并行同步记录的默认数量
+const defaultReconcileWorkers = 4
+
+// recordTargets 返回指向主IP的全部记录：主记录在前，重复的记录只保留一次；设置了 ip_source 的记录不包括在内
+func (c *Config) recordTargets() []RecordTarget {
+	targets := []RecordTarget{{ZoneID: c.ZoneID, Name: c.RecordName, Type: c.RecordType}}
+	seen := map[string]bool{c.ZoneID + "/" + strings.ToLower(c.RecordName): true}
+	for _, record := range c.Records {
+		zoneID := record.ZoneID
+		if zoneID == "" {
+			zoneID = c.ZoneID
+		}
+		key := zoneID + "/" + strings.ToLower(record.Name)
+		if record.Name == "" || record.IPSource != nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		targets = append(targets, RecordTarget{ZoneID: zoneID, Name: record.Name, Type: c.RecordType, Retry: record.RetryPolicy})
+	}
+	return targets
+}
+
+// reconcileWorkers 返回并行同步的数量
+func (c *Config) reconcileWorkers() int {
+	if c.ReconcileWorkers > 0 {
+		return c.ReconcileWorkers
+	}
+	return defaultReconcileWorkers
+}
+
+// defaultIPRaceDelay 主检测服务默认先行的时间
+const defaultIPRaceDelay = 500 * time.Millisecond
+
+// ipRaceDelay 返回主检测服务先行的时间，0 表示不竞速
+func (c *Config) ipRaceDelay() time.Duration {
+	if c.IPRaceDelay == "" {
+		return defaultIPRaceDelay
+	}
+	d, err := parseDurationOrZero(c.IPRaceDelay)
+	if err != nil || d < 0 {
+		logError("ip_race_delay 格式无效: %s，使用默认值 %v", c.IPRaceDelay, defaultIPRaceDelay)
+		return defaultIPRaceDelay
+	}
+	return d
+}
+
+// notifyPolicy 返回通知策略，未配置时返回默认值
+func (c *Config) notifyPolicy() NotifyPolicy {
+	if c.NotifyPolicy == nil {
+		return NotifyPolicy{}
+	}
+	return *c.NotifyPolicy
+}
+
+// isComplete 检查更新DNS所需的必填项是否已配置
+func (c *Config) isComplete() bool {
+	return c.APIToken != "" && c.ZoneID != "" && c.RecordName != ""
+}
+
+// missingFields 返回未填写的必填字段（配置文件中的名称）
+func (c *Config) missingFields() []string {
+	var missing []string
+	if c.APIToken == "" {
+		missing = append(missing, "api_token")
+	}
+	if c.ZoneID == "" {
+		missing = append(missing, "zone_id")
+	}
+	if c.RecordName == "" {
+		missing = append(missing, "record_name")
+	}
+	return missing
+}
+
+// getDataDir 返回程序数据目录（配置、日志、PID、状态文件）
+// 优先使用环境变量 DNS_MANAGER_HOME；用户主目录不可用（如无主目录的系统用户）时
+// 使用 /var/lib/go_dns_manager。路径在首次调用时确定，切换运行用户后保持不变
+func getDataDir() string {
+	dataDirOnce.Do(func() {
+		dataDir = resolveDataDir()
+	})
+	return dataDir
+}
+
+var (
+	dataDir     string
+	dataDirOnce sync.Once
+)
+
+// systemDataDir 无主目录的系统用户与 --user 降权运行时使用的数据目录
+const systemDataDir = "/var/lib/go_dns_manager"
+
+// dataDirMarker 默认数据目录中记录 --user 运行时所用数据目录的文件：之后不带 --user 执行的
+// status、stop、logs 等命令据此找到守护进程的PID文件、日志与控制套接字
+const dataDirMarker = "data_dir"
+
+// useSystemDataDir 以 root 启动并通过 --user 降权时，须在首次读取数据目录之前调用：
+// root 的主目录（如权限 0700 的 /root）对降权后的用户不可访问，改用 systemDataDir；
+// 设置了 DNS_MANAGER_HOME 时仍以其为准
+func useSystemDataDir() {
+	dataDirOnce.Do(func() {
+		dataDir = os.Getenv("DNS_MANAGER_HOME")
+		if dataDir == "" {
+			dataDir = systemDataDir
+		}
+	})
+}
+
+// markDataDir 在默认数据目录中记录 dir，与默认目录相同或没有主目录时不记录
+func markDataDir(dir string) error {
+	home := homeDataDir()
+	if home == "" || home == dir {
+		return nil
+	}
+	return writeFileAtomic(filepath.Join(home, dataDirMarker), []byte(dir+"\n"), 0644, false)
+}
+
+// homeDataDir 返回用户主目录下的默认数据目录，主目录不存在时返回空字符串
+func homeDataDir() string {
 	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		// 如果无法获取用户目录，使用当前目录
-		return "config.json"
+	if err == nil && homeDir != "" {
+		if stat, err := os.Stat(homeDir); err == nil && stat.IsDir() {
+			return filepath.Join(homeDir, ".go_dns_manager")
+		}
 	}
-	return filepath.Join(homeDir, ".go_dns_manager", "config.json")
+	return ""
 }
 
+func resolveDataDir() string {
+	if dir := os.Getenv("DNS_MANAGER_HOME"); dir != "" {
+		return dir
+	}
+
+	home := homeDataDir()
+	if home == "" {
+		// 无主目录的系统用户
+		return systemDataDir
+	}
+	// 以 --user 运行过时沿用当时的数据目录
+	if data, err := os.ReadFile(filepath.Join(home, dataDirMarker)); err == nil {
+		if dir := strings.TrimSpace(string(data)); dir != "" {
+			return dir
+		}
+	}
+	return home
+}
+
+func getConfigPath() string {
+	return filepath.Join(getDataDir(), "config.json")
+}
+
+// LoadConfig 加载配置；配置文件损坏时退出程序，避免后续保存时用默认值覆盖原有配置
 func LoadConfig() *Config {
+	config, err := loadConfigFile()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, tr("为避免覆盖现有配置，程序已退出。请修复或删除该文件后重试"))
+		os.Exit(1)
+	}
+	return config
+}
+
+// checksumWarned 手动编辑导致的校验和警告只提示一次
+var checksumWarned bool
+
+// loadConfigFile 读取配置文件；文件不存在时返回默认配置，文件损坏时返回错误
+func loadConfigFile() (*Config, error) {
 	configPath := getConfigPath()
 	
 	// 确保配置目录存在
 	configDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
-		fmt.Printf("警告: 无法创建配置目录: %v\n", err)
+		fmt.Printf(tr("警告: 无法创建配置目录: %v\n"), err)
 	}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
+	data, err := readFileChecked(configPath)
+	if os.IsNotExist(err) {
 		// 文件不存在，返回默认配置
 		return &Config{
 			RecordType: "A",
-		}
+		}, nil
+	}
+	mismatch := errors.Is(err, errChecksumMismatch)
+	if err != nil && !mismatch {
+		return nil, fmt.Errorf(tr("读取配置文件失败: %v"), err)
 	}
 
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		fmt.Printf("警告: 配置文件格式错误: %v\n", err)
-		return &Config{
-			RecordType: "A",
-		}
+		return nil, fmt.Errorf(tr("配置文件 %s 已损坏: %v"), configPath, err)
+	}
+
+	// 校验和不一致但内容完整，通常是手动编辑过配置文件
+	if mismatch && !checksumWarned {
+		checksumWarned = true
+		fmt.Fprintln(os.Stderr, tr("警告: 配置文件校验和不匹配（可能被手动编辑），已按当前内容加载；删除第一行校验和即可消除此警告"))
 	}
 
 	// 设置默认值
@@ -53,25 +372,19 @@ func LoadConfig() *Config {
 		config.RecordType = "A"
 	}
 
-	return &config
+	return &config, nil
 }
 
 func SaveConfig(config *Config) error {
 	configPath := getConfigPath()
-	
-	// 确保配置目录存在
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %v", err)
-	}
 
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("序列化配置失败: %v", err)
+		return fmt.Errorf(tr("序列化配置失败: %v"), err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("写入配置文件失败: %v", err)
+	if err := writeFileAtomic(configPath, data, 0600, true); err != nil {
+		return fmt.Errorf(tr("写入配置文件失败: %v"), err)
 	}
 
 	return nil
@@ -89,7 +402,7 @@ func DeleteConfig() error {
 
 	// 删除配置文件
 	if err := os.Remove(configPath); err != nil {
-		return fmt.Errorf("删除配置文件失败: %v", err)
+		return fmt.Errorf(tr("删除配置文件失败: %v"), err)
 	}
 
 	return nil
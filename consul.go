@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsulConfig 公网IP变化后同步到 Consul，使内部服务发现与外部DNS保持一致。
+// Service 与 Key 可同时配置
+type ConsulConfig struct {
+	// Address Consul agent 地址，默认 http://127.0.0.1:8500
+	Address string `json:"address,omitempty"`
+	// Token ACL 令牌，为空时不发送
+	Token string `json:"token,omitempty"`
+
+	// Service 注册到本机 Consul agent 的服务名称，服务地址为公网IP；ServiceID 默认与 Service 相同
+	Service   string   `json:"service,omitempty"`
+	ServiceID string   `json:"service_id,omitempty"`
+	Port      int      `json:"port,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+
+	// Key 写入公网IP的 KV 键（如 dns_manager/public_ip）
+	Key string `json:"key,omitempty"`
+}
+
+// defaultConsulAddress Consul agent 默认地址
+const defaultConsulAddress = "http://127.0.0.1:8500"
+
+// consulState 已同步到 Consul 的IP，未变化时不重复写入
+type consulState struct {
+	mu      sync.Mutex
+	applied string
+}
+
+var consul = &consulState{}
+
+func (c *ConsulConfig) address() string {
+	if c.Address != "" {
+		return strings.TrimSuffix(c.Address, "/")
+	}
+	return defaultConsulAddress
+}
+
+func (c *ConsulConfig) serviceID() string {
+	if c.ServiceID != "" {
+		return c.ServiceID
+	}
+	return c.Service
+}
+
+// put 向 Consul 发送 PUT 请求
+func (c *ConsulConfig) put(path string, body []byte) error {
+	req, err := http.NewRequest("PUT", c.address()+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		registerSecret(c.Token)
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: newHTTPTransport("consul")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf(tr("连接 Consul 失败: %v"), redactError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(tr("Consul 返回错误 (状态码: %d)"), resp.StatusCode)
+	}
+	return nil
+}
+
+// registerService 注册或更新服务，地址为公网IP
+func (c *ConsulConfig) registerService(ip string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"ID":      c.serviceID(),
+		"Name":    c.Service,
+		"Address": ip,
+		"Port":    c.Port,
+		"Tags":    c.Tags,
+		"Meta":    map[string]string{"managed_by": "dns_manager"},
+	})
+	if err != nil {
+		return fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+	return c.put("/v1/agent/service/register", body)
+}
+
+// putKey 把公网IP写入 KV 键
+func (c *ConsulConfig) putKey(ip string) error {
+	return c.put("/v1/kv/"+strings.TrimPrefix(c.Key, "/"), []byte(ip))
+}
+
+// updateConsul 公网IP变化后更新 Consul 服务与 KV 键。
+// 与防火墙相同，失败时只记录日志，下个周期重试
+func updateConsul(config *Config, ip string) {
+	consul.mu.Lock()
+	defer consul.mu.Unlock()
+
+	if consul.applied == ip {
+		return
+	}
+
+	c := config.Consul
+	ok := true
+	if c.Service != "" {
+		if err := c.registerService(ip); err != nil {
+			logError("更新 Consul 服务 %s 失败: %v", c.serviceID(), err)
+			ok = false
+		} else {
+			logInfo("Consul 服务 %s 已指向 %s", c.serviceID(), ip)
+		}
+	}
+	if c.Key != "" {
+		if err := c.putKey(ip); err != nil {
+			logError("写入 Consul 键 %s 失败: %v", c.Key, err)
+			ok = false
+		} else {
+			logInfo("Consul 键 %s 已更新为 %s", c.Key, ip)
+		}
+	}
+	if ok {
+		consul.applied = ip
+	}
+}
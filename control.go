@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// controlRequest 控制套接字请求，每个连接一行 JSON
+type controlRequest struct {
+	Command  string `json:"command"`
+	Duration string `json:"duration,omitempty"`
+	// IP approve 命令确认的IP
+	IP string `json:"ip,omitempty"`
+}
+
+// controlResponse 控制套接字响应
+type controlResponse struct {
+	OK          bool              `json:"ok"`
+	Error       string            `json:"error,omitempty"`
+	Maintenance maintenanceStatus `json:"maintenance"`
+	// Update update 命令的执行结果
+	Update *apiUpdateResult `json:"update,omitempty"`
+	// Pending approve 命令确认的IP变化
+	Pending *pendingChange `json:"pending,omitempty"`
+}
+
+// controlUpdateTimeout update 命令等待守护进程完成检测周期的最长时间
+const controlUpdateTimeout = 3 * time.Minute
+
+// resumeChan 恢复写入或确认新IP后通知主循环立即执行一次检测
+var resumeChan = make(chan struct{}, 1)
+
+// getControlSocketPath 返回控制套接字路径
+func getControlSocketPath() string {
+	return filepath.Join(getDataDir(), "dns_manager.sock")
+}
+
+// startControlSocket 启动控制套接字，供本机的 pause / resume 命令与守护进程通信。
+// 套接字权限为 0600，只有运行守护进程的用户（及 root）可以连接
+func startControlSocket() (func(), error) {
+	path := getControlSocketPath()
+	// 上次异常退出遗留的套接字文件
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf(tr("启动控制套接字失败: %v"), err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf(tr("启动控制套接字失败: %v"), err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handleControlConn(conn)
+		}
+	}()
+	logDebug("控制套接字已启动: %s", path)
+
+	return func() {
+		listener.Close()
+		os.Remove(path)
+	}, nil
+}
+
+// handleControlConn 处理一个控制连接
+func handleControlConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	var req controlRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	resp := controlResponse{OK: true}
+	switch req.Command {
+	case "pause":
+		var d time.Duration
+		if req.Duration != "" {
+			var err error
+			if d, err = time.ParseDuration(req.Duration); err != nil || d <= 0 {
+				resp = controlResponse{Error: fmt.Sprintf(tr("无效的时长: %s"), req.Duration)}
+				break
+			}
+		}
+		maintenance.pause(d)
+	case "resume":
+		if maintenance.resume() {
+			requestSync(resumeChan)
+		}
+	case "update":
+		// 由主循环执行检测周期，与定时检测依次进行
+		conn.SetDeadline(time.Now().Add(controlUpdateTimeout))
+		update := apiUpdateRequest{summary: true, reply: make(chan apiUpdateResult, 1)}
+		select {
+		case apiUpdateChan <- update:
+			result := <-update.reply
+			resp.Update = &result
+		case <-time.After(10 * time.Second):
+			resp = controlResponse{Error: tr("守护进程繁忙，请稍后重试")}
+		}
+	case "approve":
+		pending, err := anomaly.approve(req.IP)
+		if err != nil {
+			resp = controlResponse{Error: err.Error()}
+			break
+		}
+		logInfo("已通过控制套接字确认新IP %s", pending.IP)
+		resp.Pending = pending
+		requestSync(resumeChan)
+	case "status":
+	default:
+		resp = controlResponse{Error: fmt.Sprintf(tr("未知命令: %s"), req.Command)}
+	}
+	resp.Maintenance = maintenance.status()
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// sendControl 向运行中的守护进程发送控制命令
+func sendControl(req controlRequest) (*controlResponse, error) {
+	conn, err := net.DialTimeout("unix", getControlSocketPath(), 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf(tr("无法连接守护进程的控制套接字（守护进程是否在运行？）: %v"), err)
+	}
+	defer conn.Close()
+	timeout := 10 * time.Second
+	if req.Command == "update" {
+		timeout = controlUpdateTimeout
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	var resp controlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// delegateOnce 守护进程正在运行时通过控制套接字由守护进程执行本次更新，
+// 避免 once 与守护进程同时修改记录；返回 false 时在本进程中执行（由更新锁保证依次执行）
+func delegateOnce() (*OnceSummary, bool) {
+	// --dry-run 与 --simulate-ip 只作用于本进程
+	if dryRun || ipSimulator != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(getControlSocketPath()); err != nil {
+		return nil, false
+	}
+	resp, err := sendControl(controlRequest{Command: "update"})
+	if err != nil {
+		logInfo("无法交给守护进程执行，在本进程中执行: %v", err)
+		return nil, false
+	}
+	if resp.Update == nil || resp.Update.Summary == nil {
+		return nil, false
+	}
+	logInfo("守护进程正在运行，已由守护进程执行本次更新")
+	return resp.Update.Summary, true
+}
+
+// maintenanceState 维护模式：守护进程继续检测IP，但不修改任何记录，只记录将要执行的操作
+type maintenanceState struct {
+	mu     sync.Mutex
+	paused bool
+	until  time.Time // 为零表示直到手动恢复
+}
+
+// maintenanceStatus 维护模式状态
+type maintenanceStatus struct {
+	Paused bool       `json:"paused"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+var maintenance = &maintenanceState{}
+
+// pause 进入维护模式，d 为 0 时直到手动恢复
+func (m *maintenanceState) pause(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.paused = true
+	m.until = time.Time{}
+	if d > 0 {
+		m.until = time.Now().Add(d)
+		logInfo("已进入维护模式，暂停写入DNS记录，%s 后自动恢复", d)
+	} else {
+		logInfo("已进入维护模式，暂停写入DNS记录，直到手动恢复")
+	}
+}
+
+// resume 退出维护模式，返回之前是否处于维护模式
+func (m *maintenanceState) resume() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.paused {
+		return false
+	}
+	m.paused = false
+	m.until = time.Time{}
+	logInfo("已退出维护模式，恢复写入DNS记录")
+	return true
+}
+
+// isPaused 是否处于维护模式；到期后自动恢复
+func (m *maintenanceState) isPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.paused && !m.until.IsZero() && time.Now().After(m.until) {
+		m.paused = false
+		m.until = time.Time{}
+		logInfo("维护模式已到期，恢复写入DNS记录")
+	}
+	return m.paused
+}
+
+// status 返回维护模式状态
+func (m *maintenanceState) status() maintenanceStatus {
+	paused := m.isPaused()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := maintenanceStatus{Paused: paused}
+	if paused && !m.until.IsZero() {
+		until := m.until
+		status.Until = &until
+	}
+	return status
+}
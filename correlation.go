@@ -0,0 +1,44 @@
+package main
+
+import "sync"
+
+// correlationHeader 检测周期内发出的 HTTP 请求携带的关联ID请求头
+const correlationHeader = "X-Request-ID"
+
+// 当前检测周期的关联ID：周期内的日志、HTTP 请求与通知都带上该ID，
+// 重试与并行同步的日志交错时可以据此还原同一个周期
+var (
+	cycleIDMu sync.RWMutex
+	cycleID   string
+)
+
+// beginCycleID 为新的检测周期生成关联ID
+func beginCycleID() string {
+	id := randomHex(4)
+	cycleIDMu.Lock()
+	defer cycleIDMu.Unlock()
+	cycleID = id
+	return id
+}
+
+// endCycleID 检测周期结束，之后的日志不再带关联ID
+func endCycleID() {
+	cycleIDMu.Lock()
+	defer cycleIDMu.Unlock()
+	cycleID = ""
+}
+
+// currentCycleID 返回当前检测周期的关联ID，不在检测周期内时为空
+func currentCycleID() string {
+	cycleIDMu.RLock()
+	defer cycleIDMu.RUnlock()
+	return cycleID
+}
+
+// logPrefix 日志行中的关联ID标记
+func logPrefix() string {
+	if id := currentCycleID(); id != "" {
+		return "[cycle=" + id + "] "
+	}
+	return ""
+}
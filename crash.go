@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+)
+
+// safeCheckAndUpdate 执行一次检测更新，捕获其中的 panic 并记录崩溃信息，
+// 保证后台循环不会因单次异常而静默退出；执行期间持有周期锁，配置重载会等待本次检测结束
+func safeCheckAndUpdate() (updated bool, err error) {
+	app.cycleMu.Lock()
+	defer app.cycleMu.Unlock()
+
+	// 先注册，在 recover 之后执行，异常同样记录到追踪中
+	id := beginCycleID()
+	span := tracing.startCycle()
+	span.set("cycle.id", id)
+	cycleTiming.begin()
+	lastCycle.begin()
+	defer func() {
+		cycleTiming.end()
+		span.set("dns.record", app.Config().RecordName)
+		span.set("ip", app.CurrentIP())
+		span.set("updated", updated)
+		span.finish(err)
+		endCycleID()
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			updated = false
+			err = fmt.Errorf(tr("检测周期发生异常: %v"), r)
+			stack := debug.Stack()
+			logError("检测周期发生异常: %v", r)
+			crashFile, err := writeCrashReport(r, stack)
+			if err != nil {
+				logError("写入崩溃报告失败: %v", err)
+				logError("堆栈信息:\n%s", stack)
+			} else {
+				logError("崩溃报告已写入: %s，守护进程将继续运行", crashFile)
+			}
+		}
+	}()
+
+	return checkAndUpdate()
+}
+
+// writeCrashReport 将 panic 信息与堆栈写入日志目录下的崩溃文件
+func writeCrashReport(r interface{}, stack []byte) (string, error) {
+	logDir := getLogDir()
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return "", fmt.Errorf(tr("创建日志目录失败: %v"), err)
+	}
+
+	now := logNow()
+	crashFile := filepath.Join(logDir, fmt.Sprintf("crash_%s.log", now.Format("20060102_150405")))
+
+	content := fmt.Sprintf(tr("时间: %s\nPID: %d\n异常: %v\n\n%s"),
+		now.Format(logTimeFormat), os.Getpid(), r, stack)
+
+	if err := os.WriteFile(crashFile, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return crashFile, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cycleLockTimeout 等待其他进程释放更新锁的最长时间
+const cycleLockTimeout = 2 * time.Minute
+
+// getCycleLockPath 返回更新锁文件路径
+func getCycleLockPath() string {
+	return filepath.Join(getDataDir(), "dns_manager.lock")
+}
+
+// acquireCycleLock 获取跨进程的更新锁（Unix 为 flock，Windows 为 LockFileEx），返回释放函数。
+// 守护进程与 once 在同一台机器上运行时依次执行检测周期，不会同时发现记录缺失而各自新建一条。
+// 锁文件无法打开时（如数据目录属于其他用户）不加锁继续执行
+func acquireCycleLock(timeout time.Duration) (func(), error) {
+	// 以只读方式打开即可加锁，降权运行的守护进程也能打开 root 创建的锁文件
+	f, err := os.OpenFile(getCycleLockPath(), os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		logDebug("无法打开更新锁，不加锁执行: %v", err)
+		return func() {}, nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf(tr("获取更新锁失败: %v"), err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf(tr("等待更新锁超时（%v）：另一个进程正在更新DNS记录"), timeout)
+		}
+		if !waiting {
+			logInfo("另一个进程正在更新DNS记录，等待其完成...")
+			waiting = true
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// lockedCheckAndUpdate 持有更新锁执行一次检测周期
+func lockedCheckAndUpdate() (bool, error) {
+	unlock, err := acquireCycleLock(cycleLockTimeout)
+	if err != nil {
+		logError("%v", err)
+		return false, err
+	}
+	defer unlock()
+	return safeCheckAndUpdate()
+}
@@ -22,13 +22,13 @@ func daemonize() error {
 	// 获取可执行文件路径
 	execPath, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("获取可执行文件路径失败: %v", err)
+		return fmt.Errorf(tr("获取可执行文件路径失败: %v"), err)
 	}
 
 	// 使用绝对路径
 	absPath, err := filepath.Abs(execPath)
 	if err != nil {
-		return fmt.Errorf("获取绝对路径失败: %v", err)
+		return fmt.Errorf(tr("获取绝对路径失败: %v"), err)
 	}
 
 	// 重新启动自己，作为守护进程
@@ -36,12 +36,10 @@ func daemonize() error {
 	cmd.Env = os.Environ()
 	
 	// 设置进程属性
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true, // 创建新的会话
-	}
+	cmd.SysProcAttr = detachedProcAttr()
 
 	// 重定向标准输入输出到 /dev/null
-	nullFile, err := os.OpenFile("/dev/null", os.O_RDWR, 0)
+	nullFile, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
 	if err == nil {
 		cmd.Stdin = nullFile
 		cmd.Stdout = nullFile
@@ -50,11 +48,11 @@ func daemonize() error {
 
 	// 启动守护进程
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("启动守护进程失败: %v", err)
+		return fmt.Errorf(tr("启动守护进程失败: %v"), err)
 	}
 
-	fmt.Printf("守护进程已启动，PID: %d\n", cmd.Process.Pid)
-	fmt.Println("程序已在后台运行，可以安全关闭终端")
+	fmt.Printf(tr("守护进程已启动，PID: %d\n"), cmd.Process.Pid)
+	fmt.Println(tr("程序已在后台运行，可以安全关闭终端"))
 	
 	// 保存PID到文件
 	savePID(cmd.Process.Pid)
@@ -66,33 +64,23 @@ func daemonize() error {
 
 // savePID 保存进程ID到文件
 func savePID(pid int) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
-	}
-	pidDir := filepath.Join(homeDir, ".go_dns_manager")
-	if err := os.MkdirAll(pidDir, 0755); err != nil {
-		return err
-	}
+	// PID 文件可能被外部工具读取（如 kill $(cat ...)），只做原子写入，不添加校验和头部
+	return writeFileAtomic(getPIDPath(), []byte(strconv.Itoa(pid)), 0644, false)
+}
 
-	pidFile := filepath.Join(pidDir, "dns_manager.pid")
-	return os.WriteFile(pidFile, []byte(strconv.Itoa(pid)), 0644)
+// getPIDPath 返回PID文件路径
+func getPIDPath() string {
+	return filepath.Join(getDataDir(), "dns_manager.pid")
 }
 
 // getPID 从文件读取进程ID
 func getPID() (int, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
-	}
-	pidFile := filepath.Join(homeDir, ".go_dns_manager", "dns_manager.pid")
-	
-	data, err := os.ReadFile(pidFile)
+	data, err := os.ReadFile(getPIDPath())
 	if err != nil {
 		return 0, err
 	}
 
-	pid, err := strconv.Atoi(string(data))
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
 	if err != nil {
 		return 0, err
 	}
@@ -116,23 +104,23 @@ func isProcessRunning(pid int) bool {
 func stopDaemon() error {
 	pid, err := getPID()
 	if err != nil {
-		return fmt.Errorf("无法读取PID文件: %v", err)
+		return fmt.Errorf(tr("无法读取PID文件: %v"), err)
 	}
 
 	if !isProcessRunning(pid) {
 		// 进程不存在，清理PID文件
 		removePIDFile()
-		return fmt.Errorf("进程 %d 未运行（已清理PID文件）", pid)
+		return fmt.Errorf(tr("进程 %d 未运行（已清理PID文件）"), pid)
 	}
 
 	process, err := os.FindProcess(pid)
 	if err != nil {
-		return fmt.Errorf("无法找到进程: %v", err)
+		return fmt.Errorf(tr("无法找到进程: %v"), err)
 	}
 
 	// 发送终止信号
 	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("无法终止进程: %v", err)
+		return fmt.Errorf(tr("无法终止进程: %v"), err)
 	}
 
 	// 等待进程退出（最多等待5秒）
@@ -152,7 +140,7 @@ func stopDaemon() error {
 	// 删除PID文件
 	removePIDFile()
 
-	fmt.Printf("守护进程 (PID: %d) 已停止\n", pid)
+	fmt.Printf(tr("守护进程 (PID: %d) 已停止\n"), pid)
 	return nil
 }
 
@@ -160,39 +148,34 @@ func stopDaemon() error {
 func killDaemon() error {
 	pid, err := getPID()
 	if err != nil {
-		return fmt.Errorf("无法读取PID文件: %v", err)
+		return fmt.Errorf(tr("无法读取PID文件: %v"), err)
 	}
 
 	if !isProcessRunning(pid) {
 		removePIDFile()
-		return fmt.Errorf("进程 %d 未运行（已清理PID文件）", pid)
+		return fmt.Errorf(tr("进程 %d 未运行（已清理PID文件）"), pid)
 	}
 
 	process, err := os.FindProcess(pid)
 	if err != nil {
-		return fmt.Errorf("无法找到进程: %v", err)
+		return fmt.Errorf(tr("无法找到进程: %v"), err)
 	}
 
 	// 强制终止
 	if err := process.Signal(syscall.SIGKILL); err != nil {
-		return fmt.Errorf("无法强制终止进程: %v", err)
+		return fmt.Errorf(tr("无法强制终止进程: %v"), err)
 	}
 
 	time.Sleep(500 * time.Millisecond)
 	removePIDFile()
 
-	fmt.Printf("守护进程 (PID: %d) 已强制终止\n", pid)
+	fmt.Printf(tr("守护进程 (PID: %d) 已强制终止\n"), pid)
 	return nil
 }
 
 // removePIDFile 删除PID文件
 func removePIDFile() {
-	homeDir, _ := os.UserHomeDir()
-	if homeDir == "" {
-		homeDir = "."
-	}
-	pidFile := filepath.Join(homeDir, ".go_dns_manager", "dns_manager.pid")
-	os.Remove(pidFile)
+	os.Remove(getPIDPath())
 }
 
 // listDaemonProcesses 列出所有dns_manager进程
@@ -203,7 +186,7 @@ func listDaemonProcesses() ([]ProcessInfo, error) {
 	cmd := exec.Command("ps", "aux")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("执行ps命令失败: %v", err)
+		return nil, fmt.Errorf(tr("执行ps命令失败: %v"), err)
 	}
 
 	lines := strings.Split(string(output), "\n")
@@ -239,7 +222,7 @@ func getDaemonInfo() (map[string]interface{}, error) {
 	pid, err := getPID()
 	if err != nil {
 		info["running"] = false
-		info["error"] = "未找到PID文件"
+		info["error"] = tr("未找到PID文件")
 		return info, nil
 	}
 
@@ -247,7 +230,7 @@ func getDaemonInfo() (map[string]interface{}, error) {
 	info["running"] = isProcessRunning(pid)
 
 	if !isProcessRunning(pid) {
-		info["error"] = "进程不存在"
+		info["error"] = tr("进程不存在")
 		return info, nil
 	}
 
@@ -259,16 +242,37 @@ func getDaemonInfo() (map[string]interface{}, error) {
 	}
 
 	// 获取PID文件路径
-	homeDir, _ := os.UserHomeDir()
-	if homeDir == "" {
-		homeDir = "."
+	info["pid_file"] = getPIDPath()
+
+	// 读取运行统计（仅当状态文件属于当前守护进程时）
+	if state, err := loadDaemonState(); err == nil && state.PID == pid {
+		info["uptime"] = time.Since(state.StartTime)
+		info["cycles"] = state.Cycles
+		info["updates"] = state.Updates
+		info["consecutive_failures"] = state.ConsecutiveFailures
+		if !state.FailingSince.IsZero() {
+			info["failing_since"] = state.FailingSince
+		}
+		if !state.DegradedSince.IsZero() {
+			info["degraded_since"] = state.DegradedSince
+		}
+		if state.Version != "" {
+			info["version"] = state.Version
+		}
+		if state.CurrentIP != "" {
+			info["current_ip"] = state.CurrentIP
+		}
+		if !state.LastIPChange.IsZero() {
+			info["last_ip_change"] = state.LastIPChange
+		}
+		if state.LastError != "" {
+			info["last_error"] = state.LastError
+		}
 	}
-	pidFile := filepath.Join(homeDir, ".go_dns_manager", "dns_manager.pid")
-	info["pid_file"] = pidFile
 
 	// 检查日志文件
-	logDir := filepath.Join(homeDir, ".go_dns_manager", "logs")
-	today := time.Now().Format("2006-01-02")
+	logDir := getLogDir()
+	today := logNow().Format("2006-01-02")
 	logFile := filepath.Join(logDir, fmt.Sprintf("dns_manager_%s.log", today))
 	if _, err := os.Stat(logFile); err == nil {
 		info["log_file"] = logFile
@@ -290,7 +294,7 @@ func cleanupPIDFile() error {
 
 	if !isProcessRunning(pid) {
 		removePIDFile()
-		return fmt.Errorf("已清理无效的PID文件（进程 %d 不存在）", pid)
+		return fmt.Errorf(tr("已清理无效的PID文件（进程 %d 不存在）"), pid)
 	}
 
 	return nil
@@ -300,14 +304,14 @@ func cleanupPIDFile() error {
 func stopAllDaemonProcesses() error {
 	processes, err := listDaemonProcesses()
 	if err != nil {
-		return fmt.Errorf("列出进程失败: %v", err)
+		return fmt.Errorf(tr("列出进程失败: %v"), err)
 	}
 
 	if len(processes) == 0 {
 		return nil // 没有进程在运行
 	}
 
-	fmt.Printf("检测到 %d 个运行中的 dns_manager 进程，正在停止...\n", len(processes))
+	fmt.Printf(tr("检测到 %d 个运行中的 dns_manager 进程，正在停止...\n"), len(processes))
 
 	var stoppedCount int
 	var failedCount int
@@ -320,17 +324,17 @@ func stopAllDaemonProcesses() error {
 
 		process, err := os.FindProcess(proc.PID)
 		if err != nil {
-			fmt.Printf("  警告: 无法找到进程 %d: %v\n", proc.PID, err)
+			fmt.Printf(tr("  警告: 无法找到进程 %d: %v\n"), proc.PID, err)
 			failedCount++
 			continue
 		}
 
 		// 先尝试优雅停止
 		if err := process.Signal(syscall.SIGTERM); err != nil {
-			fmt.Printf("  警告: 无法发送 SIGTERM 到进程 %d: %v\n", proc.PID, err)
+			fmt.Printf(tr("  警告: 无法发送 SIGTERM 到进程 %d: %v\n"), proc.PID, err)
 			// 如果 SIGTERM 失败，尝试强制终止
 			if err := process.Signal(syscall.SIGKILL); err != nil {
-				fmt.Printf("  错误: 无法终止进程 %d: %v\n", proc.PID, err)
+				fmt.Printf(tr("  错误: 无法终止进程 %d: %v\n"), proc.PID, err)
 				failedCount++
 				continue
 			}
@@ -349,7 +353,7 @@ func stopAllDaemonProcesses() error {
 		// 如果进程还在运行，强制终止
 		if !stopped && isProcessRunning(proc.PID) {
 			if err := process.Signal(syscall.SIGKILL); err != nil {
-				fmt.Printf("  错误: 无法强制终止进程 %d: %v\n", proc.PID, err)
+				fmt.Printf(tr("  错误: 无法强制终止进程 %d: %v\n"), proc.PID, err)
 				failedCount++
 				continue
 			}
@@ -357,10 +361,10 @@ func stopAllDaemonProcesses() error {
 		}
 
 		if !isProcessRunning(proc.PID) {
-			fmt.Printf("  ✓ 已停止进程 PID: %d\n", proc.PID)
+			fmt.Printf(tr("  ✓ 已停止进程 PID: %d\n"), proc.PID)
 			stoppedCount++
 		} else {
-			fmt.Printf("  ✗ 无法停止进程 PID: %d\n", proc.PID)
+			fmt.Printf(tr("  ✗ 无法停止进程 PID: %d\n"), proc.PID)
 			failedCount++
 		}
 	}
@@ -372,11 +376,11 @@ func stopAllDaemonProcesses() error {
 	cleanupRemainingProcesses()
 
 	if failedCount > 0 {
-		return fmt.Errorf("成功停止 %d 个进程，%d 个进程停止失败", stoppedCount, failedCount)
+		return fmt.Errorf(tr("成功停止 %d 个进程，%d 个进程停止失败"), stoppedCount, failedCount)
 	}
 
 	if stoppedCount > 0 {
-		fmt.Printf("✓ 已成功停止 %d 个进程\n", stoppedCount)
+		fmt.Printf(tr("✓ 已成功停止 %d 个进程\n"), stoppedCount)
 	}
 
 	return nil
@@ -396,8 +400,8 @@ func cleanupRemainingProcesses() {
 			continue
 		}
 
-		// 只处理守护进程（包含 --daemon 参数）
-		if !strings.Contains(proc.Command, "--daemon") {
+		// 只处理守护进程
+		if !isDaemonCommand(proc.Command) {
 			continue
 		}
 
@@ -411,3 +415,12 @@ func cleanupRemainingProcesses() {
 		time.Sleep(200 * time.Millisecond)
 	}
 }
+
+// isDaemonCommand 判断进程命令行是否为守护进程（run 子命令或旧的 --daemon 参数）
+func isDaemonCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) > 1 && fields[1] == "run" {
+		return true
+	}
+	return strings.Contains(command, "--daemon")
+}
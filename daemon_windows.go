@@ -0,0 +1,11 @@
+package main
+
+import "syscall"
+
+// detachedProcessFlag 不继承父进程的控制台（DETACHED_PROCESS）
+const detachedProcessFlag = 0x00000008
+
+// detachedProcAttr 守护进程的进程属性：不继承控制台，关闭启动它的窗口后继续运行
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcessFlag}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// flapDamper 更新频率下限：两次写入之间至少间隔 min_update_interval，
+// 期间IP反复变化（如双 WAN 路由器在两条线路间切换）时只记录日志并通知一次
+type flapDamper struct {
+	mu         sync.Mutex
+	lastUpdate time.Time
+	notified   bool
+	suppressed int
+}
+
+var damper = &flapDamper{}
+
+// minUpdateInterval 返回配置的更新间隔下限，未配置或无效时为 0
+func (c *Config) minUpdateInterval() time.Duration {
+	if c.MinUpdateInterval == "" {
+		return 0
+	}
+	d, err := parseDurationOrZero(c.MinUpdateInterval)
+	if err != nil {
+		logError("min_update_interval 格式无效: %v，不限制更新频率", err)
+		return 0
+	}
+	return d
+}
+
+// hold 判断本次更新是否需要推迟；需要推迟时记录日志，并在本轮首次推迟时发送通知
+func (d *flapDamper) hold(floor time.Duration, record, oldIP, newIP string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if floor <= 0 || d.lastUpdate.IsZero() {
+		return false
+	}
+	wait := floor - time.Since(d.lastUpdate)
+	if wait <= 0 {
+		return false
+	}
+
+	d.suppressed++
+	logError("IP频繁变化 (%s -> %s)：距上次更新不足 %s，%s 后再更新（本轮已推迟 %d 次）",
+		oldIP, newIP, floor, wait.Round(time.Second), d.suppressed)
+
+	if !d.notified {
+		d.notified = true
+		notify(NotifyEvent{
+			Type:    EventFlapping,
+			Title:   tr("IP频繁变化"),
+			Message: tr("距上次更新不足 min_update_interval，已暂缓写入DNS记录"),
+			Record:  record,
+			OldIP:   oldIP,
+			NewIP:   newIP,
+		})
+	}
+	return true
+}
+
+// markUpdated 记录一次实际写入
+func (d *flapDamper) markUpdated() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastUpdate = time.Now()
+	d.notified = false
+	d.suppressed = 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// debugHTTP 是否记录 HTTP 请求追踪（--debug-http）
+var debugHTTP bool
+
+// debugTransport 记录每个 HTTP 请求的方法、URL、状态码、耗时、Cf-Ray，出错时记录响应内容
+type debugTransport struct {
+	name string
+	base http.RoundTripper
+}
+
+// newHTTPTransport 返回 HTTP 客户端使用的 Transport，启用 --debug-http 时包装追踪层；
+// 检测周期内的请求同时记录链路追踪 span（未启用链路追踪时直接转发）
+func newHTTPTransport(name string) http.RoundTripper {
+	return wrapTransport(name, http.DefaultTransport)
+}
+
+// wrapTransport 为自定义的底层 Transport 加上追踪，以及 --debug-http 时的调试日志
+func wrapTransport(name string, base http.RoundTripper) http.RoundTripper {
+	if debugHTTP {
+		base = &debugTransport{name: name, base: base}
+	}
+	return &traceTransport{name: name, base: base}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	logDebug("[%s] --> %s %s %s", t.name, req.Method, req.URL.String(), formatHeaders(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		logDebug("[%s] <-- %s %s 失败 (%s): %v", t.name, req.Method, req.URL.String(), latency, err)
+		return nil, err
+	}
+
+	cfRay := resp.Header.Get("Cf-Ray")
+	if cfRay == "" {
+		cfRay = "-"
+	}
+	logDebug("[%s] <-- %s %s %d (%s) cf-ray=%s", t.name, req.Method, req.URL.String(), resp.StatusCode, latency, cfRay)
+
+	// 出错时记录响应内容，并还原 Body 供调用方继续读取
+	if resp.StatusCode >= 400 {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr == nil {
+			logDebug("[%s] <-- 响应内容: %s", t.name, strings.TrimSpace(string(body)))
+		}
+	}
+
+	return resp, nil
+}
+
+// formatHeaders 格式化请求头（敏感值由日志层统一屏蔽）
+func formatHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(header[key], ",")
+		if strings.EqualFold(key, "Authorization") {
+			value = "****"
+		}
+		parts = append(parts, key+": "+value)
+	}
+	return "{" + strings.Join(parts, "; ") + "}"
+}
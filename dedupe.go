@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// recordDeleter 支持删除记录的DNS服务
+type recordDeleter interface {
+	DeleteDNSRecord(zoneID string, record DNSRecord) error
+}
+
+// duplicateSet 内容相同的一组记录：保留第一条，其余为重复
+type duplicateSet struct {
+	ZoneID     string      `json:"zone_id"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Content    string      `json:"content"`
+	Kept       DNSRecord   `json:"kept"`
+	Duplicates []DNSRecord `json:"duplicates"`
+}
+
+// findDuplicates 找出名称、类型与内容都相同的记录。
+// 每组保留第一条（API 返回顺序），多台机器或多次创建失败重试都可能留下这种记录
+func findDuplicates(records []DNSRecord) []duplicateSet {
+	var sets []duplicateSet
+	index := make(map[string]int)
+	for _, record := range records {
+		key := strings.ToLower(record.Name) + "/" + record.Type + "/" + record.Content
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(sets)
+			sets = append(sets, duplicateSet{Name: record.Name, Type: record.Type, Content: record.Content, Kept: record})
+			continue
+		}
+		sets[i].Duplicates = append(sets[i].Duplicates, record)
+	}
+
+	var duplicates []duplicateSet
+	for _, set := range sets {
+		if len(set.Duplicates) > 0 {
+			duplicates = append(duplicates, set)
+		}
+	}
+	return duplicates
+}
+
+// withoutRecords 返回 records 中不在 removed 里的记录
+func withoutRecords(records, removed []DNSRecord) []DNSRecord {
+	ids := make(map[string]bool, len(removed))
+	for _, record := range removed {
+		ids[record.ID] = true
+	}
+	var kept []DNSRecord
+	for _, record := range records {
+		if !ids[record.ID] {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
+
+// collapseDuplicates 同步前删除记录集中重复的记录，返回按剩余记录重新生成的计划。
+// 删除失败只记录错误，不影响本次同步
+func (r *Reconciler) collapseDuplicates(plan Plan, source string) Plan {
+	sets := findDuplicates(plan.Observed)
+	if len(sets) == 0 {
+		return plan
+	}
+	deleter, ok := r.Provider.(recordDeleter)
+	if !ok {
+		logDebug("DNS服务不支持删除记录，跳过去重")
+		return plan
+	}
+
+	var removed []DNSRecord
+	for _, set := range sets {
+		if r.DryRun {
+			logInfo("[dry-run] %s 有 %d 条重复的记录指向 %s，将删除多余的记录（未执行）", set.Name, len(set.Duplicates)+1, set.Content)
+			continue
+		}
+		r.Provider.SetAuditSource(source)
+		deleted := 0
+		for _, record := range set.Duplicates {
+			if err := deleter.DeleteDNSRecord(r.ZoneID, record); err != nil {
+				logError("删除重复记录失败: %s -> %s: %v", record.Name, record.Content, err)
+				continue
+			}
+			removed = append(removed, record)
+			deleted++
+		}
+		if deleted > 0 {
+			logInfo("已删除 %s 指向 %s 的 %d 条重复记录", set.Name, set.Content, deleted)
+		}
+	}
+	if len(removed) == 0 {
+		return plan
+	}
+	return planReconcile(plan.Desired, withoutRecords(plan.Observed, removed))
+}
+
+func cmdDedupeMain(args []string) int {
+	name, args := recordArgs(args)
+	fs, common := newFlagSet("dedupe")
+	recordType := fs.String("type", "", tr("只检查该类型的记录（默认检查所有类型）"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	fs.BoolVar(&dryRun, "dry-run", false, tr("只显示将要执行的更改，不修改DNS记录"))
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if !*yes && !dryRun && *output == outputJSON {
+		return failOutput(*output, errors.New(tr("--output json 需要与 --yes 一起使用")))
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+
+	// 未指定名称时检查所有跟随公网IP的记录
+	config := app.Config()
+	targets := config.ipTargets()
+	if name != "" {
+		resolved, err := resolveRecordArg(name)
+		if err != nil {
+			return failOutput(*output, err)
+		}
+		targets = []RecordTarget{{ZoneID: config.ZoneID, Name: resolved}}
+	}
+
+	client := app.Client()
+	var sets []duplicateSet
+	for _, target := range targets {
+		records, err := client.ListDNSRecords(target.ZoneID, target.Name)
+		if err != nil {
+			return failOutput(*output, err)
+		}
+		for _, set := range findDuplicates(filterRecords(records, strings.ToUpper(*recordType), "")) {
+			set.ZoneID = target.ZoneID
+			sets = append(sets, set)
+		}
+	}
+
+	if len(sets) == 0 {
+		if *output == outputJSON {
+			printJSON(map[string]interface{}{"success": true, "duplicates": []duplicateSet{}})
+			return 0
+		}
+		fmt.Println(tr("✓ 没有重复的记录"))
+		return 0
+	}
+
+	if *output != outputJSON {
+		fmt.Println(tr("\n以下记录的名称、类型与内容相同，每组保留第一条，删除其余记录:"))
+		for _, set := range sets {
+			fmt.Printf("  %s %s %s: %s %s, %s %s\n", set.Name, set.Type, set.Content,
+				tr("保留"), set.Kept.ID, tr("删除"), strings.Join(recordIDs(set.Duplicates), ", "))
+		}
+	}
+	if dryRun {
+		if *output == outputJSON {
+			printJSON(map[string]interface{}{"success": true, "dry_run": true, "duplicates": sets})
+			return 0
+		}
+		fmt.Println(tr("[dry-run] 未修改任何记录"))
+		return 0
+	}
+	if !*yes && !confirm(tr("确认删除？")) {
+		return 1
+	}
+
+	client.SetAuditSource(tr("命令行"))
+	deleted := 0
+	var failures []string
+	for _, set := range sets {
+		for _, record := range set.Duplicates {
+			if err := client.DeleteDNSRecord(set.ZoneID, record); err != nil {
+				failures = append(failures, fmt.Sprintf("%s (%s): %v", record.Name, record.ID, err))
+				continue
+			}
+			deleted++
+		}
+	}
+	if len(failures) > 0 {
+		return failOutput(*output, fmt.Errorf(tr("删除失败（已删除 %d 条）: %s"), deleted, strings.Join(failures, "; ")))
+	}
+	if *output == outputJSON {
+		printJSON(map[string]interface{}{"success": true, "duplicates": sets})
+		return 0
+	}
+	fmt.Printf(tr("✓ 已删除 %d 条重复记录\n"), deleted)
+	return 0
+}
+
+// recordIDs 返回记录的 ID 列表
+func recordIDs(records []DNSRecord) []string {
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
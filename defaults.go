@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// 发行版默认值，构建时通过 ldflags 注入，供路由器固件等发行方预置而无需修改源码：
+//
+//	go build -ldflags "-X main.defaultIPServices=https://ip.example.net,https://api.ipify.org -X main.defaultCheckInterval=30s"
+//
+// 为空时使用内置值；配置文件中的设置仍然优先
+var (
+	// defaultAPIBaseURL Cloudflare API 地址（目前只支持 Cloudflare，可指向兼容的 API 代理）
+	defaultAPIBaseURL = ""
+	// defaultIPServices IPv4 检测服务，逗号分隔，第一个为主服务
+	defaultIPServices = ""
+	// defaultIPServices6 IPv6 检测服务，逗号分隔
+	defaultIPServices6 = ""
+	// defaultCheckInterval 基础检测间隔（如 "30s"）
+	defaultCheckInterval = ""
+)
+
+// builtinAPIBaseURL 内置的 Cloudflare API 地址
+const builtinAPIBaseURL = "https://api.cloudflare.com/client/v4"
+
+// builtinCheckInterval 内置的基础检测间隔
+const builtinCheckInterval = 5 * time.Second
+
+// apiBaseURL 返回 Cloudflare API 地址
+func apiBaseURL() string {
+	if defaultAPIBaseURL != "" {
+		return strings.TrimSuffix(defaultAPIBaseURL, "/")
+	}
+	return builtinAPIBaseURL
+}
+
+// apiProbeAddr 返回离线探测连接的地址（API 主机的 443 或指定端口）
+func apiProbeAddr() string {
+	u, err := url.Parse(apiBaseURL())
+	if err != nil || u.Host == "" {
+		return "api.cloudflare.com:443"
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "443"
+	if u.Scheme == "http" {
+		port = "80"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// embeddedServices 解析注入的检测服务列表，未注入时返回 builtin
+func embeddedServices(value string, builtin []string) []string {
+	var services []string
+	for _, service := range strings.Split(value, ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			services = append(services, service)
+		}
+	}
+	if len(services) == 0 {
+		return builtin
+	}
+	return services
+}
+
+// embeddedCheckInterval 返回注入的基础检测间隔；格式无效时提示并使用内置值
+func embeddedCheckInterval() time.Duration {
+	if defaultCheckInterval == "" {
+		return builtinCheckInterval
+	}
+	d, err := time.ParseDuration(defaultCheckInterval)
+	if err != nil || d < time.Second {
+		fmt.Fprintf(os.Stderr, tr("构建时注入的检测间隔无效: %q，使用 %v\n"), defaultCheckInterval, builtinCheckInterval)
+		return builtinCheckInterval
+	}
+	return d
+}
+
+// embeddedDefaults 构建时注入的默认值，供 version 显示
+func embeddedDefaults() map[string]string {
+	defaults := map[string]string{}
+	for name, value := range map[string]string{
+		"api_base_url":   defaultAPIBaseURL,
+		"ip_services":    defaultIPServices,
+		"ip_services6":   defaultIPServices6,
+		"check_interval": defaultCheckInterval,
+	} {
+		if value != "" {
+			defaults[name] = value
+		}
+	}
+	return defaults
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DegradedConfig 连续失败达到阈值后进入降级模式：按较长的间隔检测并发送一次通知，
+// 不再以正常频率持续请求IP检测服务与 Cloudflare API；任一周期成功后恢复
+type DegradedConfig struct {
+	// After 连续失败多少个检测周期后进入降级模式（默认 20）
+	After int `json:"after,omitempty"`
+	// Interval 降级模式下的检测间隔（默认 10m）
+	Interval string `json:"interval,omitempty"`
+}
+
+// 降级模式的默认值
+const (
+	defaultDegradedAfter    = 20
+	defaultDegradedInterval = 10 * time.Minute
+)
+
+func (c *DegradedConfig) after() int {
+	if c.After > 0 {
+		return c.After
+	}
+	return defaultDegradedAfter
+}
+
+func (c *DegradedConfig) interval() time.Duration {
+	if c.Interval == "" {
+		return defaultDegradedInterval
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil || d < checkInterval {
+		logError("degraded.interval 格式无效: %s，使用默认值 %v", c.Interval, defaultDegradedInterval)
+		return defaultDegradedInterval
+	}
+	return d
+}
+
+// degradedMode 连续失败的次数与降级状态；配置重载后保留，不会因重建检测间隔而清零
+type degradedMode struct {
+	mu       sync.Mutex
+	failures int
+	since    time.Time // 本轮连续失败的开始时间
+	degraded bool
+}
+
+var degradation = &degradedMode{}
+
+// observe 记录一次检测周期结果；处于降级模式时返回降级间隔与 true
+func (d *degradedMode) observe(config *Config, err error) (time.Duration, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err == nil {
+		if d.degraded {
+			logInfo("检测已恢复（连续失败 %d 次，持续 %s），退出降级模式", d.failures, time.Since(d.since).Round(time.Second))
+			markDegraded(time.Time{})
+		}
+		d.failures = 0
+		d.degraded = false
+		return 0, false
+	}
+
+	if d.failures == 0 {
+		d.since = time.Now()
+	}
+	d.failures++
+	if config.Degraded == nil {
+		return 0, false
+	}
+	interval := config.Degraded.interval()
+	if !d.degraded && d.failures >= config.Degraded.after() {
+		d.degraded = true
+		markDegraded(time.Now())
+		elapsed := time.Since(d.since).Round(time.Second)
+		logError("连续失败 %d 次（持续 %s），进入降级模式，检测间隔改为 %s", d.failures, elapsed, interval)
+		notify(NotifyEvent{
+			Type:    EventDegraded,
+			Title:   tr("进入降级模式"),
+			Message: fmt.Sprintf(tr("连续失败 %d 次（持续 %s），检测间隔改为 %s，恢复后自动退出: %v"), d.failures, elapsed, interval, err),
+			Record:  config.RecordName,
+			OldIP:   app.CurrentIP(),
+		})
+	}
+	return interval, d.degraded
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxDiagnosticDumps 每种诊断文件保留的数量
+const maxDiagnosticDumps = 5
+
+// registerPprof 在管理 API 上挂载 /debug/pprof/，只接受来自本机且携带管理令牌的请求
+func registerPprof(mux *http.ServeMux, token string) {
+	mux.Handle("/debug/pprof/", localOnly(token, http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", localOnly(token, http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", localOnly(token, http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", localOnly(token, http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", localOnly(token, http.HandlerFunc(pprof.Trace)))
+	logInfo("已在管理 API 上启用 pprof（仅限本机访问）: /debug/pprof/")
+}
+
+// localOnly 拒绝非回环地址或经过反向代理转发的请求，并校验管理令牌
+func localOnly(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		if err != nil || ip == nil || !ip.IsLoopback() || r.Header.Get("X-Forwarded-For") != "" {
+			writeAPIError(w, http.StatusForbidden, tr("pprof 仅允许本机访问"))
+			return
+		}
+		apiAuth(token, r.Method, next.ServeHTTP).ServeHTTP(w, r)
+	})
+}
+
+// runtimeStats 运行时内存与协程统计，用于在 /status 中观察内存是否持续增长
+func runtimeStats() map[string]interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return map[string]interface{}{
+		"goroutines":   runtime.NumGoroutine(),
+		"heap_alloc":   m.HeapAlloc,
+		"heap_inuse":   m.HeapInuse,
+		"heap_objects": m.HeapObjects,
+		"sys":          m.Sys,
+		"num_gc":       m.NumGC,
+	}
+}
+
+// getDiagnosticsDir 返回诊断文件目录
+func getDiagnosticsDir() string {
+	return filepath.Join(getDataDir(), "diagnostics")
+}
+
+// startDiagnosticsSignal 收到 SIGUSR1 时写出协程栈与堆快照，返回停止函数。
+// 没有该信号的平台（Windows）不监听，通过 pprof 获取诊断信息
+func startDiagnosticsSignal() func() {
+	sig := diagnosticsSignal()
+	if sig == nil {
+		return func() {}
+	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, sig)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				files, err := writeDiagnostics()
+				if err != nil {
+					logError("写入诊断信息失败: %v", err)
+					continue
+				}
+				logInfo("已写入诊断信息: %s", strings.Join(files, ", "))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// writeDiagnostics 写出协程栈（文本）与堆快照（pprof 格式），并记录内存统计
+func writeDiagnostics() ([]string, error) {
+	dir := getDiagnosticsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	stamp := time.Now().UTC().Format("20060102-150405")
+
+	goroutinePath := filepath.Join(dir, "goroutines-"+stamp+".txt")
+	if err := writeProfile(goroutinePath, func(f *os.File) error {
+		return rpprof.Lookup("goroutine").WriteTo(f, 2)
+	}); err != nil {
+		return nil, err
+	}
+
+	heapPath := filepath.Join(dir, "heap-"+stamp+".pprof")
+	if err := writeProfile(heapPath, func(f *os.File) error {
+		runtime.GC()
+		return rpprof.WriteHeapProfile(f)
+	}); err != nil {
+		return nil, err
+	}
+
+	stats := runtimeStats()
+	logInfo("运行时统计: 协程 %d, 堆内存 %d 字节, 堆对象 %d, 系统内存 %d 字节, GC %d 次",
+		stats["goroutines"], stats["heap_alloc"], stats["heap_objects"], stats["sys"], stats["num_gc"])
+
+	pruneDiagnostics(dir, "goroutines-")
+	pruneDiagnostics(dir, "heap-")
+	return []string{goroutinePath, heapPath}, nil
+}
+
+func writeProfile(path string, write func(f *os.File) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf(tr("写入 %s 失败: %v"), path, err)
+	}
+	return f.Close()
+}
+
+// pruneDiagnostics 只保留最近的若干个诊断文件
+func pruneDiagnostics(dir, prefix string) {
+	matches, err := filepath.Glob(filepath.Join(dir, prefix+"*"))
+	if err != nil || len(matches) <= maxDiagnosticDumps {
+		return
+	}
+	// 文件名中的时间戳按字典序即时间顺序
+	sort.Strings(matches)
+	for _, path := range matches[:len(matches)-maxDiagnosticDumps] {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// diagnosticsSignal 没有 SIGUSR1 的平台返回 nil
+func diagnosticsSignal() os.Signal {
+	return nil
+}
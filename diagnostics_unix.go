@@ -0,0 +1,13 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// diagnosticsSignal 触发写出诊断信息的信号
+func diagnosticsSignal() os.Signal {
+	return syscall.SIGUSR1
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 终端颜色
+const (
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiReset = "\033[0m"
+)
+
+// colorEnabled 标准输出为终端且未设置 NO_COLOR 时启用颜色
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// stdoutIsTerminal 标准输出是否为终端
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdinIsTerminal 标准输入是否为终端；systemd、cron、容器中运行时不是终端，无法进行交互
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// systemd 默认把标准输入指向 /dev/null，它同样是字符设备
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// colorize 为文本添加颜色
+func colorize(color, text string) string {
+	if !colorEnabled() {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// printRecordDiff 逐条输出当前与期望记录集的差异：删除的行以 - 标红，新增的行以 + 标绿，未变化的记录原样输出
+func printRecordDiff(current, desired []DNSRecord) {
+	format := "%s %-30s %-8s %-30s %-8s %-8s"
+	line := func(prefix string, record DNSRecord) string {
+		return fmt.Sprintf(format, prefix, record.Name, record.Type, record.Content,
+			formatTTL(record.TTL), formatBool(record.Proxied))
+	}
+
+	fmt.Println(strings.Repeat("-", 92))
+	fmt.Printf(format+"\n", " ", tr("名称"), tr("类型"), tr("内容"), "TTL", tr("代理"))
+	fmt.Println(strings.Repeat("-", 92))
+	for i := range current {
+		if i < len(desired) && sameRecord(current[i], desired[i]) {
+			fmt.Println(line(" ", current[i]))
+			continue
+		}
+		fmt.Println(colorize(ansiRed, line("-", current[i])))
+		if i < len(desired) {
+			fmt.Println(colorize(ansiGreen, line("+", desired[i])))
+		}
+	}
+	for i := len(current); i < len(desired); i++ {
+		fmt.Println(colorize(ansiGreen, line("+", desired[i])))
+	}
+	fmt.Println(strings.Repeat("-", 92))
+}
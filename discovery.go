@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// discoveredRecord 从外部来源（Docker 容器标签、Kubernetes 注解）发现的记录，指向本机公网IP
+type discoveredRecord struct {
+	ZoneID  string
+	Name    string
+	Proxied bool
+	// Owner 声明该记录的对象，用于日志（如 容器 gitea）
+	Owner string
+}
+
+// requestSync 通知主循环同步一次（通道缓冲 1，多个事件合并为一次同步）
+func requestSync(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// syncDiscoveredRecords 让发现的记录指向当前公网IP，并删除不再被声明的记录。
+// 所有权通过记录备注 comment 区分：只修改和删除带有该备注的记录，不同来源互不影响
+func syncDiscoveredRecords(source, comment, defaultZoneID string, records []discoveredRecord) error {
+	app.cycleMu.Lock()
+	defer app.cycleMu.Unlock()
+
+	config := app.Config()
+	client := app.Client()
+	ip := app.CurrentIP()
+	if client == nil || ip == "" {
+		return nil
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	r := newReconciler()
+	// 发现的记录由本函数维护，不参与降低TTL
+	r.TTLStrategy = nil
+	client.SetAuditSource(source)
+	wanted := map[string]bool{}
+	zones := map[string]bool{config.ZoneID: true}
+	if defaultZoneID != "" {
+		zones[defaultZoneID] = true
+	}
+	var failures []string
+	for _, d := range records {
+		wanted[d.ZoneID+"/"+strings.ToLower(d.Name)] = true
+		zones[d.ZoneID] = true
+		if err := syncDiscoveredRecord(r, d, comment, source, ip); err != nil {
+			failures = append(failures, d.Name+": "+err.Error())
+		}
+	}
+
+	// 删除不再被声明的记录（包括守护进程停止期间删除的对象）
+	for zoneID := range zones {
+		existing, err := client.ListZoneDNSRecords(zoneID)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		for _, record := range existing {
+			if record.Comment != comment || wanted[zoneID+"/"+strings.ToLower(record.Name)] {
+				continue
+			}
+			if r.DryRun {
+				logInfo("[dry-run] 计划操作 %s: %s -> %s（未执行）", "delete", record.Name, record.Content)
+				continue
+			}
+			if err := client.DeleteDNSRecord(zoneID, record); err != nil {
+				failures = append(failures, record.Name+": "+err.Error())
+				continue
+			}
+			logInfo("%s 不再声明记录 %s (%s)，已删除", source, record.Name, record.Content)
+		}
+	}
+
+	if len(failures) > 0 {
+		err := fmt.Errorf(tr("同步 %s 记录失败: %s"), source, strings.Join(failures, "; "))
+		logError("%v", err)
+		return err
+	}
+	return nil
+}
+
+// syncDiscoveredRecord 同步一个发现的记录；同名记录中存在其他来源创建的记录时不接管
+func syncDiscoveredRecord(r *Reconciler, d discoveredRecord, comment, source, ip string) error {
+	t := r.forTarget(RecordTarget{ZoneID: d.ZoneID, Name: d.Name, Type: r.Type})
+	records, err := t.Observe()
+	if err != nil {
+		return err
+	}
+
+	var owned []DNSRecord
+	for _, record := range records {
+		if record.Comment == comment {
+			owned = append(owned, record)
+		}
+	}
+	if len(owned) == 0 && len(records) > 0 {
+		logError("%s 已存在不是由 %s 创建的记录，跳过（%s）", d.Name, source, d.Owner)
+		return nil
+	}
+
+	plan := planReconcile(DesiredState{ZoneID: d.ZoneID, Name: d.Name, Type: r.Type, IP: ip, Exclusive: true}, owned)
+	if plan.Action == planNone {
+		return nil
+	}
+	if plan.Action == planCreate {
+		plan.Request.Comment = comment
+		plan.Request.Proxied = d.Proxied
+	}
+	logInfo("%s: %s -> %s", d.Owner, d.Name, ip)
+	return t.Apply(plan, source)
+}
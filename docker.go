@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DockerConfig 根据本机 Docker 容器的标签维护DNS记录（单机版 external-dns）：
+// 带有 dns_manager.hostname 标签的容器运行时，标签中的名称指向本机公网IP，容器停止后删除记录
+type DockerConfig struct {
+	// Socket Docker 守护进程的 Unix 套接字，默认 /var/run/docker.sock
+	Socket string `json:"socket,omitempty"`
+	// ZoneID 记录所在区域，为空时使用主配置的 zone_id；容器可用 dns_manager.zone_id 标签覆盖
+	ZoneID string `json:"zone_id,omitempty"`
+	// Proxied 新建记录是否开启 Cloudflare 代理；容器可用 dns_manager.proxied 标签覆盖
+	Proxied bool `json:"proxied,omitempty"`
+}
+
+// 容器标签
+const (
+	dockerLabelHostname = "dns_manager.hostname"
+	dockerLabelZoneID   = "dns_manager.zone_id"
+	dockerLabelProxied  = "dns_manager.proxied"
+)
+
+// dockerRecordComment 写在记录备注中的所有权标记：只修改和删除带有该标记的记录，不影响手动创建的记录
+const dockerRecordComment = "managed by dns_manager (docker)"
+
+// defaultDockerSocket Docker 默认套接字
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// dockerSyncChan 容器启动或停止时通知主循环同步记录
+var dockerSyncChan = make(chan struct{}, 1)
+
+// newDockerClient 创建通过 Unix 套接字访问 Docker API 的客户端
+func newDockerClient(socket string) *http.Client {
+	if socket == "" {
+		socket = defaultDockerSocket
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &http.Client{Transport: transport}
+}
+
+// dockerContainer /containers/json 返回的容器信息
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// listDockerHostnames 列出运行中的容器声明的记录
+func listDockerHostnames(client *http.Client, config *Config) ([]discoveredRecord, error) {
+	filters := url.QueryEscape(`{"label":["` + dockerLabelHostname + `"]}`)
+	resp, err := client.Get("http://docker/containers/json?filters=" + filters)
+	if err != nil {
+		return nil, fmt.Errorf(tr("连接 Docker 失败: %v"), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(tr("Docker API 返回错误 (状态码: %d)"), resp.StatusCode)
+	}
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+
+	var hostnames []discoveredRecord
+	for _, c := range containers {
+		zoneID := c.Labels[dockerLabelZoneID]
+		if zoneID == "" {
+			zoneID = config.Docker.ZoneID
+		}
+		if zoneID == "" {
+			zoneID = config.ZoneID
+		}
+		proxied := config.Docker.Proxied
+		if value, ok := c.Labels[dockerLabelProxied]; ok {
+			proxied = value == "true"
+		}
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		for _, hostname := range strings.Split(c.Labels[dockerLabelHostname], ",") {
+			if hostname = strings.TrimSpace(hostname); hostname != "" {
+				hostnames = append(hostnames, discoveredRecord{ZoneID: zoneID, Name: hostname, Proxied: proxied, Owner: tr("容器") + " " + name})
+			}
+		}
+	}
+	return hostnames, nil
+}
+
+// syncDockerRecords 让运行中容器声明的记录指向当前公网IP，并删除已停止容器的记录
+func syncDockerRecords() error {
+	config := app.Config()
+	if config.Docker == nil {
+		return nil
+	}
+	hostnames, err := listDockerHostnames(newDockerClient(config.Docker.Socket), config)
+	if err != nil {
+		logError("%v", err)
+		return err
+	}
+	return syncDiscoveredRecords("docker", dockerRecordComment, config.Docker.ZoneID, hostnames)
+}
+
+// watchDocker 订阅 Docker 容器事件，容器启动或停止时请求同步；连接断开后每隔一段时间重连
+func watchDocker(socket string) {
+	client := newDockerClient(socket)
+	filters := url.QueryEscape(`{"type":["container"],"event":["start","die","destroy"]}`)
+	backoff := 5 * time.Second
+
+	for {
+		resp, err := client.Get("http://docker/events?filters=" + filters)
+		if err != nil {
+			logError("连接 Docker 事件流失败: %v，%s 后重试", err, backoff)
+			time.Sleep(backoff)
+			continue
+		}
+
+		logInfo("已连接 Docker 事件流")
+		// 连接后同步一次，补上断开期间的变化
+		requestSync(dockerSyncChan)
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event struct {
+				Action string `json:"Action"`
+				Actor  struct {
+					Attributes map[string]string `json:"Attributes"`
+				} `json:"Actor"`
+			}
+			if err := decoder.Decode(&event); err != nil {
+				logError("Docker 事件流已断开: %v，%s 后重连", err, backoff)
+				break
+			}
+			if _, ok := event.Actor.Attributes[dockerLabelHostname]; ok {
+				logDebug("容器事件: %s %s", event.Action, event.Actor.Attributes["name"])
+				requestSync(dockerSyncChan)
+			}
+		}
+		resp.Body.Close()
+		time.Sleep(backoff)
+	}
+}
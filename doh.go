@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DoHPrecheckConfig 同步前先通过 DNS-over-HTTPS 查询记录，全部记录已指向检测到的IP时跳过本周期，
+// 不调用 Cloudflare API（适合由 cron 或 systemd timer 定时执行 --once 的场景）
+type DoHPrecheckConfig struct {
+	// URL 支持 JSON 格式（application/dns-json）的 DoH 地址，默认 https://1.1.1.1/dns-query
+	URL string `json:"url,omitempty"`
+}
+
+// defaultDoHURL 默认的 DoH 地址
+const defaultDoHURL = "https://1.1.1.1/dns-query"
+
+func (c *DoHPrecheckConfig) url() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return defaultDoHURL
+}
+
+// dohQuery 通过 DoH 查询记录，返回应答中的地址
+func dohQuery(endpoint, name, recordType string) ([]string, error) {
+	req, err := http.NewRequest("GET", endpoint+"?name="+url.QueryEscape(name)+"&type="+recordType, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	client := &http.Client{Timeout: dnsQueryTimeout, Transport: newHTTPTransport("doh")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
+	}
+
+	var result struct {
+		Status int `json:"Status"`
+		Answer []struct {
+			Type int    `json:"type"`
+			Data string `json:"data"`
+		} `json:"Answer"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	// Status 为 DNS 响应码，NXDOMAIN（3）表示记录不存在
+	if result.Status != 0 && result.Status != 3 {
+		return nil, fmt.Errorf(tr("DoH 查询失败: RCODE %d"), result.Status)
+	}
+	var answers []string
+	for _, answer := range result.Answer {
+		// 跳过 CNAME 等中间结果
+		if ip := net.ParseIP(answer.Data); ip != nil && ipMatchesType(answer.Data, recordType) {
+			answers = append(answers, ip.String())
+		}
+	}
+	return answers, nil
+}
+
+// dohPrecheck 跟随公网IP的全部记录是否都已解析到 ip；任一记录不一致或查询失败时返回 false。
+// 开启代理的记录解析为 Cloudflare 的地址，总是按正常流程检查
+func dohPrecheck(config *Config, ip string) bool {
+	endpoint := config.DoHPrecheck.url()
+	for _, target := range config.ipTargets() {
+		name, err := app.Client().recordName(target.ZoneID, target.Name)
+		if err != nil {
+			logError("DoH 预检查失败: %v", err)
+			return false
+		}
+		answers, err := dohQuery(endpoint, strings.ToLower(name), target.Type)
+		if err != nil {
+			logError("DoH 预检查失败: %s: %v", name, redactError(err))
+			return false
+		}
+		if !containsString(answers, ip) {
+			logInfo("DoH 预检查: %s 解析为 %v，需要同步", name, answers)
+			return false
+		}
+	}
+	return true
+}
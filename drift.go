@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DriftConfig 检测记录被外部修改（控制台手动修改、其他工具），按策略处理，而不是在下次IP变化时静默覆盖
+type DriftConfig struct {
+	// Policy reassert（改回本机IP）、adopt（接受外部的值，下次IP变化时从该值更新）、alert（停止维护该记录并通知）
+	Policy string `json:"policy"`
+	// Interval IP未变化时检查记录的间隔，默认 10m
+	Interval string `json:"interval,omitempty"`
+}
+
+// 外部修改的处理策略
+const (
+	driftReassert = "reassert"
+	driftAdopt    = "adopt"
+	driftAlert    = "alert"
+)
+
+// defaultDriftInterval 默认检查间隔
+const defaultDriftInterval = 10 * time.Minute
+
+// driftEntry 单个记录的跟踪状态
+type driftEntry struct {
+	// ID 本机维护的记录（内容为本机IP的记录）
+	ID string
+	// Adopted 接受的外部值（adopt）
+	Adopted string
+	// Held 已停止维护（alert，或 adopt 时记录被删除）
+	Held bool
+}
+
+// driftTracker 记录本机维护的记录，检查是否被外部修改
+type driftTracker struct {
+	mu      sync.Mutex
+	checked time.Time
+	entries map[string]*driftEntry
+}
+
+var drift = &driftTracker{}
+
+func (c *DriftConfig) interval() time.Duration {
+	if c.Interval == "" {
+		return defaultDriftInterval
+	}
+	d, err := parseDurationOrZero(c.Interval)
+	if err != nil || d <= 0 {
+		logError("drift.interval 格式无效: %s，使用默认值 %v", c.Interval, defaultDriftInterval)
+		return defaultDriftInterval
+	}
+	return d
+}
+
+func driftKey(t RecordTarget) string {
+	return t.ZoneID + "/" + strings.ToLower(t.Name) + "/" + t.Type
+}
+
+// check IP未变化时按间隔检查各记录是否仍指向本机IP（或接受的外部值），不一致时按策略处理
+func (d *driftTracker) check(config *Config, r *Reconciler, ip string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if time.Since(d.checked) < config.Drift.interval() {
+		return
+	}
+	d.checked = time.Now()
+	if d.entries == nil {
+		d.entries = make(map[string]*driftEntry)
+	}
+
+	targets := config.recordTargets()
+	r.Prefetch(targets)
+	for _, target := range targets {
+		t := r.forTarget(target)
+		observed, err := t.Observe()
+		if err != nil {
+			logError("检查记录 %s 是否被修改失败: %v", target.Name, err)
+			continue
+		}
+
+		key := driftKey(target)
+		entry := d.entries[key]
+		if entry == nil {
+			entry = &driftEntry{}
+			d.entries[key] = entry
+		}
+		expected := ip
+		if entry.Adopted != "" {
+			expected = entry.Adopted
+		}
+		if found := findContent(observed, expected); found != nil {
+			if entry.Held {
+				logInfo("%s 已恢复指向 %s，继续自动维护", target.Name, expected)
+				entry.Held = false
+			}
+			entry.ID = found.ID
+			continue
+		}
+		if entry.Held {
+			continue
+		}
+
+		// 本机维护的记录被修改或删除
+		var changed *DNSRecord
+		for i := range observed {
+			if entry.ID != "" && observed[i].ID == entry.ID {
+				changed = &observed[i]
+			}
+		}
+		desc := tr("已被删除")
+		if changed != nil {
+			desc = fmt.Sprintf(tr("已被修改为 %s"), changed.Content)
+		}
+		logError("%s 的记录%s（本机IP %s）", target.Name, desc, expected)
+
+		switch config.Drift.Policy {
+		case driftReassert:
+			oldIP := ""
+			if changed != nil {
+				oldIP = changed.Content
+			}
+			plan := planReconcile(t.Desired(ip, oldIP), observed)
+			if err := t.Apply(plan, tr("恢复被外部修改的记录")); err != nil {
+				logError("恢复记录 %s 失败: %v", target.Name, err)
+				continue
+			}
+			logInfo("已把 %s 改回 %s", target.Name, ip)
+			entry.Adopted = ""
+		case driftAdopt:
+			if changed == nil {
+				entry.Held = true
+				logInfo("%s 的记录已被删除，停止自动维护", target.Name)
+				continue
+			}
+			entry.Adopted = changed.Content
+			logInfo("接受 %s 的新值 %s，IP变化时从该值更新", target.Name, changed.Content)
+		default:
+			entry.Held = true
+			notify(NotifyEvent{
+				Type:    EventDrift,
+				Title:   tr("DNS记录被外部修改"),
+				Message: fmt.Sprintf(tr("%s 的记录%s，已停止自动维护该记录；改回 %s 后恢复"), target.Name, desc, expected),
+				Record:  target.Name,
+				OldIP:   expected,
+			})
+		}
+	}
+}
+
+// filter 返回IP变化时需要同步的记录：跳过已停止维护的记录，接受了外部值的记录从该值更新
+func (d *driftTracker) filter(targets []RecordTarget) []RecordTarget {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var filtered []RecordTarget
+	for _, target := range targets {
+		entry := d.entries[driftKey(target)]
+		switch {
+		case entry == nil:
+		case entry.Held:
+			logInfo("%s 已被外部修改，跳过（改回本机IP后恢复自动维护）", target.Name)
+			continue
+		case entry.Adopted != "":
+			target.OldIP = entry.Adopted
+		}
+		filtered = append(filtered, target)
+	}
+	return filtered
+}
+
+// synced 同步成功的记录已指向新IP，清除接受的外部值，下次检查时重新确定记录ID
+func (d *driftTracker) synced(results []SyncResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, result := range results {
+		if entry := d.entries[driftKey(result.Target)]; entry != nil && result.Err == nil && !entry.Held {
+			delete(d.entries, driftKey(result.Target))
+		}
+	}
+}
+
+// overridden 记录是否已按策略停止维护或接受了外部的值，这些记录不应被改回本机IP
+func (d *driftTracker) overridden(target RecordTarget) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry := d.entries[driftKey(target)]
+	return entry != nil && (entry.Held || entry.Adopted != "")
+}
+
+// findContent 返回内容为 content 的记录
+func findContent(records []DNSRecord, content string) *DNSRecord {
+	for i := range records {
+		if records[i].Content == content {
+			return &records[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 错误类别，使用 errors.Is 判断；--once 模式按类别返回不同的退出码
+var (
+	ErrAuth           = errors.New("authentication failed")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrRecordNotFound = errors.New("record not found")
+	ErrNetwork        = errors.New("network error")
+	ErrConfig         = errors.New("configuration incomplete")
+)
+
+// once 模式的退出码：0 成功，1 其他错误，2 参数错误
+const (
+	exitAuth        = 3
+	exitRateLimited = 4
+	exitNotFound    = 5
+	exitNetwork     = 6
+	exitConfig      = 7
+)
+
+// classifiedError 带类别的错误；Error() 保持原有信息，errors.Is 可同时匹配类别与原错误
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// classify 为错误加上类别，kind 或 err 为 nil 时原样返回
+func classify(kind, err error) error {
+	if kind == nil || err == nil {
+		return err
+	}
+	return &classifiedError{kind: kind, err: err}
+}
+
+// statusKind 按 HTTP 状态码判断错误类别；服务端错误与网络错误同属暂时性错误
+func statusKind(status int) error {
+	switch {
+	case status == 401 || status == 403:
+		return ErrAuth
+	case status == 429:
+		return ErrRateLimited
+	case status == 404:
+		return ErrRecordNotFound
+	case status >= 500:
+		return ErrNetwork
+	}
+	return nil
+}
+
+// apiCodeKind 按 Cloudflare 错误码判断错误类别
+func apiCodeKind(code int) error {
+	switch code {
+	case 9106, 9109, 10000, 10001:
+		return ErrAuth
+	case 971, 10429:
+		return ErrRateLimited
+	case 81044:
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// apiResultError 根据响应中的 errors 字段构造错误（success 为 false 时）
+func apiResultError(items []struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}) error {
+	var errorMsg string
+	var kind error
+	for _, e := range items {
+		errorMsg += fmt.Sprintf("Code %d: %s; ", e.Code, e.Message)
+		if kind == nil {
+			kind = apiCodeKind(e.Code)
+		}
+	}
+	return classify(kind, fmt.Errorf(tr("API 错误: %s"), errorMsg))
+}
+
+// joinedError 多个记录同步失败时的汇总错误，errors.Is 可匹配其中任一错误
+type joinedError struct {
+	msg  string
+	errs []error
+}
+
+func (e *joinedError) Error() string {
+	return e.msg
+}
+
+func (e *joinedError) Unwrap() []error {
+	return e.errs
+}
+
+// exitCodeFor 返回错误对应的退出码；同时包含多种类别时，需要人工处理的类别优先
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrAuth):
+		return exitAuth
+	case errors.Is(err, ErrRecordNotFound):
+		return exitNotFound
+	case errors.Is(err, ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, ErrNetwork):
+		return exitNetwork
+	case errors.Is(err, ErrConfig):
+		return exitConfig
+	}
+	return 1
+}
+
+// APIResponseMeta 失败请求的响应元数据；向 Cloudflare 支持提交问题时可引用 Cf-Ray
+type APIResponseMeta struct {
+	Method          string `json:"method"`
+	Path            string `json:"path"`
+	Status          int    `json:"status"`
+	CfRay           string `json:"cf_ray,omitempty"`
+	RateLimit       string `json:"ratelimit,omitempty"`
+	RateLimitPolicy string `json:"ratelimit_policy,omitempty"`
+	RetryAfter      string `json:"retry_after,omitempty"`
+}
+
+// String 附加在错误信息后的摘要
+func (m APIResponseMeta) String() string {
+	var parts []string
+	if m.CfRay != "" {
+		parts = append(parts, "cf-ray: "+m.CfRay)
+	}
+	if m.RateLimit != "" {
+		parts = append(parts, "ratelimit: "+m.RateLimit)
+	}
+	if m.RetryAfter != "" {
+		parts = append(parts, "retry-after: "+m.RetryAfter)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// responseMeta 读取响应中的请求信息、Cf-Ray 与限流相关响应头
+func responseMeta(resp *http.Response) APIResponseMeta {
+	meta := APIResponseMeta{
+		Status:          resp.StatusCode,
+		CfRay:           resp.Header.Get("Cf-Ray"),
+		RateLimit:       resp.Header.Get("Ratelimit"),
+		RateLimitPolicy: resp.Header.Get("Ratelimit-Policy"),
+		RetryAfter:      resp.Header.Get("Retry-After"),
+	}
+	if resp.Request != nil {
+		meta.Method = resp.Request.Method
+		meta.Path = resp.Request.URL.Path
+	}
+	return meta
+}
+
+// apiResponseError 带响应元数据的 API 错误，错误信息末尾附带 Cf-Ray 等信息
+type apiResponseError struct {
+	meta APIResponseMeta
+	err  error
+}
+
+func (e *apiResponseError) Error() string {
+	if summary := e.meta.String(); summary != "" {
+		return e.err.Error() + " (" + summary + ")"
+	}
+	return e.err.Error()
+}
+
+func (e *apiResponseError) Unwrap() error {
+	return e.err
+}
+
+// withResponseMeta 为 API 错误附加响应元数据
+func withResponseMeta(resp *http.Response, err error) error {
+	if err == nil || resp == nil {
+		return err
+	}
+	return &apiResponseError{meta: responseMeta(resp), err: err}
+}
+
+// apiResponseMetas 收集错误链中所有失败请求的元数据（多个记录同步失败时包含每个请求）
+func apiResponseMetas(err error) []APIResponseMeta {
+	var metas []APIResponseMeta
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+			return
+		case *apiResponseError:
+			metas = append(metas, e.meta)
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, inner := range u.Unwrap() {
+				walk(inner)
+			}
+		}
+	}
+	walk(err)
+	return metas
+}
+
+// errorCategory 错误类别名称，记录在历史中
+func errorCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return "auth"
+	case errors.Is(err, ErrRecordNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrNetwork):
+		return "network"
+	case errors.Is(err, ErrConfig):
+		return "config"
+	}
+	return ""
+}
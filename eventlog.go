@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// defaultEventLogSource 未指定名称时的事件源
+const defaultEventLogSource = "DNS Manager"
+
+// eventLog 系统事件日志（目前只有 Windows 事件日志），错误日志同时写入其中，供系统监控采集
+type eventLog interface {
+	Error(message string) error
+	Close() error
+}
+
+// EventLogConfig 将错误日志同时写入 Windows 事件日志（应用程序日志）
+type EventLogConfig struct {
+	// Source 事件源名称（默认 "DNS Manager"）；首次使用时在注册表中注册，需要管理员权限
+	Source string `json:"source,omitempty"`
+}
+
+func (c *EventLogConfig) source() string {
+	if c.Source != "" {
+		return c.Source
+	}
+	return defaultEventLogSource
+}
+
+// configureLogOutputs 按配置设置错误日志的合并与事件日志输出，在 initLogger 之后调用
+func configureLogOutputs(config *Config) {
+	globalLogger.dedup = newLogDeduper(config.logDedupWindow())
+	if config.EventLog == nil {
+		return
+	}
+	events, err := openEventLog(config.EventLog.source())
+	if err != nil {
+		logError("打开事件日志失败: %v", err)
+		return
+	}
+	globalLogger.eventLog = events
+}
+
+// writeEventLog 将错误写入事件日志；失败时只输出到标准错误，避免递归写日志
+func (l *Logger) writeEventLog(message string) {
+	if l.eventLog == nil {
+		return
+	}
+	if err := l.eventLog.Error(message); err != nil {
+		fmt.Fprintf(os.Stderr, tr("写入事件日志失败: %v\n"), err)
+	}
+}
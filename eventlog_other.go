@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// openEventLog 事件日志只在 Windows 上可用
+func openEventLog(source string) (eventLog, error) {
+	return nil, errors.New(tr("事件日志仅在 Windows 上可用，请改用日志文件或 systemd 日志"))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+	procRegCreateKeyExW       = advapi32.NewProc("RegCreateKeyExW")
+	procRegSetValueExW        = advapi32.NewProc("RegSetValueExW")
+)
+
+const (
+	// eventSourceKey 应用程序日志事件源的注册表位置
+	eventSourceKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+	// eventMessageFile 系统自带的消息文件，事件ID 1-1000 直接显示传入的文本，无需自带消息资源
+	eventMessageFile = `%SystemRoot%\System32\EventCreate.exe`
+	eventTypeError   = 0x0001
+	eventTypesAll    = 0x0007 // 错误、警告、信息
+	eventID          = 1
+)
+
+// windowsEventLog 已注册的 Windows 事件源
+type windowsEventLog struct {
+	handle uintptr
+}
+
+// openEventLog 注册（如尚未注册）并打开事件源
+func openEventLog(source string) (eventLog, error) {
+	if err := installEventSource(source); err != nil {
+		return nil, err
+	}
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(name)))
+	if handle == 0 {
+		return nil, fmt.Errorf(tr("打开事件源 %s 失败: %v"), source, err)
+	}
+	return &windowsEventLog{handle: handle}, nil
+}
+
+// installEventSource 在注册表中注册事件源，已注册时直接返回
+func installEventSource(source string) error {
+	path, err := syscall.UTF16PtrFromString(eventSourceKey + source)
+	if err != nil {
+		return err
+	}
+	var key syscall.Handle
+	if syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, path, 0, syscall.KEY_READ, &key) == nil {
+		syscall.RegCloseKey(key)
+		return nil
+	}
+
+	var disposition uint32
+	r, _, _ := procRegCreateKeyExW.Call(uintptr(syscall.HKEY_LOCAL_MACHINE), uintptr(unsafe.Pointer(path)), 0, 0, 0,
+		uintptr(syscall.KEY_WRITE), 0, uintptr(unsafe.Pointer(&key)), uintptr(unsafe.Pointer(&disposition)))
+	if r != 0 {
+		return fmt.Errorf(tr("注册事件源 %s 失败（首次注册需要管理员权限）: %v"), source, syscall.Errno(r))
+	}
+	defer syscall.RegCloseKey(key)
+
+	file := syscall.StringToUTF16(eventMessageFile)
+	if err := regSetValue(key, "EventMessageFile", syscall.REG_EXPAND_SZ, unsafe.Slice((*byte)(unsafe.Pointer(&file[0])), len(file)*2)); err != nil {
+		return fmt.Errorf(tr("注册事件源 %s 失败（首次注册需要管理员权限）: %v"), source, err)
+	}
+	types := binary.LittleEndian.AppendUint32(nil, eventTypesAll)
+	if err := regSetValue(key, "TypesSupported", syscall.REG_DWORD, types); err != nil {
+		return fmt.Errorf(tr("注册事件源 %s 失败（首次注册需要管理员权限）: %v"), source, err)
+	}
+	return nil
+}
+
+// regSetValue 写入注册表值
+func regSetValue(key syscall.Handle, name string, valueType uint32, data []byte) error {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procRegSetValueExW.Call(uintptr(key), uintptr(unsafe.Pointer(namePtr)), 0, uintptr(valueType),
+		uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+// Error 写入一条错误事件
+func (l *windowsEventLog) Error(message string) error {
+	text, err := syscall.UTF16PtrFromString(strings.ReplaceAll(message, "\x00", ""))
+	if err != nil {
+		return err
+	}
+	strs := []*uint16{text}
+	r, _, err := procReportEventW.Call(l.handle, eventTypeError, 0, eventID, 0, 1, 0, uintptr(unsafe.Pointer(&strs[0])), 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// Close 关闭事件源
+func (l *windowsEventLog) Close() error {
+	r, _, err := procDeregisterEventSource.Call(l.handle)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
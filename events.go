@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 事件流中的事件类型
+const (
+	StreamIPDetected = "ip_detected"
+	StreamIPChanged  = "ip_changed"
+	StreamDNSUpdated = "dns_updated"
+	StreamError      = "error"
+)
+
+// StreamEvent 事件流中推送的结构化事件
+type StreamEvent struct {
+	ID      uint64    `json:"id"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Record  string    `json:"record,omitempty"`
+	IP      string    `json:"ip,omitempty"`
+	OldIP   string    `json:"old_ip,omitempty"`
+	Source  string    `json:"source,omitempty"`
+	Message string    `json:"message,omitempty"`
+	CycleID string    `json:"cycle_id,omitempty"`
+}
+
+// eventBus 事件订阅者管理；订阅者处理不及时时丢弃事件，不阻塞检测流程
+type eventBus struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[chan StreamEvent]struct{}
+}
+
+var events = &eventBus{subs: make(map[chan StreamEvent]struct{})}
+
+// subscriberBuffer 每个订阅者的事件缓冲数量
+const subscriberBuffer = 64
+
+// publish 发布事件到所有订阅者
+func (b *eventBus) publish(event StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.CycleID == "" {
+		event.CycleID = currentCycleID()
+	}
+	event.Message = redactSecrets(event.Message)
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者缓冲已满，丢弃该事件
+		}
+	}
+}
+
+func (b *eventBus) subscribe() chan StreamEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan StreamEvent, subscriberBuffer)
+	b.subs[ch] = struct{}{}
+	return ch
+}
+
+func (b *eventBus) unsubscribe(ch chan StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, ch)
+}
+
+// publishEvent 发布事件的便捷函数
+func publishEvent(event StreamEvent) {
+	events.publish(event)
+}
+
+// streamAuth 事件流认证：除 Authorization 头外，还接受 ?access_token= 参数（浏览器 EventSource 无法设置请求头）
+func streamAuth(token string, next http.HandlerFunc) http.Handler {
+	header := apiAuth(token, "GET", next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := r.URL.Query().Get("access_token")
+		if r.Header.Get("Authorization") != "" || provided == "" {
+			header.ServeHTTP(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, tr("未授权"))
+			return
+		}
+		if r.Method != "GET" {
+			writeAPIError(w, http.StatusMethodNotAllowed, tr("不支持的请求方法"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+// handleAPIEvents 以 Server-Sent Events 推送实时事件
+func handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, tr("不支持事件流"))
+		return
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	// 定期发送注释行，避免代理或负载均衡器断开空闲连接
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case event := <-ch:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
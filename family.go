@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net"
+)
+
+// addressFamilies 本机可用的地址族
+type addressFamilies struct {
+	IPv4 bool
+	IPv6 bool
+}
+
+// 判断路由时连接的地址（UDP 连接只查询路由，不发送数据）
+const (
+	familyProbeIPv4 = "1.1.1.1:53"
+	familyProbeIPv6 = "[2606:4700:4700::1111]:53"
+)
+
+// detectFamilies 检测本机的地址族，测试中可替换
+var detectFamilies = detectAddressFamilies
+
+// detectAddressFamilies 检测本机是否有通往公网的 IPv4 / IPv6 路由。
+// IPv4 通常经过 NAT，有路由即可；IPv6 还要求源地址是全局单播地址（不是 ULA 或链路本地地址）
+func detectAddressFamilies() addressFamilies {
+	var f addressFamilies
+	if conn, err := net.Dial("udp4", familyProbeIPv4); err == nil {
+		f.IPv4 = true
+		conn.Close()
+	}
+	if conn, err := net.Dial("udp6", familyProbeIPv6); err == nil {
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+			f.IPv6 = isGlobalIPv6(addr.IP)
+		}
+		conn.Close()
+	}
+	return f
+}
+
+// isGlobalIPv6 判断是否为公网可达的 IPv6 地址
+func isGlobalIPv6(ip net.IP) bool {
+	if ip == nil || ip.To4() != nil || !ip.IsGlobalUnicast() {
+		return false
+	}
+	// fc00::/7 唯一本地地址
+	return ip[0]&0xfe != 0xfc
+}
+
+// has 是否可以获取该记录类型需要的地址
+func (f addressFamilies) has(recordType string) bool {
+	if recordType == "AAAA" {
+		return f.IPv6
+	}
+	return f.IPv4
+}
+
+// otherRecordType 另一地址族的记录类型
+func otherRecordType(recordType string) string {
+	if recordType == "AAAA" {
+		return "A"
+	}
+	return "AAAA"
+}
+
+// checkAddressFamily 启动时检查本机能否获取记录类型需要的地址：dual_stack 开启且另一地址族可用时改为维护
+// 另一类型的记录，否则记录警告，避免每个周期都在检测公网IP时失败。从网络接口读取IP时不检查
+func checkAddressFamily(config *Config) {
+	if config.IPInterface != nil {
+		return
+	}
+	families := detectFamilies()
+	if families.has(config.RecordType) {
+		return
+	}
+	other := otherRecordType(config.RecordType)
+	switch {
+	case config.DualStack && families.has(other):
+		logInfo("本机没有可用的 %s 记录地址，双栈模式下改为维护 %s 记录", config.RecordType, other)
+		config.RecordType = other
+	case families.has(other):
+		logError("本机没有可用的 %s 记录地址（只有 %s 连接），检测公网IP将持续失败：请把 record_type 改为 %s，或开启 dual_stack 自动切换", config.RecordType, other, other)
+	default:
+		logError("本机没有可用的 IPv4 或 IPv6 公网连接，检测公网IP将失败，请检查网络")
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SubdomainConfig 跟随主记录的子域名，与主记录位于同一区域
+type SubdomainConfig struct {
+	// Name 子域名标签（如 nas，表示 nas.<区域名称>）或完整域名
+	Name string `json:"name"`
+	// Type 为空时与主记录类型相同，指向本机IP；CNAME 表示指向主记录
+	Type string `json:"type,omitempty"`
+}
+
+// subdomainName 返回子域名的记录名称：不含点的标签相对于区域根域名
+func subdomainName(s SubdomainConfig) string {
+	if strings.Contains(s.Name, ".") {
+		return s.Name
+	}
+	return s.Name + "." + apexName
+}
+
+// subdomainTargets 返回与主记录一起同步的记录（主记录在第一位）与需要指向主记录的 CNAME 名称
+func (c *Config) subdomainTargets() (targets []RecordTarget, aliases []string) {
+	targets = []RecordTarget{{ZoneID: c.ZoneID, Name: c.RecordName, Type: c.RecordType}}
+	seen := map[string]bool{strings.ToLower(c.RecordName): true}
+	for _, sub := range c.Subdomains {
+		name := subdomainName(sub)
+		if sub.Name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		if strings.EqualFold(sub.Type, "CNAME") {
+			aliases = append(aliases, name)
+		} else {
+			targets = append(targets, RecordTarget{ZoneID: c.ZoneID, Name: name, Type: c.RecordType})
+		}
+	}
+	return targets, aliases
+}
+
+// ipTargets 返回所有指向本机IP的记录：主记录、records 与 subdomains 中非 CNAME 的子域名
+func (c *Config) ipTargets() []RecordTarget {
+	subdomains, _ := c.subdomainTargets()
+	return append(c.recordTargets(), subdomains[1:]...)
+}
+
+// batchUpdate 批量请求中修改的一条记录
+type batchUpdate struct {
+	Old     DNSRecord
+	Request DNSRecordCreateRequest
+}
+
+// batchProvider 支持在一个请求中原子修改多条记录的DNS服务
+type batchProvider interface {
+	BatchDNSRecords(zoneID string, creates []DNSRecordCreateRequest, updates []batchUpdate) error
+}
+
+// SyncGroup 把主记录与子域名作为一组同步：所有计划在同一个批量请求中提交，
+// 全部成功或全部不生效，不会出现部分子域名已指向新IP、其余仍指向旧IP的情况。
+// aliases 为指向 aliasTarget（主记录完整名称）的 CNAME；结果顺序为 targets 之后接 aliases
+func (r *Reconciler) SyncGroup(targets []RecordTarget, aliases []string, aliasTarget, ip, oldIP, source string) []SyncResult {
+	results := make([]SyncResult, 0, len(targets)+len(aliases))
+	for _, target := range targets {
+		results = append(results, SyncResult{Target: target, Action: planNone})
+	}
+	for _, alias := range aliases {
+		results = append(results, SyncResult{Target: RecordTarget{ZoneID: r.ZoneID, Name: alias, Type: "CNAME"}, Action: planNone})
+	}
+
+	fail := func(err error) []SyncResult {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	plans, err := r.planGroup(results, aliasTarget, ip, oldIP)
+	if err != nil {
+		logError("获取DNS记录失败: %v", err)
+		return fail(err)
+	}
+	for i, plan := range plans {
+		results[i].Before = recordContents(plan.Observed)
+		if r.Dedupe && plan.Desired.Type != "CNAME" {
+			plan = r.forTarget(results[i].Target).collapseDuplicates(plan, source)
+			plans[i] = plan
+		}
+		results[i].Action = plan.Action
+		results[i].Proxied = plan.Request.Proxied
+		results[i].After = recordContents(plan.Observed)
+	}
+	if !hasChanges(plans) {
+		logInfo("%s 及 %d 个子域名已指向本机IP (%s)，无需更新", r.Name, len(plans)-1, ip)
+		return results
+	}
+
+	for _, plan := range plans {
+		if plan.Action != planNone {
+			logInfo("正在更新或创建DNS记录: %s -> %s", plan.Desired.Name, plan.Desired.IP)
+		}
+	}
+	if r.DryRun {
+		for i, plan := range plans {
+			if plan.Action != planNone {
+				logInfo("[dry-run] 计划操作 %s: %s -> %s（未执行）", plan.Action, plan.Request.Name, plan.Request.Content)
+				results[i].After = recordContents(plan.Result())
+			}
+		}
+		return results
+	}
+
+	if err := r.applyGroup(plans, results, aliasTarget, ip, oldIP, source); err != nil {
+		logError("DNS更新/创建失败: %v", err)
+		return fail(err)
+	}
+
+	for i, plan := range plans {
+		if plan.Action == planNone {
+			continue
+		}
+		results[i].Applied = true
+		results[i].After = recordContents(plan.Result())
+		if plan.Desired.Type == "CNAME" {
+			continue
+		}
+		if err := r.forTarget(results[i].Target).Verify(plan.Desired); err != nil {
+			logError("%v", err)
+			results[i].Err = err
+		}
+	}
+	logInfo("DNS记录已成功更新/创建: %s 及 %d 个子域名 -> %s", r.Name, len(plans)-1, ip)
+	return results
+}
+
+// planGroup 读取每个记录的当前状态并生成计划；任一记录读取失败时整组放弃
+func (r *Reconciler) planGroup(results []SyncResult, aliasTarget, ip, oldIP string) ([]Plan, error) {
+	plans := make([]Plan, len(results))
+	for i, result := range results {
+		t := r.forTarget(result.Target)
+		desired := t.Desired(ip, result.Target.previousIP(oldIP))
+		if result.Target.Type == "CNAME" {
+			// CNAME 只属于本组，内容固定为主记录名称
+			desired = DesiredState{ZoneID: t.ZoneID, Name: t.Name, Type: "CNAME", IP: aliasTarget, Exclusive: true}
+		}
+		plan, err := t.Plan(desired)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		plans[i] = plan
+	}
+	return plans, nil
+}
+
+// applyGroup 提交一组计划；失败时重新读取记录并重新生成计划后重试。
+// DNS服务不支持批量请求时逐条提交，此时不保证原子性
+func (r *Reconciler) applyGroup(plans []Plan, results []SyncResult, aliasTarget, ip, oldIP, source string) error {
+	r.Provider.SetAuditSource(source)
+	batch, ok := r.Provider.(batchProvider)
+	if !ok {
+		logDebug("DNS服务不支持批量请求，逐条提交")
+		for i, plan := range plans {
+			if err := r.forTarget(results[i].Target).Apply(plan, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	for i := 0; i < r.Retries; i++ {
+		if i > 0 {
+			if plans, err = r.planGroup(results, aliasTarget, ip, oldIP); err != nil {
+				// 读取失败多为暂时性错误，同样等待后再重试
+				if i < r.Retries-1 {
+					delay := r.retryDelay(i)
+					logError("获取DNS记录失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+					time.Sleep(delay)
+				}
+				continue
+			}
+			if !hasChanges(plans) {
+				return nil
+			}
+		}
+
+		// 降低TTL逐条执行：只修改TTL不影响解析结果，无需原子性
+		err = nil
+		for j := range plans {
+			if plans[j].Action == planNone {
+				continue
+			}
+			if err = r.forTarget(results[j].Target).lowerTTL(&plans[j]); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if i < r.Retries-1 {
+				delay := r.retryDelay(i)
+				logError("降低TTL失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+				time.Sleep(delay)
+			} else {
+				logError("降低TTL失败 (尝试 %d/%d): %v", i+1, r.Retries, err)
+			}
+			continue
+		}
+
+		var creates []DNSRecordCreateRequest
+		var updates []batchUpdate
+		for _, plan := range plans {
+			switch plan.Action {
+			case planCreate:
+				creates = append(creates, plan.Request)
+			case planUpdate:
+				updates = append(updates, batchUpdate{Old: *plan.Target, Request: plan.Request})
+			}
+		}
+		if err = batch.BatchDNSRecords(r.ZoneID, creates, updates); err == nil {
+			return nil
+		}
+		if i < r.Retries-1 {
+			delay := r.retryDelay(i)
+			logError("DNS更新/创建失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
+
+// hasChanges 计划中是否有需要执行的操作
+func hasChanges(plans []Plan) bool {
+	for _, plan := range plans {
+		if plan.Action != planNone {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchDNSRecords 在一个请求中创建和修改多条记录，Cloudflare 保证全部成功或全部不生效
+func (c *CloudflareClient) BatchDNSRecords(zoneID string, creates []DNSRecordCreateRequest, updates []batchUpdate) error {
+	type putRequest struct {
+		ID string `json:"id"`
+		DNSRecordCreateRequest
+	}
+	body := struct {
+		Posts []DNSRecordCreateRequest `json:"posts,omitempty"`
+		Puts  []putRequest             `json:"puts,omitempty"`
+	}{}
+
+	for _, create := range creates {
+		name, err := c.recordName(zoneID, create.Name)
+		if err != nil {
+			return err
+		}
+		create.Name = name
+		if err := c.snapshotBeforeMutation(zoneID, create.Name); err != nil {
+			return err
+		}
+		body.Posts = append(body.Posts, create)
+	}
+	for _, update := range updates {
+		if err := c.snapshotBeforeMutation(zoneID, update.Old.Name); err != nil {
+			return err
+		}
+		body.Puts = append(body.Puts, putRequest{ID: update.Old.ID, DNSRecordCreateRequest: update.Request})
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	endpoint := fmt.Sprintf("/zones/%s/dns_records/batch", zoneID)
+	resp, err := c.makeRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %w"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+		Result  struct {
+			Posts []DNSRecord `json:"posts"`
+			Puts  []DNSRecord `json:"puts"`
+		} `json:"result"`
+		Errors []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if !result.Success {
+		return apiResultError(result.Errors)
+	}
+
+	source := c.getAuditSource()
+	for _, record := range result.Result.Posts {
+		writeAudit(AuditEntry{
+			Action:   "create",
+			ZoneID:   zoneID,
+			Record:   record.Name,
+			Type:     record.Type,
+			NewValue: record.Content,
+			RecordID: record.ID,
+			Source:   source,
+		})
+	}
+	for i, record := range result.Result.Puts {
+		entry := AuditEntry{
+			Action:   "update",
+			ZoneID:   zoneID,
+			Record:   record.Name,
+			Type:     record.Type,
+			NewValue: record.Content,
+			RecordID: record.ID,
+			Source:   source,
+		}
+		if i < len(updates) {
+			entry.OldValue = updates[i].Old.Content
+		}
+		writeAudit(entry)
+	}
+	return nil
+}
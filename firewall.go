@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FirewallConfig 公网IP变化后更新的防火墙白名单（常见于在远程服务器上放行家庭IP）
+type FirewallConfig struct {
+	// Type 防火墙类型：nftables、ipset（iptables 使用的集合）或 aws_sg（AWS 安全组）
+	Type string `json:"type"`
+
+	// Set nftables / ipset 的集合名称；Family 与 Table 为 nftables 集合所在的族与表（Family 默认 inet）
+	Set    string `json:"set,omitempty"`
+	Family string `json:"family,omitempty"`
+	Table  string `json:"table,omitempty"`
+	// SSH 通过 ssh 在远程服务器上执行（如 root@vps.example.com），为空时在本机执行
+	SSH string `json:"ssh,omitempty"`
+
+	// GroupID AWS 安全组 ID；Protocol 默认 tcp，Port 为放行的端口，Region 为空时使用 aws 命令的默认配置
+	GroupID  string `json:"group_id,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Region   string `json:"region,omitempty"`
+}
+
+// firewallCommandTimeout 单条防火墙命令的超时
+const firewallCommandTimeout = 30 * time.Second
+
+// firewallState 已写入防火墙的IP，未变化时不重复执行
+type firewallState struct {
+	mu      sync.Mutex
+	applied string
+}
+
+var firewall = &firewallState{}
+
+// name 返回用于日志的防火墙描述
+func (f *FirewallConfig) name() string {
+	switch f.Type {
+	case "nftables":
+		return fmt.Sprintf("nftables %s %s %s", f.family(), f.Table, f.Set)
+	case "aws_sg":
+		return "aws_sg " + f.GroupID
+	default:
+		return f.Type + " " + f.Set
+	}
+}
+
+func (f *FirewallConfig) family() string {
+	if f.Family != "" {
+		return f.Family
+	}
+	return "inet"
+}
+
+// commands 返回把白名单从 oldIP 改为 newIP 的命令：先删除旧IP（失败可忽略），再添加新IP
+func (f *FirewallConfig) commands(oldIP, newIP string) (remove, add []string, err error) {
+	switch f.Type {
+	case "nftables":
+		if f.Table == "" || f.Set == "" {
+			return nil, nil, fmt.Errorf(tr("防火墙 %s 缺少 table 或 set"), f.Type)
+		}
+		element := func(ip string) []string {
+			return []string{"nft", "", "element", f.family(), f.Table, f.Set, "{ " + ip + " }"}
+		}
+		if oldIP != "" {
+			remove = element(oldIP)
+			remove[1] = "delete"
+		}
+		add = element(newIP)
+		add[1] = "add"
+	case "ipset":
+		if f.Set == "" {
+			return nil, nil, fmt.Errorf(tr("防火墙 %s 缺少 set"), f.Type)
+		}
+		if oldIP != "" {
+			remove = []string{"ipset", "del", f.Set, oldIP, "-exist"}
+		}
+		add = []string{"ipset", "add", f.Set, newIP, "-exist"}
+	case "aws_sg":
+		if f.GroupID == "" || f.Port == 0 {
+			return nil, nil, fmt.Errorf(tr("防火墙 %s 缺少 group_id 或 port"), f.Type)
+		}
+		rule := func(action, ip string) []string {
+			args := []string{"aws", "ec2", action, "--group-id", f.GroupID}
+			if net.ParseIP(ip).To4() != nil {
+				args = append(args, "--protocol", f.protocol(), "--port", strconv.Itoa(f.Port), "--cidr", ip+"/32")
+			} else {
+				// --cidr 只支持 IPv4，IPv6 地址通过 --ip-permissions 指定
+				args = append(args, "--ip-permissions", fmt.Sprintf("IpProtocol=%s,FromPort=%d,ToPort=%d,Ipv6Ranges=[{CidrIpv6=%s/128}]",
+					f.protocol(), f.Port, f.Port, ip))
+			}
+			if f.Region != "" {
+				args = append(args, "--region", f.Region)
+			}
+			return args
+		}
+		if oldIP != "" {
+			remove = rule("revoke-security-group-ingress", oldIP)
+		}
+		add = rule("authorize-security-group-ingress", newIP)
+	default:
+		return nil, nil, fmt.Errorf(tr("不支持的防火墙类型: %s"), f.Type)
+	}
+
+	if f.SSH != "" && f.Type != "aws_sg" {
+		if remove != nil {
+			remove = sshCommand(f.SSH, remove)
+		}
+		add = sshCommand(f.SSH, add)
+	}
+	return remove, add, nil
+}
+
+func (f *FirewallConfig) protocol() string {
+	if f.Protocol != "" {
+		return f.Protocol
+	}
+	return "tcp"
+}
+
+// sshCommand 把命令包装为通过 ssh 在远程执行；参数中的空格与花括号需要加引号
+func sshCommand(host string, args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " {}'") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return []string{"ssh", "-o", "BatchMode=yes", host, "--", strings.Join(quoted, " ")}
+}
+
+// runFirewallCommand 执行一条命令，返回合并后的输出
+func runFirewallCommand(args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// updateFirewalls 公网IP变化后更新所有防火墙白名单，删除上次放行的IP（本次运行尚未放行过时为 oldIP）。
+// 白名单不影响DNS记录，失败时只记录日志，下个周期重试
+func updateFirewalls(config *Config, oldIP, newIP string) {
+	firewall.mu.Lock()
+	defer firewall.mu.Unlock()
+
+	if firewall.applied == newIP {
+		return
+	}
+	if firewall.applied != "" {
+		oldIP = firewall.applied
+	}
+
+	ok := true
+	for i := range config.Firewalls {
+		f := &config.Firewalls[i]
+		remove, add, err := f.commands(oldIP, newIP)
+		if err != nil {
+			logError("%v", err)
+			ok = false
+			continue
+		}
+		if remove != nil {
+			if output, err := runFirewallCommand(remove); err != nil {
+				// 旧IP可能不在白名单中（首次运行或已被手动删除）
+				logDebug("从防火墙 %s 删除旧IP失败: %v %s", f.name(), err, output)
+			}
+		}
+		// 安全组中已存在相同规则时 aws 返回 InvalidPermission.Duplicate，视为成功
+		if output, err := runFirewallCommand(add); err != nil && !strings.Contains(output, "InvalidPermission.Duplicate") {
+			logError("更新防火墙 %s 失败: %v %s", f.name(), err, output)
+			ok = false
+			continue
+		}
+		logInfo("防火墙 %s 已放行新IP %s", f.name(), newIP)
+	}
+	if ok {
+		firewall.applied = newIP
+	}
+}
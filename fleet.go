@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FleetConfig 成员登记：多机器模式下每台机器在 TXT 记录中登记主机名、IP与最近在线时间，
+// 各节点和 fleet 子命令据此列出集群成员，并清理超过 TTL 未在线的节点
+type FleetConfig struct {
+	// Record 保存登记信息的 TXT 记录名称，默认 _dns_manager_fleet.<record_name>
+	Record string `json:"record,omitempty"`
+	// TTL 节点超过该时长未在线即视为离开（默认 "10m"），在线节点每隔 TTL/4 刷新一次
+	TTL string `json:"ttl,omitempty"`
+	// Node 本机名称，默认使用主机名（与写入租约的 owner 相同）
+	Node string `json:"node,omitempty"`
+	// PruneRecords 清理离开的节点时，同时从记录集中删除指向其IP的记录
+	PruneRecords bool `json:"prune_records,omitempty"`
+}
+
+// defaultFleetTTL 成员登记默认 TTL
+const defaultFleetTTL = 10 * time.Minute
+
+// fleetRecordName 返回成员登记记录名称
+func (c *Config) fleetRecordName() string {
+	if c.Fleet.Record != "" {
+		return c.Fleet.Record
+	}
+	return "_dns_manager_fleet." + c.RecordName
+}
+
+// fleetTTL 返回成员登记 TTL
+func (c *Config) fleetTTL() time.Duration {
+	if d, err := time.ParseDuration(c.Fleet.TTL); err == nil && d > 0 {
+		return d
+	}
+	return defaultFleetTTL
+}
+
+// fleetNode 返回本机名称，空白字符替换为 "-" 以免破坏记录格式
+func (c *Config) fleetNode() string {
+	node := c.Fleet.Node
+	if node == "" {
+		node = c.leaseOwner()
+	}
+	return strings.Join(strings.Fields(node), "-")
+}
+
+// FleetMember 一个集群成员
+type FleetMember struct {
+	Node     string    `json:"node"`
+	IP       string    `json:"ip"`
+	LastSeen time.Time `json:"last_seen"`
+	Stale    bool      `json:"stale"`
+	recordID string
+}
+
+// parseFleetMember 解析成员登记内容：node=<名称> ip=<IP> seen=<Unix 时间>
+func parseFleetMember(record DNSRecord) (FleetMember, bool) {
+	member := FleetMember{recordID: record.ID}
+	for _, field := range strings.Fields(strings.Trim(record.Content, `"`)) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "node":
+			member.Node = value
+		case "ip":
+			member.IP = value
+		case "seen":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				member.LastSeen = time.Unix(sec, 0)
+			}
+		}
+	}
+	return member, member.Node != ""
+}
+
+// listFleetMembers 读取成员登记，按节点名称排序
+func listFleetMembers(config *Config, client *CloudflareClient) ([]FleetMember, error) {
+	records, err := client.GetAllDNSRecords(config.ZoneID, config.fleetRecordName(), "TXT")
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取成员登记失败: %v"), err)
+	}
+
+	ttl := config.fleetTTL()
+	members := []FleetMember{}
+	for _, record := range records {
+		member, ok := parseFleetMember(record)
+		if !ok {
+			continue
+		}
+		member.Stale = time.Since(member.LastSeen) > ttl
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Node < members[j].Node })
+	return members, nil
+}
+
+// fleetState 本机的登记状态
+type fleetState struct {
+	mu        sync.Mutex
+	recordID  string
+	ip        string
+	lastWrite time.Time
+	peers     map[string]bool // 已知的其他节点，用于只在成员变化时输出日志
+}
+
+var fleet = &fleetState{}
+
+// heartbeat 刷新本机的成员登记并清理离开的节点。IP未变化时每隔 TTL/4 刷新一次；
+// 登记只是辅助信息，失败时只记录日志，不影响记录同步
+func (f *fleetState) heartbeat(config *Config, client *CloudflareClient, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ttl := config.fleetTTL()
+	if f.ip == ip && time.Since(f.lastWrite) < ttl/4 {
+		return
+	}
+
+	members, err := listFleetMembers(config, client)
+	if err != nil {
+		logError("%v", err)
+		return
+	}
+
+	node := config.fleetNode()
+	recordID := f.recordID
+	live := map[string]bool{ip: true}
+	peers := map[string]bool{}
+	var stale []FleetMember
+	for _, member := range members {
+		switch {
+		case member.Node == node:
+			if recordID == "" {
+				recordID = member.recordID
+			} else if member.recordID != recordID {
+				// 重复的本机登记（如记录ID丢失后新建）直接清理
+				stale = append(stale, member)
+			}
+		case member.Stale:
+			stale = append(stale, member)
+		default:
+			live[member.IP] = true
+			peers[member.Node] = true
+		}
+	}
+
+	content := fmt.Sprintf("node=%s ip=%s seen=%d", node, ip, time.Now().Unix())
+	written, err := client.writeMetaRecord(config.ZoneID, recordID, config.fleetRecordName(), content)
+	if err != nil {
+		logError("写入成员登记失败: %v", err)
+		return
+	}
+	f.recordID = written.ID
+	f.ip = ip
+	f.lastWrite = time.Now()
+
+	for peer := range peers {
+		if !f.peers[peer] {
+			logInfo("集群成员: %s 在线", peer)
+		}
+	}
+	f.peers = peers
+
+	for _, member := range stale {
+		if err := client.deleteMetaRecord(config.ZoneID, member.recordID); err != nil {
+			logError("清理成员登记失败: %v", err)
+			continue
+		}
+		if member.Node == node {
+			continue
+		}
+		logInfo("集群成员 %s (%s) 超过 %s 未在线，已清理", member.Node, member.IP, ttl)
+
+		// 离开节点的IP没有被在线节点使用时，删除指向它的记录
+		if config.Fleet.PruneRecords && member.IP != "" && !live[member.IP] {
+			if err := withdrawRecords(client, "fleet", config.ipTargets(), member.IP); err != nil {
+				logError("%v", err)
+			}
+		}
+	}
+}
+
+// printFleetMembers 输出成员列表
+func printFleetMembers(members []FleetMember, self string) {
+	if len(members) == 0 {
+		fmt.Println(tr("没有成员登记"))
+		return
+	}
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-24s %-40s %-24s %s\n", tr("节点"), "IP", tr("最近在线"), tr("状态"))
+	fmt.Println(strings.Repeat("-", 80))
+	for _, member := range members {
+		status := tr("在线")
+		if member.Stale {
+			status = tr("已离开")
+		}
+		node := member.Node
+		if node == self {
+			node += " *"
+		}
+		fmt.Printf("%-24s %-40s %-24s %s\n", node, member.IP,
+			member.LastSeen.In(logLocation).Format(logTimeFormat), status)
+	}
+	fmt.Println(strings.Repeat("-", 80))
+}
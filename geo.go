@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GeoConfig IP变化时查询新地址的 ASN、运营商与地理位置，写入日志、历史与通知，
+// 便于发现检测到的出口是 VPN 或错误的线路
+type GeoConfig struct {
+	// Provider ipinfo（默认，在线查询）或 mmdb（本地 MaxMind DB 文件，不访问网络）
+	Provider string `json:"provider,omitempty"`
+	// Token ipinfo 访问令牌，为空时使用免费额度
+	Token string `json:"token,omitempty"`
+	// URL ipinfo 兼容服务的地址，默认 https://ipinfo.io
+	URL string `json:"url,omitempty"`
+	// Databases MaxMind DB 文件路径，如 GeoLite2-City.mmdb 与 GeoLite2-ASN.mmdb，结果合并
+	Databases []string `json:"databases,omitempty"`
+	// Anomaly 新IP的国家或 ASN 与以往不同时告警，为空则不检查
+	Anomaly *AnomalyConfig `json:"anomaly,omitempty"`
+}
+
+// 归属查询方式
+const (
+	geoIPInfo = "ipinfo"
+	geoMMDB   = "mmdb"
+)
+
+const (
+	defaultIPInfoURL = "https://ipinfo.io"
+	geoLookupTimeout = 5 * time.Second
+)
+
+// GeoInfo IP地址的归属信息
+type GeoInfo struct {
+	ASN     string `json:"asn,omitempty"` // 如 AS4134
+	Org     string `json:"org,omitempty"`
+	Country string `json:"country,omitempty"` // ISO 3166 国家代码
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// String 如 "AS4134 CHINANET-BACKBONE, Shanghai, Shanghai, CN"
+func (g *GeoInfo) String() string {
+	if g == nil {
+		return ""
+	}
+	var parts []string
+	if network := strings.TrimSpace(g.ASN + " " + g.Org); network != "" {
+		parts = append(parts, network)
+	}
+	for _, part := range []string{g.City, g.Region, g.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *GeoConfig) provider() string {
+	if c.Provider == "" && len(c.Databases) > 0 {
+		return geoMMDB
+	}
+	if c.Provider == "" {
+		return geoIPInfo
+	}
+	return strings.ToLower(c.Provider)
+}
+
+// validate 检查配置
+func (c *GeoConfig) validate() error {
+	switch c.provider() {
+	case geoIPInfo:
+	case geoMMDB:
+		if len(c.Databases) == 0 {
+			return errors.New(tr("geo.provider 为 mmdb 时需要设置 geo.databases"))
+		}
+	default:
+		return fmt.Errorf(tr("geo.provider 无效: %s（可选 ipinfo 或 mmdb）"), c.Provider)
+	}
+	if c.Anomaly != nil {
+		return c.Anomaly.validate()
+	}
+	return nil
+}
+
+// geoCache 已查询过的IP，IP在几个地址之间来回变化时不重复查询
+type geoCache struct {
+	mu      sync.Mutex
+	entries map[string]*GeoInfo
+	// databases 已打开的 MaxMind DB，按路径缓存
+	databases map[string]*mmdbReader
+}
+
+var geo = &geoCache{}
+
+// maxGeoCacheEntries 缓存的IP数量上限，超出后清空重新缓存
+const maxGeoCacheEntries = 256
+
+// lookup 查询 ip 的归属信息
+func (g *geoCache) lookup(config *GeoConfig, ip string) (*GeoInfo, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if info, ok := g.entries[ip]; ok {
+		return info, nil
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	var info *GeoInfo
+	var err error
+	if config.provider() == geoMMDB {
+		info, err = g.lookupMMDB(config.Databases, ip)
+	} else {
+		info, err = lookupIPInfo(config, ip)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.entries == nil || len(g.entries) >= maxGeoCacheEntries {
+		g.entries = make(map[string]*GeoInfo)
+	}
+	g.entries[ip] = info
+	return info, nil
+}
+
+// cached 返回已查询过的归属信息，未查询时返回 nil
+func (g *geoCache) cached(ip string) *GeoInfo {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.entries[ip]
+}
+
+// lookupMMDB 依次查询各数据库并合并结果；调用方需持有锁
+func (g *geoCache) lookupMMDB(paths []string, ip string) (*GeoInfo, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf(tr("无效的IP地址: %s"), ip)
+	}
+	if g.databases == nil {
+		g.databases = make(map[string]*mmdbReader)
+	}
+
+	info := &GeoInfo{}
+	for _, path := range paths {
+		db := g.databases[path]
+		if db == nil {
+			var err error
+			if db, err = openMMDB(path); err != nil {
+				return nil, fmt.Errorf(tr("打开 MaxMind DB %s 失败: %v"), path, err)
+			}
+			g.databases[path] = db
+		}
+		record, err := db.lookup(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if record == nil {
+			continue
+		}
+		if n := mmdbUint(record["autonomous_system_number"]); n > 0 && info.ASN == "" {
+			info.ASN = "AS" + strconv.FormatUint(uint64(n), 10)
+		}
+		setIfEmpty(&info.Org, mmdbPath(record, "autonomous_system_organization"))
+		setIfEmpty(&info.Country, mmdbPath(record, "country", "iso_code"))
+		setIfEmpty(&info.Region, mmdbPath(record, "subdivisions", 0, "names", "en"))
+		setIfEmpty(&info.City, mmdbPath(record, "city", "names", "en"))
+	}
+	return info, nil
+}
+
+// setIfEmpty 字段为空且 value 为字符串时赋值
+func setIfEmpty(field *string, value interface{}) {
+	if s, ok := value.(string); ok && *field == "" {
+		*field = s
+	}
+}
+
+// ipinfoResponse ipinfo 的响应（只取需要的字段）
+type ipinfoResponse struct {
+	City    string `json:"city"`
+	Region  string `json:"region"`
+	Country string `json:"country"`
+	Org     string `json:"org"` // 如 "AS4134 CHINANET-BACKBONE"
+	Bogon   bool   `json:"bogon"`
+}
+
+// lookupIPInfo 通过 ipinfo 在线查询
+func lookupIPInfo(config *GeoConfig, ip string) (*GeoInfo, error) {
+	base := config.URL
+	if base == "" {
+		base = defaultIPInfoURL
+	}
+	endpoint := strings.TrimRight(base, "/") + "/" + url.PathEscape(ip) + "/json"
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if config.Token != "" {
+		registerSecret(config.Token)
+		req.Header.Set("Authorization", "Bearer "+config.Token)
+	}
+
+	client := &http.Client{Timeout: geoLookupTimeout, Transport: newHTTPTransport("geo")}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(tr("归属查询返回 HTTP %d"), resp.StatusCode)
+	}
+
+	var body ipinfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	info := &GeoInfo{City: body.City, Region: body.Region, Country: body.Country, Org: body.Org}
+	if asn, org, ok := strings.Cut(body.Org, " "); ok && strings.HasPrefix(asn, "AS") {
+		info.ASN, info.Org = asn, org
+	}
+	return info, nil
+}
+
+// annotateIP 查询新IP的归属信息并写入日志；未配置或查询失败时返回 nil，不影响更新
+func annotateIP(config *Config, ip string) *GeoInfo {
+	if config.Geo == nil {
+		return nil
+	}
+	info, err := geo.lookup(config.Geo, ip)
+	if err != nil {
+		logError("查询IP归属失败: %v", err)
+		return nil
+	}
+	if s := info.String(); s != "" {
+		logInfo("新IP %s 归属: %s", ip, s)
+	}
+	return info
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mmdbString / mmdbUint32 / mmdbMap 按 MaxMind DB 数据段格式编码测试数据
+func mmdbString(s string) []byte {
+	if len(s) >= 29 {
+		return append([]byte{2<<5 | 29, byte(len(s) - 29)}, s...)
+	}
+	return append([]byte{2<<5 | byte(len(s))}, s...)
+}
+
+func mmdbUint32(v uint32) []byte {
+	return []byte{6<<5 | 4, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func mmdbMap(pairs ...[]byte) []byte {
+	b := []byte{7<<5 | byte(len(pairs)/2)}
+	for _, p := range pairs {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// buildTestMMDB 生成只有一个节点的 IPv4 数据库：0.0.0.0/1 指向一条记录，其余地址未收录
+func buildTestMMDB() []byte {
+	// 偏移 0 处的字符串由记录中的指针引用
+	data := mmdbString("CN")
+	recordOffset := len(data)
+	data = append(data, mmdbMap(
+		mmdbString("autonomous_system_number"), mmdbUint32(4134),
+		mmdbString("autonomous_system_organization"), mmdbString("CHINANET-BACKBONE"),
+		mmdbString("country"), mmdbMap(mmdbString("iso_code"), []byte{1 << 5, 0}),
+	)...)
+
+	const nodeCount = 1
+	left := nodeCount + 16 + recordOffset
+	tree := []byte{byte(left >> 16), byte(left >> 8), byte(left), 0, 0, nodeCount}
+
+	db := append(tree, make([]byte, 16)...)
+	db = append(db, data...)
+	db = append(db, mmdbMetadataMarker...)
+	return append(db, mmdbMap(
+		mmdbString("node_count"), mmdbUint32(nodeCount),
+		mmdbString("record_size"), mmdbUint32(24),
+		mmdbString("ip_version"), mmdbUint32(4),
+		mmdbString("database_type"), mmdbString("Test-ASN"),
+	)...)
+}
+
+func TestGeoLookupMMDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, buildTestMMDB(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &GeoConfig{Databases: []string{path}}
+	cache := &geoCache{}
+
+	info, err := cache.lookup(config, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if got := info.String(); got != "AS4134 CHINANET-BACKBONE, CN" {
+		t.Fatalf("info = %q; want AS4134 CHINANET-BACKBONE, CN", got)
+	}
+	// 未收录的地址没有归属信息，不是错误
+	info, err = cache.lookup(config, "198.51.100.1")
+	if err != nil || info.String() != "" {
+		t.Fatalf("lookup unknown = %+v, %v; want empty", info, err)
+	}
+}
+
+func TestGeoLookupIPInfo(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/198.51.100.2/json" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"ip":"198.51.100.2","city":"Shanghai","region":"Shanghai","country":"CN","org":"AS4134 CHINANET-BACKBONE"}`))
+	}))
+	defer server.Close()
+
+	config := &GeoConfig{URL: server.URL, Token: "secret"}
+	cache := &geoCache{}
+	for i := 0; i < 2; i++ {
+		info, err := cache.lookup(config, "198.51.100.2")
+		if err != nil {
+			t.Fatalf("lookup: %v", err)
+		}
+		if info.ASN != "AS4134" || info.Org != "CHINANET-BACKBONE" || info.Country != "CN" {
+			t.Fatalf("info = %+v", info)
+		}
+	}
+	// 同一个IP只查询一次
+	if requests != 1 {
+		t.Fatalf("requests = %d; want 1", requests)
+	}
+}
+
+func TestAnomalyHold(t *testing.T) {
+	dataDirOnce.Do(func() {})
+	previous := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = previous })
+
+	config := &Config{RecordName: "home.example.com", Geo: &GeoConfig{Anomaly: &AnomalyConfig{Confirm: true}}}
+	home := &GeoInfo{ASN: "AS4134", Country: "CN"}
+	vpn := &GeoInfo{ASN: "AS9009", Country: "GB"}
+	guard := &anomalyGuard{}
+
+	// 第一个IP建立历史
+	if guard.hold(config, "198.51.100.1", home) {
+		t.Fatal("first IP held without history")
+	}
+	guard.accepted(config, "198.51.100.1", home)
+	if guard.hold(config, "198.51.100.2", &GeoInfo{ASN: "AS4134", Country: "CN"}) {
+		t.Fatal("IP from the usual network held")
+	}
+
+	for i := 0; i < 2; i++ {
+		if !guard.hold(config, "203.0.113.9", vpn) {
+			t.Fatal("IP from an unusual network not held")
+		}
+	}
+	if pending := guard.pending(); pending == nil || pending.IP != "203.0.113.9" {
+		t.Fatalf("pending = %+v; want 203.0.113.9", pending)
+	}
+	if _, err := guard.approve("203.0.113.10"); err == nil {
+		t.Fatal("approve succeeded for an IP that is not pending")
+	}
+	if _, err := guard.approve(""); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if guard.hold(config, "203.0.113.9", vpn) {
+		t.Fatal("approved IP still held")
+	}
+	guard.accepted(config, "203.0.113.9", vpn)
+
+	// 历史持久化：重新加载后已接受的网络不再异常
+	reloaded := &anomalyGuard{}
+	if reloaded.hold(config, "203.0.113.20", vpn) || reloaded.pending() != nil {
+		t.Fatal("accepted network held after reload")
+	}
+
+	// 只告警时不暂缓更新
+	config.Geo.Anomaly = &AnomalyConfig{Compare: anomalyCountry}
+	if reloaded.hold(config, "192.0.2.1", &GeoInfo{ASN: "AS64500", Country: "US"}) {
+		t.Fatal("alert-only anomaly held the update")
+	}
+}
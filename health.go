@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthState 记录守护进程的运行状态，供健康检查使用
+type healthState struct {
+	mu         sync.RWMutex
+	startTime  time.Time
+	lastCycle  time.Time
+	nextDelay  time.Duration // 最近一次周期安排的下次检测前的等待时间（自适应间隔与降级模式会拉长）
+	lastAPIOK  bool
+	lastAPIErr string
+}
+
+var health = &healthState{
+	startTime: time.Now(),
+	lastAPIOK: true, // 尚未调用API时视为正常
+}
+
+// markCycle 记录一次检测周期完成
+func (h *healthState) markCycle() {
+	h.mu.Lock()
+	h.lastCycle = time.Now()
+	h.mu.Unlock()
+}
+
+// markNextDelay 记录本周期安排的下次检测前的等待时间
+func (h *healthState) markNextDelay(d time.Duration) {
+	h.mu.Lock()
+	h.nextDelay = d
+	h.mu.Unlock()
+}
+
+// markAPIResult 记录最近一次 Cloudflare API 调用结果
+func (h *healthState) markAPIResult(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.lastAPIOK = false
+		h.lastAPIErr = err.Error()
+		return
+	}
+	h.lastAPIOK = true
+	h.lastAPIErr = ""
+}
+
+// ready 判断服务是否就绪：最近一次周期在2倍检测间隔（按实际安排的间隔，不低于基础间隔）内完成，
+// 且最近一次API调用成功
+func (h *healthState) ready() (bool, string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.lastCycle.IsZero() {
+		return false, tr("尚未完成首次检测")
+	}
+	interval := h.nextDelay
+	if interval < checkInterval {
+		interval = checkInterval
+	}
+	if time.Since(h.lastCycle) > 2*interval {
+		return false, tr("检测周期超时")
+	}
+	if !h.lastAPIOK {
+		return false, tr("最近一次API调用失败: ") + h.lastAPIErr
+	}
+	return true, "ok"
+}
+
+// startHealthServer 启动健康检查HTTP服务（/healthz 与 /readyz）
+func startHealthServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logInfo("健康检查服务已启动: http://%s (/healthz, /readyz)", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logError("健康检查服务启动失败: %v", err)
+		}
+	}()
+}
+
+// handleHealthz 进程存活检查
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	health.mu.RLock()
+	uptime := time.Since(health.startTime)
+	health.mu.RUnlock()
+
+	writeHealthJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"uptime": uptime.Round(time.Second).String(),
+	})
+}
+
+// handleReadyz 就绪检查
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ok, reason := health.ready()
+
+	health.mu.RLock()
+	lastCycle := health.lastCycle
+	health.mu.RUnlock()
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+
+	body := map[string]interface{}{
+		"ready":  ok,
+		"reason": reason,
+	}
+	if !lastCycle.IsZero() {
+		body["last_cycle"] = lastCycle.Format(time.RFC3339)
+	}
+	writeHealthJSON(w, status, body)
+}
+
+func writeHealthJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
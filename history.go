@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// HistoryEntry 守护进程事件历史条目
+type HistoryEntry struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"` // updated / ip_changed / error / audit
+	IP      string    `json:"ip,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Category 错误类别（auth / rate_limited / not_found / network）
+	Category string `json:"category,omitempty"`
+	// Requests 失败的 API 请求（含 Cf-Ray 与限流响应头）
+	Requests []APIResponseMeta `json:"requests,omitempty"`
+	// Geo 新IP的归属信息（配置 geo 时）
+	Geo *GeoInfo `json:"geo,omitempty"`
+}
+
+// maxHistoryEntries 内存中保留的历史条目数量
+const maxHistoryEntries = 200
+
+var (
+	history   []HistoryEntry
+	historyMu sync.Mutex
+)
+
+// appendHistory 追加一条历史记录，超出上限时丢弃最旧的记录
+func appendHistory(entry HistoryEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	history = append(history, entry)
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+}
+
+// getHistory 返回历史记录副本（新的在后）
+func getHistory() []HistoryEntry {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	result := make([]HistoryEntry, len(history))
+	copy(result, history)
+	return result
+}
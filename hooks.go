@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// HooksConfig 守护进程生命周期钩子
+type HooksConfig struct {
+	// OnStart 守护进程启动且首次检测更新成功后执行的命令
+	OnStart string `json:"on_start,omitempty"`
+	// OnStop 守护进程停止时执行的命令
+	OnStop string `json:"on_stop,omitempty"`
+	// Timeout 单个钩子的执行超时，默认 30s
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// defaultHookTimeout 默认钩子超时
+const defaultHookTimeout = 30 * time.Second
+
+// runHook 通过 /bin/sh 执行钩子命令，事件信息通过环境变量传递
+func runHook(hooks *HooksConfig, event, command string) {
+	config := app.Config()
+	currentIP := app.CurrentIP()
+	if command == "" {
+		return
+	}
+
+	timeout := defaultHookTimeout
+	if hooks.Timeout != "" {
+		if d, err := time.ParseDuration(hooks.Timeout); err != nil {
+			logError("hooks.timeout 无效: %v，使用默认值 %s", err, defaultHookTimeout)
+		} else {
+			timeout = d
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"DNS_MANAGER_EVENT="+event,
+		"DNS_MANAGER_ZONE_ID="+config.ZoneID,
+		"DNS_MANAGER_RECORD="+config.RecordName,
+		"DNS_MANAGER_RECORD_TYPE="+config.RecordType,
+		"DNS_MANAGER_IP="+currentIP,
+		fmt.Sprintf("DNS_MANAGER_PID=%d", os.Getpid()),
+	)
+
+	logInfo("执行 %s 钩子: %s", event, command)
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			logError("%s 钩子执行超时（%s）", event, timeout)
+		} else {
+			logError("%s 钩子执行失败: %v", event, err)
+		}
+		if out != "" {
+			logError("%s 钩子输出: %s", event, out)
+		}
+		return
+	}
+
+	logInfo("%s 钩子执行完成，耗时 %s", event, time.Since(start).Round(time.Millisecond))
+	if out != "" {
+		logInfo("%s 钩子输出: %s", event, out)
+	}
+}
+
+// hooksConfig 返回钩子配置，未配置时返回空配置
+func (c *Config) hooksConfig() *HooksConfig {
+	if c.Hooks == nil {
+		return &HooksConfig{}
+	}
+	return c.Hooks
+}
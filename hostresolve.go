@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// customResolver 是否指定了解析 API 与IP检测服务域名的DNS服务器
+func (c *HTTPConfig) customResolver() bool {
+	return c != nil && (c.DoH != "" || len(c.Resolvers) > 0)
+}
+
+// lookupHost 通过 DoH 或指定的DNS服务器解析主机名（不使用系统解析器），依次尝试直到得到结果
+func (c *HTTPConfig) lookupHost(ctx context.Context, host string) ([]string, error) {
+	var lastErr error
+	if c.DoH != "" {
+		var addrs []string
+		for _, recordType := range []string{"A", "AAAA"} {
+			answers, err := dohQuery(c.DoH, host, recordType)
+			if err != nil {
+				lastErr = fmt.Errorf("%s: %v", c.DoH, err)
+				continue
+			}
+			addrs = append(addrs, answers...)
+		}
+		if len(addrs) > 0 {
+			return addrs, nil
+		}
+	}
+	for _, server := range c.Resolvers {
+		addrs, err := newServerResolver(server).LookupHost(ctx, host+".")
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", server, err)
+			continue
+		}
+		if len(addrs) > 0 {
+			return addrs, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New(tr("没有找到地址"))
+	}
+	return nil, lastErr
+}
+
+// dialContext 返回建立连接的函数；指定了解析器时先用它解析主机名，再逐个连接解析到的地址
+func (c *HTTPConfig) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if !c.customResolver() {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addrs, err := c.lookupHost(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf(tr("通过指定的解析器解析 %s 失败: %v"), host, err)
+		}
+		logDebug("%s 解析为 %v", host, addrs)
+
+		lastErr := fmt.Errorf(tr("%s 没有 %s 地址"), host, network)
+		for _, addr := range addrs {
+			ip := net.ParseIP(addr)
+			if ip == nil || (network == "tcp4" && ip.To4() == nil) || (network == "tcp6" && ip.To4() != nil) {
+				continue
+			}
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
@@ -0,0 +1,1007 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// 当前输出语言：zh（中文，默认）或 en（英文）
+var lang = "zh"
+
+// initLang 设置输出语言，优先使用 --lang 参数，其次根据 LC_ALL / LANG 环境变量判断
+func initLang(flagValue string) {
+	lang = detectLang(flagValue)
+}
+
+func detectLang(flagValue string) string {
+	switch strings.ToLower(flagValue) {
+	case "zh", "cn":
+		return "zh"
+	case "en":
+		return "en"
+	}
+
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" || value == "C" || value == "POSIX" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(value), "zh") {
+			return "zh"
+		}
+		return "en"
+	}
+
+	// 未设置语言环境时保持中文
+	return "zh"
+}
+
+// langFromArgs 在解析命令行参数之前提取 --lang 的值，使参数说明也能按语言输出
+func langFromArgs(args []string) string {
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if strings.HasPrefix(name, "lang=") {
+			return strings.TrimPrefix(name, "lang=")
+		}
+		if name == "lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// tr 返回文本在当前语言下的译文，未收录的文本原样返回
+// 以中文原文作为键，便于在代码中直接阅读
+func tr(text string) string {
+	if lang == "zh" {
+		return text
+	}
+	if translated, ok := messagesEN[text]; ok {
+		return translated
+	}
+	return text
+}
+
+// messagesEN 英文翻译表
+var messagesEN = map[string]string{
+	"写入审计日志失败: %v":                             "failed to write audit log: %v",
+	"序列化审计记录失败: %v":                            "failed to serialize audit entry: %v",
+	"API Token 不能为空":                           "API Token must not be empty",
+	"状态码: %d":                                  "status code: %d",
+	"API 返回错误 (状态码: %d): %s":                   "API returned error (status code: %d): %s",
+	"请求失败: %v":                                 "request failed: %v",
+	"解析响应失败: %v":                               "failed to parse response: %v",
+	"API 错误: %s":                               "API error: %s",
+	"查找DNS记录失败: %v":                            "failed to look up DNS records: %v",
+	"未找到匹配的DNS记录: %s":                          "no matching DNS record found: %s",
+	"未找到类型为 %s 的DNS记录":                         "no DNS record of type %s found",
+	"序列化请求失败: %v":                              "failed to serialize request: %v",
+	"DNS记录更新后内容不匹配: 期望 %s，实际 %s":               "DNS record content mismatch after update: expected %s, got %s",
+	"警告: 无法创建配置目录: %v\n":                       "Warning: cannot create config directory: %v\n",
+	"创建配置目录失败: %v":                             "failed to create config directory: %v",
+	"序列化配置失败: %v":                              "failed to serialize config: %v",
+	"写入配置文件失败: %v":                             "failed to write config file: %v",
+	"删除配置文件失败: %v":                             "failed to delete config file: %v",
+	"检测周期发生异常: %v":                             "panic in check cycle: %v",
+	"写入崩溃报告失败: %v":                             "failed to write crash report: %v",
+	"堆栈信息:\n%s":                                "stack trace:\n%s",
+	"崩溃报告已写入: %s，守护进程将继续运行":                    "crash report written to %s, daemon keeps running",
+	"创建日志目录失败: %v":                             "failed to create log directory: %v",
+	"时间: %s\nPID: %d\n异常: %v\n\n%s":            "Time: %s\nPID: %d\nPanic: %v\n\n%s",
+	"获取可执行文件路径失败: %v":                          "failed to get executable path: %v",
+	"获取绝对路径失败: %v":                             "failed to get absolute path: %v",
+	"启动守护进程失败: %v":                             "failed to start daemon: %v",
+	"守护进程已启动，PID: %d\n":                        "Daemon started, PID: %d\n",
+	"程序已在后台运行，可以安全关闭终端":                        "The program is running in the background; you can safely close the terminal",
+	"无法读取PID文件: %v":                            "cannot read PID file: %v",
+	"进程 %d 未运行（已清理PID文件）":                      "process %d is not running (PID file cleaned up)",
+	"无法找到进程: %v":                               "cannot find process: %v",
+	"无法终止进程: %v":                               "cannot terminate process: %v",
+	"守护进程 (PID: %d) 已停止\n":                     "Daemon (PID: %d) stopped\n",
+	"无法强制终止进程: %v":                             "cannot force-kill process: %v",
+	"守护进程 (PID: %d) 已强制终止\n":                   "Daemon (PID: %d) force-killed\n",
+	"执行ps命令失败: %v":                             "failed to run ps: %v",
+	"未找到PID文件":                                 "PID file not found",
+	"进程不存在":                                    "process does not exist",
+	"已清理无效的PID文件（进程 %d 不存在）":                   "cleaned up stale PID file (process %d does not exist)",
+	"列出进程失败: %v":                               "failed to list processes: %v",
+	"检测到 %d 个运行中的 dns_manager 进程，正在停止...\n":    "Found %d running dns_manager processes, stopping...\n",
+	"  警告: 无法找到进程 %d: %v\n":                    "  Warning: cannot find process %d: %v\n",
+	"  警告: 无法发送 SIGTERM 到进程 %d: %v\n":          "  Warning: cannot send SIGTERM to process %d: %v\n",
+	"  错误: 无法终止进程 %d: %v\n":                    "  Error: cannot terminate process %d: %v\n",
+	"  错误: 无法强制终止进程 %d: %v\n":                  "  Error: cannot force-kill process %d: %v\n",
+	"  ✓ 已停止进程 PID: %d\n":                      "  ✓ Stopped process PID: %d\n",
+	"  ✗ 无法停止进程 PID: %d\n":                     "  ✗ Failed to stop process PID: %d\n",
+	"成功停止 %d 个进程，%d 个进程停止失败":                   "stopped %d processes, failed to stop %d processes",
+	"✓ 已成功停止 %d 个进程\n":                         "✓ Successfully stopped %d processes\n",
+	"尚未完成首次检测":                                 "first check not completed yet",
+	"检测周期超时":                                   "check cycle overdue",
+	"最近一次API调用失败: ":                            "last API call failed: ",
+	"健康检查服务已启动: http://%s (/healthz, /readyz)": "health check server started: http://%s (/healthz, /readyz)",
+	"健康检查服务启动失败: %v":                           "health check server failed: %v",
+	"所有IP检测服务均失败，最后错误: %v":                     "all IP detection services failed, last error: %v",
+	"服务返回状态码: %d":                              "service returned status code: %d",
+	"返回内容为空":                                   "empty response",
+	"无效的IP地址格式: %s":                            "invalid IP address format: %s",
+	"打开日志文件失败: %v":                             "failed to open log file: %v",
+	"日志轮转失败: %v\n":                             "log rotation failed: %v\n",
+	"压缩日志失败: %v\n":                             "failed to compress log: %v\n",
+	"读取日志文件失败: %v":                             "failed to read log file: %v",
+	"未找到日志文件: %s":                              "log file not found: %s",
+	"后台运行模式，直接开始监控（适合系统服务）":                    "run in background mode and start monitoring immediately (for system services)",
+	"执行一次更新后退出（适合 cron）":                       "run a single update and exit (for cron)",
+	"启用日志文件（daemon 模式默认启用）":                    "enable log file (enabled by default in daemon mode)",
+	"停止后台运行的守护进程":                              "stop the background daemon",
+	"强制终止守护进程":                                 "force-kill the daemon",
+	"查看守护进程状态":                                 "show daemon status",
+	"列出所有dns_manager进程":                        "list all dns_manager processes",
+	"查看守护进程详细信息":                               "show detailed daemon information",
+	"清理无效的PID文件":                               "clean up stale PID file",
+	"进入守护进程管理菜单":                               "open the daemon management menu",
+	"以 root 启动时，打开日志和PID文件后切换到该用户运行":           "when started as root, switch to this user after opening log and PID files",
+	"以 root 启动时切换到该用户组运行（默认使用 --user 的主用户组）": "when started as root, switch to this group (defaults to the primary group of --user)",
+	"查看日志文件（配合 -f 持续跟踪，-n 指定行数）":             "view the log file (use -f to follow, -n for line count)",
+	"与 --logs 配合使用，持续跟踪日志输出":                 "used with --logs: keep following log output",
+	"与 --logs 配合使用，显示的日志行数":                  "used with --logs: number of lines to show",
+	"查看日志失败: %v\n":                           "Failed to view logs: %v\n",
+	"列出进程失败: %v\n":                           "Failed to list processes: %v\n",
+	"未找到运行中的 dns_manager 进程":                 "No running dns_manager process found",
+	"找到 %d 个 dns_manager 进程:\n":              "Found %d dns_manager processes:\n",
+	"获取信息失败: %v\n":                           "Failed to get information: %v\n",
+	"PID文件检查完成，无需清理":                         "PID file checked, nothing to clean up",
+	"强制终止守护进程失败: %v\n":                       "Failed to force-kill daemon: %v\n",
+	"停止守护进程失败: %v\n":                         "Failed to stop daemon: %v\n",
+	"守护进程未运行（未找到PID文件）":                      "Daemon is not running (PID file not found)",
+	"守护进程正在运行，PID: %d\n":                     "Daemon is running, PID: %d\n",
+	"守护进程未运行（PID文件存在但进程不存在，PID: %d）\n":       "Daemon is not running (PID file exists but process does not, PID: %d)\n",
+	"提示: 使用 cleanup 命令清理无效的PID文件":            "Hint: use the cleanup command to remove the stale PID file",
+	"初始化日志失败: %v\n":                          "Failed to initialize logger: %v\n",
+	"检测到未配置，请先进行配置...":                       "No configuration found, please configure first...",
+	"初始化 Cloudflare 客户端失败: %v":               "failed to initialize Cloudflare client: %v",
+	"守护进程化失败: %v\n":                          "Failed to daemonize: %v\n",
+	"\n提示: 配置已存在，可以使用以下命令自动后台运行：":            "\nHint: configuration exists, you can run in the background with:",
+	"  或使用交互式菜单选择 '1. 开始监控'":                 "  or choose '1. Start monitoring' in the interactive menu",
+	"=== Cloudflare DNS 动态更新系统 ===":          "=== Cloudflare DNS Dynamic Update System ===",
+	"请选择操作 (1-9): ":                          "Select an option (1-9): ",
+	"感谢使用，再见！":                               "Thanks for using, goodbye!",
+	"无效的选择，请重新输入。":                           "Invalid choice, please try again.",
+	"DNS 管理器已启动（后台模式）":                       "DNS manager started (background mode)",
+	"配置信息: Zone ID=%s, 记录名称=%s, 记录类型=%s":     "Configuration: Zone ID=%s, record name=%s, record type=%s",
+	"收到停止信号，正在退出...":                         "Received stop signal, exiting...",
+	"收到重载信号，重新加载配置...":                       "Received reload signal, reloading configuration...",
+	"重新加载配置...":                              "Reloading configuration...",
+	"新配置无效，保持使用旧配置":                          "New configuration is invalid, keeping the old one",
+	"重新初始化 Cloudflare 客户端失败: %v":             "failed to re-initialize Cloudflare client: %v",
+	"Cloudflare 客户端已重新初始化":                   "Cloudflare client re-initialized",
+	"配置已重新加载":                                "Configuration reloaded",
+	"执行一次性 DNS 更新":                           "Running one-off DNS update",
+	"更新完成":                                   "Update finished",
+	"\n========== 主菜单 ==========":            "\n========== Main Menu ==========",
+	"2. 检查当前公网IP":                            "2. Check current public IP",
+	"3. 立即更新DNS记录":                           "3. Update DNS record now",
+	"4. DNS记录管理":                             "4. Manage DNS records",
+	"5. 配置设置":                                "5. Settings",
+	"6. 启动后台守护进程 (自动后台运行)":                   "6. Start background daemon (runs in background automatically)",
+	"7. 守护进程管理":                              "7. Daemon management",
+	"9. 退出":                                  "9. Exit",
+	"提示: 使用 run --detach 命令可直接后台运行":          "Hint: use run --detach to run in the background directly",
+	"提示: 使用 manage 命令进入守护进程管理":               "Hint: use the manage command to open daemon management",
+	"监控已在运行中...":                             "Monitoring is already running...",
+	"\n开始监控模式...":                            "\nStarting monitoring mode...",
+	"配置信息:\n":                                "Configuration:\n",
+	"  记录名称: %s\n":                           "  Record name: %s\n",
+	"  记录类型: %s\n":                           "  Record type: %s\n",
+	"\n按 Ctrl+C 停止监控":                        "\nPress Ctrl+C to stop monitoring",
+	"提示: 如需后台运行，请使用 run --detach 命令或配置为系统服务": "Hint: to run in the background, use run --detach or configure a system service",
+	"\n\n监控已停止":   "\n\nMonitoring stopped",
+	"正在检查公网IP...": "Checking public IP...",
+	"获取公网IP失败 (尝试 %d/%d): %v，1秒后重试...": "Failed to get public IP (attempt %d/%d): %v, retrying in 1 second...",
+	"获取公网IP失败: %v":                     "Failed to get public IP: %v",
+	"当前公网IP: %s (来源: %s)":              "Current public IP: %s (source: %s)",
+	"IP未变化 (%s)，跳过更新":                  "IP unchanged (%s), skipping update",
+	"检测到IP变化 (%s -> %s)，正在确认...":       "IP change detected (%s -> %s), confirming...",
+	"确认IP时失败: %v，取消更新":                 "Failed to confirm IP: %v, update cancelled",
+	"IP确认失败: 第一次检测到 %s，确认时检测到 %s (来源: %s)，可能是服务不稳定，取消更新": "IP confirmation failed: first detected %s, confirmation detected %s (source: %s), service may be unstable, update cancelled",
+	"IP确认失败: %s != %s":                         "IP confirmation failed: %s != %s",
+	"IP变化已确认 (%s -> %s)，正在检查DNS记录...":          "IP change confirmed (%s -> %s), checking DNS records...",
+	"未找到现有DNS记录，将创建新记录":                        "No existing DNS record found, a new record will be created",
+	"未找到指向本机IP的记录，将创建或更新记录":                    "No record points to this machine's IP, will create or update a record",
+	"正在更新或创建DNS记录: %s -> %s":                   "Updating or creating DNS record: %s -> %s",
+	"DNS更新/创建失败 (尝试 %d/%d): %v，%s后重试...":       "DNS update/create failed (attempt %d/%d): %v, retrying in %s...",
+	"DNS更新/创建失败: %v":                           "DNS update/create failed: %v",
+	"验证DNS记录失败: %v，但更新可能已成功":                   "Failed to verify DNS record: %v, but the update may have succeeded",
+	"DNS记录验证成功: %s 现在包含IP %s (共 %d 个A记录)":      "DNS record verified: %s now contains IP %s (%d A records in total)",
+	"DNS记录验证失败: 未找到指向 %s 的记录":                  "DNS record verification failed: no record points to %s",
+	"DNS记录已成功更新/创建: %s -> %s":                  "DNS record updated/created successfully: %s -> %s",
+	"\n正在检查当前公网IP...":                          "\nChecking current public IP...",
+	"❌ 获取失败: %v\n":                             "❌ Failed to get: %v\n",
+	"当前公网IP: %s (来源: %s)\n":                    "Current public IP: %s (source: %s)\n",
+	"\n正在获取当前公网IP...":                          "\nGetting current public IP...",
+	"❌ 获取公网IP失败: %v\n":                         "❌ Failed to get public IP: %v\n",
+	"正在更新DNS记录 %s...\n":                        "Updating DNS record %s...\n",
+	"手动更新: ":                                   "manual update: ",
+	"❌ 更新失败: %v\n":                             "❌ Update failed: %v\n",
+	"✓ DNS记录已成功更新: %s -> %s\n":                 "✓ DNS record updated: %s -> %s\n",
+	"\n正在获取DNS记录...":                           "\nFetching DNS records...",
+	"未找到匹配的DNS记录":                              "No matching DNS record found",
+	"\nDNS记录列表:":                               "\nDNS records:",
+	"名称":                                       "Name",
+	"类型":                                       "Type",
+	"内容":                                       "Content",
+	"\n========== 配置向导 ==========":             "\n========== Setup Wizard ==========",
+	"   请在 Cloudflare 控制台创建 API Token":         "   Create an API Token in the Cloudflare dashboard",
+	"   权限: Zone - DNS - Edit":                 "   Permission: Zone - DNS - Edit",
+	"   访问: 选择你的域名":                            "   Resources: select your domain",
+	"请输入 API Token: ":                          "Enter API Token: ",
+	"   在 Cloudflare 域名概览页面右侧可以找到 Zone ID":     "   The Zone ID is shown on the right side of the domain overview page in Cloudflare",
+	"请输入 Zone ID: ":                            "Enter Zone ID: ",
+	"Zone ID 不能为空":                             "Zone ID must not be empty",
+	"\n3. DNS 记录名称":                            "\n3. DNS record name",
+	"   例如: subdomain.example.com 或 @ (表示根域名)": "   e.g. subdomain.example.com or @ (the root domain)",
+	"请输入记录名称: ":                                "Enter record name: ",
+	"记录名称不能为空":                                 "Record name must not be empty",
+	"\n4. DNS 记录类型":                            "\n4. DNS record type",
+	"   通常为 A (IPv4) 或 AAAA (IPv6)":            "   Usually A (IPv4) or AAAA (IPv6)",
+	"请输入记录类型 (默认: A): ":                        "Enter record type (default: A): ",
+	"❌ 保存配置失败: %v\n":                           "❌ Failed to save configuration: %v\n",
+	"\n✓ 配置已保存！":                               "\n✓ Configuration saved!",
+	"\n检测到已有 %d 个守护进程在运行:\n":                   "\nDetected %d running daemons:\n",
+	"\n正在停止所有现有守护进程...":                        "\nStopping all existing daemons...",
+	"❌ 停止现有进程时出错: %v\n":                        "❌ Error while stopping existing processes: %v\n",
+	"是否继续？(y/N)":                               "Continue? (y/N)",
+	"已取消":                                      "Cancelled",
+	"✓ 所有现有守护进程已停止":                            "✓ All existing daemons stopped",
+	"\n检测到残留的PID文件（PID: %d），正在清理...\n":         "\nFound a leftover PID file (PID: %d), cleaning up...\n",
+	"✓ 已清理残留的PID文件":                            "✓ Leftover PID file cleaned up",
+	"\n========== 清理配置 ==========":             "\n========== Clean Up Configuration ==========",
+	"检测到已有服务，正在删除所有相关配置...":                    "An existing service was detected, deleting all related configuration...",
+	"⚠️  删除配置文件时出错: %v\n":                      "⚠️  Error while deleting config file: %v\n",
+	"✓ 配置文件已删除":                                "✓ Config file deleted",
+	"\n========== 重新配置 ==========":             "\n========== Reconfigure ==========",
+	"所有配置已清除，请按照提示重新输入以下信息：":                   "All configuration has been cleared, please enter the following information again:",
+	"❌ 配置未完成，无法启动守护进程":                         "❌ Configuration incomplete, cannot start daemon",
+	"❌ 初始化 Cloudflare 客户端失败: %v\n":             "❌ Failed to initialize Cloudflare client: %v\n",
+	"请检查 API Token 是否正确":                       "Please check that the API Token is correct",
+	"\n✓ 配置完成！":                                "\n✓ Configuration complete!",
+	"\n========== 首次配置 ==========":             "\n========== First-time Setup ==========",
+	"检测到未配置，需要先进行配置才能启动守护进程":                   "No configuration found; it is required before the daemon can start",
+	"请按照提示输入以下信息：":                             "Please enter the following information:",
+	"正在验证配置...":                                "Verifying configuration...",
+	"❌ 配置验证失败: %v\n":                           "❌ Configuration verification failed: %v\n",
+	"请检查配置是否正确，或使用菜单选项 5 重新配置":                 "Please check your configuration, or use menu option 5 to reconfigure",
+	"✓ 配置验证通过":                                 "✓ Configuration verified",
+	"\n正在启动后台守护进程...":                          "\nStarting background daemon...",
+	"❌ 获取可执行文件路径失败: %v\n":                      "❌ Failed to get executable path: %v\n",
+	"❌ 获取绝对路径失败: %v\n":                         "❌ Failed to get absolute path: %v\n",
+	"❌ 启动守护进程失败: %v\n":                         "❌ Failed to start daemon: %v\n",
+	"✓ 守护进程已启动，PID: %d\n":                      "✓ Daemon started, PID: %d\n",
+	"使用 './dns_manager status' 查看运行状态":         "Use './dns_manager status' to check status",
+	"使用 './dns_manager stop' 停止守护进程":           "Use './dns_manager stop' to stop the daemon",
+	"使用 './dns_manager info' 查看详细信息":           "Use './dns_manager info' to see details",
+	"记录类型必须是 A 或 AAAA":                         "record type must be A or AAAA",
+	"无法访问 Cloudflare API 或配置错误: %v":            "cannot access Cloudflare API or configuration is wrong: %v",
+	"\n========== 守护进程管理 ==========":           "\n========== Daemon Management ==========",
+	"1. 查看守护进程状态":                              "1. Show daemon status",
+	"2. 查看详细信息":                                "2. Show details",
+	"3. 列出所有进程":                                "3. List all processes",
+	"4. 停止守护进程":                                "4. Stop daemon",
+	"5. 强制终止守护进程":                              "5. Force-kill daemon",
+	"6. 清理无效PID文件":                             "6. Clean up stale PID file",
+	"7. 返回主菜单":                                 "7. Back to main menu",
+	"请选择操作 (1-7): ":                            "Select an option (1-7): ",
+	"✓ 守护进程正在运行，PID: %d\n":                     "✓ Daemon is running, PID: %d\n",
+	"✗ 守护进程未运行（PID文件存在但进程不存在，PID: %d）\n":       "✗ Daemon is not running (PID file exists but process does not, PID: %d)\n",
+	"提示: 选择选项 6 清理无效的PID文件":                    "Hint: choose option 6 to remove the stale PID file",
+	"❌ 获取信息失败: %v\n":                           "❌ Failed to get information: %v\n",
+	"❌ 列出进程失败: %v\n":                           "❌ Failed to list processes: %v\n",
+	"\n找到 %d 个 dns_manager 进程:\n":              "\nFound %d dns_manager processes:\n",
+	"❌ 停止失败: %v\n":                             "❌ Stop failed: %v\n",
+	"✓ 守护进程已停止":                                "✓ Daemon stopped",
+	"警告: 强制终止可能导致数据丢失，是否继续？(y/N)":              "Warning: force-killing may lose data, continue? (y/N)",
+	"❌ 强制终止失败: %v\n":                           "❌ Force-kill failed: %v\n",
+	"✓ 守护进程已强制终止":                              "✓ Daemon force-killed",
+	"✓ PID文件检查完成，无需清理":                         "✓ PID file checked, nothing to clean up",
+	"\n========== 守护进程信息 ==========":           "\n========== Daemon Information ==========",
+	"状态: ✓ 正在运行":                               "Status: ✓ running",
+	"状态: ✗ 未运行":                                "Status: ✗ not running",
+	"PID文件: %s\n":                              "PID file: %s\n",
+	"日志文件: %s\n":                               "Log file: %s\n",
+	"日志大小: %d 字节 (%.2f KB)\n":                  "Log size: %d bytes (%.2f KB)\n",
+	"\n进程详情:":                                  "\nProcess details:",
+	"运行时长: %s\n":                               "Uptime: %s\n",
+	"检测次数: %d\n":                               "Check cycles: %d\n",
+	"DNS更新次数: %d\n":                            "DNS updates: %d\n",
+	"当前IP: %s\n":                               "Current IP: %s\n",
+	"最近IP变化: %s\n":                             "Last IP change: %s\n",
+	"连续失败次数: %d\n":                             "Consecutive failures: %d\n",
+	"最近错误: %s\n":                               "Last error: %s\n",
+	"错误: %s\n":                                 "Error: %s\n",
+	"切换运行用户需要以 root 身份启动（当前 UID: %d）":          "switching user requires starting as root (current UID: %d)",
+	"查找用户 %s 失败: %v":                           "failed to look up user %s: %v",
+	"查找用户组 %s 失败: %v":                          "failed to look up group %s: %v",
+	"修改数据目录所有权失败: %v":                          "failed to change ownership of data directory: %v",
+	"设置附加用户组失败: %v":                            "failed to set supplementary groups: %v",
+	"切换用户组失败: %v":                              "failed to switch group: %v",
+	"切换用户失败: %v":                               "failed to switch user: %v",
+	"已切换运行身份: UID=%d, GID=%d":                  "switched identity: UID=%d, GID=%d",
+	"写入状态文件失败: %v":                             "failed to write state file: %v",
+	"输出语言: en 或 zh（默认根据 LANG 环境变量判断）":          "output language: en or zh (defaults from the LANG environment variable)",
+	"无效的日志时区 %s: %v":                           "invalid log timezone %s: %v",
+	"[%s] <-- %s %s 失败 (%s): %v":               "[%s] <-- %s %s failed (%s): %v",
+	"[%s] <-- 响应内容: %s":                        "[%s] <-- response body: %s",
+	"记录所有 HTTP 请求的追踪信息（方法、URL、状态码、耗时、Cf-Ray，出错时记录响应内容）": "trace every HTTP request (method, URL, status, latency, Cf-Ray, and body on error)",
+	"DNS更新失败":                   "DNS update failed",
+	"DNS记录已更新":                  "DNS record updated",
+	"通知渠道配置无效: %v":              "invalid notification channel configuration: %v",
+	"已启用 %d 个通知渠道":              "%d notification channels enabled",
+	"%s 通知缺少 webhook":           "%s notification is missing webhook",
+	"不支持的通知类型: %s":              "unsupported notification type: %s",
+	"发送通知失败 (%s): %v":           "failed to send notification (%s): %v",
+	"记录: %s\n":                  "Record: %s\n",
+	"主机: %s\n":                  "Host: %s\n",
+	"时间: %s":                    "Time: %s",
+	"(无)":                       "(none)",
+	"机器人返回错误 (errcode: %d): %s": "robot returned error (errcode: %d): %s",
+	"无效的 webhook 地址: %v":        "invalid webhook URL: %v",
+	"通知渠道 %s 的事件过滤无效: %s，将接收所有事件": "invalid event filter for notification channel %s: %s, all events will be delivered",
+	"%s 通知缺少 %s":                          "%s notification is missing %s",
+	"Bark 返回错误 (code: %d): %s":            "Bark returned error (code: %d): %s",
+	"通知渠道 %s 的模板无效: %v，将使用默认格式":           "invalid template for notification channel %s: %v, using default format",
+	"通知渠道 %s 的 min_interval 无效: %v":       "invalid min_interval for notification channel %s: %v",
+	"通知渠道 %s 的 dedup_window 无效: %v":       "invalid dedup_window for notification channel %s: %v",
+	"通知已被限流或去重 (%s): %s":                  "notification throttled or deduplicated (%s): %s",
+	"（此前有 %d 条通知因限流被省略）":                  "(%d earlier notifications were suppressed by throttling)",
+	"渲染通知模板失败 (%s): %v":                   "failed to render notification template (%s): %v",
+	"notify_policy.escalate_after 无效: %v": "invalid notify_policy.escalate_after: %v",
+	"DNS更新已恢复":                            "DNS updates recovered",
+	"连续失败 %d 次（持续 %s）后恢复正常":               "back to normal after %d consecutive failures (lasting %s)",
+	"连续失败 %d 次: %v":                       "%d consecutive failures: %v",
+	"DNS更新持续失败（升级通知）":                     "DNS updates still failing (escalation)",
+	"已持续失败 %s（%d 次）: %v":                  "failing for %s (%d times): %v",
+	"hooks.timeout 无效: %v，使用默认值 %s":       "invalid hooks.timeout: %v, using default %s",
+	"执行 %s 钩子: %s":                        "running %s hook: %s",
+	"%s 钩子执行超时（%s）":                       "%s hook timed out (%s)",
+	"%s 钩子执行失败: %v":                       "%s hook failed: %v",
+	"%s 钩子输出: %s":                         "%s hook output: %s",
+	"%s 钩子执行完成，耗时 %s":                     "%s hook finished in %s",
+	"管理 API 未启动: 必须配置 api_auth_token":     "management API not started: api_auth_token is required",
+	"管理 API 已启动: http://%s":               "management API started: http://%s",
+	"管理 API 启动失败: %v":                     "management API failed: %v",
+	"未授权":                                 "unauthorized",
+	"不支持的请求方法":                            "method not allowed",
+	"守护进程繁忙，请稍后重试":                        "daemon is busy, please retry later",
+	"收到管理 API 更新请求":                       "received update request from management API",
+	"运行守护进程（默认前台运行，适合 systemd；--detach 转为后台）": "Run the daemon (foreground by default, suitable for systemd; --detach to background)",
+	"停止守护进程（--force 强制终止）":                    "Stop the daemon (--force to kill)",
+	"DNS记录管理":                                 "Manage DNS records",
+	"立即检测公网IP并更新DNS记录":                        "Detect the public IP and update the DNS record now",
+	"配置管理（查看、路径、编辑）":                          "Manage configuration (show, path, edit)",
+	"显示帮助信息":                                  "Show help",
+	"未知命令: %s\n\n":                            "Unknown command: %s\n\n",
+	"用法: dns_manager [命令] [参数]":               "Usage: dns_manager [command] [flags]",
+	"不带命令运行时进入交互式菜单。可用命令:":                    "Without a command the interactive menu starts. Commands:",
+	"使用 'dns_manager <命令> --help' 查看命令参数。":    "Use 'dns_manager <command> --help' for command flags.",
+	"旧的参数形式（--daemon、--once、--status 等）仍然可用。": "Legacy flags (--daemon, --once, --status, ...) are still accepted.",
+	"用法: dns_manager %s\n\n%s\n\n参数:\n":       "Usage: dns_manager %s\n\n%s\n\nFlags:\n",
+	"初始化日志失败: %v":                             "Failed to initialize logging: %v",
+	"转为后台守护进程运行":                              "Detach and run as a background daemon",
+	"持续跟踪日志输出":                                "Follow log output",
+	"显示的日志行数":                                 "Number of log lines to show",
+	"用法: dns_manager records list":            "Usage: dns_manager records list",
+	"用法: dns_manager config show|path|edit":   "Usage: dns_manager config show|path|edit",
+	"旧参数:": "Legacy flags:",
+	"输出格式: text 或 json（json 便于脚本处理）": "Output format: text or json (json is easier for scripts)",
+	"无效的输出格式: %s（可选 text 或 json）\n":  "Invalid output format: %s (expected text or json)\n",
+	"更新失败: %v":                        "Update failed: %v",
+	"\n========== DNS记录管理 ==========": "\n========== DNS Records ==========",
+	"1. 查看当前配置的记录":                    "1. View the configured record",
+	"2. 查看区域内所有记录":                    "2. View all records in the zone",
+	"3. 创建记录":                         "3. Create a record",
+	"4. 编辑记录":                         "4. Edit a record",
+	"5. 删除记录":                         "5. Delete a record",
+	"6. 返回主菜单":                        "6. Back to main menu",
+	"请选择操作 (1-6): ":                   "Select an option (1-6): ",
+	"交互式菜单":                           "interactive menu",
+	"代理":                              "Proxied",
+	"自动":                              "auto",
+	"是":                               "yes",
+	"否":                               "no",
+	"请输入记录序号（直接回车取消）: ": "Enter the record number (press Enter to cancel): ",
+	"无效的序号":                                         "Invalid number",
+	"无效的IPv6地址格式: %s":                               "Invalid IPv6 address: %s",
+	"记录内容不能为空":                                      "Record content must not be empty",
+	"无效的 TTL: %s（1 表示自动，或 60-86400 秒）":              "Invalid TTL: %s (1 for auto, or 60-86400 seconds)",
+	"\n========== 创建记录 ==========":                  "\n========== Create Record ==========",
+	"记录类型（如 A、AAAA、CNAME、TXT、MX）: ":                 "Record type (e.g. A, AAAA, CNAME, TXT, MX): ",
+	"记录名称（完整域名）: ":                                  "Record name (fully qualified): ",
+	"记录内容: ":                                        "Record content: ",
+	"TTL（秒，1 表示自动，默认 1）: ":                          "TTL (seconds, 1 for auto, default 1): ",
+	"是否开启 Cloudflare 代理？(y/N): ":                    "Enable Cloudflare proxy? (y/N): ",
+	"优先级（默认 10）: ":                                  "Priority (default 10): ",
+	"\n即将创建: %s %s %s (TTL: %s, 代理: %s)\n":          "\nAbout to create: %s %s %s (TTL: %s, proxied: %s)\n",
+	"确认创建？":                                         "Create this record?",
+	"❌ 创建失败: %v\n":                                  "❌ Create failed: %v\n",
+	"✓ 记录已创建: %s %s %s (ID: %s)\n":                  "✓ Record created: %s %s %s (ID: %s)\n",
+	"\n正在编辑: %s %s（直接回车保留原值）\n":                     "\nEditing: %s %s (press Enter to keep the current value)\n",
+	"记录内容 [%s]: ":                                   "Record content [%s]: ",
+	"是否开启 Cloudflare 代理 [%s]: ":                     "Enable Cloudflare proxy [%s]: ",
+	"记录未修改":                                         "Record unchanged",
+	"\n内容: %s -> %s\nTTL: %s -> %s\n代理: %s -> %s\n": "\nContent: %s -> %s\nTTL: %s -> %s\nProxied: %s -> %s\n",
+	"确认修改？":                                         "Apply these changes?",
+	"✓ 记录已更新: %s %s %s\n":                           "✓ Record updated: %s %s %s\n",
+	"\n即将删除: %s %s %s\n":                            "\nAbout to delete: %s %s %s\n",
+	"警告: 该记录由本程序自动维护，删除后守护进程可能会重新创建": "Warning: this record is maintained by this program; the daemon may recreate it",
+	"确认删除？":        "Delete this record?",
+	"❌ 删除失败: %v\n": "❌ Delete failed: %v\n",
+	"✓ 记录已删除":      "✓ Record deleted",
+	"解析请求失败: %v":   "Failed to parse request: %v",
+	"代理 %s 上报IP变化 (%s -> %s)，正在更新记录 %s": "Agent %s reported an IP change (%s -> %s), updating record %s",
+	"代理: %s":                 "agent: %s",
+	"代理 %s 的记录更新失败: %v":      "Failed to update the record for agent %s: %v",
+	"代理 %s 的记录已更新: %s -> %s": "Record for agent %s updated: %s -> %s",
+	"代理已启动，控制器: %s":          "Agent started, controller: %s",
+	"上报IP失败: %v":             "Failed to report IP: %v",
+	"已上报IP: %s (来源: %s)":     "Reported IP: %s (source: %s)",
+	"控制器已更新DNS记录: %s":        "Controller updated the DNS record: %s",
+	"控制器返回错误 (状态码: %d): %s":  "Controller returned an error (status: %d): %s",
+	"代理模式需要在配置文件中设置 controller.url 和 controller.token": "Agent mode requires controller.url and controller.token in the config file",
+	"代理模式：检测本机公网IP并上报给控制器（本机无需 Cloudflare 令牌）":         "Agent mode: detect this host's public IP and report it to a controller (no Cloudflare token needed here)",
+	"不支持事件流": "Event streaming is not supported",
+	"查询权威名称服务器（及公共解析器），检查记录的传播情况":                  "Query the authoritative nameservers (and public resolvers) to check record propagation",
+	"记录类型（默认使用配置中的记录类型）":                           "Record type (defaults to the configured record type)",
+	"期望的解析结果，多个值用逗号分隔（默认使用 Cloudflare 中的记录值）":      "Expected answers, comma separated (defaults to the record values in Cloudflare)",
+	"同时查询公共解析器 1.1.1.1 与 8.8.8.8":                  "Also query the public resolvers 1.1.1.1 and 8.8.8.8",
+	"提示: 记录已开启 Cloudflare 代理，解析结果为 Cloudflare 的地址": "Note: the record is proxied by Cloudflare, so answers are Cloudflare addresses",
+	"✓ 所有权威名称服务器均已返回期望值":                           "✓ All authoritative nameservers return the expected value",
+	"✗ 部分权威名称服务器尚未返回期望值":                           "✗ Some authoritative nameservers do not return the expected value yet",
+	"获取区域信息失败，改为查询 NS 记录: %v":                      "Failed to get zone details, falling back to NS lookup: %v",
+	"未找到 %s 的权威名称服务器":                              "No authoritative nameservers found for %s",
+	"\n记录: %s (%s)\n期望值: %s\n":                     "\nRecord: %s (%s)\nExpected: %s\n",
+	"解析器":                                          "Resolver",
+	"状态":                                           "Status",
+	"解析结果":                                         "Answers",
+	"* 权威名称服务器":                                    "* authoritative nameserver",
+	"记录已开启代理，跳过DNS传播验证":                            "The record is proxied, skipping DNS propagation verification",
+	"正在等待权威名称服务器返回新IP %s（最长 %s）...":                "Waiting for the authoritative nameservers to return the new IP %s (up to %s)...",
+	"DNS传播已验证: %s -> %s（耗时 %s）":                    "DNS propagation verified: %s -> %s (took %s)",
+	"DNS传播验证超时（%s）: %s 尚未返回 %s":                    "DNS propagation verification timed out (%s): %s do not return %s yet",
+	"等待DNS传播: %s 尚未返回 %s":                          "Waiting for DNS propagation: %s do not return %s yet",
+	"将修改前快照中的记录恢复到 Cloudflare（不指定文件时列出快照）":         "Push the records from a pre-change snapshot back to Cloudflare (lists snapshots when no file is given)",
+	"不询问确认，直接执行":                                   "Do not ask for confirmation",
+	"读取快照失败: %v":                                   "Failed to read snapshot: %v",
+	"没有可用的快照":                                      "No snapshots available",
+	"快照目录: %s\n":                                   "Snapshot directory: %s\n",
+	"快照: %s（%s，%d 条记录）\n":                          "Snapshot: %s (%s, %d records)\n",
+	"当前记录与快照一致，无需恢复":                               "Current records match the snapshot, nothing to restore",
+	"确认恢复？":                                        "Restore this snapshot?",
+	"恢复快照: ":                                       "restore snapshot: ",
+	"✓ 快照已恢复":                                      "✓ Snapshot restored",
+	"保存修改前快照失败: %v":                                "Failed to save the pre-change snapshot: %v",
+	"已保存 %s 的修改前快照（%d 条记录）: %s":                    "Saved pre-change snapshot of %s (%d records): %s",
+	"解析快照失败: %v":                                   "Failed to parse snapshot: %v",
+	"快照文件缺少 zone_id 或 name":                        "Snapshot file is missing zone_id or name",
+	"%s %s %s 失败: %v":                              "%s %s %s failed: %v",
+	"为避免覆盖现有配置，程序已退出。请修复或删除该文件后重试": "Exiting to avoid overwriting the existing configuration. Fix or remove the file and try again",
+	"读取配置文件失败: %v":    "Failed to read config file: %v",
+	"配置文件 %s 已损坏: %v": "Config file %s is corrupted: %v",
+	"警告: 配置文件校验和不匹配（可能被手动编辑），已按当前内容加载；删除第一行校验和即可消除此警告": "Warning: config file checksum mismatch (probably edited by hand), loaded as-is; remove the checksum on the first line to silence this warning",
+	"%v，保持使用旧配置":                                       "%v, keeping the previous configuration",
+	"快照文件校验失败，文件可能已损坏: %s":                             "Snapshot checksum mismatch, the file may be corrupted: %s",
+	"✓ DNS记录已是最新: %s -> %s\n":                          "✓ DNS record is already up to date: %s -> %s\n",
+	"\n将对 %s (%s) 进行以下更改:\n":                           "\nThe following changes will be made to %s (%s):\n",
+	"确认应用以上更改？":                                        "Apply the changes above?",
+	"只显示将要执行的更改，不修改DNS记录":                              "Only show the changes that would be made, without modifying DNS records",
+	"获取DNS记录失败: %v":                                    "Failed to get DNS records: %v",
+	"dry-run 模式，未修改任何记录":                               "dry-run mode, no records were modified",
+	"[dry-run] 计划操作 %s: %s -> %s（未执行）":                 "[dry-run] planned %s: %s -> %s (not applied)",
+	"配置或客户端无效，保持使用旧配置":                                 "Invalid config or client, keeping the previous configuration",
+	"max_check_interval 格式无效: %v，使用默认值":                "Invalid max_check_interval: %v, using the default",
+	"检测间隔调整为 %s":                                       "Check interval adjusted to %s",
+	"%d/%d 个记录同步失败: %s":                                "%d/%d records failed to sync: %s",
+	"%s: 找到 %d 个DNS记录":                                 "%s: found %d DNS records",
+	"%s 已存在指向本机IP (%s) 的DNS记录，无需更新":                    "%s already has a DNS record pointing to this machine's IP (%s), no update needed",
+	"同步记录 %s 时发生异常: %v":                                "Panic while syncing record %s: %v",
+	"读取租约记录失败: %v":                                     "Failed to read lease record: %v",
+	"写入租约记录失败: %v":                                     "Failed to write lease record: %v",
+	"已获得写入租约（%s），本实例负责更新记录":                            "Acquired the write lease (%s), this instance now updates the records",
+	"写入租约已被 %s 接管，本实例转为待命":                             "The write lease was taken over by %s, this instance is now on standby",
+	"写入租约由 %s 持有（%s 到期），本实例待命":                         "The write lease is held by %s (expires %s), this instance is on standby",
+	"释放写入租约失败: %v":                                     "Failed to release the write lease: %v",
+	"已释放写入租约":                                          "Released the write lease",
+	"min_update_interval 格式无效: %v，不限制更新频率":             "Invalid min_update_interval: %v, update frequency is not limited",
+	"IP频繁变化 (%s -> %s)：距上次更新不足 %s，%s 后再更新（本轮已推迟 %d 次）": "IP is flapping (%s -> %s): less than %s since the last update, updating in %s (deferred %d times so far)",
+	"IP频繁变化": "IP is flapping",
+	"距上次更新不足 min_update_interval，已暂缓写入DNS记录": "Less than min_update_interval since the last update, DNS record write deferred",
+	"连接探测失败: %v": "Connectivity probe failed: %v",
+	"网络不可用，暂停检测，每 %s 探测一次连接；待同步的IP: %s":       "Network unavailable, pausing checks and probing connectivity every %s; IP pending sync: %s",
+	"网络不可用，暂停检测，每 %s 探测一次连接":                  "Network unavailable, pausing checks and probing connectivity every %s",
+	"网络已恢复（离线 %s），立即执行检测":                     "Network restored (offline for %s), checking immediately",
+	"service_check.target 应为 host:port: %v":   "service_check.target must be host:port: %v",
+	"service_check.target 应为 http(s) URL: %s": "service_check.target must be an http(s) URL: %s",
+	"不支持的 service_check.type: %s":             "Unsupported service_check.type: %s",
+	"本机服务健康检查失败 (%s): %v":                     "Local service health check failed (%s): %v",
+	"%s 没有其他机器的记录，保留本机记录":                     "%s has no records from other machines, keeping this machine's record",
+	"已从 %s 撤下本机记录 (%s)":                       "Withdrew this machine's record from %s (%s)",
+	"撤下本机记录失败: %s":                            "Failed to withdraw this machine's records: %s",
+	"健康检查配置无效，不检查本机服务: %v":                    "Invalid service_check config, not checking the local service: %v",
+	"本机服务持续不可用超过 %s，撤下本机记录":                   "Local service has been unavailable for over %s, withdrawing this machine's records",
+	"本机记录已撤下":                                 "Record withdrawn",
+	"本机服务健康检查持续失败，已从记录集中撤下本机记录":               "The local service health check kept failing; this machine's record was withdrawn from the record set",
+	"本机服务已恢复，重新加入记录集":                         "Local service recovered, rejoining the record set",
+	"本机记录已恢复":                                 "Record restored",
+	"本机服务健康检查恢复正常，重新加入记录集":                    "The local service health check passed again; rejoining the record set",
+	"Cloudflare 不允许重复的记录，普通记录模式下权重 %d 与其他机器相同；需要按权重分配流量时请配置 load_balancer_pool": "Cloudflare does not allow duplicate records, so in plain record mode weight %d is the same as other machines; configure load_balancer_pool to split traffic by weight",
+	"权重已恢复为 %d，重新加入记录集":          "Weight restored to %d, rejoining the record set",
+	"权重为 0，撤下本机记录":               "Weight is 0, withdrawing this machine's records",
+	"读取源站池失败: %v":                "Failed to read load balancer pool: %v",
+	"修改源站池失败: %v":                "Failed to update load balancer pool: %v",
+	"源站池中的 %s 已更新: %s (权重 %.2f)": "Pool origin %s updated: %s (weight %.2f)",
+	"列出成员登记中的集群成员":               "List cluster members from the fleet registry",
+	"未配置成员登记（fleet）":             "Fleet registry (fleet) is not configured",
+	"读取成员登记失败: %v":               "Failed to read fleet registry: %v",
+	"写入成员登记失败: %v":               "Failed to write fleet registry: %v",
+	"集群成员: %s 在线":                "Fleet member online: %s",
+	"清理成员登记失败: %v":               "Failed to prune fleet registry entry: %v",
+	"集群成员 %s (%s) 超过 %s 未在线，已清理": "Fleet member %s (%s) not seen for over %s, pruned",
+	"没有成员登记":                     "No fleet members registered",
+	"节点":                         "Node",
+	"最近在线":                       "Last seen",
+	"在线":                         "Online",
+	"已离开":                        "Gone",
+	"获取区域名称失败: %v":               "Failed to get zone name: %v",
+	"   @ 表示根域名: %s\n":           "   @ is the zone apex: %s\n",
+	"   ⚠ 无法获取区域名称，保存为 @，运行时再转换: %v\n":  "   ⚠ Could not get the zone name; saving @ and converting at runtime: %v\n",
+	"%s 及 %d 个子域名已指向本机IP (%s)，无需更新":     "%s and %d subdomains already point to this machine's IP (%s), no update needed",
+	"DNS记录已成功更新/创建: %s 及 %d 个子域名 -> %s": "DNS records updated/created: %s and %d subdomains -> %s",
+	"DNS服务不支持批量请求，逐条提交":                 "DNS provider does not support batch requests, submitting one by one",
+	"清除缓存失败 (%s): %v":                   "Failed to purge cache (%s): %v",
+	"源站IP已变化，已清除 %s 所在区域的缓存":            "Origin IP changed, purged the cache of the zone containing %s",
+	"防火墙 %s 缺少 table 或 set":             "Firewall %s is missing table or set",
+	"防火墙 %s 缺少 set":                     "Firewall %s is missing set",
+	"防火墙 %s 缺少 group_id 或 port":         "Firewall %s is missing group_id or port",
+	"不支持的防火墙类型: %s":                     "Unsupported firewall type: %s",
+	"从防火墙 %s 删除旧IP失败: %v %s":            "Failed to remove the old IP from firewall %s: %v %s",
+	"更新防火墙 %s 失败: %v %s":                "Failed to update firewall %s: %v %s",
+	"防火墙 %s 已放行新IP %s":                  "Firewall %s now allows the new IP %s",
+	"连接 Docker 失败: %v":                  "Failed to connect to Docker: %v",
+	"Docker API 返回错误 (状态码: %d)":         "Docker API returned an error (status: %d)",
+	"连接 Docker 事件流失败: %v，%s 后重试":        "Failed to connect to the Docker event stream: %v, retrying in %s",
+	"已连接 Docker 事件流":                    "Connected to the Docker event stream",
+	"Docker 事件流已断开: %v，%s 后重连":          "Docker event stream disconnected: %v, reconnecting in %s",
+	"容器事件: %s %s":                       "Container event: %s %s",
+	"%s 不再声明记录 %s (%s)，已删除":             "%s no longer declares record %s (%s), deleted",
+	"同步 %s 记录失败: %s":                    "Failed to sync %s records: %s",
+	"%s 已存在不是由 %s 创建的记录，跳过（%s）":         "%s already has records not created by %s, skipping (%s)",
+	"容器": "container",
+	"不在 Kubernetes 集群内运行，请配置 kubernetes.api_server": "Not running inside a Kubernetes cluster; set kubernetes.api_server",
+	"读取 Kubernetes CA 证书失败: %v":                     "Failed to read the Kubernetes CA certificate: %v",
+	"Kubernetes CA 证书无效: %s":                        "Invalid Kubernetes CA certificate: %s",
+	"读取 Kubernetes 令牌失败: %v":                        "Failed to read the Kubernetes token: %v",
+	"Kubernetes API 返回错误 (状态码: %d)":                 "Kubernetes API returned an error (status: %d)",
+	"监听 Kubernetes 资源失败 (%s): %v，%s 后重试":            "Failed to watch Kubernetes resources (%s): %v, retrying in %s",
+	"已连接 Kubernetes 监听: %s":                         "Connected Kubernetes watch: %s",
+	"Kubernetes 监听已断开 (%s): %v":                     "Kubernetes watch disconnected (%s): %v",
+	"Kubernetes 事件: %s %s/%s":                       "Kubernetes event: %s %s/%s",
+	"连接 Consul 失败: %v":                              "Failed to connect to Consul: %v",
+	"Consul 返回错误 (状态码: %d)":                         "Consul returned an error (status: %d)",
+	"更新 Consul 服务 %s 失败: %v":                        "Failed to update Consul service %s: %v",
+	"Consul 服务 %s 已指向 %s":                           "Consul service %s now points to %s",
+	"写入 Consul 键 %s 失败: %v":                         "Failed to write Consul key %s: %v",
+	"Consul 键 %s 已更新为 %s":                           "Consul key %s updated to %s",
+	"进入维护模式：守护进程继续检测，但暂停写入DNS记录（--for 指定时长后自动恢复）": "Enter maintenance mode: the daemon keeps detecting but stops writing DNS records (--for resumes automatically after the given duration)",
+	"退出维护模式，恢复写入DNS记录":                 "Leave maintenance mode and resume writing DNS records",
+	"维护模式时长（如 2h），为空时直到执行 resume":      "Maintenance duration (e.g. 2h); empty lasts until resume",
+	"已进入维护模式，暂停写入DNS记录，将于 %s 自动恢复\n":   "Maintenance mode on, DNS writes paused; resumes automatically at %s\n",
+	"已进入维护模式，暂停写入DNS记录，使用 resume 命令恢复": "Maintenance mode on, DNS writes paused; run resume to continue",
+	"已退出维护模式，恢复写入DNS记录":                "Maintenance mode off, DNS writes resumed",
+	"启动控制套接字失败: %v":                    "Failed to start the control socket: %v",
+	"控制套接字已启动: %s":                     "Control socket listening: %s",
+	"无效的时长: %s":                        "Invalid duration: %s",
+	"未知命令: %s":                         "Unknown command: %s",
+	"无法连接守护进程的控制套接字（守护进程是否在运行？）: %v":   "Cannot connect to the daemon's control socket (is the daemon running?): %v",
+	"已进入维护模式，暂停写入DNS记录，%s 后自动恢复":       "Maintenance mode on, DNS writes paused; resuming automatically in %s",
+	"已进入维护模式，暂停写入DNS记录，直到手动恢复":         "Maintenance mode on, DNS writes paused until resumed manually",
+	"维护模式已到期，恢复写入DNS记录":                "Maintenance window expired, DNS writes resumed",
+	"维护模式中：只记录将要执行的操作，不修改DNS记录":        "Maintenance mode: logging planned changes without modifying DNS records",
+	"降低TTL失败 (尝试 %d/%d): %v":           "Failed to lower TTL (attempt %d/%d): %v",
+	"正在降低 %s 的TTL: %d -> %d":           "Lowering TTL of %s: %d -> %d",
+	"恢复TTL时读取记录失败: %v":                 "Failed to read records while restoring TTL: %v",
+	"恢复 %s 的TTL失败: %v":                 "Failed to restore TTL of %s: %v",
+	"已恢复 %s 的TTL: %d -> %d":            "Restored TTL of %s: %d -> %d",
+	"读取网络接口 %s 失败: %v":                 "Failed to read network interface %s: %v",
+	"网络接口 %s 上没有公网 IPv4 地址":            "No public IPv4 address on network interface %s",
+	"网络接口 %s: %v":                      "Network interface %s: %v",
+	"无法读取 IPv6 地址标志，不能识别临时地址: %v":      "Cannot read IPv6 address flags; temporary addresses cannot be identified: %v",
+	"不支持的 IPv6 地址选择策略: %s":             "Unsupported IPv6 address selection policy: %s",
+	"没有符合策略 %s 的公网 IPv6 地址":            "No public IPv6 address matches policy %s",
+	"反向解析服务商 %s 缺少 instance_id":        "Reverse DNS provider %s requires instance_id",
+	"不支持的反向解析服务商: %s":                  "Unsupported reverse DNS provider: %s",
+	"%s 返回错误 (状态码: %d): %s":            "%s returned an error (status: %d): %s",
+	"设置反向解析失败: %v":                     "Failed to set reverse DNS: %v",
+	"已将 %s 的反向解析指向 %s (%s)":            "Reverse DNS of %s now points to %s (%s)",
+	"--simulate-ip 只能在前台运行时使用":         "--simulate-ip can only be used when running in the foreground",
+	"模拟IP序列中的值无效: %s":                  "Invalid value in simulated IP sequence: %s",
+	"模拟IP序列为空":                         "Simulated IP sequence is empty",
+	"模拟IP: 第 %d/%d 个值 %s":              "Simulated IP: value %d/%d %s",
+	"模拟的IP检测失败":                        "Simulated IP detection failure",
+	"用脚本中的IP代替真实检测（逗号分隔的序列或每行一个IP的文件，fail 表示检测失败），用于测试确认、防抖与通知": "Use scripted IPs instead of real detection (comma-separated sequence or a file with one IP per line; fail simulates a detection failure), for testing confirmation, damping and notifications",
+	"⚠️  已启用IP模拟，模拟的IP会写入DNS记录；只想观察行为时请同时使用 --dry-run":          "⚠️  IP simulation enabled; simulated IPs will be written to DNS records. Add --dry-run to only observe the behavior",
+	"显示版本与构建信息":        "Show version and build information",
+	"版本: %s":           "Version: %s",
+	"版本: %s\n":         "Version: %s\n",
+	"未知":               "unknown",
+	"提交: %s\n":         "Commit: %s\n",
+	"构建时间: %s\n":       "Build date: %s\n",
+	"Go 版本: %s (%s)\n": "Go version: %s (%s)\n",
+	"已在管理 API 上启用 pprof（仅限本机访问）: /debug/pprof/": "pprof enabled on the management API (local access only): /debug/pprof/",
+	"pprof 仅允许本机访问": "pprof is only accessible from localhost",
+	"写入诊断信息失败: %v":  "Failed to write diagnostics: %v",
+	"已写入诊断信息: %s":   "Diagnostics written: %s",
+	"运行时统计: 协程 %d, 堆内存 %d 字节, 堆对象 %d, 系统内存 %d 字节, GC %d 次": "Runtime stats: %d goroutines, heap %d bytes, %d heap objects, system memory %d bytes, %d GCs",
+	"写入 %s 失败: %v":             "Failed to write %s: %v",
+	"链路追踪已启用，导出到 %s":           "Tracing enabled, exporting to %s",
+	"导出链路追踪失败: %v":             "Failed to export traces: %v",
+	"获取区域名称失败: %w":             "Failed to get zone name: %w",
+	"查找DNS记录失败: %w":            "failed to look up DNS records: %w",
+	"请求失败: %w":                 "request failed: %w",
+	"\n周期: %s":                 "\nCycle: %s",
+	"预读区域 %s 的记录失败，改为逐个查询: %v": "Failed to prefetch records of zone %s, falling back to per-record lookups: %v",
+	"已预读区域 %s 的 %d 条 %s 记录，用于 %d 个记录的计划": "Prefetched zone %s: %d %s records for planning %d records",
+	"读取文件失败: %v":                                              "Failed to read file: %v",
+	"解析 %s 失败: %v":                                            "Failed to parse %s: %v",
+	"第 %d 条记录缺少 name 或 type":                                  "Record %d is missing name or type",
+	"%s %s: 无效的 TTL %d（1 表示自动，或 60-86400 秒）":                  "%s %s: invalid TTL %d (1 means automatic, otherwise 60-86400 seconds)",
+	"%s %s: 该类型的记录不能开启代理":                                     "%s %s: records of this type cannot be proxied",
+	"重复的记录: %s %s %s":                                         "Duplicate record: %s %s %s",
+	"只显示与区域中记录的差异，不修改记录":                                      "Only show the differences from the zone's records, do not modify anything",
+	"删除文件中未声明的记录（本程序维护的记录除外）":                                 "Delete records not declared in the file (except records maintained by this program)",
+	"用法: dns_manager apply <file> [--diff] [--prune] [--yes]": "Usage: dns_manager apply <file> [--diff] [--prune] [--yes]",
+	"区域中的记录与文件一致，无需修改":                                        "The zone's records match the file, nothing to change",
+	"计划: 新建 %d 条，修改 %d 条，删除 %d 条\n":                           "Plan: %d to create, %d to update, %d to delete\n",
+	"确认应用？":  "Apply these changes?",
+	"应用文件: ": "apply file: ",
+	"✓ 已应用":  "✓ Applied",
+	"未指定区域：请在文件中填写 zone_id 或先完成配置": "No zone specified: set zone_id in the file or configure the program first",
+	"%s %s 由本程序自动维护，不能在文件中声明":      "%s %s is maintained automatically by this program and cannot be declared in the file",
+	"获取DNS记录失败: %w":                "Failed to get DNS records: %w",
+	"按 YAML 文件声明的期望状态同步区域中的记录（新建缺少的、修改不一致的，--prune 删除未声明的）": "Reconcile the zone's records to the desired state declared in a YAML file (create missing, update drifted, delete undeclared with --prune)",
+	"第 %d 行: YAML 不能使用制表符缩进":   "line %d: YAML must not be indented with tabs",
+	"第 %d 行: 缩进不正确":            "line %d: bad indentation",
+	"第 %d 行: 应为 \"键: 值\"":      "line %d: expected \"key: value\"",
+	"第 %d 行: 重复的键 %s":          "line %d: duplicate key %s",
+	"第 %d 行: 键不能为空":            "line %d: empty key",
+	"第 %d 行: 无效的字符串 %s":        "line %d: invalid string %s",
+	"第 %d 行: 不支持的 YAML 语法: %s": "line %d: unsupported YAML syntax: %s",
+	"从区域中已有的 A/AAAA 记录选择要自动维护的记录，生成多记录配置": "Pick existing A/AAAA records of a zone to maintain automatically and generate the multi-record config",
+	"未找到区域 %s（请确认 API Token 有该区域的权限）":     "Zone %s not found (make sure the API token has access to it)",
+	"无效的序号: %s":                    "Invalid number: %s",
+	"区域域名（如 example.com）或 Zone ID": "Zone domain (e.g. example.com) or Zone ID",
+	"导入的记录类型 A 或 AAAA（默认使用配置中的记录类型，未配置时为 A）":                                   "Record type to import, A or AAAA (defaults to the configured record type, or A)",
+	"选择区域内全部记录，不逐个询问":                                                          "Select all records in the zone without asking",
+	"用法: dns_manager import --zone example.com [--type A] [--all] [--yes]":     "Usage: dns_manager import --zone example.com [--type A] [--all] [--yes]",
+	"不支持的记录类型: %s（只能导入 A 或 AAAA 记录）\n":                                         "Unsupported record type: %s (only A or AAAA records can be imported)\n",
+	"配置中的记录类型为 %s，只能导入同类型的记录\n":                                                "The configured record type is %s; only records of that type can be imported\n",
+	"区域 %s 中没有 %s 记录\n":                                                        "Zone %s has no %s records\n",
+	"区域 %s（%s）中的 %s 记录:\n":                                                     "Zone %s (%s), %s records:\n",
+	"选择要自动维护的记录（序号用逗号分隔，支持范围如 1,3-5，all 表示全部，直接回车取消）: ":                        "Select the records to maintain automatically (comma-separated numbers, ranges like 1,3-5, all for every record, Enter to cancel): ",
+	"所选记录均已在配置中":                                                               "The selected records are already in the config",
+	"\n将加入配置的记录:":                                                              "\nRecords to add to the config:",
+	"  %s（主记录）\n":                                                              "  %s (main record)\n",
+	"确认保存配置？":                                                                  "Save the config?",
+	"✓ 配置已保存: %s\n":                                                            "✓ Config saved: %s\n",
+	"ip_source 需要且只能设置 interface、service、stun、router、tailscale、wireguard 中的一个": "ip_source must set exactly one of interface, service, stun, router, tailscale or wireguard",
+	"ip_source.interface.name 不能为空":                                            "ip_source.interface.name must not be empty",
+	"ip_source 中的地址应为 http(s) URL: %s":                                         "ip_source addresses must be http(s) URLs: %s",
+	"ip_source.bind_address 只能与 service、stun、router 一起使用":                      "ip_source.bind_address can only be used with service, stun or router",
+	"无效的 ip_source.bind_address: %s":                                           "Invalid ip_source.bind_address: %s",
+	"%s 返回的地址 %s 不是 %s 记录可用的地址":                                                "%s returned %s, which cannot be used for %s records",
+	"无效的 STUN 响应":                                                              "Invalid STUN response",
+	"STUN 响应中没有映射地址":                                                           "STUN response has no mapped address",
+	"UPnP 响应中没有 WAN 口地址":                                                       "UPnP response has no WAN address",
+	"IP来源 %s 配置无效: %v":                                                         "IP source %s is misconfigured: %v",
+	"IP来源 %s: IP未变化 (%s)，跳过 %d 个记录":                                            "IP source %s: IP unchanged (%s), skipping %d records",
+	"IP来源 %s: 检测到IP变化 (%s -> %s)，正在确认...":                                      "IP source %s: IP change detected (%s -> %s), confirming...",
+	"独立IP来源的记录同步失败: %s":                                                        "Failed to sync records with their own IP source: %s",
+	"retry_policy.%s 格式无效: %s，使用默认值":                                           "Invalid retry_policy.%s: %s, using the default",
+	"连接 %s 失败: %v":                                                             "Failed to connect to %s: %v",
+	"rfc2136.zone 不能为空":                                                        "rfc2136.zone must not be empty",
+	"无效的IP地址: %s":                                                              "Invalid IP address: %s",
+	"%s 不在区域 %s 中":                                                             "%s is not in zone %s",
+	"不支持的 TSIG 算法: %s":                                                         "Unsupported TSIG algorithm: %s",
+	"tsig_secret 不是有效的 base64":                                                 "tsig_secret is not valid base64",
+	"无效的 DNS 响应":                                                               "Invalid DNS response",
+	"DNS 服务器拒绝更新: %s（请检查 TSIG 密钥与服务器的 update-policy）":                          "DNS server refused the update: %s (check the TSIG key and the server's update-policy)",
+	"DNS 服务器拒绝更新: %s":                                                          "DNS server refused the update: %s",
+	"无效的 split_horizon.address: %s（应为 public、lan、tailscale、wireguard 或IP地址）":   "Invalid split_horizon.address: %s (expected public, lan, tailscale, wireguard or an IP address)",
+	"获取局域网地址失败: %v":                                                            "Failed to determine the LAN address: %v",
+	"网络接口 %s 上没有局域网地址":                                                         "Network interface %s has no LAN address",
+	"内部DNS: %v":                                 "Internal DNS: %v",
+	"内部DNS: 没有需要维护的名称":                          "Internal DNS: no names to maintain",
+	"更新内部DNS %s 失败: %v":                         "Failed to update internal DNS %s: %v",
+	"内部DNS %s: %s 已指向 %s":                       "Internal DNS %s: %s now points to %s",
+	"连接 AdGuard Home 失败: %v":                    "Failed to connect to AdGuard Home: %v",
+	"AdGuard Home 认证失败 (状态码: %d)":               "AdGuard Home authentication failed (status: %d)",
+	"AdGuard Home 返回错误 (状态码: %d)":               "AdGuard Home returned an error (status: %d)",
+	"Pi-hole 登录失败：密码错误":                         "Pi-hole login failed: wrong password",
+	"Pi-hole 认证失败 (状态码: %d)":                    "Pi-hole authentication failed (status: %d)",
+	"Pi-hole 返回错误 (状态码: %d)":                    "Pi-hole returned an error (status: %d)",
+	"连接 Pi-hole 失败: %v":                         "Failed to connect to Pi-hole: %v",
+	"Pi-hole 拒绝了请求：请检查 api_token":               "Pi-hole rejected the request: check api_token",
+	"Pi-hole 返回错误: %s":                          "Pi-hole returned an error: %s",
+	"读取网络接口失败: %v":                              "Failed to read network interfaces: %v",
+	"没有找到 Tailscale 的 %s 地址（tailscaled 是否已登录？）": "No Tailscale %s address found (is tailscaled logged in?)",
+	"网络接口 %s 上没有 %s 地址":                         "Network interface %s has no %s address",
+	"DoH 查询失败: RCODE %d":                        "DoH query failed: RCODE %d",
+	"DoH 预检查失败: %v":                             "DoH pre-check failed: %v",
+	"DoH 预检查失败: %s: %v":                         "DoH pre-check failed: %s: %v",
+	"DoH 预检查: %s 解析为 %v，需要同步":                   "DoH pre-check: %s resolves to %v, syncing",
+	"DoH 预检查: 全部记录已解析到 %s":                      "DoH pre-check: all records already resolve to %s",
+	"drift.interval 格式无效: %s，使用默认值 %v":          "Invalid drift.interval: %s, using the default %v",
+	"检查记录 %s 是否被修改失败: %v":                       "Failed to check whether %s was modified: %v",
+	"%s 已恢复指向 %s，继续自动维护":                        "%s points to %s again, resuming automatic management",
+	"已被删除":                    "was deleted",
+	"已被修改为 %s":                "was changed to %s",
+	"%s 的记录%s（本机IP %s）":       "The %s record %s (local IP %s)",
+	"恢复被外部修改的记录":              "Restore externally modified record",
+	"恢复记录 %s 失败: %v":          "Failed to restore %s: %v",
+	"已把 %s 改回 %s":             "Restored %s to %s",
+	"%s 的记录已被删除，停止自动维护":       "The %s record was deleted, no longer managing it",
+	"接受 %s 的新值 %s，IP变化时从该值更新": "Adopted the new value of %s (%s); it will be updated from that value when the IP changes",
+	"DNS记录被外部修改":              "DNS record modified externally",
+	"%s 的记录%s，已停止自动维护该记录；改回 %s 后恢复": "The %s record %s; stopped managing it until it points to %s again",
+	"%s 已被外部修改，跳过（改回本机IP后恢复自动维护）":   "%s was modified externally, skipping (management resumes once it points to this host's IP again)",
+	"记录不存在":              "record does not exist",
+	"指向 %s，期望 %s":        "points to %s, expected %s",
+	"TTL 为 %s，期望 %s":     "TTL is %s, expected %s",
+	"代理为 %s，期望 %s":       "proxied is %s, expected %s",
+	"正常":                 "OK",
+	"读取失败: %s":           "read failed: %s",
+	"启动检查: 获取公网IP失败: %v": "Startup check: failed to get public IP: %v",
+	"启动检查: %s %s 正常":     "Startup check: %s %s OK",
+	"启动检查: %s %s %s":     "Startup check: %s %s %s",
+	"启动检查: 读取失败: %s":     "Startup check: read failed: %s",
+	"启动检查完成: 共 %d 条记录，%d 条需要注意":     "Startup check finished: %d records, %d need attention",
+	"启动检查: 全部记录正常":                  "Startup check: all records OK",
+	"启动检查: %d 条记录需要注意":              "Startup check: %d records need attention",
+	"向所有已配置的通知渠道发送测试通知":             "Send a test message to every configured notification channel",
+	"用法: dns_manager notify test":   "Usage: dns_manager notify test",
+	"未配置通知渠道（配置文件中的 notifications）": "No notification channels configured (notifications in the config file)",
+	"正在向 %d 个通知渠道发送测试通知...\n":       "Sending a test notification to %d channels...\n",
+	"8. 测试通知": "8. Test notifications",
+	"测试通知":    "Test notification",
+	"这是一条来自 %s 的测试通知，收到说明该渠道配置正确": "This is a test notification from %s; if you received it, the channel is configured correctly",
+	"模板无效: %v":    "invalid template: %v",
+	"\n正在检查配置...": "\nChecking the configuration...",
+	"\n部分检查未通过，仍要保存配置？(y/N): ": "\nSome checks failed. Save the configuration anyway? (y/N): ",
+	"已取消，配置未保存":                "Cancelled, configuration not saved",
+	"访问 Cloudflare API":        "Cloudflare API reachability",
+	"获取公网IP":                   "Public IP detection",
+	"所有IP检测服务均不可用":             "all IP detection services are unavailable",
+	"令牌状态: %s":                 "token status: %s",
+	"有效，过期时间 %s":               "valid, expires %s",
+	"有效":                       "valid",
+	"区域":                       "Zone",
+	"现有记录":                     "Existing records",
+	"不存在，首次运行时创建":              "none yet, created on the first run",
+	"已有 %d 条: %s":              "%d existing: %s",
+	"，首次运行时新增指向 %s 的记录（已有记录保留）":    "; the first run adds a record pointing to %s (existing records are kept)",
+	"\n========== 配置检查 ==========": "\n========== Configuration check ==========",
+	"认证错误的原始信息: %v":                "Original authentication error: %v",
+	"API 令牌无效（已删除、已轮换或填写错误）：请在 %s 重新创建令牌并更新配置中的 api_token":                           "The API token is invalid (deleted, rolled or mistyped): create a new token at %s and update api_token in the config",
+	"API 令牌已于 %s 过期：请在 %s 延长有效期或重新创建令牌":                                              "The API token expired on %s: extend it or create a new one at %s",
+	"API 令牌状态为 %s，不能使用：请在 %s 启用令牌":                                                   "The API token is %s and cannot be used: enable it at %s",
+	"API 令牌无权访问区域 %s：令牌的区域资源（Zone Resources）未包含该区域，或 zone_id 填写错误":                   "The API token cannot access zone %s: its Zone Resources do not include this zone, or zone_id is wrong",
+	"API 令牌可以访问区域 %s，但没有读取DNS记录的权限：请在 %s 编辑令牌，添加 Zone - DNS - Edit 权限":               "The API token can access zone %s but cannot read DNS records: edit the token at %s and add the Zone - DNS - Edit permission",
+	"API 令牌可以读取区域 %s 的DNS记录，但没有修改权限：请在 %s 编辑令牌，添加 Zone - DNS - Edit 权限":              "The API token can read DNS records in zone %s but cannot change them: edit the token at %s and add the Zone - DNS - Edit permission",
+	"本机没有可用的 %s 记录地址，双栈模式下改为维护 %s 记录":                                                "This host has no address for %s records; dual-stack mode switches to %s records",
+	"本机没有可用的 %s 记录地址（只有 %s 连接），检测公网IP将持续失败：请把 record_type 改为 %s，或开启 dual_stack 自动切换": "This host has no address for %s records (only %s connectivity), so public IP detection will keep failing: set record_type to %s or enable dual_stack to switch automatically",
+	"本机没有可用的 IPv4 或 IPv6 公网连接，检测公网IP将失败，请检查网络":                                       "This host has no public IPv4 or IPv6 connectivity, so public IP detection will fail; check the network",
+	"未配置IPv6检测服务":                               "no IPv6 detection services configured",
+	"所有IPv6检测服务均失败，最后错误: %v":                    "all IPv6 detection services failed, last error: %v",
+	"ip_race_delay 格式无效: %s，使用默认值 %v":           "invalid ip_race_delay: %s, using the default %v",
+	"IP检测服务 %s 在 %v 内未响应，同时请求 %s":               "IP service %s did not answer within %v, also asking %s",
+	"使用 %v 前读取的 %s 记录列表":                        "Reusing the record list read %v ago for %s",
+	"http.%s 格式无效: %s，使用默认值 %v":                 "Invalid http.%s: %s, using default %v",
+	"没有找到地址":                                    "no addresses found",
+	"通过指定的解析器解析 %s 失败: %v":                      "Resolving %s with the configured resolvers failed: %v",
+	"%s 解析为 %v":                                 "%s resolved to %v",
+	"%s 没有 %s 地址":                               "%s has no %s address",
+	"连续失败 %d 次（自 %s 起）: %s\n":                   "%d consecutive failures (since %s): %s\n",
+	"已进入降级模式（自 %s 起），按较长间隔检测\n":                 "Degraded mode since %s, checking at a longer interval\n",
+	"degraded.interval 格式无效: %s，使用默认值 %v":       "Invalid degraded.interval: %s, using default %v",
+	"检测已恢复（连续失败 %d 次，持续 %s），退出降级模式":             "Checks recovered after %d consecutive failures (%s), leaving degraded mode",
+	"连续失败 %d 次（持续 %s），进入降级模式，检测间隔改为 %s":         "%d consecutive failures (%s), entering degraded mode with a %s check interval",
+	"进入降级模式":                                    "Entered degraded mode",
+	"连续失败 %d 次（持续 %s），检测间隔改为 %s，恢复后自动退出: %v":    "%d consecutive failures (%s), check interval raised to %s until a check succeeds: %v",
+	"开始失败时间: %s\n":                              "Failing since: %s\n",
+	"降级模式: 自 %s 起\n":                            "Degraded mode: since %s\n",
+	"%v，继续运行当前版本":                               "%v, continuing with the current version",
+	"获取可执行文件信息失败: %v":                           "Failed to stat the executable: %v",
+	"新版本无法运行: %v: %s":                           "The new version does not run: %v: %s",
+	"可执行文件已更新，重新执行: %s":                         "Executable was upgraded, re-executing: %s",
+	"重新执行失败: %v":                                "Re-exec failed: %v",
+	"已重新执行为新版本: %s":                             "Re-executed as the new version: %s",
+	"1. 开始监控 (每%s自动检测并更新)\n":                    "1. Start monitoring (check and update every %s)\n",
+	"  检测间隔: 每%s\n":                             "  Check interval: every %s\n",
+	"程序将在后台自动运行，每%s检测一次IP变化\n":                  "The program will run in the background and check for IP changes every %s\n",
+	"构建时注入的检测间隔无效: %q，使用 %v\n":                  "Invalid embedded check interval %q, using %v\n",
+	"构建时注入的默认值:":                                "Build-time defaults:",
+	"记录名称":                                      "Record name",
+	"记录 %s 不属于区域 %s（zone_id %s），zone_id 可能填写错误": "Record %s is not in zone %s (zone_id %s); the zone_id is probably wrong",
+	"；该记录属于区域 %s，zone_id 应为 %s":                 "; the record belongs to zone %s, whose zone_id is %s",
+	"无法确认记录 %s 所属的区域: %v":                       "Could not confirm the zone of record %s: %v",
+	"已取消，配置未改变":                                 "Cancelled, configuration unchanged",
+	"当前配置:":                                     "Current configuration:",
+	"  3. 记录名称: %s\n":                           "  3. Record name: %s\n",
+	"  4. 记录类型: %s\n":                           "  4. Record type: %s\n",
+	"选择要修改的项 (1-4)，直接回车逐项修改，0 取消: ":             "Choose an item to change (1-4), press Enter to go through all, 0 to cancel: ",
+	" [回车保留 %s]: ":                              " [Enter keeps %s]: ",
+	"请输入记录类型: ":                                 "Enter record type: ",
+	"读取 http.ca_file 失败: %v":                    "Failed to read http.ca_file: %v",
+	"http.ca_file 中没有有效的证书: %s":                 "No valid certificates in http.ca_file: %s",
+	"http.client_cert 与 http.client_key 需要同时设置": "http.client_cert and http.client_key must be set together",
+	"加载客户端证书失败: %v":                             "Failed to load the client certificate: %v",
+	"%s 耗时 %v":                                  "%s took %v",
+	"检测周期耗时 %v: %s":                             "Check cycle took %v: %s",
+	"以下消息在 %s 内又重复了 %d 次: %s":                   "The following message repeated %[2]d more times within %[1]s: %[3]s",
+	"log_dedup_window 格式无效: %s，使用默认值 %v\n":      "Invalid log_dedup_window: %s, using the default %v\n",
+	"打开事件日志失败: %v":                              "Failed to open the event log: %v",
+	"写入事件日志失败: %v\n":                            "Failed to write to the event log: %v\n",
+	"事件日志仅在 Windows 上可用，请改用日志文件或 systemd 日志":    "The event log is only available on Windows; use the log file or the systemd journal instead",
+	"打开事件源 %s 失败: %v":                           "Failed to open event source %s: %v",
+	"注册事件源 %s 失败（首次注册需要管理员权限）: %v":              "Failed to register event source %s (the first registration requires administrator rights): %v",
+	"desktop 通知目前只支持 Linux（notify-send）与 macOS": "desktop notifications are only supported on Linux (notify-send) and macOS",
+	"desktop 通知需要 %s: %v":                       "desktop notifications require %s: %v",
+	"跳过检测，将记录更新为指定的IP（检测结果有误或预先切换到计划中的新地址时使用）": "skip detection and update the record to this IP (when detection is wrong or to pre-stage a planned address change)",
+	"--ip 与 --simulate-ip 不能同时使用":        "--ip and --simulate-ip cannot be used together",
+	"--ip 与 --output json 一起使用时需要 --yes": "--ip with --output json requires --yes",
+	"手动指定": "set manually",
+	"DNS记录管理（list 列出；create、delete 使用已配置的令牌直接创建或删除区域内的记录）": "DNS record management (list; create and delete records in the zone directly with the configured token)",
+	"用法: dns_manager records list|create|delete":           "Usage: dns_manager records list|create|delete",
+	"记录类型（如 A、AAAA、CNAME、TXT、MX）":                          "record type (e.g. A, AAAA, CNAME, TXT, MX)",
+	"记录内容":                             "record content",
+	"TTL（秒，1 表示自动）":                    "TTL in seconds (1 means automatic)",
+	"开启 Cloudflare 代理（仅 A、AAAA、CNAME）": "enable the Cloudflare proxy (A, AAAA and CNAME only)",
+	"MX 记录的优先级":                        "priority for MX records",
+	"记录备注":                             "record comment",
+	"用法: dns_manager records create <name> --type A --content 1.2.3.4 [--ttl 300] [--proxied] [--yes]": "Usage: dns_manager records create <name> --type A --content 1.2.3.4 [--ttl 300] [--proxied] [--yes]",
+	"%s 记录不能开启 Cloudflare 代理\n":                                                                        "%s records cannot be proxied by Cloudflare\n",
+	"--output json 需要与 --yes 一起使用":                                                                     "--output json requires --yes",
+	"命令行":         "command line",
+	"创建失败: %v":    "Create failed: %v",
+	"只删除该类型的记录":   "only delete records of this type",
+	"只删除内容为该值的记录": "only delete records with this content",
+	"删除所有匹配的记录（匹配多条时需要）":                                                                   "delete all matching records (required when several match)",
+	"用法: dns_manager records delete <name> [--type A] [--content 1.2.3.4] [--all] [--yes]": "Usage: dns_manager records delete <name> [--type A] [--content 1.2.3.4] [--all] [--yes]",
+	"匹配到 %d 条记录，请用 --type、--content 缩小范围，或使用 --all 全部删除":                                   "%d records match; narrow it down with --type or --content, or use --all to delete them all",
+	"\n即将删除以下记录:":           "\nAbout to delete the following records:",
+	"删除失败（已删除 %d/%d 条）: %v": "Delete failed (%d/%d deleted): %v",
+	"✓ 已删除 %d 条记录\n":        "✓ Deleted %d records\n",
+	"DNS记录管理（list 列出；create、delete 使用已配置的令牌直接创建或删除区域内的记录；import 从 CSV 或区域文件批量导入）": "DNS record management (list; create and delete records in the zone directly with the configured token; import in bulk from a CSV or zone file)",
+	"用法: dns_manager records list|create|delete|import":                "Usage: dns_manager records list|create|delete|import",
+	"读取 CSV 列名失败: %v":                                                  "Failed to read the CSV header: %v",
+	"CSV 中未知的列: %s（可用 name、type、content、ttl、proxied、priority、comment）": "Unknown CSV column: %s (available: name, type, content, ttl, proxied, priority, comment)",
+	"CSV 缺少 %s 列":         "The CSV has no %s column",
+	"第 %d 行: %v":          "line %d: %v",
+	"第 %d 行: 无效的 TTL: %s": "line %d: invalid TTL: %s",
+	"第 %d 行: 无效的 proxied: %s（true 或 false）":                                                      "line %d: invalid proxied: %s (true or false)",
+	"第 %d 行: 无效的 priority: %s":                                                                   "line %d: invalid priority: %s",
+	"第 %d 行: 不支持 %s，已跳过":                                                                         "line %d: %s is not supported, skipped",
+	"第 %d 行: 无法解析的记录":                                                                            "line %d: unparseable record",
+	"第 %d 行: 不支持的记录类型 %s，已跳过":                                                                    "line %d: unsupported record type %s, skipped",
+	"第 %d 行: %s %s 由本程序自动维护，已跳过":                                                                 "line %d: %s %s is maintained automatically by this program, skipped",
+	"第 %d 行: 与第 %d 行重复，已跳过":                                                                      "line %d: duplicate of line %d, skipped",
+	"文件格式: csv 或 zone（默认按扩展名判断，.csv 为 CSV，其他为区域文件）":                                              "file format: csv or zone (by default .csv files are CSV and anything else is a zone file)",
+	"用法: dns_manager records import <file.csv|zonefile> [--format csv|zone] [--dry-run] [--yes]": "Usage: dns_manager records import <file.csv|zonefile> [--format csv|zone] [--dry-run] [--yes]",
+	"无效的文件格式: %s（可选 csv 或 zone）\n":                                                               "Invalid file format: %s (csv or zone)\n",
+	"读取 %d 条记录，区域中的记录已一致，无需修改\n":                                                                 "Read %d records; the zone already matches, nothing to change\n",
+	"读取 %d 条记录，跳过 %d 条；计划: 新建 %d 条，修改 %d 条\n":                                                    "Read %d records, skipped %d; plan: create %d, update %d\n",
+	"确认导入？":  "Import these records?",
+	"导入文件: ": "import file: ",
+	"导入完成: 成功 %d 条，失败 %d 条，跳过 %d 条\n":             "Import finished: %d succeeded, %d failed, %d skipped\n",
+	"只列出该类型的记录":                                   "only list records of this type",
+	"只列出内容包含该文本的记录（不区分大小写）":                       "only list records whose content contains this text (case-insensitive)",
+	"只列出名称匹配该通配符的记录（如 \"*.example.com\"）":         "only list records whose name matches this glob (e.g. \"*.example.com\")",
+	"排序字段: name、type、content 或 ttl":               "sort by: name, type, content or ttl",
+	"无效的名称通配符: %s":                                "Invalid name pattern: %s",
+	"无效的排序字段: %s（可选 name、type、content、ttl）":       "Invalid sort field: %s (name, type, content or ttl)",
+	"持续显示记录的值、在权威与公共解析器上的传播情况以及本机检测到的IP（调试迁移时使用）": "Continuously show the record's value, its propagation on authoritative and public resolvers, and the locally detected IP (useful while debugging a migration)",
+	"%s 本机IP: %s -> %s":                           "%s local IP: %s -> %s",
+	"%s 记录值: %s -> %s":                            "%s record value: %s -> %s",
+	"记录: %s (%s)    %s\n":                         "Record: %s (%s)    %s\n",
+	"本机检测到的IP: %s\n":                              "Locally detected IP: %s\n",
+	"Cloudflare 记录: %s\n":                         "Cloudflare record: %s\n",
+	"（与本机IP不一致）":                                  " (differs from the local IP)",
+	"（已开启代理）":                                     " (proxied)",
+	"最近的变化:":                                      "Recent changes:",
+	"刷新间隔":                                        "refresh interval",
+	"刷新次数后退出，0 表示一直运行直到 Ctrl+C":                   "exit after this many refreshes; 0 runs until Ctrl+C",
+	"刷新间隔不能小于 1s":                                 "The refresh interval must be at least 1s",
+	"\n每 %s 刷新一次，按 Ctrl+C 退出\n":                   "\nRefreshing every %s, press Ctrl+C to exit\n",
+	"输出格式: text 或 json（json 时不输出日志，结束后输出结构化结果）":   "Output format: text or json (json suppresses log output and prints a structured summary at the end)",
+	"结束后将结构化结果（JSON）写入该文件":                        "Write the structured summary (JSON) to this file when finished",
+	"写入结果文件失败: %v":                                "Failed to write summary file: %v",
+	"更新失败: %s":                                    "Update failed: %s",
+	"无法交给守护进程执行，在本进程中执行: %v":                      "Could not hand the update to the daemon, running it in this process: %v",
+	"守护进程正在运行，已由守护进程执行本次更新":                       "The daemon is running; the update was performed by the daemon",
+	"无法打开更新锁，不加锁执行: %v":                           "Cannot open the update lock, continuing without it: %v",
+	"获取更新锁失败: %v":                                 "Failed to acquire the update lock: %v",
+	"等待更新锁超时（%v）：另一个进程正在更新DNS记录":                  "Timed out after %v waiting for the update lock: another process is updating DNS records",
+	"另一个进程正在更新DNS记录，等待其完成...":                     "Another process is updating DNS records, waiting for it to finish...",
+	"once 命令检测到守护进程正在运行，由守护进程执行本次更新":              "The once command found this daemon running; performing the update on its behalf",
+	"删除名称、类型与内容都相同的重复记录，每组保留一条（默认检查跟随公网IP的记录）":    "Delete duplicate records with the same name, type and content, keeping one of each (checks the records that follow the public IP by default)",
+	"DNS服务不支持删除记录，跳过去重":                           "The DNS provider does not support deleting records, skipping deduplication",
+	"[dry-run] %s 有 %d 条重复的记录指向 %s，将删除多余的记录（未执行）": "[dry-run] %[1]s has %[2]d identical records pointing to %[3]s; the extra records would be deleted (not executed)",
+	"删除重复记录失败: %s -> %s: %v":                      "Failed to delete duplicate record: %s -> %s: %v",
+	"已删除 %s 指向 %s 的 %d 条重复记录":                     "Deleted %[3]d duplicate records of %[1]s pointing to %[2]s",
+	"只检查该类型的记录（默认检查所有类型）":                         "Only check records of this type (all types by default)",
+	"✓ 没有重复的记录":                                   "✓ No duplicate records",
+	"\n以下记录的名称、类型与内容相同，每组保留第一条，删除其余记录:":           "\nThe following records share the same name, type and content; the first of each group is kept and the rest deleted:",
+	"保留":                 "keep",
+	"删除":                 "delete",
+	"[dry-run] 未修改任何记录":  "[dry-run] No records were modified",
+	"删除失败（已删除 %d 条）: %s": "Delete failed (%d deleted): %s",
+	"✓ 已删除 %d 条重复记录\n":   "✓ Deleted %d duplicate records\n",
+	"比较跟随公网IP的全部记录与期望状态，报告（--repair 时修复）缺失或指向其他地址的记录": "Compare all records that follow the public IP with the desired state and report (or with --repair, fix) records that are missing or point elsewhere",
+	"record_audit.interval 格式无效: %s，使用默认值 %v":         "Invalid record_audit.interval: %s, using default %v",
+	"（已修复）":               " (repaired)",
+	"（修复失败: %s）":          " (repair failed: %s)",
+	"已按 drift 策略停止维护，不修复": "maintenance stopped by the drift policy, not repaired",
+	"定期审计":                "scheduled audit",
+	"定期审计: %s":            "Scheduled audit: %s",
+	"共 %d 条记录，%d 条与期望状态不一致，已修复 %d 条": "%d records, %d differ from the desired state, %d repaired",
+	"定期审计完成: %s":                              "Scheduled audit finished: %s",
+	"定期审计: %d 条记录与期望状态不一致":                    "Scheduled audit: %d records differ from the desired state",
+	"修复缺失或指向其他地址的记录（默认只报告）":                   "Fix records that are missing or point elsewhere (report only by default)",
+	"✓ %s %s 正常\n":                            "✓ %s %s OK\n",
+	"\n共 %d 条记录，%d 条与期望状态不一致，已修复 %d 条\n":      "\n%d records, %d differ from the desired state, %d repaired\n",
+	"提示: 使用 --repair 修复缺失或指向其他地址的记录":          "Hint: use --repair to fix records that are missing or point elsewhere",
+	"geo.provider 为 mmdb 时需要设置 geo.databases": "geo.databases is required when geo.provider is mmdb",
+	"geo.provider 无效: %s（可选 ipinfo 或 mmdb）":   "Invalid geo.provider: %s (ipinfo or mmdb)",
+	"打开 MaxMind DB %s 失败: %v":                 "Failed to open MaxMind DB %s: %v",
+	"归属查询返回 HTTP %d":                          "IP lookup returned HTTP %d",
+	"查询IP归属失败: %v":                            "IP geolocation lookup failed: %v",
+	"新IP %s 归属: %s":                           "New IP %s belongs to: %s",
+	"不是有效的 MaxMind DB 文件：未找到元数据":              "Not a valid MaxMind DB file: metadata not found",
+	"解析 MaxMind DB 元数据失败: %v":                 "Failed to parse MaxMind DB metadata: %v",
+	"解析 MaxMind DB 元数据失败: 格式无效":               "Failed to parse MaxMind DB metadata: invalid format",
+	"不支持的 MaxMind DB 记录大小: %d":                "Unsupported MaxMind DB record size: %d",
+	"MaxMind DB 搜索树指向的数据无效":                   "MaxMind DB search tree points to invalid data",
+	"归属: %s\n": "Network: %s\n",
+	"geo.anomaly.compare 无效: %s（可选 any、country 或 asn）": "invalid geo.anomaly.compare: %s (expected any, country or asn)",
+	"国家 %s 不在以往的 %s 中":                                 "country %s is not among previous %s",
+	"ASN %s 不在以往的 %s 中":                                "ASN %s is not among previous %s",
+	"读取IP归属统计失败，从空的历史开始: %v":                           "Failed to read IP origin profile, starting with empty history: %v",
+	"写入IP归属统计失败: %v":                                   "Failed to write IP origin profile: %v",
+	"新IP %s 的归属异常已确认，继续更新":                             "Unusual origin of new IP %s was approved, continuing update",
+	"新IP %s 等待确认，暂不更新DNS记录（执行 approve 命令确认）":           "New IP %s is awaiting approval, DNS records not updated (run the approve command to approve)",
+	"新IP %s 的归属与以往不同: %s":                              "Origin of new IP %s differs from history: %s",
+	"新IP %s 的归属 %s 与以往不同: %s":                          "Origin %[2]s of new IP %[1]s differs from history: %[3]s",
+	"；确认前不会更新DNS记录，使用 approve 命令确认":                    "; DNS records will not be updated until approved with the approve command",
+	"新IP的归属异常":                                         "Unusual origin of new IP",
+	"没有等待确认的IP变化":                                      "no IP change is awaiting approval",
+	"等待确认的IP是 %s，不是 %s":                                "the IP awaiting approval is %s, not %s",
+	"只在等待确认的IP与之一致时确认":                                 "Only approve if the IP awaiting approval matches",
+	"已确认新IP %s，将更新DNS记录\n":                             "Approved new IP %s, DNS records will be updated\n",
+	"已通过管理 API 确认新IP %s":                               "New IP %s approved via management API",
+	"确认归属异常、等待确认的新IP（geo.anomaly.confirm）":             "Approve a new IP held for unusual origin (geo.anomaly.confirm)",
+	"已通过控制套接字确认新IP %s":                                 "New IP %s approved via control socket",
+	"观察模式：只比较DNS记录与公网IP，不修改任何记录":                       "Observe-only mode: comparing DNS records with the public IP, no records will be changed",
+	"无记录": "no record",
+	"观察模式: DNS记录已指向公网IP %s":                                   "Observe-only mode: DNS records now point to public IP %s",
+	"观察模式: %d 个记录未指向公网IP %s: %s":                              "Observe-only mode: %d record(s) do not point to public IP %s: %s",
+	"DNS记录与公网IP不一致":                                           "DNS records do not match the public IP",
+	"%d 个记录未指向公网IP %s（观察模式，未修改）: %s":                          "%d record(s) do not point to public IP %s (observe-only mode, not changed): %s",
+	"配置不完整（缺少 %s），请先运行 'dns_manager config edit' 进行配置，或编辑 %s": "Configuration is incomplete (missing %s): run 'dns_manager config edit' first, or edit %s",
+	"配置不完整时直接报错退出，不进入配置向导":                                    "Exit with an error when the configuration is incomplete instead of starting the setup wizard",
+	"切换运行用户仅在类 Unix 系统上可用":                                    "switching the running user is only supported on Unix-like systems",
+	"无效的优先级: %s（0-65535）":                                     "invalid priority: %s (0-65535)",
+	"优先级 [%d]: ":                                              "Priority [%d]: ",
+	"优先级: %d -> %d\n":                                         "Priority: %d -> %d\n",
+	"获取DNS记录失败 (尝试 %d/%d): %v，%s后重试...":                       "Failed to fetch DNS records (attempt %d/%d): %v, retrying in %s...",
+	"降低TTL失败 (尝试 %d/%d): %v，%s后重试...":                         "Failed to lower TTL (attempt %d/%d): %v, retrying in %s...",
+	"记录数据目录失败: %v":                                            "Failed to record the data directory: %v",
+}
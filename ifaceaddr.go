@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InterfaceSourceConfig 从本机网络接口读取IP，而不是查询外部服务（适合直接获得公网IPv6前缀的主机）
+type InterfaceSourceConfig struct {
+	// Name 网络接口名称，如 eth0
+	Name string `json:"name"`
+	// IPv6Policy AAAA 记录的地址选择策略：
+	// stable（默认，跳过临时地址，优先 EUI-64）、eui64（只用 EUI-64 地址）、
+	// stable_privacy（只用非 EUI-64 的稳定地址，如 RFC 7217 地址）、any（包括临时地址）
+	IPv6Policy string `json:"ipv6_policy,omitempty"`
+}
+
+// IPv6 地址选择策略
+const (
+	ipv6PolicyStable        = "stable"
+	ipv6PolicyEUI64         = "eui64"
+	ipv6PolicyStablePrivacy = "stable_privacy"
+	ipv6PolicyAny           = "any"
+)
+
+// /proc/net/if_inet6 中的地址标志（IFA_F_*，只包含低 8 位）
+const (
+	ifaFlagTemporary  = 0x01
+	ifaFlagDADFailed  = 0x08
+	ifaFlagDeprecated = 0x20
+	ifaFlagTentative  = 0x40
+)
+
+// interfaceAddr 接口上的一个地址
+type interfaceAddr struct {
+	IP net.IP
+	// Temporary RFC 4941 临时隐私地址，会每隔几小时更换
+	Temporary bool
+	// Unusable 已弃用、尚未完成或未通过重复地址检测的地址
+	Unusable bool
+}
+
+func (c *InterfaceSourceConfig) policy() string {
+	if c.IPv6Policy != "" {
+		return c.IPv6Policy
+	}
+	return ipv6PolicyStable
+}
+
+// detect 按记录类型读取接口上的公网地址
+func (c *InterfaceSourceConfig) detect(recordType string) (string, error) {
+	iface, err := net.InterfaceByName(c.Name)
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), c.Name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), c.Name, err)
+	}
+
+	if recordType != "AAAA" {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && isPublicIP(ipNet.IP) {
+				return ipNet.IP.String(), nil
+			}
+		}
+		return "", fmt.Errorf(tr("网络接口 %s 上没有公网 IPv4 地址"), c.Name)
+	}
+
+	candidates := interfaceIPv6Addrs(c.Name, addrs)
+	ip, err := selectIPv6(candidates, c.policy())
+	if err != nil {
+		return "", fmt.Errorf(tr("网络接口 %s: %v"), c.Name, err)
+	}
+	return ip.String(), nil
+}
+
+// isPublicIP 是否为可以写入公网记录的单播地址（排除私有、链路本地、回环地址）
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// interfaceIPv6Addrs 返回接口上的公网 IPv6 地址及其标志。
+// Linux 从 /proc/net/if_inet6 读取标志；其他系统无法区分临时地址，只按地址本身判断
+func interfaceIPv6Addrs(name string, addrs []net.Addr) []interfaceAddr {
+	flags, err := readInet6Flags(name)
+	if err != nil {
+		logDebug("无法读取 IPv6 地址标志，不能识别临时地址: %v", err)
+	}
+
+	var result []interfaceAddr
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil || !isPublicIP(ipNet.IP) {
+			continue
+		}
+		a := interfaceAddr{IP: ipNet.IP}
+		if f, ok := flags[ipNet.IP.String()]; ok {
+			a.Temporary = f&ifaFlagTemporary != 0
+			a.Unusable = f&(ifaFlagDADFailed|ifaFlagDeprecated|ifaFlagTentative) != 0
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// readInet6Flags 读取 /proc/net/if_inet6 中指定接口的地址标志。
+// 每行格式: 地址(32位十六进制) 接口序号 前缀长度 范围 标志 接口名称
+func readInet6Flags(name string) (map[string]int, error) {
+	f, err := os.Open("/proc/net/if_inet6")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	flags := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || fields[5] != name {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[0])
+		if err != nil || len(raw) != net.IPv6len {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[4], 16, 32)
+		if err != nil {
+			continue
+		}
+		flags[net.IP(raw).String()] = int(value)
+	}
+	return flags, scanner.Err()
+}
+
+// isEUI64 接口标识是否由 MAC 地址生成（第 12、13 字节为 ff:fe）
+func isEUI64(ip net.IP) bool {
+	ip = ip.To16()
+	return ip != nil && ip[11] == 0xff && ip[12] == 0xfe
+}
+
+// selectIPv6 按策略选择地址。多个地址都符合时优先 EUI-64，其次按地址排序，保证每次选择结果一致
+func selectIPv6(addrs []interfaceAddr, policy string) (net.IP, error) {
+	var candidates []interfaceAddr
+	for _, a := range addrs {
+		if a.Unusable {
+			continue
+		}
+		switch policy {
+		case ipv6PolicyStable:
+			if a.Temporary {
+				continue
+			}
+		case ipv6PolicyEUI64:
+			if a.Temporary || !isEUI64(a.IP) {
+				continue
+			}
+		case ipv6PolicyStablePrivacy:
+			if a.Temporary || isEUI64(a.IP) {
+				continue
+			}
+		case ipv6PolicyAny:
+		default:
+			return nil, fmt.Errorf(tr("不支持的 IPv6 地址选择策略: %s"), policy)
+		}
+		candidates = append(candidates, a)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf(tr("没有符合策略 %s 的公网 IPv6 地址"), policy)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Temporary != b.Temporary {
+			return !a.Temporary
+		}
+		if isEUI64(a.IP) != isEUI64(b.IP) {
+			return isEUI64(a.IP)
+		}
+		return bytes.Compare(a.IP.To16(), b.IP.To16()) < 0
+	})
+	return candidates[0].IP, nil
+}
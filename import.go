@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// zoneIDPattern Cloudflare 区域ID（32 位十六进制）
+var zoneIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// FindZone 按域名查找区域
+func (c *CloudflareClient) FindZone(name string) (*Zone, error) {
+	resp, err := c.makeRequest("GET", "/zones?name="+url.QueryEscape(name), nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %w"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool   `json:"success"`
+		Result  []Zone `json:"result"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if !result.Success {
+		return nil, apiResultError(result.Errors)
+	}
+	if len(result.Result) == 0 {
+		return nil, classify(ErrRecordNotFound, fmt.Errorf(tr("未找到区域 %s（请确认 API Token 有该区域的权限）"), name))
+	}
+	return &result.Result[0], nil
+}
+
+// lookupZone 按区域ID或域名查找区域
+func lookupZone(client *CloudflareClient, zone string) (*Zone, error) {
+	if zoneIDPattern.MatchString(zone) {
+		return client.GetZone(zone)
+	}
+	return client.FindZone(strings.TrimSuffix(strings.ToLower(zone), "."))
+}
+
+// parseSelection 解析序号选择：逗号分隔的序号或范围（如 1,3-5），all 表示全部；返回从 0 开始的下标
+func parseSelection(input string, n int) ([]int, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, "all") {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to := part, part
+		if i := strings.Index(part, "-"); i > 0 {
+			from, to = part[:i], part[i+1:]
+		}
+		start, err1 := strconv.Atoi(strings.TrimSpace(from))
+		end, err2 := strconv.Atoi(strings.TrimSpace(to))
+		if err1 != nil || err2 != nil || start < 1 || end > n || start > end {
+			return nil, fmt.Errorf(tr("无效的序号: %s"), part)
+		}
+		for i := start - 1; i < end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// mergeImportedRecords 把选中的记录名称加入配置：尚未配置主记录时第一个作为主记录，
+// 其余加入 records；已在配置中的记录跳过。返回新加入的记录名称
+func mergeImportedRecords(config *Config, zoneID, recordType string, names []string) []string {
+	var added []string
+	if config.RecordName == "" && len(names) > 0 {
+		config.ZoneID = zoneID
+		config.RecordName = names[0]
+		config.RecordType = recordType
+		added = append(added, names[0])
+		names = names[1:]
+	}
+
+	existing := make(map[string]bool)
+	for _, target := range append(config.recordTargets(), config.pinnedTargets()...) {
+		existing[target.ZoneID+"/"+strings.ToLower(target.Name)] = true
+	}
+	for _, name := range names {
+		if existing[zoneID+"/"+strings.ToLower(name)] {
+			continue
+		}
+		existing[zoneID+"/"+strings.ToLower(name)] = true
+		record := RecordConfig{Name: name}
+		if zoneID != config.ZoneID {
+			record.ZoneID = zoneID
+		}
+		config.Records = append(config.Records, record)
+		added = append(added, name)
+	}
+	return added
+}
+
+func cmdImportMain(args []string) int {
+	fs, common := newFlagSet("import")
+	zone := fs.String("zone", "", tr("区域域名（如 example.com）或 Zone ID"))
+	recordType := fs.String("type", "", tr("导入的记录类型 A 或 AAAA（默认使用配置中的记录类型，未配置时为 A）"))
+	all := fs.Bool("all", false, tr("选择区域内全部记录，不逐个询问"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	parseFlags(fs, common, args)
+	if *zone == "" {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager import --zone example.com [--type A] [--all] [--yes]"))
+		return 2
+	}
+
+	config := LoadConfig()
+	if err := initLogger(false, true, LogRotation{}); err != nil {
+		fmt.Fprintf(os.Stderr, tr("初始化日志失败: %v")+"\n", err)
+		return 1
+	}
+	defer globalLogger.Close()
+	debugHTTP = *common.debugHTTP
+	globalLogger.debug = *common.debugHTTP
+	if *recordType == "" {
+		*recordType = config.RecordType
+	}
+	*recordType = strings.ToUpper(*recordType)
+	if *recordType == "" {
+		*recordType = "A"
+	}
+	if *recordType != "A" && *recordType != "AAAA" {
+		fmt.Fprintf(os.Stderr, tr("不支持的记录类型: %s（只能导入 A 或 AAAA 记录）\n"), *recordType)
+		return 2
+	}
+	// 所有记录共用配置中的记录类型
+	if config.RecordName != "" && config.RecordType != *recordType {
+		fmt.Fprintf(os.Stderr, tr("配置中的记录类型为 %s，只能导入同类型的记录\n"), config.RecordType)
+		return 2
+	}
+
+	token := config.APIToken
+	if token == "" {
+		token = getUserInput(tr("请输入 API Token: "))
+	}
+	client, err := NewCloudflareClient(token)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	found, err := lookupZone(client, *zone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ 获取失败: %v\n"), err)
+		return exitCodeFor(err)
+	}
+	records, err := client.ListZoneDNSRecordsByType(found.ID, *recordType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ 获取失败: %v\n"), err)
+		return exitCodeFor(err)
+	}
+	if len(records) == 0 {
+		fmt.Printf(tr("区域 %s 中没有 %s 记录\n"), found.Name, *recordType)
+		return 1
+	}
+
+	fmt.Printf(tr("区域 %s（%s）中的 %s 记录:\n"), found.Name, found.ID, *recordType)
+	printRecordTable(records)
+
+	var indexes []int
+	if *all {
+		indexes, _ = parseSelection("all", len(records))
+	} else {
+		input := getUserInput(tr("选择要自动维护的记录（序号用逗号分隔，支持范围如 1,3-5，all 表示全部，直接回车取消）: "))
+		if input == "" {
+			fmt.Println(tr("已取消"))
+			return 1
+		}
+		if indexes, err = parseSelection(input, len(records)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	// 多台机器共用一个名称时同名记录有多条，配置中只需一项
+	var names []string
+	seen := make(map[string]bool)
+	for _, i := range indexes {
+		name := strings.ToLower(records[i].Name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	mainBefore := config.RecordName
+	added := mergeImportedRecords(config, found.ID, *recordType, names)
+	if len(added) == 0 {
+		fmt.Println(tr("所选记录均已在配置中"))
+		return 0
+	}
+	fmt.Println(tr("\n将加入配置的记录:"))
+	for _, name := range added {
+		if mainBefore == "" && name == config.RecordName {
+			fmt.Printf(tr("  %s（主记录）\n"), name)
+			continue
+		}
+		fmt.Printf("  %s\n", name)
+	}
+	if !*yes && !confirm(tr("确认保存配置？")) {
+		return 1
+	}
+
+	config.APIToken = token
+	if err := SaveConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ 保存配置失败: %v\n"), err)
+		return 1
+	}
+	fmt.Printf(tr("✓ 配置已保存: %s\n"), getConfigPath())
+	return 0
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportRecords(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addZone("zone2", "example.org")
+	client := cf.client()
+
+	zone, err := lookupZone(client, "Example.ORG.")
+	if err != nil || zone.ID != "zone2" {
+		t.Fatalf("lookupZone = %+v, %v; want zone2", zone, err)
+	}
+	if _, err := lookupZone(client, "missing.example"); exitCodeFor(err) != exitNotFound {
+		t.Fatalf("lookupZone(missing) error = %v; want not found", err)
+	}
+
+	indexes, err := parseSelection("3, 1-2,2", 4)
+	if err != nil || !reflect.DeepEqual(indexes, []int{0, 1, 2}) {
+		t.Fatalf("parseSelection = %v, %v; want [0 1 2]", indexes, err)
+	}
+	for _, input := range []string{"0", "5", "2-1", "x"} {
+		if _, err := parseSelection(input, 4); err == nil {
+			t.Errorf("parseSelection(%q) accepted", input)
+		}
+	}
+
+	// 未配置时第一个记录作为主记录
+	config := &Config{RecordType: "A"}
+	added := mergeImportedRecords(config, testZoneID, "A", []string{"home.example.com", "vpn.example.com"})
+	if !reflect.DeepEqual(added, []string{"home.example.com", "vpn.example.com"}) || config.RecordName != "home.example.com" ||
+		config.ZoneID != testZoneID || !reflect.DeepEqual(config.Records, []RecordConfig{{Name: "vpn.example.com"}}) {
+		t.Fatalf("first import: added %v, config %+v", added, config)
+	}
+
+	// 其他区域的记录带上 zone_id，已有的记录跳过
+	added = mergeImportedRecords(config, "zone2", "A", []string{"home.example.org"})
+	added = append(added, mergeImportedRecords(config, testZoneID, "A", []string{"VPN.example.com"})...)
+	want := []RecordConfig{{Name: "vpn.example.com"}, {Name: "home.example.org", ZoneID: "zone2"}}
+	if !reflect.DeepEqual(added, []string{"home.example.org"}) || !reflect.DeepEqual(config.Records, want) {
+		t.Fatalf("second import: added %v, records %+v; want %+v", added, config.Records, want)
+	}
+}
+
+func TestRecordsImportFiles(t *testing.T) {
+	zone := `$ORIGIN example.com.
+$TTL 1h
+@       IN SOA ns1.example.net. admin.example.com. (
+            2024010101 ; serial
+            3600 600 86400 300 )
+@       IN NS   ns1.example.net.
+@          MX   10 mail
+www     300 IN CNAME @
+mail        A   192.0.2.10
+            AAAA 2001:db8::10
+txt         TXT "v=spf1 " "-all" ; 拼接多段字符串
+sub         NS  ns.sub.example.com.
+srv     IN SRV 0 5 5060 sip.example.com.
+`
+	records, issues := parseZoneFile([]byte(zone), testZoneName)
+	var got []string
+	for _, r := range records {
+		got = append(got, fmt.Sprintf("%d %s %s %s %d", r.Line, r.Name, r.Type, r.Content, r.TTL))
+	}
+	want := []string{
+		"7 example.com MX mail.example.com 3600",
+		"8 www.example.com CNAME example.com 300",
+		"9 mail.example.com A 192.0.2.10 3600",
+		"10 mail.example.com AAAA 2001:db8::10 3600",
+		"11 txt.example.com TXT v=spf1 -all 3600",
+		"12 sub.example.com NS ns.sub.example.com 3600",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("zone records = %q; want %q", got, want)
+	}
+	if len(issues) != 1 || !strings.Contains(issues[0], "SRV") {
+		t.Fatalf("zone issues = %q; want the SRV record reported", issues)
+	}
+
+	csvData := "name,type,content,ttl,proxied\nwww,CNAME,example.com,,true\n@,A,192.0.2.1,abc,\n"
+	csvRecords, csvIssues, err := parseRecordCSV([]byte(csvData))
+	if err != nil || len(csvRecords) != 1 || !csvRecords[0].Proxied || csvRecords[0].Line != 2 || len(csvIssues) != 1 {
+		t.Fatalf("parseRecordCSV = %+v, %q, %v; want one record and one issue", csvRecords, csvIssues, err)
+	}
+	if _, _, err := parseRecordCSV([]byte("name,type,value\n")); err == nil {
+		t.Error("parseRecordCSV accepted an unknown column")
+	}
+
+	// 无效、重复与本程序维护的记录跳过，其余新建或修改
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+	cf.addRecord(testZoneID, DNSRecord{Type: "CNAME", Name: "www.example.com", Content: "old.example.net", TTL: 1})
+	imported := []importedRecord{
+		{Line: 1, ApplyRecord: ApplyRecord{Name: "www", Type: "CNAME", Content: "example.com"}},
+		{Line: 2, ApplyRecord: ApplyRecord{Name: "mail", Type: "A", Content: "192.0.2.10"}},
+		{Line: 3, ApplyRecord: ApplyRecord{Name: "mail", Type: "A", Content: "192.0.2.10"}},
+		{Line: 4, ApplyRecord: ApplyRecord{Name: "bad", Type: "A", Content: "not-an-ip"}},
+		{Line: 5, ApplyRecord: ApplyRecord{Name: testRecord, Type: "A", Content: "192.0.2.1"}},
+	}
+	actions, skipped, err := planImport(imported, config, app.Client(), testZoneID)
+	if err != nil {
+		t.Fatalf("planImport: %v", err)
+	}
+	if len(actions) != 2 || actions[0].Action != "update" || actions[1].Action != "create" || len(skipped) != 3 {
+		t.Fatalf("actions = %+v, skipped = %q; want update www, create mail and three skipped", actions, skipped)
+	}
+	for _, action := range actions {
+		if err := applyRestoreAction(testZoneID, action); err != nil {
+			t.Fatalf("applyRestoreAction: %v", err)
+		}
+	}
+	if got := cf.contents(testZoneID, "mail.example.com", "A"); len(got) != 1 || got[0] != "192.0.2.10" {
+		t.Errorf("mail records = %v; want [192.0.2.10]", got)
+	}
+}
+
+func TestRecordFilter(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.pageSize = 2 // 覆盖翻页
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "vpn.example.com", Content: "192.0.2.7", TTL: 60})
+	cf.addRecord(testZoneID, DNSRecord{Type: "CNAME", Name: "www.example.com", Content: "home.example.com", TTL: 1})
+	cf.addRecord(testZoneID, DNSRecord{Type: "TXT", Name: "example.com", Content: "v=spf1 -all", TTL: 1})
+
+	names := func(filter recordFilter) []string {
+		t.Helper()
+		records, err := listFilteredRecords(filter)
+		if err != nil {
+			t.Fatalf("listFilteredRecords(%+v): %v", filter, err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.Name+"/"+r.Type)
+		}
+		return got
+	}
+
+	if got := names(recordFilter{}); !reflect.DeepEqual(got, []string{testRecord + "/A"}) {
+		t.Errorf("no filter = %v; want only the configured record", got)
+	}
+	if got := names(recordFilter{Type: "a", Sort: "ttl"}); !reflect.DeepEqual(got, []string{"vpn.example.com/A", testRecord + "/A"}) {
+		t.Errorf("type filter sorted by ttl = %v", got)
+	}
+	if got := names(recordFilter{Name: "*.EXAMPLE.com", ContentContains: "HOME"}); !reflect.DeepEqual(got, []string{"www.example.com/CNAME"}) {
+		t.Errorf("name and content filter = %v", got)
+	}
+	if err := (recordFilter{Name: "[", Sort: "name"}).validate(); err == nil {
+		t.Error("validate accepted a bad pattern")
+	}
+	if err := (recordFilter{Sort: "proxied"}).validate(); err == nil {
+		t.Error("validate accepted an unknown sort field")
+	}
+}
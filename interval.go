@@ -0,0 +1,119 @@
+package main
+
+import "time"
+
+// stableCyclesBeforeStretch IP连续多少个周期未变化后拉长一次检测间隔
+const stableCyclesBeforeStretch = 12
+
+// defaultMaxCheckInterval 未配置 max_check_interval 时，网络故障退避的间隔上限
+const defaultMaxCheckInterval = time.Minute
+
+// adaptiveInterval 自适应检测间隔：IP长期稳定时逐步拉长间隔（最多到 max），
+// IP变化后立即恢复为基础间隔；检测失败时按指数退避，恢复后回到基础间隔
+type adaptiveInterval struct {
+	base    time.Duration
+	max     time.Duration
+	stretch bool // 是否在IP稳定时拉长间隔（配置了 max_check_interval）
+
+	current time.Duration
+	stable  int
+	failing bool
+}
+
+// newAdaptiveInterval 按配置创建检测间隔；max_check_interval 未配置或无效时只在失败时退避
+func newAdaptiveInterval(config *Config) *adaptiveInterval {
+	a := &adaptiveInterval{base: checkInterval, max: defaultMaxCheckInterval, current: checkInterval}
+	if config.MaxCheckInterval != "" {
+		max, err := parseDurationOrZero(config.MaxCheckInterval)
+		switch {
+		case err != nil:
+			logError("max_check_interval 格式无效: %v，使用默认值", err)
+		case max > checkInterval:
+			a.max = max
+			a.stretch = true
+		}
+	}
+
+	// 启用写入租约时，间隔不能超过租约时长的三分之一，保证持有者按时续期
+	if config.Lease != nil {
+		a.limit(config.leaseDuration() / 3)
+	}
+	// 启用本机服务健康检查时，间隔不能超过撤下前允许的失败时长，避免故障后迟迟不撤下记录
+	if config.ServiceCheck != nil {
+		a.limit(config.ServiceCheck.failAfter())
+	}
+	// 启用成员登记时，间隔不能超过刷新周期，避免在线节点被其他节点视为离开
+	if config.Fleet != nil {
+		a.limit(config.fleetTTL() / 4)
+	}
+	return a
+}
+
+// limit 把间隔上限降低到 limit（不低于基础间隔）
+func (a *adaptiveInterval) limit(limit time.Duration) {
+	if limit < checkInterval {
+		limit = checkInterval
+	}
+	if limit < a.max {
+		a.max = limit
+		a.stretch = a.stretch && limit > checkInterval
+	}
+}
+
+// next 根据本周期结果计算下一次检测前的等待时间
+func (a *adaptiveInterval) next(changed bool, err error) time.Duration {
+	previous := a.current
+
+	switch {
+	case err != nil:
+		// 连续失败时指数退避，避免网络中断期间每隔几秒重复失败
+		a.stable = 0
+		if a.failing {
+			a.current = minDuration(a.current*2, a.max)
+		} else {
+			a.failing = true
+			a.current = a.base
+		}
+	case changed || a.failing:
+		a.stable = 0
+		a.failing = false
+		a.current = a.base
+	case a.stretch:
+		a.stable++
+		if a.stable >= stableCyclesBeforeStretch {
+			a.stable = 0
+			a.current = minDuration(a.current*2, a.max)
+		}
+	}
+
+	if a.current != previous {
+		logInfo("检测间隔调整为 %s", a.current)
+	}
+	return a.current
+}
+
+// reset 恢复为基础间隔（配置重载后）
+func (a *adaptiveInterval) reset() time.Duration {
+	a.stable = 0
+	a.failing = false
+	a.current = a.base
+	return a.current
+}
+
+// resetTimer 重新设置定时器，丢弃尚未读取的到期信号
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -14,22 +16,32 @@ type IPChecker struct {
 	services []string
 	// 优先使用的服务（最可靠）
 	primaryService string
+	// services6 AAAA 记录使用的IPv6检测服务（只通过IPv6访问）
+	services6 []string
 }
 
 func NewIPChecker() *IPChecker {
+	// 构建时注入 defaultIPServices 时替换内置列表，第一个为主服务
+	services := embeddedServices(defaultIPServices, []string{
+		"https://api.ipify.org",
+		"https://ifconfig.me/ip",
+		"https://icanhazip.com",
+		"https://api.ip.sb/ip",
+	})
 	return &IPChecker{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: newHTTPTransport("ip-checker"),
 		},
 		// 优先使用最可靠的服务
-		primaryService: "https://api.ipify.org",
+		primaryService: services[0],
 		// 备用服务列表
-		services: []string{
-			"https://api.ipify.org",
-			"https://ifconfig.me/ip",
-			"https://icanhazip.com",
-			"https://api.ip.sb/ip",
-		},
+		services: services,
+		services6: embeddedServices(defaultIPServices6, []string{
+			"https://api6.ipify.org",
+			"https://ipv6.icanhazip.com",
+			"https://v6.ident.me",
+		}),
 	}
 }
 
@@ -54,31 +66,115 @@ func (ic *IPChecker) GetPublicIP() (string, error) {
 		lastErr = err
 	}
 
-	return "", fmt.Errorf("所有IP检测服务均失败，最后错误: %v", lastErr)
+	return "", classify(ErrNetwork, fmt.Errorf(tr("所有IP检测服务均失败，最后错误: %v"), lastErr))
 }
 
 // GetPublicIPWithService 获取公网IP并返回使用的服务名称
 func (ic *IPChecker) GetPublicIPWithService() (string, string, error) {
-	// 优先使用主服务
-	ip, err := ic.getIPFromService(ic.primaryService)
-	if err == nil && ip != "" && isValidIPv4(ip) {
-		return ip, ic.primaryService, nil
+	// 模拟模式下按脚本返回IP
+	if ipSimulator != nil {
+		ip, err := ipSimulator.next()
+		return ip, simulatedSource, err
+	}
+
+	// 配置了网络接口时直接读取接口上的地址
+	config := app.Config()
+	if source := config.IPInterface; source != nil {
+		ip, err := source.detect(config.RecordType)
+		return ip, "interface " + source.Name, err
+	}
+
+	// AAAA 记录使用IPv6检测服务
+	if config.RecordType == "AAAA" {
+		if len(ic.services6) == 0 {
+			return "", "", classify(ErrNetwork, errors.New(tr("未配置IPv6检测服务")))
+		}
+		return ic.detectFrom(ic.services6[0], ic.services6, "AAAA", config.ipRaceDelay())
+	}
+	return ic.detectFrom(ic.primaryService, ic.services, "A", config.ipRaceDelay())
+}
+
+// detectFrom 优先使用主服务：主服务与第一个备用服务竞速（主服务先行 raceDelay），取最先返回的有效结果；
+// 都失败时依次尝试其余备用服务。raceDelay 为 0 时依次尝试
+func (ic *IPChecker) detectFrom(primary string, services []string, recordType string, raceDelay time.Duration) (string, string, error) {
+	ordered := []string{primary}
+	for _, service := range services {
+		if service != primary {
+			ordered = append(ordered, service)
+		}
 	}
 
-	// 主服务失败，尝试备用服务
 	var lastErr error
-	for _, service := range ic.services {
-		if service == ic.primaryService {
-			continue
+	if raceDelay > 0 && len(ordered) >= 2 {
+		ip, service, err := ic.race(ordered[0], ordered[1], recordType, raceDelay)
+		if err == nil {
+			return ip, service, nil
 		}
-		ip, err := ic.getIPFromService(service)
-		if err == nil && ip != "" && isValidIPv4(ip) {
+		lastErr = err
+		ordered = ordered[2:]
+	}
+	for _, service := range ordered {
+		ip, err := ic.fetchIP(service, recordType)
+		if err == nil {
 			return ip, service, nil
 		}
 		lastErr = err
 	}
 
-	return "", "", fmt.Errorf("所有IP检测服务均失败，最后错误: %v", lastErr)
+	if recordType == "AAAA" {
+		return "", "", classify(ErrNetwork, fmt.Errorf(tr("所有IPv6检测服务均失败，最后错误: %v"), lastErr))
+	}
+	return "", "", classify(ErrNetwork, fmt.Errorf(tr("所有IP检测服务均失败，最后错误: %v"), lastErr))
+}
+
+// raceResult 竞速中一个服务的结果
+type raceResult struct {
+	ip      string
+	service string
+	err     error
+}
+
+// race 先请求 first，delay 后（或 first 失败时立即）请求 second，返回最先得到的有效结果并取消另一个请求；
+// 主服务响应慢但没有失败时，检测耗时不超过 delay 加上备用服务的响应时间
+func (ic *IPChecker) race(first, second, recordType string, delay time.Duration) (string, string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := make(chan raceResult, 2)
+	run := func(service string) {
+		go func() {
+			ip, err := ic.fetchIPContext(ctx, service, recordType)
+			results <- raceResult{ip: ip, service: service, err: err}
+		}()
+	}
+	run(first)
+	started, pending := 1, 1
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if started < 2 {
+				logDebug("IP检测服务 %s 在 %v 内未响应，同时请求 %s", first, delay, second)
+				run(second)
+				started, pending = started+1, pending+1
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.ip, result.service, nil
+			}
+			lastErr = result.err
+			if started < 2 {
+				run(second)
+				started, pending = started+1, pending+1
+			}
+		}
+	}
+	return "", "", lastErr
 }
 
 // isValidIPv4 验证是否为有效的IPv4地址
@@ -93,14 +189,27 @@ func isValidIPv4(ip string) bool {
 }
 
 func (ic *IPChecker) getIPFromService(url string) (string, error) {
-	resp, err := ic.client.Get(url)
+	return ic.fetchIP(url, "A")
+}
+
+// fetchIP 从服务获取与记录类型一致的地址
+func (ic *IPChecker) fetchIP(url, recordType string) (string, error) {
+	return ic.fetchIPContext(context.Background(), url, recordType)
+}
+
+func (ic *IPChecker) fetchIPContext(ctx context.Context, url, recordType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := ic.client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("服务返回状态码: %d", resp.StatusCode)
+		return "", fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -110,12 +219,12 @@ func (ic *IPChecker) getIPFromService(url string) (string, error) {
 
 	ip := strings.TrimSpace(string(body))
 	if ip == "" {
-		return "", fmt.Errorf("返回内容为空")
+		return "", errors.New(tr("返回内容为空"))
 	}
 
 	// 验证IP格式
-	if !isValidIPv4(ip) {
-		return "", fmt.Errorf("无效的IP地址格式: %s", ip)
+	if !ipMatchesType(ip, recordType) {
+		return "", fmt.Errorf(tr("无效的IP地址格式: %s"), ip)
 	}
 
 	return ip, nil
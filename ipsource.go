@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPSourceConfig 单个记录使用的IP来源，与主记录的检测互不影响：
+// 有多条上行线路的主机可以让不同的记录分别指向各条线路的地址，
+// 组网（Tailscale、WireGuard）内的名称可以指向本机在组网中的地址。
+// interface、service、stun、router、tailscale、wireguard 只能设置一个
+type IPSourceConfig struct {
+	// Interface 从网络接口读取地址（与 ip_interface 相同）
+	Interface *InterfaceSourceConfig `json:"interface,omitempty"`
+	// Service 查询指定的IP检测服务，返回内容为纯文本IP（如 https://api.ipify.org）
+	Service string `json:"service,omitempty"`
+	// STUN 向 STUN 服务器查询映射地址，格式为 host[:port]（默认端口 3478）
+	STUN string `json:"stun,omitempty"`
+	// Router 路由器 UPnP IGD 的控制地址（WANIPConnection 或 WANPPPConnection），查询 WAN 口地址
+	Router string `json:"router,omitempty"`
+	// Tailscale 使用本机的 Tailscale 地址（100.64.0.0/10 或 fd7a:115c:a1e0::/48）
+	Tailscale bool `json:"tailscale,omitempty"`
+	// WireGuard 使用该 WireGuard 接口（如 wg0）上的地址
+	WireGuard string `json:"wireguard,omitempty"`
+	// BindAddress 查询 service、stun、router 时使用的本机源地址，配合策略路由选择出口线路
+	BindAddress string `json:"bind_address,omitempty"`
+}
+
+// stunMagicCookie STUN 消息头中的固定值（RFC 5389）
+const stunMagicCookie = 0x2112A442
+
+// ipSourceTimeout 独立IP来源单次查询的超时时间
+const ipSourceTimeout = 10 * time.Second
+
+// validate 检查IP来源配置
+func (s *IPSourceConfig) validate() error {
+	kinds := 0
+	for _, set := range []bool{s.Interface != nil, s.Service != "", s.STUN != "", s.Router != "", s.Tailscale, s.WireGuard != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New(tr("ip_source 需要且只能设置 interface、service、stun、router、tailscale、wireguard 中的一个"))
+	}
+	if s.Interface != nil && s.Interface.Name == "" {
+		return errors.New(tr("ip_source.interface.name 不能为空"))
+	}
+	for _, u := range []string{s.Service, s.Router} {
+		if u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return fmt.Errorf(tr("ip_source 中的地址应为 http(s) URL: %s"), u)
+		}
+	}
+	if s.BindAddress != "" {
+		if s.Interface != nil || s.Tailscale || s.WireGuard != "" {
+			return errors.New(tr("ip_source.bind_address 只能与 service、stun、router 一起使用"))
+		}
+		if net.ParseIP(s.BindAddress) == nil {
+			return fmt.Errorf(tr("无效的 ip_source.bind_address: %s"), s.BindAddress)
+		}
+	}
+	return nil
+}
+
+// String 来源描述，用于日志与区分不同来源
+func (s *IPSourceConfig) String() string {
+	var desc string
+	switch {
+	case s.Interface != nil:
+		desc = "interface " + s.Interface.Name
+	case s.Service != "":
+		desc = "service " + s.Service
+	case s.STUN != "":
+		desc = "stun " + s.STUN
+	case s.Router != "":
+		desc = "router " + s.Router
+	case s.Tailscale:
+		desc = "tailscale"
+	case s.WireGuard != "":
+		desc = "wireguard " + s.WireGuard
+	}
+	if s.BindAddress != "" {
+		desc += " via " + s.BindAddress
+	}
+	return desc
+}
+
+// pinnedSource 按 IPSourceConfig 检测IP，实现 IPSource
+type pinnedSource struct {
+	config     *IPSourceConfig
+	recordType string
+	client     *http.Client
+}
+
+func newPinnedSource(config *IPSourceConfig, recordType string) *pinnedSource {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.BindAddress != "" {
+		dialer := &net.Dialer{Timeout: ipSourceTimeout, LocalAddr: &net.TCPAddr{IP: net.ParseIP(config.BindAddress)}}
+		transport.DialContext = dialer.DialContext
+	}
+	return &pinnedSource{
+		config:     config,
+		recordType: recordType,
+		client:     &http.Client{Timeout: ipSourceTimeout, Transport: wrapTransport("ip-source", transport)},
+	}
+}
+
+// GetPublicIPWithService 检测IP并返回来源描述
+func (p *pinnedSource) GetPublicIPWithService() (string, string, error) {
+	var ip string
+	var err error
+	switch {
+	case p.config.Interface != nil:
+		ip, err = p.config.Interface.detect(p.recordType)
+	case p.config.Service != "":
+		ip, err = p.fetchService()
+	case p.config.STUN != "":
+		ip, err = p.stunMappedAddress()
+	case p.config.Router != "":
+		ip, err = p.upnpExternalIP()
+	case p.config.Tailscale:
+		ip, err = tailscaleAddress(p.recordType)
+	case p.config.WireGuard != "":
+		ip, err = wireguardAddress(p.config.WireGuard, p.recordType)
+	}
+	desc := p.config.String()
+	if err != nil {
+		return "", desc, classify(ErrNetwork, fmt.Errorf("%s: %w", desc, err))
+	}
+	if !ipMatchesType(ip, p.recordType) {
+		return "", desc, fmt.Errorf(tr("%s 返回的地址 %s 不是 %s 记录可用的地址"), desc, ip, p.recordType)
+	}
+	return ip, desc, nil
+}
+
+// ipMatchesType 地址族是否与记录类型一致
+func ipMatchesType(ip, recordType string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	if recordType == "AAAA" {
+		return parsed.To4() == nil
+	}
+	return parsed.To4() != nil
+}
+
+func (p *pinnedSource) fetchService() (string, error) {
+	resp, err := p.client.Get(p.config.Service)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// stunMappedAddress 发送 STUN Binding 请求，读取 XOR-MAPPED-ADDRESS（或 MAPPED-ADDRESS）
+func (p *pinnedSource) stunMappedAddress() (string, error) {
+	server := p.config.STUN
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "3478")
+	}
+	network := "udp4"
+	if p.recordType == "AAAA" {
+		network = "udp6"
+	}
+	dialer := &net.Dialer{Timeout: ipSourceTimeout}
+	if p.config.BindAddress != "" {
+		dialer.LocalAddr = &net.UDPAddr{IP: net.ParseIP(p.config.BindAddress)}
+	}
+	conn, err := dialer.Dial(network, server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ipSourceTimeout))
+
+	request := make([]byte, 20)
+	binary.BigEndian.PutUint16(request[0:], 0x0001) // Binding Request
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	rand.Read(request[8:20])
+	if _, err := conn.Write(request); err != nil {
+		return "", err
+	}
+
+	response := make([]byte, 1500)
+	n, err := conn.Read(response)
+	if err != nil {
+		return "", err
+	}
+	return parseSTUNResponse(response[:n], request[8:20])
+}
+
+// parseSTUNResponse 解析 Binding 成功响应中的映射地址
+func parseSTUNResponse(msg, transactionID []byte) (string, error) {
+	if len(msg) < 20 || binary.BigEndian.Uint16(msg[0:]) != 0x0101 ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || !bytes.Equal(msg[8:20], transactionID) {
+		return "", errors.New(tr("无效的 STUN 响应"))
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if 20+length > len(msg) {
+		return "", errors.New(tr("无效的 STUN 响应"))
+	}
+
+	var mapped string
+	attrs := msg[20 : 20+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+		switch attrType {
+		case 0x0020: // XOR-MAPPED-ADDRESS
+			if ip := stunAddress(value, msg[4:20]); ip != nil {
+				return ip.String(), nil
+			}
+		case 0x0001: // MAPPED-ADDRESS
+			if ip := stunAddress(value, nil); ip != nil {
+				mapped = ip.String()
+			}
+		}
+		// 属性按 4 字节对齐
+		attrs = attrs[4+(attrLen+3)&^3:]
+	}
+	if mapped == "" {
+		return "", errors.New(tr("STUN 响应中没有映射地址"))
+	}
+	return mapped, nil
+}
+
+// stunAddress 解析地址属性；xorKey 为魔术字与事务ID（XOR-MAPPED-ADDRESS），为 nil 时不做异或
+func stunAddress(value, xorKey []byte) net.IP {
+	if len(value) < 4 {
+		return nil
+	}
+	size := 4
+	if value[1] == 0x02 {
+		size = 16
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if xorKey != nil {
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+	return ip
+}
+
+// upnpServices 查询 WAN 口地址时依次尝试的 UPnP 服务类型
+var upnpServices = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// upnpExternalIP 通过 UPnP IGD 的 GetExternalIPAddress 查询路由器 WAN 口地址
+func (p *pinnedSource) upnpExternalIP() (string, error) {
+	var lastErr error
+	for _, service := range upnpServices {
+		body := `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+			`<s:Body><u:GetExternalIPAddress xmlns:u="` + service + `"></u:GetExternalIPAddress></s:Body></s:Envelope>`
+		req, err := http.NewRequest("POST", p.config.Router, strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+		req.Header.Set("SOAPAction", `"`+service+`#GetExternalIPAddress"`)
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		ip, err := readUPnPExternalIP(resp)
+		if err == nil {
+			return ip, nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+func readUPnPExternalIP(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
+	}
+	decoder := xml.NewDecoder(io.LimitReader(resp.Body, 64*1024))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", errors.New(tr("UPnP 响应中没有 WAN 口地址"))
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "NewExternalIPAddress" {
+			var ip string
+			if err := decoder.DecodeElement(&ip, &start); err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(ip), nil
+		}
+	}
+}
+
+// pinnedGroup 使用同一个IP来源的记录
+type pinnedGroup struct {
+	Source  *IPSourceConfig
+	Targets []RecordTarget
+}
+
+// pinnedGroups 按IP来源分组返回设置了 ip_source 的记录；与主记录或其他记录重复的记录只保留第一次出现
+func (c *Config) pinnedGroups() []pinnedGroup {
+	seen := make(map[string]bool)
+	for _, target := range c.recordTargets() {
+		seen[target.ZoneID+"/"+strings.ToLower(target.Name)] = true
+	}
+
+	var groups []pinnedGroup
+	index := make(map[string]int)
+	for _, record := range c.Records {
+		if record.IPSource == nil || record.Name == "" {
+			continue
+		}
+		zoneID := record.ZoneID
+		if zoneID == "" {
+			zoneID = c.ZoneID
+		}
+		key := zoneID + "/" + strings.ToLower(record.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		target := RecordTarget{ZoneID: zoneID, Name: record.Name, Type: c.RecordType, Retry: record.RetryPolicy}
+		source := record.IPSource.String()
+		if i, ok := index[source]; ok {
+			groups[i].Targets = append(groups[i].Targets, target)
+			continue
+		}
+		index[source] = len(groups)
+		groups = append(groups, pinnedGroup{Source: record.IPSource, Targets: []RecordTarget{target}})
+	}
+	return groups
+}
+
+// pinnedTargets 返回设置了 ip_source 的全部记录
+func (c *Config) pinnedTargets() []RecordTarget {
+	var targets []RecordTarget
+	for _, group := range c.pinnedGroups() {
+		targets = append(targets, group.Targets...)
+	}
+	return targets
+}
+
+// pinnedState 各独立IP来源最近同步的IP，键为来源描述
+type pinnedState struct {
+	mu  sync.Mutex
+	ips map[string]string
+}
+
+var pinnedIPs = &pinnedState{}
+
+func (s *pinnedState) get(source string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ips[source]
+}
+
+func (s *pinnedState) set(source, ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ips == nil {
+		s.ips = make(map[string]string)
+	}
+	s.ips[source] = ip
+}
+
+// syncPinnedRecords 分别检测每个独立IP来源并同步其记录；与主记录相同，IP变化时先确认再写入。
+// 程序启动后第一次检测时直接按检测结果生成计划，记录已指向该IP时不会写入
+func syncPinnedRecords(r *Reconciler, config *Config) (bool, error) {
+	updated := false
+	var failures []string
+	var errs []error
+	for _, group := range config.pinnedGroups() {
+		key := group.Source.String()
+		fail := func(name string, err error) {
+			failures = append(failures, name+": "+err.Error())
+			errs = append(errs, err)
+		}
+		if err := group.Source.validate(); err != nil {
+			logError("IP来源 %s 配置无效: %v", key, err)
+			fail(key, err)
+			continue
+		}
+
+		pr := *r
+		pr.IPSource = newPinnedSource(group.Source, config.RecordType)
+		ip, source, err := pr.Detect()
+		if err != nil {
+			logError("获取公网IP失败: %v", err)
+			fail(key, err)
+			continue
+		}
+		last := pinnedIPs.get(key)
+		if ip == last {
+			logInfo("IP来源 %s: IP未变化 (%s)，跳过 %d 个记录", key, ip, len(group.Targets))
+			continue
+		}
+		if last != "" {
+			logInfo("IP来源 %s: 检测到IP变化 (%s -> %s)，正在确认...", key, last, ip)
+			if err := pr.confirmFor(group.Targets).ConfirmChange(ip); err != nil {
+				fail(key, err)
+				continue
+			}
+			publishEvent(StreamEvent{Type: StreamIPChanged, Record: group.Targets[0].Name, IP: ip, OldIP: last, Source: source})
+		}
+
+		pr.Prefetch(group.Targets)
+		applyFailed := false
+		results := pr.SyncAll(group.Targets, ip, last, source, config.reconcileWorkers())
+		lastCycle.synced(ip, results)
+		for _, result := range results {
+			name := result.Target.Name
+			if result.Applied {
+				updated = true
+				publishEvent(StreamEvent{Type: StreamDNSUpdated, Record: name, IP: ip, OldIP: last, Source: source})
+				notify(NotifyEvent{
+					Type:   EventDNSUpdated,
+					Title:  tr("DNS记录已更新"),
+					Record: name,
+					OldIP:  last,
+					NewIP:  ip,
+				})
+			}
+			if result.Err != nil {
+				applyFailed = applyFailed || !result.Applied
+				fail(name, result.Err)
+			}
+		}
+		if !applyFailed && !pr.DryRun {
+			pinnedIPs.set(key, ip)
+		}
+	}
+
+	if len(failures) > 0 {
+		msg := fmt.Sprintf(tr("独立IP来源的记录同步失败: %s"), strings.Join(failures, "; "))
+		return updated, &joinedError{msg: msg, errs: errs}
+	}
+	return updated, nil
+}
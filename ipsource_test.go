@@ -0,0 +1,324 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAndUpdateSyncsPinnedRecords(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.2")
+	wan2 := newFakeIPService(t, "203.0.113.5")
+	config.Records = []RecordConfig{
+		{Name: "vpn.example.com"},
+		{Name: "wan2.example.com", IPSource: &IPSourceConfig{Service: wan2.server.URL}},
+	}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "vpn.example.com", Content: "198.51.100.2"})
+	t.Cleanup(func() { pinnedIPs = &pinnedState{} })
+
+	// 主记录的IP未变化时仍然同步独立来源的记录，缺少的记录按该来源的IP新建
+	updated, err := checkAndUpdate()
+	if err != nil || !updated {
+		t.Fatalf("checkAndUpdate = %v, %v; want updated", updated, err)
+	}
+	want := map[string]string{testRecord: "198.51.100.2", "vpn.example.com": "198.51.100.2", "wan2.example.com": "203.0.113.5"}
+	for name, ip := range want {
+		if got := cf.contents(testZoneID, name, "A"); len(got) != 1 || got[0] != ip {
+			t.Errorf("%s records = %v; want [%s]", name, got, ip)
+		}
+	}
+
+	writes := cf.writes()
+	if updated, err := checkAndUpdate(); err != nil || updated {
+		t.Fatalf("second checkAndUpdate = %v, %v; want nothing to do", updated, err)
+	}
+	if cf.writes() != writes {
+		t.Errorf("second cycle wrote %d records; want none", cf.writes()-writes)
+	}
+
+	config.Records[1].IPSource = &IPSourceConfig{Service: wan2.server.URL, STUN: "stun.example.net"}
+	if _, err := checkAndUpdate(); err == nil {
+		t.Error("checkAndUpdate accepted an ip_source with two sources")
+	}
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	id := []byte("0123456789ab")
+	message := func(attrType uint16, value []byte) []byte {
+		msg := make([]byte, 20, 20+4+len(value))
+		binary.BigEndian.PutUint16(msg[0:], 0x0101)
+		binary.BigEndian.PutUint16(msg[2:], uint16(4+len(value)))
+		binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+		copy(msg[8:], id)
+		attr := make([]byte, 4)
+		binary.BigEndian.PutUint16(attr[0:], attrType)
+		binary.BigEndian.PutUint16(attr[2:], uint16(len(value)))
+		return append(append(msg, attr...), value...)
+	}
+
+	// XOR-MAPPED-ADDRESS: 203.0.113.5 与魔术字异或
+	xored := make([]byte, 8)
+	xored[1] = 0x01
+	ip := net.ParseIP("203.0.113.5").To4()
+	binary.BigEndian.PutUint32(xored[4:], binary.BigEndian.Uint32(ip)^stunMagicCookie)
+	if got, err := parseSTUNResponse(message(0x0020, xored), id); err != nil || got != "203.0.113.5" {
+		t.Errorf("XOR-MAPPED-ADDRESS = %q, %v; want 203.0.113.5", got, err)
+	}
+
+	plain := append([]byte{0, 0x01, 0, 0}, 192, 0, 2, 7)
+	if got, err := parseSTUNResponse(message(0x0001, plain), id); err != nil || got != "192.0.2.7" {
+		t.Errorf("MAPPED-ADDRESS = %q, %v; want 192.0.2.7", got, err)
+	}
+
+	if _, err := parseSTUNResponse(message(0x0001, plain), []byte("other-id-xyz")); err == nil {
+		t.Error("parseSTUNResponse accepted a response for another transaction")
+	}
+	if _, err := parseSTUNResponse(message(0x8022, []byte("test")), id); err == nil {
+		t.Error("parseSTUNResponse accepted a response without an address")
+	}
+}
+
+func TestOverlayAddress(t *testing.T) {
+	var addrs []net.Addr
+	for _, cidr := range []string{"fe80::1/64", "192.168.1.5/24", "100.101.102.103/32", "fd7a:115c:a1e0::1234/128"} {
+		ip, ipNet, _ := net.ParseCIDR(cidr)
+		ipNet.IP = ip
+		addrs = append(addrs, ipNet)
+	}
+
+	tests := []struct {
+		recordType string
+		match      func(net.IP) bool
+		want       string
+	}{
+		{"A", isTailscaleIP, "100.101.102.103"},
+		{"AAAA", isTailscaleIP, "fd7a:115c:a1e0::1234"},
+		{"A", nil, "192.168.1.5"},
+		{"AAAA", nil, "fd7a:115c:a1e0::1234"},
+	}
+	for _, tt := range tests {
+		if got := overlayAddress(addrs, tt.recordType, tt.match); got.String() != tt.want {
+			t.Errorf("overlayAddress(%s) = %v; want %s", tt.recordType, got, tt.want)
+		}
+	}
+	if got := overlayAddress(addrs[:2], "A", isTailscaleIP); got != nil {
+		t.Errorf("overlayAddress without a Tailscale address = %v; want nil", got)
+	}
+
+	if err := (&IPSourceConfig{Tailscale: true}).validate(); err != nil {
+		t.Errorf("tailscale source: %v", err)
+	}
+	if err := (&IPSourceConfig{WireGuard: "wg0", BindAddress: "192.0.2.1"}).validate(); err == nil {
+		t.Error("validate accepted bind_address with wireguard")
+	}
+}
+
+func TestCheckAddressFamily(t *testing.T) {
+	t.Cleanup(func() { detectFamilies = detectAddressFamilies })
+	detectFamilies = func() addressFamilies { return addressFamilies{IPv6: true} }
+
+	// 只有 IPv6 的主机：双栈模式改为 AAAA，否则只警告
+	config := &Config{RecordType: "A", DualStack: true}
+	checkAddressFamily(config)
+	if config.RecordType != "AAAA" {
+		t.Errorf("dual-stack record type = %s; want AAAA", config.RecordType)
+	}
+	config = &Config{RecordType: "A"}
+	checkAddressFamily(config)
+	if config.RecordType != "A" {
+		t.Errorf("record type = %s; want A unchanged without dual_stack", config.RecordType)
+	}
+
+	for ip, want := range map[string]bool{
+		"2001:db8::1": true,
+		"fd7a::1":     false,
+		"fe80::1":     false,
+		"192.0.2.1":   false,
+	} {
+		if got := isGlobalIPv6(net.ParseIP(ip)); got != want {
+			t.Errorf("isGlobalIPv6(%s) = %v; want %v", ip, got, want)
+		}
+	}
+}
+
+func TestIPCheckerRace(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		w.Write([]byte("198.51.100.1"))
+	}))
+	defer slow.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	backup := newFakeIPService(t, "198.51.100.2")
+	ic := &IPChecker{client: http.DefaultClient}
+
+	// 主服务响应慢：先行时间过后备用服务的结果先返回
+	start := time.Now()
+	ip, service, err := ic.detectFrom(slow.URL, []string{slow.URL, backup.server.URL}, "A", 50*time.Millisecond)
+	if err != nil || ip != "198.51.100.2" || service != backup.server.URL {
+		t.Fatalf("detectFrom = %q, %q, %v; want the backup's answer", ip, service, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("detection took %v; want it bounded by the head start", elapsed)
+	}
+
+	// 主服务失败时不等待先行时间
+	start = time.Now()
+	if ip, _, err := ic.detectFrom(broken.URL, []string{backup.server.URL}, "A", time.Hour); err != nil || ip != "198.51.100.2" {
+		t.Fatalf("detectFrom = %q, %v; want the backup's answer", ip, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("detection took %v after the primary failed", elapsed)
+	}
+
+	// 竞速的两个服务都失败时依次尝试其余服务
+	if ip, _, err := ic.detectFrom(broken.URL, []string{broken.URL + "/2", backup.server.URL}, "A", time.Millisecond); err != nil || ip != "198.51.100.2" {
+		t.Fatalf("detectFrom = %q, %v; want the third service's answer", ip, err)
+	}
+}
+
+func TestHTTPConfigTimeouts(t *testing.T) {
+	var cfg *HTTPConfig
+	if cfg.cloudflareTimeout() != defaultCloudflareTimeout || cfg.ipCheckTimeout() != defaultIPCheckTimeout || cfg.transport() != http.DefaultTransport {
+		t.Fatal("nil http config should keep the defaults and the shared transport")
+	}
+	cfg = &HTTPConfig{CloudflareTimeout: "0", IPCheckTimeout: "bogus", KeepAlive: "0", MaxIdleConnsPerHost: 8}
+	if cfg.cloudflareTimeout() != 0 || cfg.ipCheckTimeout() != defaultIPCheckTimeout {
+		t.Errorf("timeouts = %v, %v; want none and the default", cfg.cloudflareTimeout(), cfg.ipCheckTimeout())
+	}
+	if transport, ok := cfg.transport().(*http.Transport); !ok || !transport.DisableKeepAlives || transport.MaxIdleConnsPerHost != 8 {
+		t.Errorf("transport = %+v; want keep-alives off and 8 idle conns per host", transport)
+	}
+
+	// 检测服务超过 ip_check_timeout 未返回时放弃
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	ic := newIPCheckerFor(&Config{HTTP: &HTTPConfig{IPCheckTimeout: "100ms"}})
+	start := time.Now()
+	if _, err := ic.fetchIP(slow.URL, "A"); err == nil {
+		t.Fatal("fetchIP succeeded; want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("fetchIP took %v; want it bounded by ip_check_timeout", elapsed)
+	}
+}
+
+func TestHTTPConfigDoHResolver(t *testing.T) {
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") == "ip.example.test" && r.URL.Query().Get("type") == "A" {
+			w.Write([]byte(`{"Status":0,"Answer":[{"type":1,"data":"127.0.0.1"}]}`))
+			return
+		}
+		w.Write([]byte(`{"Status":3}`))
+	}))
+	defer doh.Close()
+	service := newFakeIPService(t, "198.51.100.7")
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(service.server.URL, "http://"))
+
+	// 域名只能通过 DoH 解析
+	ic := newIPCheckerFor(&Config{HTTP: &HTTPConfig{DoH: doh.URL}})
+	if ip, err := ic.fetchIP("http://ip.example.test:"+port, "A"); err != nil || ip != "198.51.100.7" {
+		t.Fatalf("fetchIP = %q, %v; want the service's answer via DoH", ip, err)
+	}
+	if _, err := ic.fetchIP("http://missing.example.test:"+port, "A"); err == nil || !strings.Contains(err.Error(), "missing.example.test") {
+		t.Errorf("fetchIP error = %v; want the unresolved host", err)
+	}
+}
+
+func TestEmbeddedDefaults(t *testing.T) {
+	t.Cleanup(func() { defaultIPServices, defaultAPIBaseURL = "", "" })
+
+	if ic := NewIPChecker(); ic.primaryService != "https://api.ipify.org" || len(ic.services) != 4 {
+		t.Fatalf("built-in services = %q, %v", ic.primaryService, ic.services)
+	}
+	defaultIPServices = "https://ip.example.net, https://api.ipify.org,"
+	if ic := NewIPChecker(); ic.primaryService != "https://ip.example.net" || len(ic.services) != 2 {
+		t.Errorf("embedded services = %q, %v; want the injected list", ic.primaryService, ic.services)
+	}
+
+	for _, c := range []struct{ base, want string }{
+		{"", "api.cloudflare.com:443"},
+		{"https://cf-proxy.example.net/v4/", "cf-proxy.example.net:443"},
+		{"http://192.168.1.1:8080/client/v4", "192.168.1.1:8080"},
+	} {
+		defaultAPIBaseURL = c.base
+		if got := apiProbeAddr(); got != c.want {
+			t.Errorf("apiProbeAddr with %q = %q; want %q", c.base, got, c.want)
+		}
+	}
+	if got := apiBaseURL(); got != "http://192.168.1.1:8080/client/v4" {
+		t.Errorf("apiBaseURL = %q", got)
+	}
+}
+
+func TestHTTPConfigClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	// 自签名的客户端证书
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dns-manager"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	certFile, keyFile, caFile := dir+"/client.crt", dir+"/client.key", dir+"/ca.crt"
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	clientCert, _ := x509.ParseCertificate(der)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("198.51.100.9"))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	ic := newIPCheckerFor(&Config{HTTP: &HTTPConfig{CAFile: caFile, ClientCert: certFile, ClientKey: keyFile}})
+	if ip, err := ic.fetchIP(server.URL, "A"); err != nil || ip != "198.51.100.9" {
+		t.Fatalf("fetchIP = %q, %v; want the answer over mutual TLS", ip, err)
+	}
+	ic = newIPCheckerFor(&Config{HTTP: &HTTPConfig{CAFile: caFile}})
+	if _, err := ic.fetchIP(server.URL, "A"); err == nil {
+		t.Error("fetchIP without a client certificate succeeded")
+	}
+	if _, err := (&HTTPConfig{ClientCert: certFile}).tlsConfig(); err == nil {
+		t.Error("client_cert without client_key accepted")
+	}
+}
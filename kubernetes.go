@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// KubernetesConfig 根据 Kubernetes 中带注解的 Service / Ingress 维护DNS记录（适合 k3s 等家用集群）。
+// 默认使用集群内的 ServiceAccount；在集群外运行时指定 APIServer、TokenFile 与 CAFile
+type KubernetesConfig struct {
+	APIServer string `json:"api_server,omitempty"`
+	TokenFile string `json:"token_file,omitempty"`
+	CAFile    string `json:"ca_file,omitempty"`
+	// Namespace 只监听该命名空间，为空时监听全部命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// ZoneID 记录所在区域，为空时使用主配置的 zone_id；可用 dns-manager/zone-id 注解覆盖
+	ZoneID string `json:"zone_id,omitempty"`
+	// Proxied 新建记录是否开启 Cloudflare 代理；可用 dns-manager/proxied 注解覆盖
+	Proxied bool `json:"proxied,omitempty"`
+}
+
+// 注解
+const (
+	kubeAnnotationHostname = "dns-manager/hostname"
+	kubeAnnotationZoneID   = "dns-manager/zone-id"
+	kubeAnnotationProxied  = "dns-manager/proxied"
+)
+
+// kubernetesRecordComment 记录备注中的所有权标记
+const kubernetesRecordComment = "managed by dns_manager (kubernetes)"
+
+// kubeServiceAccountDir 集群内 ServiceAccount 凭据位置
+const kubeServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// kubeResyncInterval 定期完整同步的间隔：删除注解不会产生带注解的事件，靠定期同步清理对应记录
+const kubeResyncInterval = 10 * time.Minute
+
+// kubernetesSyncChan Service / Ingress 变化时通知主循环同步记录
+var kubernetesSyncChan = make(chan struct{}, 1)
+
+// kubeObject Service / Ingress 中用到的字段
+type kubeObject struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// kubeClient 访问 Kubernetes API 的客户端
+type kubeClient struct {
+	server    string
+	tokenFile string
+	client    *http.Client
+}
+
+// newKubeClient 按配置创建客户端，未配置时使用集群内凭据
+func newKubeClient(c *KubernetesConfig) (*kubeClient, error) {
+	server := c.APIServer
+	if server == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New(tr("不在 Kubernetes 集群内运行，请配置 kubernetes.api_server"))
+		}
+		server = "https://" + net.JoinHostPort(host, port)
+	}
+	tokenFile := c.TokenFile
+	if tokenFile == "" {
+		tokenFile = kubeServiceAccountDir + "/token"
+	}
+	caFile := c.CAFile
+	if caFile == "" && c.APIServer == "" {
+		caFile = kubeServiceAccountDir + "/ca.crt"
+	}
+
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf(tr("读取 Kubernetes CA 证书失败: %v"), err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(tr("Kubernetes CA 证书无效: %s"), caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &kubeClient{
+		server:    strings.TrimSuffix(server, "/"),
+		tokenFile: tokenFile,
+		client:    &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}},
+	}, nil
+}
+
+// get 发送 GET 请求；令牌每次重新读取（ServiceAccount 令牌会定期轮换）
+func (k *kubeClient) get(path string) (*http.Response, error) {
+	token, err := os.ReadFile(k.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取 Kubernetes 令牌失败: %v"), err)
+	}
+	registerSecret(strings.TrimSpace(string(token)))
+
+	req, err := http.NewRequest("GET", k.server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf(tr("Kubernetes API 返回错误 (状态码: %d)"), resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// kubeResourcePaths 返回 Service 与 Ingress 的 API 路径
+func kubeResourcePaths(namespace string) []string {
+	scope := ""
+	if namespace != "" {
+		scope = "/namespaces/" + namespace
+	}
+	return []string{
+		"/api/v1" + scope + "/services",
+		"/apis/networking.k8s.io/v1" + scope + "/ingresses",
+	}
+}
+
+// listKubernetesHostnames 列出带注解的 Service / Ingress 声明的记录。
+// Ingress 的 dns-manager/hostname 注解为空时使用 spec.rules 中的主机名
+func listKubernetesHostnames(k *kubeClient, config *Config) ([]discoveredRecord, error) {
+	var records []discoveredRecord
+	for _, path := range kubeResourcePaths(config.Kubernetes.Namespace) {
+		resp, err := k.get(path)
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Items []kubeObject `json:"items"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+		}
+
+		kind := "Service"
+		if strings.HasSuffix(path, "/ingresses") {
+			kind = "Ingress"
+		}
+		for _, obj := range list.Items {
+			records = append(records, kubeObjectRecords(obj, kind, config)...)
+		}
+	}
+	return records, nil
+}
+
+// kubeObjectRecords 返回一个对象声明的记录
+func kubeObjectRecords(obj kubeObject, kind string, config *Config) []discoveredRecord {
+	annotations := obj.Metadata.Annotations
+	value, ok := annotations[kubeAnnotationHostname]
+	if !ok {
+		return nil
+	}
+
+	var hostnames []string
+	for _, hostname := range strings.Split(value, ",") {
+		if hostname = strings.TrimSpace(hostname); hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	if len(hostnames) == 0 && kind == "Ingress" {
+		for _, rule := range obj.Spec.Rules {
+			// 通配符主机名无法对应单条记录
+			if rule.Host != "" && !strings.HasPrefix(rule.Host, "*") {
+				hostnames = append(hostnames, rule.Host)
+			}
+		}
+	}
+
+	zoneID := annotations[kubeAnnotationZoneID]
+	if zoneID == "" {
+		zoneID = config.Kubernetes.ZoneID
+	}
+	if zoneID == "" {
+		zoneID = config.ZoneID
+	}
+	proxied := config.Kubernetes.Proxied
+	if value, ok := annotations[kubeAnnotationProxied]; ok {
+		proxied = value == "true"
+	}
+
+	owner := fmt.Sprintf("%s %s/%s", kind, obj.Metadata.Namespace, obj.Metadata.Name)
+	records := make([]discoveredRecord, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		records = append(records, discoveredRecord{ZoneID: zoneID, Name: hostname, Proxied: proxied, Owner: owner})
+	}
+	return records
+}
+
+// syncKubernetesRecords 让带注解的 Service / Ingress 声明的记录指向当前公网IP，并删除已不再声明的记录
+func syncKubernetesRecords() error {
+	config := app.Config()
+	if config.Kubernetes == nil {
+		return nil
+	}
+	k, err := newKubeClient(config.Kubernetes)
+	if err != nil {
+		logError("%v", err)
+		return err
+	}
+	records, err := listKubernetesHostnames(k, config)
+	if err != nil {
+		logError("%v", err)
+		return err
+	}
+	return syncDiscoveredRecords("kubernetes", kubernetesRecordComment, config.Kubernetes.ZoneID, records)
+}
+
+// watchKubernetes 监听 Service 与 Ingress 的变化，带注解的对象变化时请求同步
+func watchKubernetes(c *KubernetesConfig) {
+	k, err := newKubeClient(c)
+	if err != nil {
+		logError("%v", err)
+		return
+	}
+	for _, path := range kubeResourcePaths(c.Namespace) {
+		go k.watch(path)
+	}
+	for range time.Tick(kubeResyncInterval) {
+		requestSync(kubernetesSyncChan)
+	}
+}
+
+// watch 监听一种资源；API 服务器会定期关闭监听连接，断开后重新连接（重连时会收到全部现有对象）
+func (k *kubeClient) watch(path string) {
+	backoff := 5 * time.Second
+	for {
+		resp, err := k.get(path + "?watch=1")
+		if err != nil {
+			logError("监听 Kubernetes 资源失败 (%s): %v，%s 后重试", path, err, backoff)
+			time.Sleep(backoff)
+			continue
+		}
+
+		logDebug("已连接 Kubernetes 监听: %s", path)
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event struct {
+				Type   string     `json:"type"`
+				Object kubeObject `json:"object"`
+			}
+			if err := decoder.Decode(&event); err != nil {
+				logDebug("Kubernetes 监听已断开 (%s): %v", path, err)
+				break
+			}
+			if _, ok := event.Object.Metadata.Annotations[kubeAnnotationHostname]; ok {
+				logDebug("Kubernetes 事件: %s %s/%s", event.Type, event.Object.Metadata.Namespace, event.Object.Metadata.Name)
+				requestSync(kubernetesSyncChan)
+			}
+		}
+		resp.Body.Close()
+		time.Sleep(time.Second)
+	}
+}
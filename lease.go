@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LeaseConfig 写入租约：多台机器误管理同一条记录时，只有持有租约的实例执行写入，其余实例待命接管
+type LeaseConfig struct {
+	// Record 保存租约的 TXT 记录名称，默认 _dns_manager_lease.<record_name>
+	Record string `json:"record,omitempty"`
+	// Duration 租约时长（默认 "90s"），持有者在剩余不足一半时续期
+	Duration string `json:"duration,omitempty"`
+	// Owner 本实例的标识，默认使用主机名；同一台机器上运行多个实例时需分别设置
+	Owner string `json:"owner,omitempty"`
+}
+
+// defaultLeaseDuration 租约默认时长
+const defaultLeaseDuration = 90 * time.Second
+
+// leaseRecordName 返回租约记录名称
+func (c *Config) leaseRecordName() string {
+	if c.Lease.Record != "" {
+		return c.Lease.Record
+	}
+	return "_dns_manager_lease." + c.RecordName
+}
+
+// leaseDuration 返回租约时长
+func (c *Config) leaseDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Lease.Duration); err == nil && d > 0 {
+		return d
+	}
+	return defaultLeaseDuration
+}
+
+// leaseOwner 返回本实例的标识
+func (c *Config) leaseOwner() string {
+	if c.Lease.Owner != "" {
+		return c.Lease.Owner
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return fmt.Sprintf("pid-%d", os.Getpid())
+	}
+	return hostname
+}
+
+// leaseState 本实例的租约状态
+type leaseState struct {
+	mu       sync.Mutex
+	active   bool
+	expires  time.Time
+	recordID string
+	holder   string // 待命时记录当前持有者，用于只在变化时输出日志
+}
+
+var lease = &leaseState{}
+
+// parseLease 解析租约记录内容：owner=<标识> expires=<Unix 时间>
+func parseLease(content string) (owner string, expires time.Time) {
+	for _, field := range strings.Fields(strings.Trim(content, `"`)) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "owner":
+			owner = value
+		case "expires":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				expires = time.Unix(sec, 0)
+			}
+		}
+	}
+	return owner, expires
+}
+
+// currentLease 从租约记录中选出有效租约：未过期的记录中取记录ID最小的一条，所有实例得出相同结果
+func currentLease(records []DNSRecord, now time.Time) (holder string, expires time.Time, ok bool) {
+	bestID := ""
+	for _, record := range records {
+		owner, until := parseLease(record.Content)
+		if owner == "" || !now.Before(until) {
+			continue
+		}
+		if !ok || record.ID < bestID {
+			bestID, holder, expires, ok = record.ID, owner, until, true
+		}
+	}
+	return holder, expires, ok
+}
+
+// checkLease 确认本实例持有写入租约，必要时获取或续期。
+// 返回是否可以写入，以及本次是否刚获得租约（此时调用方应重新核对记录）
+func checkLease(config *Config, client *CloudflareClient) (active, acquired bool, err error) {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+
+	duration := config.leaseDuration()
+	if lease.active && time.Until(lease.expires) > duration/2 {
+		return true, false, nil
+	}
+
+	name := config.leaseRecordName()
+	owner := config.leaseOwner()
+	now := time.Now()
+
+	records, err := client.GetAllDNSRecords(config.ZoneID, name, "TXT")
+	if err != nil {
+		return false, false, fmt.Errorf(tr("读取租约记录失败: %v"), err)
+	}
+	if holder, expires, ok := currentLease(records, now); ok && holder != owner {
+		lease.standby(holder, expires)
+		return false, false, nil
+	}
+
+	// 优先复用本实例的记录，其次复用已过期的记录，没有记录时新建
+	recordID := ""
+	for _, record := range records {
+		if holder, _ := parseLease(record.Content); holder == owner {
+			recordID = record.ID
+			break
+		}
+	}
+	if recordID == "" && len(records) > 0 {
+		recordID = records[0].ID
+	}
+
+	expires := now.Add(duration)
+	content := fmt.Sprintf("owner=%s expires=%d", owner, expires.Unix())
+	written, err := client.writeMetaRecord(config.ZoneID, recordID, name, content)
+	if err != nil {
+		return false, false, fmt.Errorf(tr("写入租约记录失败: %v"), err)
+	}
+
+	wasActive := lease.active
+	if !wasActive {
+		// 两个实例可能同时接管，写入后重新读取确认归属
+		records, err := client.GetAllDNSRecords(config.ZoneID, name, "TXT")
+		if err != nil {
+			return false, false, fmt.Errorf(tr("读取租约记录失败: %v"), err)
+		}
+		if holder, holderExpires, ok := currentLease(records, time.Now()); ok && holder != owner {
+			lease.standby(holder, holderExpires)
+			return false, false, nil
+		}
+		logInfo("已获得写入租约（%s），本实例负责更新记录", name)
+		lease.holder = ""
+	}
+
+	lease.active = true
+	lease.expires = expires
+	lease.recordID = written.ID
+	return true, !wasActive, nil
+}
+
+// standby 记录其他实例持有租约（调用方已持有锁）
+func (l *leaseState) standby(holder string, expires time.Time) {
+	if l.active {
+		logError("写入租约已被 %s 接管，本实例转为待命", holder)
+	} else if l.holder != holder {
+		logInfo("写入租约由 %s 持有（%s 到期），本实例待命", holder, expires.In(logLocation).Format(logTimeFormat))
+	}
+	l.active = false
+	l.holder = holder
+}
+
+// releaseLease 退出时删除本实例持有的租约，待命实例可以立即接管
+func releaseLease(config *Config, client *CloudflareClient) {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+
+	if !lease.active || lease.recordID == "" || client == nil {
+		return
+	}
+	if err := client.deleteMetaRecord(config.ZoneID, lease.recordID); err != nil {
+		logError("释放写入租约失败: %v", err)
+		return
+	}
+	lease.active = false
+	logInfo("已释放写入租约")
+}
+
+// writeMetaRecord 创建或更新租约、成员登记等程序自用的 TXT 记录。
+// 这些记录每隔几十秒到几分钟写入一次，不保存快照也不写入审计日志
+func (c *CloudflareClient) writeMetaRecord(zoneID, recordID, name, content string) (*DNSRecord, error) {
+	name, err := c.recordName(zoneID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	method := "POST"
+	endpoint := fmt.Sprintf("/zones/%s/dns_records", zoneID)
+	if recordID != "" {
+		method = "PUT"
+		endpoint += "/" + recordID
+	}
+
+	jsonData, err := json.Marshal(DNSRecordCreateRequest{Type: "TXT", Name: name, Content: content, TTL: 60})
+	if err != nil {
+		return nil, fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	resp, err := c.makeRequest(method, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool      `json:"success"`
+		Result  DNSRecord `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf(tr("API 错误: %s"), "success=false")
+	}
+	return &result.Result, nil
+}
+
+// deleteMetaRecord 删除程序自用的 TXT 记录，同样不保存快照也不写入审计日志
+func (c *CloudflareClient) deleteMetaRecord(zoneID, recordID string) error {
+	endpoint := fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, recordID)
+	resp, err := c.makeRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiStatusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultListCacheTTL 记录列表的缓存时间：交互式菜单与验证步骤在几秒内重复读取同一记录时不再请求 API
+const defaultListCacheTTL = 5 * time.Second
+
+// recordListCache 按区域与名称缓存记录列表；通过同一客户端修改区域内记录时清除该区域的缓存
+type recordListCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]listCacheEntry
+}
+
+type listCacheEntry struct {
+	records []DNSRecord
+	at      time.Time
+}
+
+func newRecordListCache(ttl time.Duration) *recordListCache {
+	return &recordListCache{ttl: ttl, entries: make(map[string]listCacheEntry)}
+}
+
+func listCacheKey(zoneID, name string) string {
+	return zoneID + "/" + strings.ToLower(name)
+}
+
+// get 返回未过期的记录列表副本
+func (c *recordListCache) get(zoneID, name string) ([]DNSRecord, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[listCacheKey(zoneID, name)]
+	if !ok || time.Since(entry.at) >= c.ttl {
+		return nil, false
+	}
+	logDebug("使用 %v 前读取的 %s 记录列表", time.Since(entry.at).Round(time.Millisecond), name)
+	return append([]DNSRecord(nil), entry.records...), true
+}
+
+// put 保存记录列表的副本
+func (c *recordListCache) put(zoneID, name string, records []DNSRecord) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[listCacheKey(zoneID, name)] = listCacheEntry{records: append([]DNSRecord(nil), records...), at: time.Now()}
+}
+
+// invalidate 清除区域内全部名称的缓存
+func (c *recordListCache) invalidate(zoneID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := zoneID + "/"
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidateForRequest 修改类请求（包括失败的请求，可能已经生效）发出前清除对应区域的缓存
+func (c *recordListCache) invalidateForRequest(method, endpoint string) {
+	if c == nil || method == "GET" || !strings.HasPrefix(endpoint, "/zones/") {
+		return
+	}
+	zoneID := strings.TrimPrefix(endpoint, "/zones/")
+	if i := strings.IndexAny(zoneID, "/?"); i >= 0 {
+		zoneID = zoneID[:i]
+	}
+	c.invalidate(zoneID)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultLogDedupWindow 相同错误日志的合并窗口
+const defaultLogDedupWindow = 10 * time.Minute
+
+// logDeduper 合并重复的错误日志：同一内容在窗口内只记录第一次，其余计数，
+// 窗口结束后输出一行 "又重复了 N 次" 的汇总。网络中断时每 5 秒一次的失败不会写出大量相同的日志行
+type logDeduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*repeatedLine
+}
+
+// repeatedLine 窗口内某条日志的首次出现时间与被省略的次数
+type repeatedLine struct {
+	first      time.Time
+	suppressed int
+}
+
+// newLogDeduper 创建合并器，window 为 0 时返回 nil（不合并）
+func newLogDeduper(window time.Duration) *logDeduper {
+	if window <= 0 {
+		return nil
+	}
+	return &logDeduper{window: window, seen: make(map[string]*repeatedLine)}
+}
+
+// allow 判断 message 是否需要写出，并返回已结束窗口的汇总行
+func (d *logDeduper) allow(message string, now time.Time) (summaries []string, ok bool) {
+	if d == nil {
+		return nil, true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summaries = d.expire(now)
+	if line, ok := d.seen[message]; ok {
+		line.suppressed++
+		return summaries, false
+	}
+	d.seen[message] = &repeatedLine{first: now}
+	return summaries, true
+}
+
+// flush 返回所有未输出的汇总（退出前调用）
+func (d *logDeduper) flush() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.expire(time.Time{})
+}
+
+// expire 移除在 now 之前结束的窗口并生成汇总，now 为零值时移除全部；调用方需持有锁
+func (d *logDeduper) expire(now time.Time) []string {
+	type expired struct {
+		message string
+		line    *repeatedLine
+	}
+	var lines []expired
+	for message, line := range d.seen {
+		if !now.IsZero() && now.Sub(line.first) < d.window {
+			continue
+		}
+		delete(d.seen, message)
+		if line.suppressed > 0 {
+			lines = append(lines, expired{message, line})
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].line.first.Before(lines[j].line.first)
+	})
+
+	var summaries []string
+	for _, e := range lines {
+		summaries = append(summaries, fmt.Sprintf(tr("以下消息在 %s 内又重复了 %d 次: %s"), d.window, e.line.suppressed, e.message))
+	}
+	return summaries
+}
+
+// logDedupWindow 返回 log_dedup_window 设置的合并窗口，"0" 表示不合并
+func (c *Config) logDedupWindow() time.Duration {
+	if c.LogDedupWindow == "" {
+		return defaultLogDedupWindow
+	}
+	d, err := parseDurationOrZero(c.LogDedupWindow)
+	if err != nil || d < 0 {
+		fmt.Fprintf(os.Stderr, tr("log_dedup_window 格式无效: %s，使用默认值 %v\n"), c.LogDedupWindow, defaultLogDedupWindow)
+		return defaultLogDedupWindow
+	}
+	return d
+}
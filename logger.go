@@ -1,82 +1,343 @@
 package main
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	_ "time/tzdata" // 静态编译的二进制在精简系统上也能解析时区名称
 )
 
 type Logger struct {
 	fileLogger *log.Logger
 	console    bool
 	logFile    *os.File
+	debug      bool
+	dedup      *logDeduper // 合并重复的错误日志，nil 表示不合并
+	eventLog   eventLog    // 错误日志同时写入的系统事件日志，nil 表示不写入
+
+	mu       sync.Mutex
+	logDir   string
+	logDate  string
+	logSize  int64
+	rotation LogRotation
+}
+
+// LogRotation 日志轮转与保留策略
+type LogRotation struct {
+	MaxSizeMB  int // 单个日志文件最大大小（MB），超过后轮转
+	MaxFiles   int // 保留的历史日志文件数量（不含当前文件）
+	MaxAgeDays int // 历史日志最长保留天数
 }
 
+// 默认轮转策略
+const (
+	defaultLogMaxSizeMB  = 10
+	defaultLogMaxFiles   = 10
+	defaultLogMaxAgeDays = 30
+)
+
 var globalLogger *Logger
 
-func initLogger(enableFileLog bool, enableConsole bool) error {
+// logTimeFormat 统一的日志时间格式（RFC 3339，包含时区偏移，UTC 显示为 Z）
+const logTimeFormat = "2006-01-02T15:04:05Z07:00"
+
+// logLocation 日志时间戳使用的时区，默认 UTC
+var logLocation = time.UTC
+
+// setLogTimezone 设置日志时区：空值或 UTC 为协调世界时，Local 为系统时区，其他为 IANA 时区名称（如 Asia/Shanghai）
+func setLogTimezone(name string) error {
+	switch strings.ToLower(name) {
+	case "", "utc":
+		logLocation = time.UTC
+		return nil
+	case "local":
+		logLocation = time.Local
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf(tr("无效的日志时区 %s: %v"), name, err)
+	}
+	logLocation = loc
+	return nil
+}
+
+// logNow 返回日志时区下的当前时间
+func logNow() time.Time {
+	return time.Now().In(logLocation)
+}
+
+func initLogger(enableFileLog bool, enableConsole bool, rotation LogRotation) error {
+	if rotation.MaxSizeMB <= 0 {
+		rotation.MaxSizeMB = defaultLogMaxSizeMB
+	}
+	if rotation.MaxFiles <= 0 {
+		rotation.MaxFiles = defaultLogMaxFiles
+	}
+	if rotation.MaxAgeDays <= 0 {
+		rotation.MaxAgeDays = defaultLogMaxAgeDays
+	}
+
 	globalLogger = &Logger{
-		console: enableConsole,
+		console:  enableConsole,
+		rotation: rotation,
 	}
 
 	if enableFileLog {
 		// 创建日志目录
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			homeDir = "."
-		}
-		logDir := filepath.Join(homeDir, ".go_dns_manager", "logs")
+		logDir := getLogDir()
 		if err := os.MkdirAll(logDir, 0755); err != nil {
-			return fmt.Errorf("创建日志目录失败: %v", err)
+			return fmt.Errorf(tr("创建日志目录失败: %v"), err)
 		}
+		globalLogger.logDir = logDir
 
-		// 创建日志文件（按日期命名）
-		logFileName := fmt.Sprintf("dns_manager_%s.log", time.Now().Format("2006-01-02"))
-		logPath := filepath.Join(logDir, logFileName)
-
-		file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		if err != nil {
-			return fmt.Errorf("打开日志文件失败: %v", err)
+		if err := globalLogger.openLogFile(logNow()); err != nil {
+			return err
 		}
+		globalLogger.cleanupOldLogs()
+	}
 
-		globalLogger.logFile = file
-		globalLogger.fileLogger = log.New(file, "", log.LstdFlags)
+	return nil
+}
+
+// getLogDir 返回日志目录路径
+func getLogDir() string {
+	return filepath.Join(getDataDir(), "logs")
+}
+
+// logFileName 返回指定日期的日志文件名（按日期命名）
+func logFileName(date string) string {
+	return fmt.Sprintf("dns_manager_%s.log", date)
+}
+
+// openLogFile 打开（或创建）指定日期的日志文件，调用方需持有锁或处于初始化阶段
+func (l *Logger) openLogFile(now time.Time) error {
+	date := now.Format("2006-01-02")
+	logPath := filepath.Join(l.logDir, logFileName(date))
+
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf(tr("打开日志文件失败: %v"), err)
+	}
+
+	var size int64
+	if stat, err := file.Stat(); err == nil {
+		size = stat.Size()
 	}
 
+	l.logFile = file
+	l.logDate = date
+	l.logSize = size
+	// 时间戳由 Logger 统一添加，不使用 log 包自带的时间前缀
+	l.fileLogger = log.New(file, "", 0)
 	return nil
 }
 
+// writeFile 写入一行日志，必要时按日期或大小轮转
+func (l *Logger) writeFile(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fileLogger == nil {
+		return
+	}
+
+	now := logNow()
+	if now.Format("2006-01-02") != l.logDate {
+		// 日期变化，切换到新文件
+		l.logFile.Close()
+		if err := l.openLogFile(now); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			l.fileLogger = nil
+			return
+		}
+		go l.cleanupOldLogs()
+	} else if l.logSize >= int64(l.rotation.MaxSizeMB)*1024*1024 {
+		l.rotate(now)
+	}
+
+	l.fileLogger.Println(message)
+	l.logSize += int64(len(message) + 1)
+}
+
+// rotate 将当前日志文件重命名为带序号的历史文件并压缩，然后重新打开新文件
+func (l *Logger) rotate(now time.Time) {
+	l.logFile.Close()
+
+	current := filepath.Join(l.logDir, logFileName(l.logDate))
+	base := strings.TrimSuffix(current, ".log")
+	var rotated string
+	for i := 1; ; i++ {
+		rotated = fmt.Sprintf("%s.%d.log", base, i)
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			if _, err := os.Stat(rotated + ".gz"); os.IsNotExist(err) {
+				break
+			}
+		}
+	}
+
+	if err := os.Rename(current, rotated); err != nil {
+		fmt.Fprintf(os.Stderr, tr("日志轮转失败: %v\n"), err)
+	}
+
+	if err := l.openLogFile(now); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		l.fileLogger = nil
+		return
+	}
+
+	go func() {
+		if err := gzipFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, tr("压缩日志失败: %v\n"), err)
+		}
+		l.cleanupOldLogs()
+	}()
+}
+
+// gzipFile 压缩文件为 .gz 并删除原文件
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// cleanupOldLogs 按保留数量和保留天数删除历史日志（当前写入的文件除外）
+func (l *Logger) cleanupOldLogs() {
+	entries, err := os.ReadDir(l.logDir)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	current := logFileName(l.logDate)
+	l.mu.Unlock()
+
+	type logEntry struct {
+		path    string
+		modTime time.Time
+	}
+	var logs []logEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == current || !strings.HasPrefix(name, "dns_manager_") {
+			continue
+		}
+		if !strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".log.gz") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, logEntry{path: filepath.Join(l.logDir, name), modTime: info.ModTime()})
+	}
+
+	// 新的在前
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].modTime.After(logs[j].modTime)
+	})
+
+	cutoff := logNow().AddDate(0, 0, -l.rotation.MaxAgeDays)
+	for i, entry := range logs {
+		if i >= l.rotation.MaxFiles || entry.modTime.Before(cutoff) {
+			os.Remove(entry.path)
+		}
+	}
+}
+
 func (l *Logger) Info(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logMessage := fmt.Sprintf("[%s] %s", timestamp, message)
+	message := redactSecrets(fmt.Sprintf(tr(format), v...))
+	logMessage := fmt.Sprintf("[%s] %s%s", logNow().Format(logTimeFormat), logPrefix(), message)
 
 	if l.console {
 		fmt.Println(logMessage)
 	}
 
-	if l.fileLogger != nil {
-		l.fileLogger.Println(message)
-	}
+	l.writeFile(logMessage)
 }
 
 func (l *Logger) Error(format string, v ...interface{}) {
-	message := fmt.Sprintf("ERROR: %s", fmt.Sprintf(format, v...))
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logMessage := fmt.Sprintf("[%s] %s", timestamp, message)
+	message := redactSecrets(fmt.Sprintf(tr(format), v...))
+	summaries, ok := l.dedup.allow(message, time.Now())
+	for _, summary := range summaries {
+		l.writeError("", summary)
+		l.writeEventLog(summary)
+	}
+	if ok {
+		l.writeError(logPrefix(), message)
+		l.writeEventLog(message)
+	}
+}
+
+// writeError 带时间戳与 ERROR 标记写出一行日志
+func (l *Logger) writeError(prefix, message string) {
+	logMessage := fmt.Sprintf("[%s] %sERROR: %s", logNow().Format(logTimeFormat), prefix, message)
 
 	if l.console {
 		fmt.Fprintln(os.Stderr, logMessage)
 	}
 
-	if l.fileLogger != nil {
-		l.fileLogger.Println(message)
+	l.writeFile(logMessage)
+}
+
+// Debug 调试日志，仅在启用调试输出时记录
+func (l *Logger) Debug(format string, v ...interface{}) {
+	if !l.debug {
+		return
+	}
+	message := redactSecrets(fmt.Sprintf("DEBUG: %s", fmt.Sprintf(tr(format), v...)))
+	logMessage := fmt.Sprintf("[%s] %s%s", logNow().Format(logTimeFormat), logPrefix(), message)
+
+	if l.console {
+		fmt.Println(logMessage)
 	}
+
+	l.writeFile(logMessage)
 }
 
 func (l *Logger) Close() error {
+	for _, summary := range l.dedup.flush() {
+		l.writeError("", summary)
+		l.writeEventLog(summary)
+	}
+	if l.eventLog != nil {
+		l.eventLog.Close()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.logFile != nil {
 		return l.logFile.Close()
 	}
@@ -88,7 +349,13 @@ func logInfo(format string, v ...interface{}) {
 	if globalLogger != nil {
 		globalLogger.Info(format, v...)
 	} else {
-		fmt.Printf(format+"\n", v...)
+		fmt.Println(redactSecrets(fmt.Sprintf(tr(format), v...)))
+	}
+}
+
+func logDebug(format string, v ...interface{}) {
+	if globalLogger != nil {
+		globalLogger.Debug(format, v...)
 	}
 }
 
@@ -96,6 +363,6 @@ func logError(format string, v ...interface{}) {
 	if globalLogger != nil {
 		globalLogger.Error(format, v...)
 	} else {
-		fmt.Fprintf(os.Stderr, format+"\n", v...)
+		fmt.Fprintln(os.Stderr, redactSecrets(fmt.Sprintf(tr(format), v...)))
 	}
 }
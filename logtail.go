@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tailLogs 输出最近 n 行日志（今天的日志不足时补充昨天的），follow 为 true 时持续跟踪新内容
+func tailLogs(n int, follow bool) error {
+	logDir := getLogDir()
+	now := logNow()
+	todayPath := filepath.Join(logDir, logFileName(now.Format("2006-01-02")))
+	yesterdayPath := filepath.Join(logDir, logFileName(now.AddDate(0, 0, -1).Format("2006-01-02")))
+
+	todayLines, todayErr := readLastLines(todayPath, n)
+	if todayErr != nil && !os.IsNotExist(todayErr) {
+		return fmt.Errorf(tr("读取日志文件失败: %v"), todayErr)
+	}
+
+	var lines []string
+	if len(todayLines) < n {
+		yesterdayLines, err := readLastLines(yesterdayPath, n-len(todayLines))
+		if err == nil {
+			lines = append(lines, yesterdayLines...)
+		}
+	}
+	lines = append(lines, todayLines...)
+
+	if len(lines) == 0 && !follow {
+		if os.IsNotExist(todayErr) {
+			return fmt.Errorf(tr("未找到日志文件: %s"), todayPath)
+		}
+		return nil
+	}
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	if !follow {
+		return nil
+	}
+
+	return followLog(logDir)
+}
+
+// readLastLines 读取文件的最后 n 行
+func readLastLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// followLog 持续输出日志新增内容，日期变化或文件轮转时自动切换到新文件
+func followLog(logDir string) error {
+	var file *os.File
+	var path string
+	var offset int64
+
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	var partial string
+	buf := make([]byte, 32*1024)
+
+	for {
+		currentPath := filepath.Join(logDir, logFileName(logNow().Format("2006-01-02")))
+
+		// 文件切换或被轮转（变小）时重新打开
+		if stat, err := os.Stat(currentPath); err == nil {
+			if currentPath != path || stat.Size() < offset {
+				if file != nil {
+					file.Close()
+				}
+				f, err := os.Open(currentPath)
+				if err != nil {
+					return fmt.Errorf(tr("打开日志文件失败: %v"), err)
+				}
+				file = f
+				if path == "" {
+					// 首次打开时从末尾开始（已输出的内容不重复）
+					offset, _ = file.Seek(0, io.SeekEnd)
+				} else {
+					offset = 0
+				}
+				path = currentPath
+				partial = ""
+			}
+		}
+
+		if file != nil {
+			for {
+				count, err := file.Read(buf)
+				if count > 0 {
+					offset += int64(count)
+					data := partial + string(buf[:count])
+					lastNewline := strings.LastIndex(data, "\n")
+					if lastNewline >= 0 {
+						fmt.Print(data[:lastNewline+1])
+						partial = data[lastNewline+1:]
+					} else {
+						partial = data
+					}
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+}
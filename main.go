@@ -2,190 +2,130 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
 var (
-	config     *Config
-	cfClient   *CloudflareClient
-	ipChecker  *IPChecker
-	running    bool
-	currentIP  string
+	dryRun     bool
 	reloadChan chan bool
 )
 
-func main() {
-	// 解析命令行参数
-	daemonMode := flag.Bool("daemon", false, "后台运行模式，直接开始监控（适合系统服务）")
-	onceMode := flag.Bool("once", false, "执行一次更新后退出（适合 cron）")
-	logFile := flag.Bool("log-file", false, "启用日志文件（daemon 模式默认启用）")
-	stopFlag := flag.Bool("stop", false, "停止后台运行的守护进程")
-	killFlag := flag.Bool("kill", false, "强制终止守护进程")
-	statusFlag := flag.Bool("status", false, "查看守护进程状态")
-	listFlag := flag.Bool("list", false, "列出所有dns_manager进程")
-	infoFlag := flag.Bool("info", false, "查看守护进程详细信息")
-	cleanupFlag := flag.Bool("cleanup", false, "清理无效的PID文件")
-	manageFlag := flag.Bool("manage", false, "进入守护进程管理菜单")
-	flag.Parse()
+// checkInterval IP检测间隔（构建时可通过 defaultCheckInterval 修改）
+var checkInterval = embeddedCheckInterval()
 
-	// 列出所有进程
-	if *listFlag {
-		processes, err := listDaemonProcesses()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "列出进程失败: %v\n", err)
-			os.Exit(1)
-		}
-		if len(processes) == 0 {
-			fmt.Println("未找到运行中的 dns_manager 进程")
-		} else {
-			fmt.Printf("找到 %d 个 dns_manager 进程:\n", len(processes))
-			fmt.Println(strings.Repeat("-", 80))
-			for _, proc := range processes {
-				fmt.Printf("PID: %d\n%s\n", proc.PID, proc.Command)
-				fmt.Println(strings.Repeat("-", 80))
-			}
-		}
-		os.Exit(0)
-	}
+func main() {
+	// 先确定输出语言，参数说明同样需要翻译
+	initLang(langFromArgs(os.Args[1:]))
 
-	// 查看详细信息
-	if *infoFlag {
-		info, err := getDaemonInfo()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "获取信息失败: %v\n", err)
-			os.Exit(1)
-		}
-		printDaemonInfo(info)
-		os.Exit(0)
+	// 子命令形式：dns_manager <命令> [参数]
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runSubcommand(os.Args[1], os.Args[2:]))
 	}
 
-	// 清理PID文件
-	if *cleanupFlag {
-		if err := cleanupPIDFile(); err != nil {
-			fmt.Println(err)
-		} else {
-			fmt.Println("PID文件检查完成，无需清理")
-		}
-		os.Exit(0)
+	// 兼容旧的参数形式，各参数对应到同名子命令
+	daemonMode := flag.Bool("daemon", false, tr("后台运行模式，直接开始监控（适合系统服务）"))
+	onceMode := flag.Bool("once", false, tr("执行一次更新后退出（适合 cron）"))
+	logFile := flag.Bool("log-file", false, tr("启用日志文件（daemon 模式默认启用）"))
+	stopFlag := flag.Bool("stop", false, tr("停止后台运行的守护进程"))
+	killFlag := flag.Bool("kill", false, tr("强制终止守护进程"))
+	statusFlag := flag.Bool("status", false, tr("查看守护进程状态"))
+	listFlag := flag.Bool("list", false, tr("列出所有dns_manager进程"))
+	infoFlag := flag.Bool("info", false, tr("查看守护进程详细信息"))
+	cleanupFlag := flag.Bool("cleanup", false, tr("清理无效的PID文件"))
+	manageFlag := flag.Bool("manage", false, tr("进入守护进程管理菜单"))
+	runUser := flag.String("user", "", tr("以 root 启动时，打开日志和PID文件后切换到该用户运行"))
+	runGroup := flag.String("group", "", tr("以 root 启动时切换到该用户组运行（默认使用 --user 的主用户组）"))
+	logsFlag := flag.Bool("logs", false, tr("查看日志文件（配合 -f 持续跟踪，-n 指定行数）"))
+	followFlag := flag.Bool("f", false, tr("与 --logs 配合使用，持续跟踪日志输出"))
+	linesFlag := flag.Int("n", 100, tr("与 --logs 配合使用，显示的日志行数"))
+	debugHTTPFlag := flag.Bool("debug-http", false, tr("记录所有 HTTP 请求的追踪信息（方法、URL、状态码、耗时、Cf-Ray，出错时记录响应内容）"))
+	langFlag := flag.String("lang", "", tr("输出语言: en 或 zh（默认根据 LANG 环境变量判断）"))
+	versionFlag := flag.Bool("version", false, tr("显示版本与构建信息"))
+	nonInteractive := flag.Bool("non-interactive", false, tr("配置不完整时直接报错退出，不进入配置向导"))
+	flag.Usage = func() {
+		printUsage()
+		fmt.Println()
+		fmt.Println(tr("旧参数:"))
+		flag.PrintDefaults()
 	}
-
-	// 强制终止守护进程
-	if *killFlag {
-		if err := killDaemon(); err != nil {
-			fmt.Fprintf(os.Stderr, "强制终止守护进程失败: %v\n", err)
-			os.Exit(1)
-		}
+	flag.Parse()
+	initLang(*langFlag)
+
+	switch {
+	case *versionFlag:
+		os.Exit(cmdVersion(outputText))
+	case *logsFlag:
+		os.Exit(cmdLogs(*linesFlag, *followFlag))
+	case *listFlag:
+		os.Exit(cmdList())
+	case *infoFlag:
+		os.Exit(cmdInfo())
+	case *cleanupFlag:
+		os.Exit(cmdCleanup())
+	case *killFlag:
+		os.Exit(cmdKill())
+	case *stopFlag:
+		os.Exit(cmdStop())
+	case *statusFlag:
+		os.Exit(cmdStatus())
+	case *manageFlag:
+		manageDaemonMenu()
 		os.Exit(0)
 	}
 
-	// 停止守护进程
-	if *stopFlag {
-		if err := stopDaemon(); err != nil {
-			fmt.Fprintf(os.Stderr, "停止守护进程失败: %v\n", err)
-			os.Exit(1)
-		}
-		os.Exit(0)
+	opts := runtimeOptions{
+		fileLog:     *logFile || *daemonMode,
+		console:     !*daemonMode,
+		debugHTTP:   *debugHTTPFlag,
+		interactive: !*nonInteractive,
 	}
 
-	// 查看状态
-	if *statusFlag {
-		pid, err := getPID()
-		if err != nil {
-			fmt.Println("守护进程未运行（未找到PID文件）")
-			os.Exit(0)
-		}
-		if isProcessRunning(pid) {
-			fmt.Printf("守护进程正在运行，PID: %d\n", pid)
-		} else {
-			fmt.Printf("守护进程未运行（PID文件存在但进程不存在，PID: %d）\n", pid)
-			fmt.Println("提示: 使用 --cleanup 清理无效的PID文件")
-		}
-		os.Exit(0)
+	// 根据参数选择运行模式
+	if *onceMode {
+		os.Exit(cmdOnce(opts, *runUser, *runGroup, onceReport{output: outputText}))
 	}
-
-	// 管理菜单
-	if *manageFlag {
-		manageDaemonMenu()
-		os.Exit(0)
+	if *daemonMode {
+		// 后台运行模式：自动daemon化
+		os.Exit(cmdRun(opts, true, *runUser, *runGroup))
 	}
 
-	// 初始化日志（daemon 模式默认启用文件日志）
-	enableFileLog := *logFile || *daemonMode
-	if err := initLogger(enableFileLog, !*daemonMode); err != nil {
-		fmt.Fprintf(os.Stderr, "初始化日志失败: %v\n", err)
-		os.Exit(1)
+	// 交互式模式（默认）
+	if err := initRuntime(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCodeFor(err))
 	}
 	defer globalLogger.Close()
 
-	// 初始化重载通道
-	reloadChan = make(chan bool, 1)
-
-	// 加载配置
-	config = LoadConfig()
-	if config.APIToken == "" || config.ZoneID == "" || config.RecordName == "" {
-		logInfo("检测到未配置，请先进行配置...")
-		interactiveConfig()
-		config = LoadConfig()
-	}
-
-	// 初始化客户端
-	var err error
-	cfClient, err = NewCloudflareClient(config.APIToken)
-	if err != nil {
-		logError("初始化 Cloudflare 客户端失败: %v", err)
-		os.Exit(1)
-	}
-
-	ipChecker = NewIPChecker()
-
-	// 根据参数选择运行模式
-	if *onceMode {
-		// 执行一次模式
-		runOnce()
-		os.Exit(0)
-	} else if *daemonMode {
-		// 后台运行模式：自动daemon化
-		// 如果不是守护进程，先转换为守护进程
-		if os.Getppid() != 1 {
-			if err := daemonize(); err != nil {
-				fmt.Fprintf(os.Stderr, "守护进程化失败: %v\n", err)
-				os.Exit(1)
-			}
-			// daemonize 会退出父进程，这里不会执行到
-			return
-		}
-		// 已经是守护进程，直接运行
-		runDaemon()
-	} else {
-		// 交互式模式（默认）
-		// 如果配置已存在，提示可以自动启动
-		if config.APIToken != "" && config.ZoneID != "" && config.RecordName != "" {
-			fmt.Println("\n提示: 配置已存在，可以使用以下命令自动后台运行：")
-			fmt.Println("  ./dns_manager --daemon")
-			fmt.Println("  或使用交互式菜单选择 '1. 开始监控'")
-			fmt.Println()
-		}
-		runInteractive()
+	// 如果配置已存在，提示可以自动启动
+	if app.Config().isComplete() {
+		fmt.Println(tr("\n提示: 配置已存在，可以使用以下命令自动后台运行："))
+		fmt.Println("  ./dns_manager run --detach")
+		fmt.Println(tr("  或使用交互式菜单选择 '1. 开始监控'"))
+		fmt.Println()
 	}
+	runInteractive()
 }
 
 // 交互式模式
 func runInteractive() {
-	fmt.Println("=== Cloudflare DNS 动态更新系统 ===")
+	fmt.Println(tr("=== Cloudflare DNS 动态更新系统 ==="))
 	fmt.Println()
 
 	// 显示主菜单
 	for {
 		showMainMenu()
-		choice := getUserInput("请选择操作 (1-8): ")
+		choice := getUserInput(tr("请选择操作 (1-9): "))
 
 		switch choice {
 		case "1":
@@ -193,29 +133,36 @@ func runInteractive() {
 		case "2":
 			checkCurrentIP()
 		case "3":
-			updateDNSNow()
+			updateDNSNow(true, "")
 		case "4":
-			viewDNSRecords()
+			manageRecordsMenu()
 		case "5":
 			interactiveConfig()
-			config = LoadConfig()
-			cfClient, _ = NewCloudflareClient(config.APIToken)
+			if cfg := LoadConfig(); cfg.isComplete() {
+				if err := app.ApplyConfig(cfg); err != nil {
+					fmt.Printf(tr("❌ 初始化 Cloudflare 客户端失败: %v\n"), err)
+				}
+			}
 		case "6":
 			startBackgroundDaemon()
 		case "7":
 			manageDaemonMenu()
 		case "8":
-			fmt.Println("感谢使用，再见！")
+			runNotifyTest(app.Config().Notifications)
+		case "9":
+			fmt.Println(tr("感谢使用，再见！"))
 			os.Exit(0)
 		default:
-			fmt.Println("无效的选择，请重新输入。")
+			fmt.Println(tr("无效的选择，请重新输入。"))
 		}
 	}
 }
 
 // 后台运行模式（适合系统服务）
 func runDaemon() {
+	config := app.Config()
 	logInfo("DNS 管理器已启动（后台模式）")
+	logInfo("版本: %s", getBuildInfo())
 	logInfo("配置信息: Zone ID=%s, 记录名称=%s, 记录类型=%s", 
 		config.ZoneID, config.RecordName, config.RecordType)
 
@@ -223,363 +170,836 @@ func runDaemon() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
-	// 立即执行一次
-	checkAndUpdate()
+	// 启动健康检查服务（可选）
+	if config.HealthListen != "" {
+		startHealthServer(config.HealthListen)
+	}
+
+	// 启动管理 API（可选）
+	if config.APIListen != "" {
+		startAPIServer(config.APIListen, config.APIAuthToken)
+	}
+
+	// 启动控制套接字，供 pause / resume 命令使用
+	if stopControl, err := startControlSocket(); err != nil {
+		logError("%v", err)
+	} else {
+		defer stopControl()
+	}
+
+	// 收到 SIGUSR1 时写出协程栈与堆快照
+	defer startDiagnosticsSignal()()
+
+	// 监听 Docker 容器事件（可选）
+	if config.Docker != nil {
+		go watchDocker(config.Docker.Socket)
+	}
+	// 监听 Kubernetes Service / Ingress（可选）
+	if config.Kubernetes != nil {
+		go watchKubernetes(config.Kubernetes)
+	}
 
-	// 定时任务（每5秒检测一次）
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	// 初始化运行状态
+	initDaemonState()
+	recordStartBinary()
+
+	// 检测间隔按IP稳定程度与失败情况自动调整
+	interval := newAdaptiveInterval(config)
+	timer := time.NewTimer(interval.base)
+	defer timer.Stop()
+
+	cycle := func() (bool, error) {
+		previousIP := app.CurrentIP()
+		updated, err := runDaemonCycle()
+		next := scheduleNext(interval, updated || app.CurrentIP() != previousIP, err)
+		health.markNextDelay(next)
+		resetTimer(timer, next)
+		return updated, err
+	}
+
+	// 检查本机能否获取记录类型需要的地址，以及记录名称是否属于配置的区域
+	checkAddressFamily(config)
+	checkRecordZones(config)
+
+	// 比较记录的实际状态与配置，然后立即执行一次
+	reportStartup(config)
+	cycle()
 
 	for {
 		select {
-		case <-ticker.C:
-			checkAndUpdate()
+		case <-timer.C:
+			// 离线期间只探测连接，恢复后立即执行检测
+			if !offline.ready() {
+				resetTimer(timer, offlineProbeInterval)
+				continue
+			}
+			cycle()
 
 		case sig := <-sigChan:
 			switch sig {
 			case syscall.SIGTERM, os.Interrupt:
 				logInfo("收到停止信号，正在退出...")
+				releaseLease(app.Config(), app.Client())
+				hooks := app.Config().hooksConfig()
+				runHook(hooks, "stop", hooks.OnStop)
+				waitNotifications(5 * time.Second)
+				waitTracing(5 * time.Second)
 				return
 			case syscall.SIGHUP:
+				// 可执行文件已升级时重新执行新版本（同时加载新配置），失败则只重新加载配置
+				if path, upgraded := binaryUpgraded(); upgraded {
+					if err := reexecDaemon(path); err != nil {
+						logError("%v，继续运行当前版本", err)
+					}
+				}
 				logInfo("收到重载信号，重新加载配置...")
 				reloadConfig()
+				interval = newAdaptiveInterval(app.Config())
+				resetTimer(timer, interval.reset())
 			}
 
 		case <-reloadChan:
 			logInfo("重新加载配置...")
 			reloadConfig()
+			interval = newAdaptiveInterval(app.Config())
+			resetTimer(timer, interval.reset())
+
+		case req := <-apiUpdateChan:
+			if req.summary {
+				logInfo("once 命令检测到守护进程正在运行，由守护进程执行本次更新")
+			} else {
+				logInfo("收到管理 API 更新请求")
+			}
+			if req.force {
+				app.SetCurrentIP("")
+			}
+			start := time.Now()
+			updated, err := cycle()
+			result := apiUpdateResult{Updated: updated, IP: app.CurrentIP()}
+			if err != nil {
+				result.Error = redactSecrets(err.Error())
+			}
+			if req.summary {
+				summary := lastCycle.summary(app.Config(), start, err)
+				result.Summary = &summary
+			}
+			req.reply <- result
+
+		case <-resumeChan:
+			// 维护模式结束，立即补上暂停期间的变化
+			cycle()
+
+		case req := <-agentReportChan:
+			req.reply <- reconcileAgent(req)
+
+		case <-dockerSyncChan:
+			syncDockerRecords()
+
+		case <-kubernetesSyncChan:
+			syncKubernetesRecords()
 		}
 	}
 }
 
+// startHookFired 启动钩子是否已执行（首次检测更新成功后执行一次）
+var startHookFired bool
+
+// runDaemonCycle 执行一次检测周期并记录运行状态
+func runDaemonCycle() (bool, error) {
+	previousIP := app.CurrentIP()
+	updated, err := lockedCheckAndUpdate()
+	health.markCycle()
+	recordCycle(updated, err)
+	policy.observe(err)
+
+	currentIP := app.CurrentIP()
+	switch {
+	case err != nil:
+		appendHistory(HistoryEntry{Type: "error", IP: currentIP, Message: redactSecrets(err.Error()), Category: errorCategory(err), Requests: apiResponseMetas(err)})
+		publishEvent(StreamEvent{Type: StreamError, Record: app.Config().RecordName, IP: currentIP, Message: err.Error()})
+	case updated:
+		appendHistory(HistoryEntry{Type: "updated", IP: currentIP, Message: previousIP + " -> " + currentIP, Geo: geo.cached(currentIP)})
+	case currentIP != previousIP:
+		appendHistory(HistoryEntry{Type: "ip_changed", IP: currentIP, Message: previousIP + " -> " + currentIP, Geo: geo.cached(currentIP)})
+	}
+
+	// 公网IP变化后容器与 Kubernetes 记录同样指向新IP
+	if currentIP != previousIP {
+		syncDockerRecords()
+		syncKubernetesRecords()
+	}
+
+	if err == nil && !startHookFired {
+		startHookFired = true
+		hooks := app.Config().hooksConfig()
+		runHook(hooks, "start", hooks.OnStart)
+	}
+
+	return updated, err
+}
+
 // 重新加载配置
 func reloadConfig() {
-	newConfig := LoadConfig()
-	if newConfig.APIToken == "" || newConfig.ZoneID == "" || newConfig.RecordName == "" {
+	newConfig, err := loadConfigFile()
+	if err != nil {
+		logError("%v，保持使用旧配置", err)
+		return
+	}
+	if !newConfig.isComplete() {
 		logError("新配置无效，保持使用旧配置")
 		return
 	}
+	checkAddressFamily(newConfig)
 
-	// 如果配置有变化，重新初始化客户端
-	if newConfig.APIToken != config.APIToken {
-		var err error
-		cfClient, err = NewCloudflareClient(newConfig.APIToken)
-		if err != nil {
-			logError("重新初始化 Cloudflare 客户端失败: %v", err)
-			return
-		}
+	// 等待进行中的检测周期结束，再同时替换配置和客户端；令牌变化时先创建新客户端，失败则保持旧配置
+	app.cycleMu.Lock()
+	defer app.cycleMu.Unlock()
+
+	tokenChanged := newConfig.APIToken != app.Config().APIToken
+	httpChanged := !reflect.DeepEqual(newConfig.HTTP, app.Config().HTTP)
+	if err := app.ApplyConfig(newConfig); err != nil {
+		logError("重新初始化 Cloudflare 客户端失败: %v", err)
+		return
+	}
+	if tokenChanged || httpChanged {
 		logInfo("Cloudflare 客户端已重新初始化")
 	}
+	if httpChanged {
+		app.SetIPChecker(newIPCheckerFor(newConfig))
+	}
 
-	config = newConfig
+	registerAgentSecrets(newConfig.Agents)
+	initNotifiers(newConfig.Notifications, newConfig.notifyPolicy().EscalateTo, newConfig.HTTP)
+	initTracing(newConfig.Tracing)
+	initNotifyPolicy(newConfig.notifyPolicy())
 	logInfo("配置已重新加载")
 }
 
-// 执行一次模式（适合 cron）
-func runOnce() {
+// 执行一次模式（适合 cron），返回本次检测周期的错误
+func runOnce() error {
 	logInfo("执行一次性 DNS 更新")
-	checkAndUpdate()
+	logInfo("版本: %s", getBuildInfo())
+	checkAddressFamily(app.Config())
+	checkRecordZones(app.Config())
+	_, err := lockedCheckAndUpdate()
+	policy.observe(err)
+	releaseLease(app.Config(), app.Client())
+	waitNotifications(10 * time.Second)
+	waitTracing(5 * time.Second)
 	logInfo("更新完成")
+	return err
 }
 
 func showMainMenu() {
-	fmt.Println("\n========== 主菜单 ==========")
-	fmt.Println("1. 开始监控 (每5秒自动检测并更新)")
-	fmt.Println("2. 检查当前公网IP")
-	fmt.Println("3. 立即更新DNS记录")
-	fmt.Println("4. 查看DNS记录")
-	fmt.Println("5. 配置设置")
-	fmt.Println("6. 启动后台守护进程 (自动后台运行)")
-	fmt.Println("7. 守护进程管理")
-	fmt.Println("8. 退出")
+	fmt.Println(tr("\n========== 主菜单 =========="))
+	fmt.Printf(tr("1. 开始监控 (每%s自动检测并更新)\n"), checkInterval)
+	fmt.Println(tr("2. 检查当前公网IP"))
+	fmt.Println(tr("3. 立即更新DNS记录"))
+	fmt.Println(tr("4. DNS记录管理"))
+	fmt.Println(tr("5. 配置设置"))
+	fmt.Println(tr("6. 启动后台守护进程 (自动后台运行)"))
+	fmt.Println(tr("7. 守护进程管理"))
+	fmt.Println(tr("8. 测试通知"))
+	fmt.Println(tr("9. 退出"))
 	fmt.Println("===========================")
-	fmt.Println("提示: 使用 --daemon 参数可直接后台运行")
-	fmt.Println("提示: 使用 --manage 参数进入守护进程管理")
+	fmt.Println(tr("提示: 使用 run --detach 命令可直接后台运行"))
+	fmt.Println(tr("提示: 使用 manage 命令进入守护进程管理"))
 	fmt.Println("===========================")
 }
 
 func startMonitoring() {
-	if running {
-		fmt.Println("监控已在运行中...")
+	config := app.Config()
+	if !app.startRunning() {
+		fmt.Println(tr("监控已在运行中..."))
 		return
 	}
+	defer app.stopRunning()
 
-	fmt.Println("\n开始监控模式...")
-	fmt.Printf("配置信息:\n")
+	fmt.Println(tr("\n开始监控模式..."))
+	fmt.Printf(tr("配置信息:\n"))
 	fmt.Printf("  Zone ID: %s\n", config.ZoneID)
-	fmt.Printf("  记录名称: %s\n", config.RecordName)
-	fmt.Printf("  记录类型: %s\n", config.RecordType)
-	fmt.Printf("  检测间隔: 每5秒\n")
-	fmt.Println("\n按 Ctrl+C 停止监控")
-	fmt.Println("提示: 如需后台运行，请使用 --daemon 参数或配置为系统服务")
+	fmt.Printf(tr("  记录名称: %s\n"), config.RecordName)
+	fmt.Printf(tr("  记录类型: %s\n"), config.RecordType)
+	fmt.Printf(tr("  检测间隔: 每%s\n"), checkInterval)
+	fmt.Println(tr("\n按 Ctrl+C 停止监控"))
+	fmt.Println(tr("提示: 如需后台运行，请使用 run --detach 命令或配置为系统服务"))
 	fmt.Println()
 
-	running = true
-
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// 立即执行一次
-	checkAndUpdate()
+	interval := newAdaptiveInterval(config)
+	timer := time.NewTimer(interval.base)
+	defer timer.Stop()
 
-	// 定时任务（每5秒检测一次）
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	cycle := func() {
+		previousIP := app.CurrentIP()
+		updated, err := safeCheckAndUpdate()
+		policy.observe(err)
+		resetTimer(timer, scheduleNext(interval, updated || app.CurrentIP() != previousIP, err))
+	}
+
+	// 立即执行一次
+	cycle()
 
 	for {
 		select {
-		case <-ticker.C:
-			checkAndUpdate()
+		case <-timer.C:
+			if !offline.ready() {
+				resetTimer(timer, offlineProbeInterval)
+				continue
+			}
+			cycle()
 		case <-sigChan:
-			fmt.Println("\n\n监控已停止")
-			running = false
+			fmt.Println(tr("\n\n监控已停止"))
 			return
 		}
 	}
 }
 
-func checkAndUpdate() {
+// checkAndUpdate 检测公网IP并在变化时更新DNS记录
+// 返回是否执行了DNS更新，以及本周期的错误（无错误表示周期正常完成）
+func checkAndUpdate() (updated bool, err error) {
+	config := app.Config()
+	currentIP := app.CurrentIP()
+	r := newReconciler()
+	// 认证失败时诊断令牌的问题，以处理建议代替 API 响应内容
+	defer func() {
+		err = diagnoseAuthError(app.Client(), config.ZoneID, err)
+	}()
+	// 使用独立IP来源的记录不受主IP检测结果影响，主流程提前返回时同样同步
+	if len(config.pinnedGroups()) > 0 {
+		defer func() {
+			pinnedUpdated, pinnedErr := syncPinnedRecords(r, config)
+			updated = updated || pinnedUpdated
+			if err == nil {
+				err = pinnedErr
+			}
+		}()
+	}
 	logInfo("正在检查公网IP...")
-
-	// 获取IP（带服务信息）
-	var ip string
-	var serviceName string
-	var err error
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		ip, serviceName, err = ipChecker.GetPublicIPWithService()
-		if err == nil {
-			break
-		}
-		if i < maxRetries-1 {
-			logError("获取公网IP失败 (尝试 %d/%d): %v，1秒后重试...", i+1, maxRetries, err)
-			time.Sleep(1 * time.Second)
-		}
+	if config.ObserveOnly {
+		logInfo("观察模式：只比较DNS记录与公网IP，不修改任何记录")
+	} else if r.DryRun && !dryRun {
+		logInfo("维护模式中：只记录将要执行的操作，不修改DNS记录")
 	}
 
+	ip, serviceName, err := r.Detect()
 	if err != nil {
 		logError("获取公网IP失败: %v", err)
-		return
+		return false, err
 	}
 
 	logInfo("当前公网IP: %s (来源: %s)", ip, serviceName)
+	lastCycle.detected(ip, serviceName, r.DryRun)
+	offline.observe(ip)
+	publishEvent(StreamEvent{Type: StreamIPDetected, Record: config.RecordName, IP: ip, Source: serviceName})
+
+	// 刷新成员登记（与写入租约无关，待命实例同样登记）
+	if config.Fleet != nil && !r.DryRun {
+		fleet.heartbeat(config, app.Client(), ip)
+	}
+
+	// 启用写入租约时，只有持有租约的实例修改记录
+	if config.Lease != nil && !r.DryRun {
+		active, acquired, err := checkLease(config, app.Client())
+		if err != nil {
+			logError("%v", err)
+			return false, err
+		}
+		if !active {
+			return false, nil
+		}
+		if acquired {
+			// 刚接管租约，之前由其他实例维护的记录需要重新核对
+			currentIP = ""
+		}
+	}
+
+	// 本机服务持续不可用时撤下本机记录，恢复后重新加入
+	rejoin := false
+	if config.ServiceCheck != nil && !r.DryRun {
+		proceed, rejoined, err := checkMembership(config, ip, currentIP)
+		if err != nil || !proceed {
+			return false, err
+		}
+		if rejoined {
+			rejoin = true
+			currentIP = ""
+		}
+	}
+
+	// 权重：配置源站池时维护池中的本机源站，否则权重为 0 时撤下本机记录
+	if !r.DryRun {
+		if config.LoadBalancerPool != nil {
+			if _, err := checkPoolOrigin(config, ip); err != nil {
+				return false, err
+			}
+		} else if config.Weight != nil {
+			proceed, rejoined, err := checkWeight(config, ip, currentIP)
+			if err != nil || !proceed {
+				return false, err
+			}
+			if rejoined {
+				rejoin = true
+				currentIP = ""
+			}
+		}
+	}
+
+	// 降低TTL的记录到期后恢复（只处理已指向当前IP的记录）
+	if config.TTLStrategy != nil && !r.DryRun {
+		restoreTTLs(config, app.Client(), ip)
+	}
+
+	// 记录已解析到检测到的IP时（如 --once 每次启动时）按IP未变化处理，不调用 API 读取记录
+	if ip != currentIP && !rejoin && config.DoHPrecheck != nil && dohPrecheck(config, ip) {
+		logInfo("DoH 预检查: 全部记录已解析到 %s", ip)
+		if !r.DryRun {
+			app.SetCurrentIP(ip)
+		}
+		currentIP = ip
+	}
 
 	// 如果IP没有变化，跳过更新
 	if ip == currentIP {
 		logInfo("IP未变化 (%s)，跳过更新", ip)
-		return
+		// 按间隔检查记录是否被外部修改
+		if config.Drift != nil {
+			drift.check(config, r, ip)
+		}
+		if config.RecordAudit != nil && recordAudit.due(config.RecordAudit.interval()) {
+			runScheduledAudit(config, r, ip)
+		}
+		// 上次更新防火墙失败时重试（已放行当前IP时直接返回）
+		if len(config.Firewalls) > 0 && !r.DryRun {
+			updateFirewalls(config, currentIP, ip)
+		}
+		if config.Consul != nil && !r.DryRun {
+			updateConsul(config, ip)
+		}
+		if config.SplitHorizon != nil && !r.DryRun {
+			updateSplitHorizon(config, ip)
+		}
+		if config.PTR != nil && !r.DryRun {
+			updatePTR(config, app.Client(), ip)
+		}
+		return false, nil
 	}
 
 	// IP发生变化，需要确认（避免不同服务返回不同IP导致的误判）
 	logInfo("检测到IP变化 (%s -> %s)，正在确认...", currentIP, ip)
-	
-	// 等待3秒后再次检测确认
-	time.Sleep(3 * time.Second)
-	
-	// 再次获取IP进行确认
-	confirmIP, confirmService, err := ipChecker.GetPublicIPWithService()
-	if err != nil {
-		logError("确认IP时失败: %v，取消更新", err)
-		return
-	}
-
-	// 如果确认的IP与第一次检测的不同，说明可能是服务不稳定，取消更新
-	if confirmIP != ip {
-		logError("IP确认失败: 第一次检测到 %s，确认时检测到 %s (来源: %s)，可能是服务不稳定，取消更新", 
-			ip, confirmIP, confirmService)
-		return
+	if err := r.confirmFor(config.ipTargets()).ConfirmChange(ip); err != nil {
+		return false, err
 	}
 
 	// IP确认一致，检查当前DNS记录（支持多机器场景）
 	logInfo("IP变化已确认 (%s -> %s)，正在检查DNS记录...", currentIP, ip)
-	
-	// 获取所有匹配的DNS记录
-	allRecords, err := cfClient.GetAllDNSRecords(config.ZoneID, config.RecordName, config.RecordType)
-	if err != nil {
-		logInfo("未找到现有DNS记录，将创建新记录")
-	} else {
-		logInfo("找到 %d 个DNS记录", len(allRecords))
-		// 检查是否已有指向本机IP的记录
-		hasCurrentIP := false
-		for _, record := range allRecords {
-			if record.Content == ip {
-				hasCurrentIP = true
-				logInfo("已存在指向本机IP (%s) 的DNS记录，无需更新", ip)
-				currentIP = ip
-				return
+	geoInfo := annotateIP(config, ip)
+	publishEvent(StreamEvent{Type: StreamIPChanged, Record: config.RecordName, IP: ip, OldIP: currentIP, Source: serviceName})
+
+	// 新IP的国家或 ASN 与以往不同且需要确认时，确认前保持未同步状态
+	if !rejoin && anomaly.hold(config, ip, geoInfo) {
+		return false, nil
+	}
+
+	// 距上次写入不足 min_update_interval 时暂缓更新，IP保持未同步状态，下限过后的周期再写入
+	// 重新加入记录集不是IP变化，不受该限制
+	if !rejoin && damper.hold(config.minUpdateInterval(), config.RecordName, currentIP, ip) {
+		return false, nil
+	}
+
+	// 各记录互不依赖，并行同步；同一区域的记录先一次读取，不再逐个查询
+	var results []SyncResult
+	targets := config.recordTargets()
+	r.Prefetch(config.ipTargets())
+	if len(config.Subdomains) > 0 {
+		// 主记录与子域名在同一个批量请求中原子更新，其他记录照常并行同步
+		group, aliases := config.subdomainTargets()
+		if config.Drift != nil {
+			group = drift.filter(group)
+		}
+		aliasTarget := ""
+		if len(aliases) > 0 {
+			if aliasTarget, err = app.Client().recordName(config.ZoneID, config.RecordName); err != nil {
+				logError("%v", err)
+				return false, err
 			}
 		}
-		if !hasCurrentIP {
-			logInfo("未找到指向本机IP的记录，将创建或更新记录")
+		others := targets[1:]
+		if config.Drift != nil {
+			others = drift.filter(others)
 		}
+		results = r.SyncGroup(group, aliases, aliasTarget, ip, currentIP, serviceName)
+		results = append(results, r.SyncAll(others, ip, currentIP, serviceName, config.reconcileWorkers())...)
+	} else {
+		if config.Drift != nil {
+			targets = drift.filter(targets)
+		}
+		results = r.SyncAll(targets, ip, currentIP, serviceName, config.reconcileWorkers())
 	}
-	
-	// 使用更新或创建逻辑（支持多机器：每个机器维护自己的A记录）
-	logInfo("正在更新或创建DNS记录: %s -> %s", config.RecordName, ip)
-	
-	// 获取默认TTL（如果记录存在，使用现有记录的TTL；否则使用3600）
-	defaultTTL := 3600
-	if len(allRecords) > 0 {
-		defaultTTL = allRecords[0].TTL
+	if config.Drift != nil && !r.DryRun {
+		drift.synced(results)
 	}
-	
-	updateSuccess := false
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		// 使用更新或创建逻辑（支持多机器：每个机器维护自己的A记录）
-		// 如果存在指向旧IP的记录，会更新它；否则创建新记录
-		lastErr = cfClient.UpdateOrCreateDNSRecord(config.ZoneID, config.RecordName, config.RecordType, ip, defaultTTL, currentIP)
-		if lastErr == nil {
-			updateSuccess = true
-			break
+	lastCycle.synced(ip, results)
+
+	updated = false
+	var failures []string
+	var errs []error
+	applyFailed := false
+	for _, result := range results {
+		name := result.Target.Name
+		if result.Applied {
+			updated = true
+			damper.markUpdated()
+			publishEvent(StreamEvent{Type: StreamDNSUpdated, Record: name, IP: ip, OldIP: currentIP, Source: serviceName})
+			notify(NotifyEvent{
+				Type:   EventDNSUpdated,
+				Title:  tr("DNS记录已更新"),
+				Record: name,
+				OldIP:  currentIP,
+				NewIP:  ip,
+				Geo:    geoInfo,
+			})
 		}
-		
-		if i < maxRetries-1 {
-			logError("DNS更新/创建失败 (尝试 %d/%d): %v，2秒后重试...", i+1, maxRetries, lastErr)
-			time.Sleep(2 * time.Second)
+		if result.Err != nil {
+			// 记录已写入但验证失败时不再重复提交，其余失败在下个周期重试
+			applyFailed = applyFailed || !result.Applied
+			failures = append(failures, name+": "+result.Err.Error())
+			errs = append(errs, result.Err)
 		}
 	}
 
-	if !updateSuccess {
-		logError("DNS更新/创建失败: %v", lastErr)
-		return
-	}
-
-	// 验证记录是否存在
-	verifyRecords, err := cfClient.GetAllDNSRecords(config.ZoneID, config.RecordName, config.RecordType)
-	if err != nil {
-		logError("验证DNS记录失败: %v，但更新可能已成功", err)
-	} else {
-		// 检查是否包含本机IP
-		found := false
-		for _, record := range verifyRecords {
-			if record.Content == ip {
-				found = true
-				break
-			}
-		}
-		if found {
-			logInfo("DNS记录验证成功: %s 现在包含IP %s (共 %d 个A记录)", config.RecordName, ip, len(verifyRecords))
-		} else {
-			logError("DNS记录验证失败: 未找到指向 %s 的记录", ip)
+	if config.ObserveOnly {
+		// 记录已由人工或其他系统指向新IP后按IP未变化处理，不再每个周期读取记录
+		if observer.report(config, ip, results) {
+			app.SetCurrentIP(ip)
+			anomaly.accepted(config, ip, geoInfo)
 		}
+		return false, nil
+	}
+	if r.DryRun {
+		return false, nil
+	}
+	if config.CachePurge != nil {
+		purgeAfterSync(config, app.Client(), results)
+	}
+	if len(config.Firewalls) > 0 {
+		updateFirewalls(config, currentIP, ip)
+	}
+	if config.Consul != nil {
+		updateConsul(config, ip)
+	}
+	if config.SplitHorizon != nil {
+		updateSplitHorizon(config, ip)
+	}
+	if config.PTR != nil {
+		updatePTR(config, app.Client(), ip)
+	}
+	if !applyFailed {
+		app.SetCurrentIP(ip)
+		anomaly.accepted(config, ip, geoInfo)
+	}
+	switch {
+	case len(failures) == 0:
+		return updated, nil
+	case len(results) == 1:
+		return updated, errs[0]
+	default:
+		msg := fmt.Sprintf(tr("%d/%d 个记录同步失败: %s"), len(failures), len(results), strings.Join(failures, "; "))
+		return updated, &joinedError{msg: msg, errs: errs}
 	}
-
-	logInfo("DNS记录已成功更新/创建: %s -> %s", config.RecordName, ip)
-	currentIP = ip
 }
 
 func checkCurrentIP() {
-	fmt.Println("\n正在检查当前公网IP...")
+	ipChecker := app.IPChecker()
+	fmt.Println(tr("\n正在检查当前公网IP..."))
 	ip, service, err := ipChecker.GetPublicIPWithService()
 	if err != nil {
-		fmt.Printf("❌ 获取失败: %v\n", err)
+		fmt.Printf(tr("❌ 获取失败: %v\n"), err)
 		return
 	}
-	fmt.Printf("当前公网IP: %s (来源: %s)\n", ip, service)
+	fmt.Printf(tr("当前公网IP: %s (来源: %s)\n"), ip, service)
 }
 
-func updateDNSNow() {
-	fmt.Println("\n正在获取当前公网IP...")
-	ip, service, err := ipChecker.GetPublicIPWithService()
+// updateDNSNow 立即检测公网IP并更新DNS记录；manualIP 非空时跳过检测，直接使用该IP；
+// confirmChanges 为 true 时先显示记录差异并要求确认
+func updateDNSNow(confirmChanges bool, manualIP string) error {
+	config := app.Config()
+	currentIP := app.CurrentIP()
+	r := newReconciler()
+	r.Retries = 1
+
+	if manualIP == "" {
+		fmt.Println(tr("\n正在获取当前公网IP..."))
+	}
+	ip, service, err := detectForUpdate(r, manualIP)
 	if err != nil {
-		fmt.Printf("❌ 获取公网IP失败: %v\n", err)
-		return
+		fmt.Printf(tr("❌ 获取公网IP失败: %v\n"), err)
+		return err
 	}
 
-	fmt.Printf("当前公网IP: %s (来源: %s)\n", ip, service)
-	fmt.Printf("正在更新DNS记录 %s...\n", config.RecordName)
+	fmt.Printf(tr("当前公网IP: %s (来源: %s)\n"), ip, service)
 
-	err = cfClient.UpdateDNSRecord(config.ZoneID, config.RecordName, config.RecordType, ip)
+	desired := r.Desired(ip, currentIP)
+	desired.Exclusive = true
+	plan, err := r.Plan(desired)
 	if err != nil {
-		fmt.Printf("❌ 更新失败: %v\n", err)
-		return
+		fmt.Printf(tr("❌ 获取失败: %v\n"), err)
+		return err
+	}
+	if plan.Action == planNone {
+		fmt.Printf(tr("✓ DNS记录已是最新: %s -> %s\n"), config.RecordName, ip)
+		app.SetCurrentIP(ip)
+		return nil
+	}
+
+	fmt.Printf(tr("\n将对 %s (%s) 进行以下更改:\n"), config.RecordName, config.RecordType)
+	printRecordDiff(plan.Observed, plan.Result())
+	if confirmChanges && !confirm(tr("确认应用以上更改？")) {
+		return nil
 	}
 
-	fmt.Printf("✓ DNS记录已成功更新: %s -> %s\n", config.RecordName, ip)
-	currentIP = ip
+	fmt.Printf(tr("正在更新DNS记录 %s...\n"), config.RecordName)
+	if err := r.Apply(plan, tr("手动更新: ")+service); err != nil {
+		fmt.Printf(tr("❌ 更新失败: %v\n"), err)
+		return err
+	}
+	if r.DryRun {
+		fmt.Println(tr("dry-run 模式，未修改任何记录"))
+		return nil
+	}
+
+	fmt.Printf(tr("✓ DNS记录已成功更新: %s -> %s\n"), config.RecordName, ip)
+	app.SetCurrentIP(ip)
+	return nil
 }
 
-func viewDNSRecords() {
-	fmt.Println("\n正在获取DNS记录...")
-	records, err := cfClient.ListDNSRecords(config.ZoneID, config.RecordName)
+// detectForUpdate 检测公网IP；manualIP 非空时直接使用（由调用方校验），不访问检测服务
+func detectForUpdate(r *Reconciler, manualIP string) (ip, source string, err error) {
+	if manualIP == "" {
+		return r.Detect()
+	}
+	return manualIP, tr("手动指定"), nil
+}
+
+// UpdateResult 立即更新的结果，供 --output json 输出
+type UpdateResult struct {
+	Success bool   `json:"success"`
+	Record  string `json:"record"`
+	Type    string `json:"type"`
+	IP      string `json:"ip,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Action  string `json:"action,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// updateDNSResult 与 updateDNSNow 相同的更新流程，不输出过程信息，返回结构化结果
+func updateDNSResult(manualIP string) UpdateResult {
+	config := app.Config()
+	currentIP := app.CurrentIP()
+	r := newReconciler()
+	r.Retries = 1
+	result := UpdateResult{
+		Record: config.RecordName,
+		Type:   config.RecordType,
+		DryRun: r.DryRun,
+	}
+
+	ip, service, err := detectForUpdate(r, manualIP)
 	if err != nil {
-		fmt.Printf("❌ 获取失败: %v\n", err)
-		return
+		result.Error = redactSecrets(fmt.Sprintf(tr("获取公网IP失败: %v"), err))
+		return result
+	}
+	result.IP = ip
+	result.Source = service
+
+	desired := r.Desired(ip, currentIP)
+	desired.Exclusive = true
+	plan, err := r.Plan(desired)
+	if err == nil {
+		result.Action = plan.Action
+		err = r.Apply(plan, tr("手动更新: ")+service)
+	}
+	if err != nil {
+		result.Error = redactSecrets(fmt.Sprintf(tr("更新失败: %v"), err))
+		return result
+	}
+
+	result.Success = true
+	if !r.DryRun {
+		app.SetCurrentIP(ip)
+	}
+	return result
+}
+
+// viewDNSRecords 列出配置的DNS记录；设置了筛选条件时列出区域内符合条件的记录
+func viewDNSRecords(filter recordFilter) error {
+	fmt.Println(tr("\n正在获取DNS记录..."))
+	records, err := listFilteredRecords(filter)
+	if err != nil {
+		fmt.Printf(tr("❌ 获取失败: %v\n"), err)
+		return err
 	}
 
 	if len(records) == 0 {
-		fmt.Println("未找到匹配的DNS记录")
-		return
+		fmt.Println(tr("未找到匹配的DNS记录"))
+		return nil
 	}
 
-	fmt.Println("\nDNS记录列表:")
+	fmt.Println(tr("\nDNS记录列表:"))
 	fmt.Println(strings.Repeat("-", 80))
-	fmt.Printf("%-30s %-10s %-20s %-10s\n", "名称", "类型", "内容", "TTL")
+	fmt.Printf("%-30s %-10s %-20s %-10s\n", tr("名称"), tr("类型"), tr("内容"), "TTL")
 	fmt.Println(strings.Repeat("-", 80))
 	for _, record := range records {
 		fmt.Printf("%-30s %-10s %-20s %-10d\n", 
 			record.Name, record.Type, record.Content, record.TTL)
 	}
 	fmt.Println(strings.Repeat("-", 80))
+	return nil
 }
 
 func interactiveConfig() {
-	fmt.Println("\n========== 配置向导 ==========")
-	
-	// API Token
-	fmt.Println("\n1. Cloudflare API Token")
-	fmt.Println("   请在 Cloudflare 控制台创建 API Token")
-	fmt.Println("   权限: Zone - DNS - Edit")
-	fmt.Println("   访问: 选择你的域名")
-	
-	token := getUserInput("请输入 API Token: ")
-	if token == "" {
-		fmt.Println("API Token 不能为空")
-		return
+	fmt.Println(tr("\n========== 配置向导 =========="))
+
+	// 已有完整配置时在其基础上修改：保留未在向导中出现的设置，可以只修改其中一项
+	cfg := &Config{RecordType: "A"}
+	steps := []int{wizardToken, wizardZoneID, wizardRecordName, wizardRecordType}
+	editing := false
+	if current, err := loadConfigFile(); err == nil && current.isComplete() {
+		cfg, editing = current, true
+		var ok bool
+		if steps, ok = chooseWizardSteps(cfg); !ok {
+			fmt.Println(tr("已取消，配置未改变"))
+			return
+		}
 	}
 
-	// Zone ID
-	fmt.Println("\n2. Zone ID")
-	fmt.Println("   在 Cloudflare 域名概览页面右侧可以找到 Zone ID")
-	zoneID := getUserInput("请输入 Zone ID: ")
-	if zoneID == "" {
-		fmt.Println("Zone ID 不能为空")
-		return
+	for _, step := range steps {
+		if !runWizardStep(cfg, step, editing) {
+			return
+		}
 	}
 
-	// 记录名称
-	fmt.Println("\n3. DNS 记录名称")
-	fmt.Println("   例如: subdomain.example.com 或 @ (表示根域名)")
-	recordName := getUserInput("请输入记录名称: ")
-	if recordName == "" {
-		fmt.Println("记录名称不能为空")
+	client, err := NewCloudflareClient(cfg.APIToken)
+	if err != nil {
+		fmt.Printf(tr("❌ 初始化 Cloudflare 客户端失败: %v\n"), err)
 		return
 	}
-
-	// 记录类型
-	fmt.Println("\n4. DNS 记录类型")
-	fmt.Println("   通常为 A (IPv4) 或 AAAA (IPv6)")
-	recordType := getUserInput("请输入记录类型 (默认: A): ")
-	if recordType == "" {
-		recordType = "A"
+	// "@" 转换为区域名称后保存，查询记录时才能匹配
+	if cfg.RecordName == apexName {
+		if name, err := client.recordName(cfg.ZoneID, cfg.RecordName); err == nil {
+			fmt.Printf(tr("   @ 表示根域名: %s\n"), name)
+			cfg.RecordName = name
+		} else {
+			fmt.Printf(tr("   ⚠ 无法获取区域名称，保存为 @，运行时再转换: %v\n"), err)
+		}
 	}
 
-	// 保存配置
-	config = &Config{
-		APIToken:   token,
-		ZoneID:     zoneID,
-		RecordName: recordName,
-		RecordType: recordType,
+	// 保存前检查网络、令牌、区域与现有记录，有未通过的检查时确认后才保存
+	fmt.Println(tr("\n正在检查配置..."))
+	if printSetupProbes(runSetupProbes(client, newIPCheckerFor(cfg), cfg)) {
+		confirm := getUserInput(tr("\n部分检查未通过，仍要保存配置？(y/N): "))
+		if confirm != "y" && confirm != "Y" {
+			fmt.Println(tr("已取消，配置未保存"))
+			return
+		}
 	}
 
-	if err := SaveConfig(config); err != nil {
-		fmt.Printf("❌ 保存配置失败: %v\n", err)
+	if err := SaveConfig(cfg); err != nil {
+		fmt.Printf(tr("❌ 保存配置失败: %v\n"), err)
 		return
 	}
 
-	fmt.Println("\n✓ 配置已保存！")
+	fmt.Println(tr("\n✓ 配置已保存！"))
+}
+
+// 配置向导的步骤
+const (
+	wizardToken = iota + 1
+	wizardZoneID
+	wizardRecordName
+	wizardRecordType
+)
+
+// chooseWizardSteps 显示当前配置，选择只修改其中一项或逐项修改；输入 0 时返回 false
+func chooseWizardSteps(cfg *Config) ([]int, bool) {
+	fmt.Println(tr("当前配置:"))
+	fmt.Printf("  1. API Token: %s\n", maskSecret(cfg.APIToken))
+	fmt.Printf("  2. Zone ID: %s\n", cfg.ZoneID)
+	fmt.Printf(tr("  3. 记录名称: %s\n"), cfg.RecordName)
+	fmt.Printf(tr("  4. 记录类型: %s\n"), cfg.RecordType)
+	for {
+		choice := getUserInput(tr("选择要修改的项 (1-4)，直接回车逐项修改，0 取消: "))
+		switch choice {
+		case "":
+			return []int{wizardToken, wizardZoneID, wizardRecordName, wizardRecordType}, true
+		case "0":
+			return nil, false
+		case "1", "2", "3", "4":
+			step, _ := strconv.Atoi(choice)
+			return []int{step}, true
+		}
+		fmt.Println(tr("无效的选择，请重新输入。"))
+	}
+}
+
+// wizardInput 读取输入；已有值时提示中显示该值（令牌只显示前几位），直接回车保留
+func wizardInput(prompt, current, shown string) string {
+	if current == "" {
+		return getUserInput(prompt)
+	}
+	prompt = strings.TrimSuffix(prompt, ": ") + fmt.Sprintf(tr(" [回车保留 %s]: "), shown)
+	if input := getUserInput(prompt); input != "" {
+		return input
+	}
+	return current
+}
+
+// runWizardStep 执行向导的一个步骤并写入 cfg；输入无效时返回 false
+func runWizardStep(cfg *Config, step int, editing bool) bool {
+	switch step {
+	case wizardToken:
+		fmt.Println("\n1. Cloudflare API Token")
+		fmt.Println(tr("   请在 Cloudflare 控制台创建 API Token"))
+		fmt.Println(tr("   权限: Zone - DNS - Edit"))
+		fmt.Println(tr("   访问: 选择你的域名"))
+		cfg.APIToken = wizardInput(tr("请输入 API Token: "), cfg.APIToken, maskSecret(cfg.APIToken))
+		if cfg.APIToken == "" {
+			fmt.Println(tr("API Token 不能为空"))
+			return false
+		}
+
+	case wizardZoneID:
+		fmt.Println("\n2. Zone ID")
+		fmt.Println(tr("   在 Cloudflare 域名概览页面右侧可以找到 Zone ID"))
+		cfg.ZoneID = wizardInput(tr("请输入 Zone ID: "), cfg.ZoneID, cfg.ZoneID)
+		if cfg.ZoneID == "" {
+			fmt.Println(tr("Zone ID 不能为空"))
+			return false
+		}
+
+	case wizardRecordName:
+		fmt.Println(tr("\n3. DNS 记录名称"))
+		fmt.Println(tr("   例如: subdomain.example.com 或 @ (表示根域名)"))
+		cfg.RecordName = wizardInput(tr("请输入记录名称: "), cfg.RecordName, cfg.RecordName)
+		if cfg.RecordName == "" {
+			fmt.Println(tr("记录名称不能为空"))
+			return false
+		}
+
+	case wizardRecordType:
+		fmt.Println(tr("\n4. DNS 记录类型"))
+		fmt.Println(tr("   通常为 A (IPv4) 或 AAAA (IPv6)"))
+		if editing {
+			cfg.RecordType = wizardInput(tr("请输入记录类型: "), cfg.RecordType, cfg.RecordType)
+		} else if cfg.RecordType = getUserInput(tr("请输入记录类型 (默认: A): ")); cfg.RecordType == "" {
+			cfg.RecordType = "A"
+		}
+	}
+	return true
 }
 
 func startBackgroundDaemon() {
@@ -587,33 +1007,33 @@ func startBackgroundDaemon() {
 	processes, err := listDaemonProcesses()
 	hasExistingDaemon := false
 	if err == nil && len(processes) > 0 {
-		// 过滤出守护进程（包含 --daemon 参数）
+		// 过滤出守护进程
 		daemonProcesses := []ProcessInfo{}
 		for _, proc := range processes {
-			if strings.Contains(proc.Command, "--daemon") && proc.PID != os.Getpid() {
+			if isDaemonCommand(proc.Command) && proc.PID != os.Getpid() {
 				daemonProcesses = append(daemonProcesses, proc)
 			}
 		}
 
 		if len(daemonProcesses) > 0 {
 			hasExistingDaemon = true
-			fmt.Printf("\n检测到已有 %d 个守护进程在运行:\n", len(daemonProcesses))
+			fmt.Printf(tr("\n检测到已有 %d 个守护进程在运行:\n"), len(daemonProcesses))
 			for _, proc := range daemonProcesses {
 				fmt.Printf("  - PID: %d\n", proc.PID)
 			}
-			fmt.Println("\n正在停止所有现有守护进程...")
+			fmt.Println(tr("\n正在停止所有现有守护进程..."))
 
 			// 停止所有守护进程
 			if err := stopAllDaemonProcesses(); err != nil {
-				fmt.Printf("❌ 停止现有进程时出错: %v\n", err)
-				fmt.Println("是否继续？(y/N)")
+				fmt.Printf(tr("❌ 停止现有进程时出错: %v\n"), err)
+				fmt.Println(tr("是否继续？(y/N)"))
 				confirm := getUserInput("")
 				if confirm != "y" && confirm != "Y" {
-					fmt.Println("已取消")
+					fmt.Println(tr("已取消"))
 					return
 				}
 			} else {
-				fmt.Println("✓ 所有现有守护进程已停止")
+				fmt.Println(tr("✓ 所有现有守护进程已停止"))
 				// 等待一下确保进程完全退出
 				time.Sleep(1 * time.Second)
 			}
@@ -624,7 +1044,7 @@ func startBackgroundDaemon() {
 	pid, err := getPID()
 	if err == nil {
 		if isProcessRunning(pid) {
-			fmt.Printf("\n检测到残留的PID文件（PID: %d），正在清理...\n", pid)
+			fmt.Printf(tr("\n检测到残留的PID文件（PID: %d），正在清理...\n"), pid)
 			removePIDFile()
 			// 如果进程还在，尝试停止
 			if isProcessRunning(pid) {
@@ -638,7 +1058,7 @@ func startBackgroundDaemon() {
 					}
 				}
 			}
-			fmt.Println("✓ 已清理残留的PID文件")
+			fmt.Println(tr("✓ 已清理残留的PID文件"))
 		} else {
 			// 进程不存在，清理PID文件
 			removePIDFile()
@@ -647,121 +1067,113 @@ func startBackgroundDaemon() {
 
 	// 如果检测到已有服务，先删除配置，然后重新配置
 	if hasExistingDaemon {
-		fmt.Println("\n========== 清理配置 ==========")
-		fmt.Println("检测到已有服务，正在删除所有相关配置...")
+		fmt.Println(tr("\n========== 清理配置 =========="))
+		fmt.Println(tr("检测到已有服务，正在删除所有相关配置..."))
 		
 		// 删除配置文件
 		if err := DeleteConfig(); err != nil {
-			fmt.Printf("⚠️  删除配置文件时出错: %v\n", err)
+			fmt.Printf(tr("⚠️  删除配置文件时出错: %v\n"), err)
 		} else {
-			fmt.Println("✓ 配置文件已删除")
+			fmt.Println(tr("✓ 配置文件已删除"))
 		}
 
 		// 清空内存中的配置
-		config = &Config{
-			RecordType: "A",
-		}
-		cfClient = nil
+		app.ClearConfig()
 
-		fmt.Println("\n========== 重新配置 ==========")
-		fmt.Println("所有配置已清除，请按照提示重新输入以下信息：")
+		fmt.Println(tr("\n========== 重新配置 =========="))
+		fmt.Println(tr("所有配置已清除，请按照提示重新输入以下信息："))
 		fmt.Println()
 
 		// 进行交互式配置
 		interactiveConfig()
 
 		// 重新加载配置
-		config = LoadConfig()
-		if config.APIToken == "" || config.ZoneID == "" || config.RecordName == "" {
-			fmt.Println("❌ 配置未完成，无法启动守护进程")
+		cfg := LoadConfig()
+		if !cfg.isComplete() {
+			fmt.Println(tr("❌ 配置未完成，无法启动守护进程"))
 			return
 		}
 
 		// 重新初始化客户端
-		var clientErr error
-		cfClient, clientErr = NewCloudflareClient(config.APIToken)
-		if clientErr != nil {
-			fmt.Printf("❌ 初始化 Cloudflare 客户端失败: %v\n", clientErr)
-			fmt.Println("请检查 API Token 是否正确")
+		if err := app.ApplyConfig(cfg); err != nil {
+			fmt.Printf(tr("❌ 初始化 Cloudflare 客户端失败: %v\n"), err)
+			fmt.Println(tr("请检查 API Token 是否正确"))
 			return
 		}
 
-		fmt.Println("\n✓ 配置完成！")
+		fmt.Println(tr("\n✓ 配置完成！"))
 		fmt.Println()
 	} else {
 		// 检查配置是否存在（首次运行）
-		if config.APIToken == "" || config.ZoneID == "" || config.RecordName == "" {
-			fmt.Println("\n========== 首次配置 ==========")
-			fmt.Println("检测到未配置，需要先进行配置才能启动守护进程")
-			fmt.Println("请按照提示输入以下信息：")
+		if !app.Config().isComplete() {
+			fmt.Println(tr("\n========== 首次配置 =========="))
+			fmt.Println(tr("检测到未配置，需要先进行配置才能启动守护进程"))
+			fmt.Println(tr("请按照提示输入以下信息："))
 			fmt.Println()
 
 			// 进行交互式配置
 			interactiveConfig()
 
 			// 重新加载配置
-			config = LoadConfig()
-			if config.APIToken == "" || config.ZoneID == "" || config.RecordName == "" {
-				fmt.Println("❌ 配置未完成，无法启动守护进程")
+			cfg := LoadConfig()
+			if !cfg.isComplete() {
+				fmt.Println(tr("❌ 配置未完成，无法启动守护进程"))
 				return
 			}
 
 			// 重新初始化客户端
-			var clientErr error
-			cfClient, clientErr = NewCloudflareClient(config.APIToken)
-			if clientErr != nil {
-				fmt.Printf("❌ 初始化 Cloudflare 客户端失败: %v\n", clientErr)
-				fmt.Println("请检查 API Token 是否正确")
+			if err := app.ApplyConfig(cfg); err != nil {
+				fmt.Printf(tr("❌ 初始化 Cloudflare 客户端失败: %v\n"), err)
+				fmt.Println(tr("请检查 API Token 是否正确"))
 				return
 			}
 
-			fmt.Println("\n✓ 配置完成！")
+			fmt.Println(tr("\n✓ 配置完成！"))
 			fmt.Println()
 		}
 	}
 
 	// 验证配置有效性
-	fmt.Println("正在验证配置...")
+	fmt.Println(tr("正在验证配置..."))
 	if err := verifyConfig(); err != nil {
-		fmt.Printf("❌ 配置验证失败: %v\n", err)
-		fmt.Println("请检查配置是否正确，或使用菜单选项 5 重新配置")
+		fmt.Printf(tr("❌ 配置验证失败: %v\n"), err)
+		fmt.Println(tr("请检查配置是否正确，或使用菜单选项 5 重新配置"))
 		return
 	}
-	fmt.Println("✓ 配置验证通过")
+	fmt.Println(tr("✓ 配置验证通过"))
 
-	fmt.Println("\n正在启动后台守护进程...")
-	fmt.Println("程序将在后台自动运行，每5秒检测一次IP变化")
-	fmt.Printf("配置信息:\n")
+	config := app.Config()
+	fmt.Println(tr("\n正在启动后台守护进程..."))
+	fmt.Printf(tr("程序将在后台自动运行，每%s检测一次IP变化\n"), checkInterval)
+	fmt.Printf(tr("配置信息:\n"))
 	fmt.Printf("  Zone ID: %s\n", config.ZoneID)
-	fmt.Printf("  记录名称: %s\n", config.RecordName)
-	fmt.Printf("  记录类型: %s\n", config.RecordType)
+	fmt.Printf(tr("  记录名称: %s\n"), config.RecordName)
+	fmt.Printf(tr("  记录类型: %s\n"), config.RecordType)
 	fmt.Println()
 	
 	// 获取可执行文件路径
 	execPath, err := os.Executable()
 	if err != nil {
-		fmt.Printf("❌ 获取可执行文件路径失败: %v\n", err)
+		fmt.Printf(tr("❌ 获取可执行文件路径失败: %v\n"), err)
 		return
 	}
 
 	// 使用绝对路径
 	absPath, err := filepath.Abs(execPath)
 	if err != nil {
-		fmt.Printf("❌ 获取绝对路径失败: %v\n", err)
+		fmt.Printf(tr("❌ 获取绝对路径失败: %v\n"), err)
 		return
 	}
 
 	// 启动守护进程
-	cmd := exec.Command(absPath, "--daemon")
+	cmd := exec.Command(absPath, "--daemon", "--lang", lang)
 	cmd.Env = os.Environ()
 	
 	// 设置进程属性
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true, // 创建新的会话
-	}
+	cmd.SysProcAttr = detachedProcAttr()
 
 	// 重定向标准输入输出到 /dev/null
-	nullFile, err := os.OpenFile("/dev/null", os.O_RDWR, 0)
+	nullFile, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
 	if err == nil {
 		cmd.Stdin = nullFile
 		cmd.Stdout = nullFile
@@ -770,15 +1182,15 @@ func startBackgroundDaemon() {
 
 	// 启动守护进程
 	if err := cmd.Start(); err != nil {
-		fmt.Printf("❌ 启动守护进程失败: %v\n", err)
+		fmt.Printf(tr("❌ 启动守护进程失败: %v\n"), err)
 		return
 	}
 
-	fmt.Printf("✓ 守护进程已启动，PID: %d\n", cmd.Process.Pid)
-	fmt.Println("程序已在后台运行，可以安全关闭终端")
-	fmt.Println("使用 './dns_manager --status' 查看运行状态")
-	fmt.Println("使用 './dns_manager --stop' 停止守护进程")
-	fmt.Println("使用 './dns_manager --info' 查看详细信息")
+	fmt.Printf(tr("✓ 守护进程已启动，PID: %d\n"), cmd.Process.Pid)
+	fmt.Println(tr("程序已在后台运行，可以安全关闭终端"))
+	fmt.Println(tr("使用 './dns_manager status' 查看运行状态"))
+	fmt.Println(tr("使用 './dns_manager stop' 停止守护进程"))
+	fmt.Println(tr("使用 './dns_manager info' 查看详细信息"))
 	
 	// 保存PID到文件
 	savePID(cmd.Process.Pid)
@@ -786,39 +1198,45 @@ func startBackgroundDaemon() {
 
 // verifyConfig 验证配置有效性
 func verifyConfig() error {
+	config := app.Config()
 	// 验证 API Token
 	if config.APIToken == "" {
-		return fmt.Errorf("API Token 不能为空")
+		return errors.New(tr("API Token 不能为空"))
 	}
 
 	// 验证 Zone ID
 	if config.ZoneID == "" {
-		return fmt.Errorf("Zone ID 不能为空")
+		return errors.New(tr("Zone ID 不能为空"))
 	}
 
 	// 验证记录名称
 	if config.RecordName == "" {
-		return fmt.Errorf("记录名称不能为空")
+		return errors.New(tr("记录名称不能为空"))
 	}
 
 	// 验证记录类型
 	if config.RecordType != "A" && config.RecordType != "AAAA" {
-		return fmt.Errorf("记录类型必须是 A 或 AAAA")
+		return errors.New(tr("记录类型必须是 A 或 AAAA"))
 	}
 
 	// 尝试连接 Cloudflare API 验证配置
-	if cfClient == nil {
+	client := app.Client()
+	if client == nil {
 		var err error
-		cfClient, err = NewCloudflareClient(config.APIToken)
+		client, err = NewCloudflareClient(config.APIToken)
 		if err != nil {
-			return fmt.Errorf("初始化 Cloudflare 客户端失败: %v", err)
+			return fmt.Errorf(tr("初始化 Cloudflare 客户端失败: %v"), err)
 		}
+		app.Apply(config, client)
 	}
 
 	// 尝试获取DNS记录验证配置
-	_, err := cfClient.ListDNSRecords(config.ZoneID, config.RecordName)
+	_, err := client.ListDNSRecords(config.ZoneID, config.RecordName)
 	if err != nil {
-		return fmt.Errorf("无法访问 Cloudflare API 或配置错误: %v", err)
+		return fmt.Errorf(tr("无法访问 Cloudflare API 或配置错误: %v"), err)
+	}
+	if err := checkRecordZone(client, config.ZoneID, config.RecordName); err != nil {
+		return err
 	}
 
 	return nil
@@ -827,34 +1245,34 @@ func verifyConfig() error {
 // manageDaemonMenu 守护进程管理菜单
 func manageDaemonMenu() {
 	for {
-		fmt.Println("\n========== 守护进程管理 ==========")
-		fmt.Println("1. 查看守护进程状态")
-		fmt.Println("2. 查看详细信息")
-		fmt.Println("3. 列出所有进程")
-		fmt.Println("4. 停止守护进程")
-		fmt.Println("5. 强制终止守护进程")
-		fmt.Println("6. 清理无效PID文件")
-		fmt.Println("7. 返回主菜单")
+		fmt.Println(tr("\n========== 守护进程管理 =========="))
+		fmt.Println(tr("1. 查看守护进程状态"))
+		fmt.Println(tr("2. 查看详细信息"))
+		fmt.Println(tr("3. 列出所有进程"))
+		fmt.Println(tr("4. 停止守护进程"))
+		fmt.Println(tr("5. 强制终止守护进程"))
+		fmt.Println(tr("6. 清理无效PID文件"))
+		fmt.Println(tr("7. 返回主菜单"))
 		fmt.Println("================================")
 
-		choice := getUserInput("请选择操作 (1-7): ")
+		choice := getUserInput(tr("请选择操作 (1-7): "))
 
 		switch choice {
 		case "1":
 			pid, err := getPID()
 			if err != nil {
-				fmt.Println("守护进程未运行（未找到PID文件）")
+				fmt.Println(tr("守护进程未运行（未找到PID文件）"))
 			} else if isProcessRunning(pid) {
-				fmt.Printf("✓ 守护进程正在运行，PID: %d\n", pid)
+				fmt.Printf(tr("✓ 守护进程正在运行，PID: %d\n"), pid)
 			} else {
-				fmt.Printf("✗ 守护进程未运行（PID文件存在但进程不存在，PID: %d）\n", pid)
-				fmt.Println("提示: 选择选项 6 清理无效的PID文件")
+				fmt.Printf(tr("✗ 守护进程未运行（PID文件存在但进程不存在，PID: %d）\n"), pid)
+				fmt.Println(tr("提示: 选择选项 6 清理无效的PID文件"))
 			}
 
 		case "2":
 			info, err := getDaemonInfo()
 			if err != nil {
-				fmt.Printf("❌ 获取信息失败: %v\n", err)
+				fmt.Printf(tr("❌ 获取信息失败: %v\n"), err)
 			} else {
 				printDaemonInfo(info)
 			}
@@ -862,11 +1280,11 @@ func manageDaemonMenu() {
 		case "3":
 			processes, err := listDaemonProcesses()
 			if err != nil {
-				fmt.Printf("❌ 列出进程失败: %v\n", err)
+				fmt.Printf(tr("❌ 列出进程失败: %v\n"), err)
 			} else if len(processes) == 0 {
-				fmt.Println("未找到运行中的 dns_manager 进程")
+				fmt.Println(tr("未找到运行中的 dns_manager 进程"))
 			} else {
-				fmt.Printf("\n找到 %d 个 dns_manager 进程:\n", len(processes))
+				fmt.Printf(tr("\n找到 %d 个 dns_manager 进程:\n"), len(processes))
 				fmt.Println(strings.Repeat("-", 80))
 				for _, proc := range processes {
 					fmt.Printf("PID: %d\n%s\n", proc.PID, proc.Command)
@@ -876,49 +1294,49 @@ func manageDaemonMenu() {
 
 		case "4":
 			if err := stopDaemon(); err != nil {
-				fmt.Printf("❌ 停止失败: %v\n", err)
+				fmt.Printf(tr("❌ 停止失败: %v\n"), err)
 			} else {
-				fmt.Println("✓ 守护进程已停止")
+				fmt.Println(tr("✓ 守护进程已停止"))
 			}
 
 		case "5":
-			fmt.Println("警告: 强制终止可能导致数据丢失，是否继续？(y/N)")
+			fmt.Println(tr("警告: 强制终止可能导致数据丢失，是否继续？(y/N)"))
 			confirm := getUserInput("")
 			if confirm == "y" || confirm == "Y" {
 				if err := killDaemon(); err != nil {
-					fmt.Printf("❌ 强制终止失败: %v\n", err)
+					fmt.Printf(tr("❌ 强制终止失败: %v\n"), err)
 				} else {
-					fmt.Println("✓ 守护进程已强制终止")
+					fmt.Println(tr("✓ 守护进程已强制终止"))
 				}
 			} else {
-				fmt.Println("已取消")
+				fmt.Println(tr("已取消"))
 			}
 
 		case "6":
 			if err := cleanupPIDFile(); err != nil {
 				fmt.Println(err)
 			} else {
-				fmt.Println("✓ PID文件检查完成，无需清理")
+				fmt.Println(tr("✓ PID文件检查完成，无需清理"))
 			}
 
 		case "7":
 			return
 
 		default:
-			fmt.Println("无效的选择，请重新输入。")
+			fmt.Println(tr("无效的选择，请重新输入。"))
 		}
 	}
 }
 
 // printDaemonInfo 打印守护进程详细信息
 func printDaemonInfo(info map[string]interface{}) {
-	fmt.Println("\n========== 守护进程信息 ==========")
+	fmt.Println(tr("\n========== 守护进程信息 =========="))
 	
 	if running, ok := info["running"].(bool); ok {
 		if running {
-			fmt.Println("状态: ✓ 正在运行")
+			fmt.Println(tr("状态: ✓ 正在运行"))
 		} else {
-			fmt.Println("状态: ✗ 未运行")
+			fmt.Println(tr("状态: ✗ 未运行"))
 		}
 	}
 
@@ -926,24 +1344,61 @@ func printDaemonInfo(info map[string]interface{}) {
 		fmt.Printf("PID: %d\n", pid)
 	}
 
+	if version, ok := info["version"].(string); ok {
+		fmt.Printf(tr("版本: %s\n"), version)
+	}
+
 	if pidFile, ok := info["pid_file"].(string); ok {
-		fmt.Printf("PID文件: %s\n", pidFile)
+		fmt.Printf(tr("PID文件: %s\n"), pidFile)
 	}
 
 	if logFile, ok := info["log_file"].(string); ok {
-		fmt.Printf("日志文件: %s\n", logFile)
+		fmt.Printf(tr("日志文件: %s\n"), logFile)
 		if logSize, ok := info["log_size"].(int64); ok {
-			fmt.Printf("日志大小: %d 字节 (%.2f KB)\n", logSize, float64(logSize)/1024)
+			fmt.Printf(tr("日志大小: %d 字节 (%.2f KB)\n"), logSize, float64(logSize)/1024)
 		}
 	}
 
 	if details, ok := info["details"].(string); ok && details != "" {
-		fmt.Println("\n进程详情:")
+		fmt.Println(tr("\n进程详情:"))
 		fmt.Println(details)
 	}
 
+	if uptime, ok := info["uptime"].(time.Duration); ok {
+		fmt.Printf(tr("运行时长: %s\n"), uptime.Round(time.Second))
+	}
+
+	if cycles, ok := info["cycles"].(int64); ok {
+		fmt.Printf(tr("检测次数: %d\n"), cycles)
+	}
+
+	if updates, ok := info["updates"].(int64); ok {
+		fmt.Printf(tr("DNS更新次数: %d\n"), updates)
+	}
+
+	if currentIP, ok := info["current_ip"].(string); ok {
+		fmt.Printf(tr("当前IP: %s\n"), currentIP)
+	}
+
+	if lastChange, ok := info["last_ip_change"].(time.Time); ok {
+		fmt.Printf(tr("最近IP变化: %s\n"), lastChange.In(logLocation).Format(logTimeFormat))
+	}
+
+	if failures, ok := info["consecutive_failures"].(int); ok {
+		fmt.Printf(tr("连续失败次数: %d\n"), failures)
+		if since, ok := info["failing_since"].(time.Time); ok {
+			fmt.Printf(tr("开始失败时间: %s\n"), since.In(logLocation).Format(logTimeFormat))
+		}
+		if since, ok := info["degraded_since"].(time.Time); ok {
+			fmt.Printf(tr("降级模式: 自 %s 起\n"), since.In(logLocation).Format(logTimeFormat))
+		}
+		if lastErr, ok := info["last_error"].(string); ok && lastErr != "" {
+			fmt.Printf(tr("最近错误: %s\n"), lastErr)
+		}
+	}
+
 	if err, ok := info["error"].(string); ok {
-		fmt.Printf("错误: %s\n", err)
+		fmt.Printf(tr("错误: %s\n"), err)
 	}
 
 	fmt.Println("==================================")
@@ -0,0 +1,1057 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testZoneID   = "zone1"
+	testZoneName = "example.com"
+	testRecord   = "home.example.com"
+)
+
+func TestMain(m *testing.M) {
+	// 快照、审计日志等写入临时目录，不影响用户数据
+	dir, err := os.MkdirTemp("", "dns_manager_test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("DNS_MANAGER_HOME", dir)
+	defaultConfirmDelay = 0
+	defaultRetryDelay = 0
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// fakeIPService 返回固定IP的检测服务，IP可在测试中修改
+type fakeIPService struct {
+	mu     sync.Mutex
+	ip     string
+	server *httptest.Server
+}
+
+func newFakeIPService(t *testing.T, ip string) *fakeIPService {
+	s := &fakeIPService{ip: ip}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Write([]byte(s.ip + "\n"))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *fakeIPService) set(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ip = ip
+}
+
+// checker 返回只使用该服务的IP检测器
+func (s *fakeIPService) checker() *IPChecker {
+	return &IPChecker{
+		client:         s.server.Client(),
+		primaryService: s.server.URL,
+		services:       []string{s.server.URL},
+	}
+}
+
+// setupApp 使用模拟服务器初始化全局运行时状态，测试结束后恢复
+func setupApp(t *testing.T, cf *fakeCloudflare, ips *fakeIPService, currentIP string) *Config {
+	t.Helper()
+	cf.addZone(testZoneID, testZoneName)
+	config := &Config{
+		APIToken:   fakeToken,
+		ZoneID:     testZoneID,
+		RecordName: testRecord,
+		RecordType: "A",
+	}
+	if err := app.Apply(config, cf.client()); err != nil {
+		t.Fatalf("app.Apply: %v", err)
+	}
+	app.SetIPChecker(ips.checker())
+	app.SetCurrentIP(currentIP)
+	t.Cleanup(func() {
+		app.ClearConfig()
+		app.SetCurrentIP("")
+		dryRun = false
+	})
+	return config
+}
+
+func TestCheckAndUpdateUpdatesChangedIP(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+
+	updated, err := checkAndUpdate()
+	if err != nil || !updated {
+		t.Fatalf("checkAndUpdate() = %v, %v; want true, nil", updated, err)
+	}
+	records := cf.find(testZoneID, testRecord, "A")
+	if len(records) != 1 || records[0].Content != "198.51.100.2" || records[0].TTL != 300 {
+		t.Fatalf("records = %+v; want one record -> 198.51.100.2 with TTL 300", records)
+	}
+	if got := app.CurrentIP(); got != "198.51.100.2" {
+		t.Fatalf("CurrentIP = %q; want 198.51.100.2", got)
+	}
+}
+
+func TestCheckAndUpdateCreatesMissingRecord(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != "198.51.100.2" {
+		t.Fatalf("records = %v; want [198.51.100.2]", got)
+	}
+}
+
+func TestCheckAndUpdateSkipsUnchangedIP(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.1"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+
+	updated, err := checkAndUpdate()
+	if err != nil || updated {
+		t.Fatalf("checkAndUpdate() = %v, %v; want false, nil", updated, err)
+	}
+	if n := len(cf.requests); n != 0 {
+		t.Fatalf("unchanged IP sent %d API requests: %v", n, cf.requests)
+	}
+}
+
+func TestUpdateWithManualIP(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+
+	// 使用指定的IP而不是检测结果
+	result := updateDNSResult("203.0.113.9")
+	if !result.Success || result.IP != "203.0.113.9" || result.Source != tr("手动指定") || result.Action != planUpdate {
+		t.Fatalf("result = %+v; want an update to 203.0.113.9", result)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != "203.0.113.9" {
+		t.Fatalf("records = %v; want [203.0.113.9]", got)
+	}
+}
+
+func TestOnceSummary(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+
+	start := time.Now()
+	_, err := safeCheckAndUpdate()
+	s := lastCycle.summary(app.Config(), start, err)
+	if !s.Success || s.IP != "198.51.100.2" || s.Action != planUpdate || s.ExitCode != 0 {
+		t.Fatalf("summary = %+v; want a successful update to 198.51.100.2", s)
+	}
+	if len(s.Records) != 1 || !reflect.DeepEqual(s.Records[0].Before, []string{"198.51.100.1"}) || !reflect.DeepEqual(s.Records[0].After, []string{"198.51.100.2"}) {
+		t.Fatalf("records = %+v; want 198.51.100.1 -> 198.51.100.2", s.Records)
+	}
+
+	// IP未变化时不读取记录，操作为 none
+	_, err = safeCheckAndUpdate()
+	if s := lastCycle.summary(app.Config(), start, err); !s.Success || s.Action != planNone || len(s.Records) != 0 {
+		t.Fatalf("summary = %+v; want no action", s)
+	}
+
+	// 写入失败时记录值不变，带错误类别与退出码
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.3"), "198.51.100.2")
+	cf.failNext("PUT", "/dns_records/", http.StatusInternalServerError, 3)
+	_, err = safeCheckAndUpdate()
+	s = lastCycle.summary(app.Config(), start, err)
+	if s.Success || s.Action != planNone || s.Category != "network" || s.ExitCode != exitNetwork {
+		t.Fatalf("summary = %+v; want a failed network error", s)
+	}
+	if len(s.Records) != 1 || s.Records[0].Error == "" || !reflect.DeepEqual(s.Records[0].After, []string{"198.51.100.2"}) {
+		t.Fatalf("records = %+v; want unchanged record with error", s.Records)
+	}
+}
+
+func TestCheckAndUpdateKeepsOtherMachinesRecords(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "203.0.113.7"})
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	got := cf.contents(testZoneID, testRecord, "A")
+	if len(got) != 2 || got[0] != "198.51.100.2" || got[1] != "203.0.113.7" {
+		t.Fatalf("records = %v; want both machines' records", got)
+	}
+}
+
+func TestCheckAndUpdateRetriesAfterAPIError(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.failNext("PUT", "/dns_records/", http.StatusInternalServerError, 1)
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != "198.51.100.2" {
+		t.Fatalf("records = %v; want [198.51.100.2]", got)
+	}
+	if n := cf.count("PUT"); n != 2 {
+		t.Fatalf("PUT requests = %d; want 2 (one failure, one retry)", n)
+	}
+}
+
+func TestCheckAndUpdateFailureKeepsCurrentIP(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.failNext("PUT", "/dns_records/", http.StatusInternalServerError, 3)
+
+	if _, err := checkAndUpdate(); err == nil {
+		t.Fatal("checkAndUpdate succeeded; want error after retries are exhausted")
+	}
+	// 未同步的IP不能记为当前IP，否则下个周期会跳过更新
+	if got := app.CurrentIP(); got != "198.51.100.1" {
+		t.Fatalf("CurrentIP = %q; want unchanged 198.51.100.1", got)
+	}
+}
+
+func TestCheckAndUpdateDryRun(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	dryRun = true
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	if n := cf.writes(); n != 0 {
+		t.Fatalf("dry run sent %d write requests", n)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); got[0] != "198.51.100.1" {
+		t.Fatalf("records = %v; want unchanged", got)
+	}
+}
+
+func TestCheckAndUpdateObserveOnly(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	config.ObserveOnly = true
+	t.Cleanup(func() { observer = &observerState{} })
+
+	if updated, err := checkAndUpdate(); err != nil || updated {
+		t.Fatalf("checkAndUpdate() = %v, %v; want false, nil", updated, err)
+	}
+	if n := cf.writes(); n != 0 {
+		t.Fatalf("observe-only mode sent %d write requests", n)
+	}
+	if observer.alerted == "" {
+		t.Fatal("mismatch not reported")
+	}
+	// 不一致时每个周期继续比较
+	if got := app.CurrentIP(); got != "198.51.100.1" {
+		t.Fatalf("CurrentIP = %q; want 198.51.100.1", got)
+	}
+
+	// 由其他系统指向新IP后按IP未变化处理
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2"})
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	if got := app.CurrentIP(); got != "198.51.100.2" || observer.alerted != "" {
+		t.Fatalf("CurrentIP = %q, alerted = %q; want 198.51.100.2 and no alert", got, observer.alerted)
+	}
+	if n := cf.writes(); n != 0 {
+		t.Fatalf("observe-only mode sent %d write requests", n)
+	}
+}
+
+func TestCheckAndUpdateSyncsAdditionalRecords(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addZone("zone2", "example.org")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}, {Name: "home.example.org", ZoneID: "zone2"}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "vpn.example.com", Content: "198.51.100.1"})
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	for _, r := range []struct{ zone, name string }{{testZoneID, testRecord}, {testZoneID, "vpn.example.com"}, {"zone2", "home.example.org"}} {
+		if got := cf.contents(r.zone, r.name, "A"); len(got) != 1 || got[0] != "198.51.100.2" {
+			t.Errorf("%s records = %v; want [198.51.100.2]", r.name, got)
+		}
+	}
+}
+
+func TestCheckAndUpdateListsZoneOnce(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}, {Name: "nas.example.com"}}
+	cf.pageSize = 2
+	// 记录已指向新IP，无需写入与验证，只剩生成计划时的读取
+	for _, name := range []string{testRecord, "vpn.example.com", "nas.example.com"} {
+		cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: name, Content: "198.51.100.2"})
+	}
+	cf.addRecord(testZoneID, DNSRecord{Type: "AAAA", Name: "nas.example.com", Content: "2001:db8::1"})
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	lists := 0
+	cf.mu.Lock()
+	for _, r := range cf.requests {
+		if r == "GET /zones/"+testZoneID+"/dns_records" {
+			lists++
+		}
+	}
+	cf.mu.Unlock()
+	// 3 条 A 记录按每页 2 条分两页读取
+	if lists != 2 {
+		t.Errorf("list requests = %d; want 2 pages of a single zone list", lists)
+	}
+	if cf.writes() != 0 {
+		t.Errorf("writes = %d; want 0", cf.writes())
+	}
+}
+
+func TestCheckAndUpdateWithSimulatedIPs(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "203.0.113.1"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	sim, err := parseIPSimulation("198.51.100.2, 198.51.100.3, 198.51.100.3")
+	if err != nil {
+		t.Fatalf("parseIPSimulation: %v", err)
+	}
+	ipSimulator = sim
+	t.Cleanup(func() { ipSimulator = nil })
+
+	// 第一次检测与确认结果不同，不应更新
+	if updated, _ := checkAndUpdate(); updated {
+		t.Fatal("update applied although the confirmation saw a different IP")
+	}
+	if updated, err := checkAndUpdate(); err != nil || !updated {
+		t.Fatalf("checkAndUpdate() = %v, %v; want true, nil", updated, err)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != "198.51.100.3" {
+		t.Fatalf("records = %v; want [198.51.100.3]", got)
+	}
+}
+
+func TestCheckAndUpdateDoHPrecheck(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "vpn.example.com", Content: "198.51.100.1"})
+
+	var mu sync.Mutex
+	answers := map[string]string{testRecord: "198.51.100.2", "vpn.example.com": "198.51.100.2"}
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Status": 0,
+			"Answer": []map[string]interface{}{{"type": 1, "data": answers[r.URL.Query().Get("name")]}},
+		})
+	}))
+	defer doh.Close()
+	config.DoHPrecheck = &DoHPrecheckConfig{URL: doh.URL}
+
+	// 解析结果已是检测到的IP：不调用 API
+	if updated, err := checkAndUpdate(); err != nil || updated {
+		t.Fatalf("checkAndUpdate = %v, %v; want skipped", updated, err)
+	}
+	if n := len(cf.requests); n != 0 {
+		t.Fatalf("API requests = %v; want none", cf.requests)
+	}
+	if got := app.CurrentIP(); got != "198.51.100.2" {
+		t.Errorf("current IP = %q; want 198.51.100.2", got)
+	}
+
+	// 任一记录不一致时按正常流程同步
+	app.SetCurrentIP("")
+	mu.Lock()
+	answers["vpn.example.com"] = "198.51.100.1"
+	mu.Unlock()
+	if updated, err := checkAndUpdate(); err != nil || !updated {
+		t.Fatalf("checkAndUpdate = %v, %v; want updated", updated, err)
+	}
+	if got := cf.contents(testZoneID, "vpn.example.com", "A"); len(got) != 2 {
+		t.Errorf("vpn records = %v; want the new IP beside the other machine's", got)
+	}
+}
+
+func TestCheckAndUpdateErrorKeepsCategory(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}}
+	// 区域预读失败后两个记录分别查询，也都失败
+	cf.failNext("GET", "/dns_records", http.StatusUnauthorized, 3)
+
+	// 多个记录失败时汇总错误仍可按类别判断退出码
+	_, err := checkAndUpdate()
+	if code := exitCodeFor(err); code != exitAuth {
+		t.Fatalf("exit code for %v = %d; want %d", err, code, exitAuth)
+	}
+	if metas := apiResponseMetas(err); len(metas) != 2 || metas[0].CfRay == metas[1].CfRay {
+		t.Fatalf("metas = %+v; want both failed requests with their own Cf-Ray", metas)
+	}
+}
+
+func TestCheckAndUpdateDrift(t *testing.T) {
+	for _, policy := range []string{driftReassert, driftAdopt, driftAlert} {
+		t.Run(policy, func(t *testing.T) {
+			cf := newFakeCloudflare(t)
+			ips := newFakeIPService(t, "198.51.100.1")
+			config := setupApp(t, cf, ips, "198.51.100.1")
+			config.Drift = &DriftConfig{Policy: policy, Interval: "1ns"}
+			drift = &driftTracker{}
+			t.Cleanup(func() { drift = &driftTracker{} })
+			record := cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+
+			// 第一次检查记下本机维护的记录，随后在控制台中修改
+			if _, err := checkAndUpdate(); err != nil {
+				t.Fatalf("checkAndUpdate: %v", err)
+			}
+			cf.mu.Lock()
+			cf.records[testZoneID][0].Content = "203.0.113.9"
+			cf.mu.Unlock()
+			time.Sleep(time.Millisecond)
+			if _, err := checkAndUpdate(); err != nil {
+				t.Fatalf("checkAndUpdate: %v", err)
+			}
+			want := map[string]string{driftReassert: "198.51.100.1", driftAdopt: "203.0.113.9", driftAlert: "203.0.113.9"}[policy]
+			if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != want {
+				t.Fatalf("records after the drift check = %v; want [%s]", got, want)
+			}
+
+			// IP变化：adopt 从接受的值更新，alert 不再维护该记录
+			ips.set("198.51.100.2")
+			if _, err := checkAndUpdate(); err != nil {
+				t.Fatalf("checkAndUpdate: %v", err)
+			}
+			want = map[string]string{driftReassert: "198.51.100.2", driftAdopt: "198.51.100.2", driftAlert: "203.0.113.9"}[policy]
+			got := cf.find(testZoneID, testRecord, "A")
+			if len(got) != 1 || got[0].Content != want || got[0].ID != record.ID {
+				t.Errorf("records after the IP change = %+v; want record %s with %s", got, record.ID, want)
+			}
+		})
+	}
+}
+
+func TestBuildStartupReport(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "")
+	proxied := false
+	config.StartupReport = &StartupReportConfig{TTL: 300, Proxied: &proxied}
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}, {Name: "lab.example.com"}, {Name: "git.example.com"}}
+	config.Subdomains = []SubdomainConfig{{Name: "www", Type: "CNAME"}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 300})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "lab.example.com", Content: "203.0.113.9", TTL: 300})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "git.example.com", Content: "198.51.100.2", TTL: 3600, Proxied: true})
+	cf.addRecord(testZoneID, DNSRecord{Type: "CNAME", Name: "www.example.com", Content: testRecord, TTL: 300})
+
+	report := buildStartupReport(config, newReconciler(), "198.51.100.2")
+	got := map[string]string{}
+	for _, entry := range report.Entries {
+		got[entry.Name] = entry.Status
+	}
+	want := map[string]string{
+		testRecord:        reportOK,
+		"vpn.example.com": reportMissing,
+		"lab.example.com": reportDrifted,
+		// 开启代理的记录不比较TTL，只报告代理不一致
+		"git.example.com": reportMismatch,
+		"www.example.com": reportOK,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("statuses = %v; want %v", got, want)
+	}
+	if n := report.problems(); n != 3 {
+		t.Errorf("problems = %d; want 3\n%s", n, report)
+	}
+	if n := cf.writes(); n != 0 {
+		t.Errorf("report wrote %d records; want none", n)
+	}
+}
+
+func TestRecordAudit(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.2")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}, {Name: "lab.example.com"}}
+	config.Subdomains = []SubdomainConfig{{Name: "www", Type: "CNAME"}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: "lab.example.com", Content: "198.51.100.9"})
+
+	// 只报告时不修改记录
+	report := runRecordAudit(config, newReconciler(), "198.51.100.2", false)
+	if problems, repaired := report.counts(); problems != 3 || repaired != 0 {
+		t.Fatalf("counts = %d, %d; want 3 problems, none repaired\n%s", problems, repaired, report)
+	}
+	if n := cf.writes(); n != 0 {
+		t.Fatalf("report-only audit wrote %d records", n)
+	}
+
+	report = runRecordAudit(config, newReconciler(), "198.51.100.2", true)
+	if problems, repaired := report.counts(); problems != 3 || repaired != 3 {
+		t.Fatalf("counts = %d, %d; want 3 problems, all repaired\n%s", problems, repaired, report)
+	}
+	if got := cf.contents(testZoneID, "vpn.example.com", "A"); !reflect.DeepEqual(got, []string{"198.51.100.2"}) {
+		t.Errorf("vpn = %v; want recreated", got)
+	}
+	// 其他地址的记录可能属于其他机器，保留并新增本机记录
+	got := cf.contents(testZoneID, "lab.example.com", "A")
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"198.51.100.2", "198.51.100.9"}) {
+		t.Errorf("lab = %v; want own record added", got)
+	}
+	if got := cf.contents(testZoneID, "www.example.com", "CNAME"); !reflect.DeepEqual(got, []string{testRecord}) {
+		t.Errorf("www = %v; want CNAME to %s", got, testRecord)
+	}
+}
+
+func TestTestNotifications(t *testing.T) {
+	setupApp(t, newFakeCloudflare(t), newFakeIPService(t, "198.51.100.1"), "")
+	var mu sync.Mutex
+	var texts []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		texts = append(texts, body["text"])
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	// 测试通知不受事件过滤影响；配置无效与发送失败的渠道分别报告
+	results := testNotifications([]NotificationConfig{
+		{Type: "slack", Name: "ops", Webhook: webhook.URL + "/ok", Events: "change", Template: "{{.Type}}: {{.Title}}"},
+		{Type: "slack", Webhook: webhook.URL + "/broken"},
+		{Type: "dingtalk"},
+	})
+	if len(results) != 3 || results[0].Error != "" || results[1].Error == "" || results[2].Error == "" {
+		t.Fatalf("results = %+v; want ops to succeed and the other two to fail", results)
+	}
+	if results[0].Channel != "ops" || results[2].Channel != "dingtalk" {
+		t.Errorf("channel names = %q, %q; want ops, dingtalk", results[0].Channel, results[2].Channel)
+	}
+	if len(texts) != 1 || texts[0] != "test: "+tr("测试通知") {
+		t.Errorf("sent texts = %q; want the rendered template", texts)
+	}
+}
+
+func TestDesktopNotifier(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses a fake notify-send")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + dir + "/args\n"
+	if err := os.WriteFile(dir+"/notify-send", []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	n, err := newNotifier(NotificationConfig{Type: "desktop"})
+	if err != nil {
+		t.Fatalf("newNotifier: %v", err)
+	}
+	if err := n.Send(NotifyEvent{Type: EventDNSUpdated, Title: "IP changed", Message: "1.2.3.4 -> 5.6.7.8"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	data, err := os.ReadFile(dir + "/args")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args := string(data); !strings.Contains(args, "DNS Manager: IP changed\n") || !strings.Contains(args, "5.6.7.8") {
+		t.Errorf("notify-send args = %q; want the title and message", args)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := newNotifier(NotificationConfig{Type: "desktop"}); err == nil {
+		t.Error("newNotifier succeeded without notify-send; want an error")
+	}
+}
+
+func TestRunSetupProbes(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	checker := newFakeIPService(t, "198.51.100.2").checker()
+	checker.services = append(checker.services, "http://127.0.0.1:1/ip")
+
+	statuses := func(probes []setupProbe) []string {
+		var result []string
+		for _, probe := range probes {
+			result = append(result, probe.Status)
+		}
+		return result
+	}
+
+	// API、一个可用的IP服务、一个不可用的服务（仅警告）、令牌、区域、现有记录
+	probes := runSetupProbes(cf.client(), checker, &Config{ZoneID: testZoneID, RecordName: testRecord, RecordType: "A"})
+	want := []string{probeOK, probeOK, probeWarn, probeOK, probeOK, probeOK}
+	if got := statuses(probes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statuses = %v; want %v\n%+v", got, want, probes)
+	}
+	if detail := probes[5].Detail; !strings.Contains(detail, "198.51.100.1") || !strings.Contains(detail, "198.51.100.2") {
+		t.Errorf("record probe = %q; want the existing record and the detected IP", detail)
+	}
+
+	// 区域不可访问时不再检查记录
+	probes = runSetupProbes(cf.client(), checker, &Config{ZoneID: "missing", RecordName: testRecord, RecordType: "A"})
+	if last := probes[len(probes)-1]; last.Name != tr("区域") || last.Status != probeFail {
+		t.Errorf("last probe = %+v; want a failed zone probe", last)
+	}
+
+	// 粘贴了另一个区域的 Zone ID：指出记录实际所属的区域
+	cf.addZone("zone2", "example.net")
+	probes = runSetupProbes(cf.client(), checker, &Config{ZoneID: "zone2", RecordName: testRecord, RecordType: "A"})
+	if last := probes[len(probes)-1]; last.Name != tr("记录名称") || last.Status != probeFail || !strings.Contains(last.Detail, testZoneID) {
+		t.Errorf("last probe = %+v; want a zone mismatch naming %s", last, testZoneID)
+	}
+}
+
+func TestCheckAndUpdateDiagnosesAuthErrors(t *testing.T) {
+	resetDiagnosis := func() { authDiagnosis.at = time.Time{} }
+	resetDiagnosis()
+	t.Cleanup(resetDiagnosis)
+
+	// 令牌有效、可以读取记录，写入时 403：缺少 DNS 编辑权限
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.failNext("PUT", "/dns_records", http.StatusForbidden, 10)
+	_, err := checkAndUpdate()
+	if code := exitCodeFor(err); code != exitAuth {
+		t.Fatalf("exit code for %v = %d; want %d", err, code, exitAuth)
+	}
+	if !strings.Contains(err.Error(), "Zone - DNS - Edit") || len(apiResponseMetas(err)) == 0 {
+		t.Errorf("err = %v; want the missing permission with the original Cf-Ray", err)
+	}
+
+	// 无法访问区域：令牌的区域范围不包含该区域
+	resetDiagnosis()
+	cf = newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	cf.failNext("", "/dns_records", http.StatusForbidden, 10)
+	cf.failNext("GET", "/zones/"+testZoneID, http.StatusForbidden, 1)
+	if _, err := checkAndUpdate(); !strings.Contains(err.Error(), "Zone Resources") {
+		t.Errorf("err = %v; want the zone scope diagnosis", err)
+	}
+}
+
+func TestDegradedMode(t *testing.T) {
+	config := &Config{Degraded: &DegradedConfig{After: 3, Interval: "1m"}}
+	d := &degradedMode{}
+	failure := errors.New("network unreachable")
+
+	for i := 1; i < 3; i++ {
+		if _, ok := d.observe(config, failure); ok {
+			t.Fatalf("degraded after %d failures; want 3", i)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if interval, ok := d.observe(config, failure); !ok || interval != time.Minute {
+			t.Fatalf("observe = %v, %v; want the degraded interval", interval, ok)
+		}
+	}
+	if _, ok := d.observe(config, nil); ok || d.failures != 0 {
+		t.Fatalf("still degraded after a successful cycle (failures = %d)", d.failures)
+	}
+
+	// 未配置时只计数，不进入降级模式
+	for i := 0; i < 50; i++ {
+		if _, ok := d.observe(&Config{}, failure); ok {
+			t.Fatal("degraded without a degraded config")
+		}
+	}
+}
+
+func TestReadyWithStretchedInterval(t *testing.T) {
+	// IP长期稳定后间隔拉长到上限：上一周期结束已超过2倍基础间隔，但仍在安排的间隔内
+	interval := newAdaptiveInterval(&Config{MaxCheckInterval: (8 * checkInterval).String()})
+	next := interval.base
+	for i := 0; i < 3*stableCyclesBeforeStretch; i++ {
+		next = interval.next(false, nil)
+	}
+	if next != 8*checkInterval {
+		t.Fatalf("stretched interval = %v; want %v", next, 8*checkInterval)
+	}
+	h := &healthState{lastAPIOK: true}
+	h.lastCycle = time.Now().Add(-5 * checkInterval)
+	h.markNextDelay(next)
+	if ok, reason := h.ready(); !ok {
+		t.Errorf("ready() = false, %q; want ready while waiting for a stretched cycle", reason)
+	}
+
+	// 降级模式的间隔同样计入
+	h.lastCycle = time.Now().Add(-defaultDegradedInterval - time.Minute)
+	h.markNextDelay(defaultDegradedInterval)
+	if ok, reason := h.ready(); !ok {
+		t.Errorf("ready() = false, %q; want ready while waiting for a degraded cycle", reason)
+	}
+
+	// 超过安排间隔的2倍仍未完成周期时视为超时
+	h.lastCycle = time.Now().Add(-3 * next)
+	h.markNextDelay(next)
+	if ok, _ := h.ready(); ok {
+		t.Error("ready() = true; want not ready after twice the scheduled interval")
+	}
+}
+
+func TestCycleTimingSummary(t *testing.T) {
+	timer := &cycleTimer{}
+	timer.phase("ip.detect")() // 周期外的阶段不计入
+	timer.begin()
+	timer.phase("ip.detect")()
+	for i := 0; i < 2; i++ {
+		timer.phase("dns.list")()
+	}
+	timer.phase("dns.update")()
+
+	summary := timer.summary()
+	for _, want := range []string{"ip.detect ", "dns.list 2×", "dns.update "} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary = %q; want it to contain %q", summary, want)
+		}
+	}
+	if strings.Index(summary, "ip.detect") > strings.Index(summary, "dns.list") {
+		t.Errorf("summary = %q; want phases in the order they ran", summary)
+	}
+	timer.end()
+	if summary := timer.summary(); summary != "" {
+		t.Errorf("summary after end = %q; want empty", summary)
+	}
+}
+
+func TestLogDeduper(t *testing.T) {
+	d := newLogDeduper(10 * time.Minute)
+	start := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	failure := "获取公网IP失败 (尝试 1/3): dial tcp: network is unreachable"
+
+	if _, ok := d.allow(failure, start); !ok {
+		t.Fatal("first occurrence suppressed")
+	}
+	for i := 1; i <= 100; i++ {
+		if _, ok := d.allow(failure, start.Add(time.Duration(i)*5*time.Second)); ok {
+			t.Fatalf("repeat %d was written; want it suppressed within the window", i)
+		}
+	}
+	if _, ok := d.allow("other error", start.Add(time.Minute)); !ok {
+		t.Fatal("a different message was suppressed")
+	}
+
+	summaries, ok := d.allow(failure, start.Add(11*time.Minute))
+	if !ok {
+		t.Fatal("message after the window was suppressed")
+	}
+	if len(summaries) != 1 || !strings.Contains(summaries[0], "100") || !strings.Contains(summaries[0], failure) {
+		t.Fatalf("summaries = %q; want one summary counting 100 repeats", summaries)
+	}
+
+	// 退出前输出未结束窗口的汇总；没有重复的消息不输出
+	d.allow(failure, start.Add(12*time.Minute))
+	if summaries := d.flush(); len(summaries) != 1 {
+		t.Fatalf("flush = %q; want one summary", summaries)
+	}
+
+	if newLogDeduper(0) != nil {
+		t.Error("newLogDeduper(0) != nil; want dedup disabled")
+	}
+}
+
+func TestWatchChanges(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	prev := watchSnapshot{
+		LocalIP:   "198.51.100.1",
+		Values:    []string{"198.51.100.1"},
+		Resolvers: []ResolverResult{{Resolver: "ns1", Status: propagationOK}, {Resolver: "1.1.1.1", Status: propagationOK}},
+	}
+	cur := watchSnapshot{
+		Time:      at,
+		LocalIP:   "198.51.100.2",
+		Values:    []string{"198.51.100.2"},
+		Resolvers: []ResolverResult{{Resolver: "ns1", Status: propagationOK}, {Resolver: "1.1.1.1", Status: propagationStale}},
+	}
+	changes := watchChanges(prev, cur)
+	if len(changes) != 3 || !strings.Contains(changes[2], "1.1.1.1: ok -> stale") {
+		t.Fatalf("changes = %q; want local IP, record value and 1.1.1.1", changes)
+	}
+	if changes := watchChanges(cur, cur); len(changes) != 0 {
+		t.Errorf("changes between identical snapshots = %q", changes)
+	}
+
+	var out strings.Builder
+	renderWatch(&out, testRecord, "A", watchSnapshot{Time: at, LocalIP: "198.51.100.2", Values: []string{"198.51.100.1"}}, changes)
+	if view := out.String(); !strings.Contains(view, tr("（与本机IP不一致）")) || !strings.Contains(view, changes[0]) {
+		t.Errorf("view = %q; want the mismatch and the change history", view)
+	}
+}
+
+func TestCycleLock(t *testing.T) {
+	dataDirOnce.Do(func() {})
+	previous := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = previous })
+
+	unlock, err := acquireCycleLock(time.Second)
+	if err != nil {
+		t.Fatalf("acquireCycleLock: %v", err)
+	}
+	// 另一个 once 或守护进程持有锁时等待，超时后放弃
+	if _, err := acquireCycleLock(300 * time.Millisecond); err == nil {
+		t.Fatal("second acquireCycleLock succeeded while the lock is held")
+	}
+	unlock()
+	unlock, err = acquireCycleLock(time.Second)
+	if err != nil {
+		t.Fatalf("acquireCycleLock after release: %v", err)
+	}
+	unlock()
+}
+
+func TestBinaryUpgradedAndResume(t *testing.T) {
+	path := t.TempDir() + "/dns_manager"
+	if err := os.WriteFile(path, []byte("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	startBinary.path, startBinary.info = path, info
+	t.Cleanup(func() { startBinary.path, startBinary.info = "", nil })
+	if _, upgraded := binaryUpgraded(); upgraded {
+		t.Fatal("unchanged binary reported as upgraded")
+	}
+
+	// 包管理器写入新文件后改名替换
+	if err := os.WriteFile(path+".new", []byte("v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path+".new", path); err != nil {
+		t.Fatal(err)
+	}
+	if got, upgraded := binaryUpgraded(); !upgraded || got != path {
+		t.Fatalf("binaryUpgraded = %q, %v; want the replaced binary", got, upgraded)
+	}
+
+	t.Cleanup(func() { startHookFired = false; app.SetCurrentIP("") })
+	t.Setenv(reexecEnv, "198.51.100.7")
+	if !resumeAfterReexec() || app.CurrentIP() != "198.51.100.7" || !startHookFired {
+		t.Fatalf("resumeAfterReexec did not restore the IP (%q) and start hook state", app.CurrentIP())
+	}
+	if _, ok := os.LookupEnv(reexecEnv); ok {
+		t.Error("re-exec marker still set after resuming")
+	}
+}
+
+func TestInitRuntimeIncompleteConfig(t *testing.T) {
+	dataDirOnce.Do(func() {})
+	previousDir, previousLogger, previousStdin := dataDir, globalLogger, os.Stdin
+	dataDir = t.TempDir()
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	// systemd 等环境中标准输入是 /dev/null，不能进入配置向导
+	os.Stdin = null
+	t.Cleanup(func() {
+		dataDir, globalLogger, os.Stdin = previousDir, previousLogger, previousStdin
+		app.ClearConfig()
+	})
+
+	if stdinIsTerminal() {
+		t.Fatal("stdinIsTerminal() = true for /dev/null")
+	}
+	err = initRuntime(runtimeOptions{interactive: true})
+	if code := exitCodeFor(err); code != exitConfig {
+		t.Fatalf("initRuntime() = %v (exit %d); want exit %d", err, code, exitConfig)
+	}
+	if !strings.Contains(err.Error(), "api_token, zone_id, record_name") {
+		t.Fatalf("error %q does not list the missing fields", err)
+	}
+}
+
+func TestUseSystemDataDir(t *testing.T) {
+	dataDirOnce.Do(func() {})
+	previous := dataDir
+	t.Cleanup(func() {
+		dataDirOnce = sync.Once{}
+		dataDirOnce.Do(func() {})
+		dataDir = previous
+	})
+
+	// 指定 --user 时不使用 root 的主目录
+	t.Setenv("DNS_MANAGER_HOME", "")
+	dataDirOnce = sync.Once{}
+	useSystemDataDir()
+	if got := getDataDir(); got != systemDataDir {
+		t.Fatalf("getDataDir() = %q; want %q", got, systemDataDir)
+	}
+
+	t.Setenv("DNS_MANAGER_HOME", "/srv/dns_manager")
+	dataDirOnce = sync.Once{}
+	useSystemDataDir()
+	if got := getDataDir(); got != "/srv/dns_manager" {
+		t.Fatalf("getDataDir() = %q; want DNS_MANAGER_HOME", got)
+	}
+	// 降权时在默认数据目录中记录所用的目录，之后不带 --user 的 status、stop、logs 等命令
+	// 能找到守护进程的PID文件与控制套接字
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DNS_MANAGER_HOME", "")
+	defaultDir := filepath.Join(home, ".go_dns_manager")
+	if got := resolveDataDir(); got != defaultDir {
+		t.Fatalf("resolveDataDir() = %q; want %q before running with --user", got, defaultDir)
+	}
+	if err := markDataDir(systemDataDir); err != nil {
+		t.Fatalf("markDataDir: %v", err)
+	}
+	dataDirOnce = sync.Once{}
+	if got, want := getPIDPath(), filepath.Join(systemDataDir, "dns_manager.pid"); got != want {
+		t.Fatalf("getPIDPath() = %q; want %q", got, want)
+	}
+	if got := getControlSocketPath(); !strings.HasPrefix(got, systemDataDir) {
+		t.Fatalf("getControlSocketPath() = %q; want it under %s", got, systemDataDir)
+	}
+	// DNS_MANAGER_HOME 仍然优先
+	t.Setenv("DNS_MANAGER_HOME", "/srv/dns_manager")
+	if got := resolveDataDir(); got != "/srv/dns_manager" {
+		t.Fatalf("resolveDataDir() = %q; want DNS_MANAGER_HOME", got)
+	}
+}
+
+func TestReconcileAgentAfterControllerRestart(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	setupApp(t, cf, newFakeIPService(t, "198.51.100.1"), "198.51.100.1")
+	const agentRecord = "nas." + testZoneName
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: agentRecord, Content: "198.51.100.10", TTL: 300})
+	// 控制器重启后不知道代理上次上报的IP
+	agentStatuses = make(map[string]*AgentStatus)
+	t.Cleanup(func() { agentStatuses = make(map[string]*AgentStatus) })
+
+	agent := AgentConfig{Name: "nas", Record: agentRecord}
+	result := reconcileAgent(agentReportRequest{agent: agent, ip: "198.51.100.20"})
+	if result.Error != "" || !result.Updated {
+		t.Fatalf("reconcileAgent() = %+v; want updated", result)
+	}
+	// 修改代理已有的记录，不新增一条
+	if got := cf.contents(testZoneID, agentRecord, "A"); !reflect.DeepEqual(got, []string{"198.51.100.20"}) {
+		t.Fatalf("records = %v; want [198.51.100.20]", got)
+	}
+}
+
+func TestNtfyEncodesTitle(t *testing.T) {
+	var title string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title = r.Header.Get("Title")
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{name: "ntfy", topicURL: server.URL}
+	if err := n.Send(NotifyEvent{Type: EventDNSUpdated, Title: "DNS记录已更新", Time: time.Now()}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(title)
+	if err != nil || decoded != "DNS Manager: DNS记录已更新" {
+		t.Fatalf("Title = %q (decoded %q, %v)", title, decoded, err)
+	}
+	for _, c := range title {
+		if c > 127 {
+			t.Fatalf("Title %q contains non-ASCII characters", title)
+		}
+	}
+}
+
+func TestConfigShowMasksSecrets(t *testing.T) {
+	dataDirOnce.Do(func() {})
+	previousDir, previousStdout := dataDir, os.Stdout
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir, os.Stdout = previousDir, previousStdout })
+
+	secrets := []string{
+		"cf-api-token-0001", "api-auth-token-0002", "agent-token-0003", "controller-token-0004",
+		"consul-token-0005", "ptr-token-0006", "ptr-password-0007", "tracing-header-0008",
+		"dingtalk-secret-0009", "dingtalk-access-0010", "gotify-token-0011", "bark-key-0012",
+		"slack-webhook-path-0013", "tsig-secret-0014", "ipinfo-token-0015", "pihole-pass-0016",
+		"pihole-api-token-0017", "adguard-pass-0018", "client-key-pem-0019", "proxy-password-0020",
+	}
+	config := &Config{
+		APIToken:     secrets[0],
+		ZoneID:       testZoneID,
+		RecordName:   testRecord,
+		RecordType:   "A",
+		APIAuthToken: secrets[1],
+		Agents:       []AgentConfig{{Name: "office", Token: secrets[2], Record: "office.example.com"}},
+		Controller:   &ControllerConfig{URL: "https://controller.example.com", Token: secrets[3]},
+		Consul:       &ConsulConfig{Address: "http://127.0.0.1:8500", Token: secrets[4]},
+		PTR:          &PTRConfig{Provider: "vultr", Token: secrets[5], Password: secrets[6]},
+		Tracing:      &TracingConfig{Headers: map[string]string{"x-honeycomb-team": secrets[7]}},
+		Notifications: []NotificationConfig{
+			{Type: "dingtalk", Webhook: "https://oapi.dingtalk.com/robot/send?access_token=" + secrets[9], Secret: secrets[8]},
+			{Type: "gotify", Server: "https://gotify.example.com", Token: secrets[10]},
+			{Type: "bark", Server: "https://api.day.app", Key: secrets[11]},
+			{Type: "slack", Webhook: "https://hooks.slack.com/services/T000/B000/" + secrets[12]},
+		},
+		SplitHorizon: &SplitHorizonConfig{
+			RFC2136: &RFC2136Config{Server: "192.0.2.53", Zone: "example.com", TSIGKey: "ddns", TSIGSecret: secrets[13]},
+			PiHole:  &PiHoleConfig{URL: "http://pi.hole", Password: secrets[15], APIToken: secrets[16]},
+			AdGuard: &AdGuardConfig{URL: "http://adguard.lan", Username: "admin", Password: secrets[17]},
+		},
+		Geo:  &GeoConfig{Provider: "ipinfo", Token: secrets[14]},
+		HTTP: &HTTPConfig{ClientKey: secrets[18], DoH: "https://user:" + secrets[19] + "@doh.example.com/dns-query"},
+	}
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	code := cmdConfigShow()
+	w.Close()
+	os.Stdout = previousStdout
+	out, _ := io.ReadAll(r)
+	if code != 0 {
+		t.Fatalf("cmdConfigShow() = %d; output:\n%s", code, out)
+	}
+
+	for _, secret := range secrets {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("config show printed secret %q", secret)
+		}
+	}
+	// 非敏感字段保持原样，输出仍是有效的 JSON
+	var shown Config
+	if err := json.Unmarshal(out, &shown); err != nil {
+		t.Fatalf("config show output is not JSON: %v\n%s", err, out)
+	}
+	if shown.RecordName != testRecord || shown.SplitHorizon.RFC2136.Server != "192.0.2.53" || shown.Agents[0].Record != "office.example.com" {
+		t.Errorf("config show changed non-secret fields:\n%s", out)
+	}
+}
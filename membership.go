@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceCheckConfig 本机服务健康检查：服务持续不可用时从多机器记录集中撤下本机的记录，恢复后重新加入
+type ServiceCheckConfig struct {
+	// Type 探测方式：tcp（默认）或 http
+	Type string `json:"type,omitempty"`
+	// Target tcp 时为 host:port，http 时为完整 URL（2xx/3xx 视为健康）
+	Target string `json:"target"`
+	// Timeout 单次探测超时（默认 "3s"）
+	Timeout string `json:"timeout,omitempty"`
+	// FailAfter 连续失败多久后撤下记录（默认 "30s"）
+	FailAfter string `json:"fail_after,omitempty"`
+}
+
+// 健康检查默认值
+const (
+	defaultServiceCheckTimeout   = 3 * time.Second
+	defaultServiceCheckFailAfter = 30 * time.Second
+)
+
+// timeout 返回单次探测超时
+func (c *ServiceCheckConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultServiceCheckTimeout
+}
+
+// failAfter 返回撤下记录前允许的连续失败时长
+func (c *ServiceCheckConfig) failAfter() time.Duration {
+	if d, err := parseDurationOrZero(c.FailAfter); err == nil {
+		return d
+	}
+	return defaultServiceCheckFailAfter
+}
+
+// validate 检查健康检查配置
+func (c *ServiceCheckConfig) validate() error {
+	switch strings.ToLower(c.Type) {
+	case "", "tcp":
+		if _, _, err := net.SplitHostPort(c.Target); err != nil {
+			return fmt.Errorf(tr("service_check.target 应为 host:port: %v"), err)
+		}
+	case "http":
+		if !strings.HasPrefix(c.Target, "http://") && !strings.HasPrefix(c.Target, "https://") {
+			return fmt.Errorf(tr("service_check.target 应为 http(s) URL: %s"), c.Target)
+		}
+	default:
+		return fmt.Errorf(tr("不支持的 service_check.type: %s"), c.Type)
+	}
+	return nil
+}
+
+// probe 执行一次探测
+func (c *ServiceCheckConfig) probe() error {
+	timeout := c.timeout()
+	if strings.ToLower(c.Type) == "http" {
+		client := &http.Client{Timeout: timeout}
+		resp, err := client.Get(c.Target)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", c.Target, timeout)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+// 成员状态变化
+const (
+	membershipKeep     = iota // 状态不变
+	membershipWithdraw        // 需要撤下本机记录
+	membershipRejoin          // 服务恢复，需要重新加入
+)
+
+// membershipState 本机在记录集中的成员状态
+type membershipState struct {
+	mu          sync.Mutex
+	failingFrom time.Time
+	lastErr     string
+	withdrawn   bool
+}
+
+var membership = &membershipState{}
+
+// evaluate 执行一次健康检查，返回成员状态的变化
+func (m *membershipState) evaluate(check *ServiceCheckConfig) int {
+	err := check.probe()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		m.failingFrom = time.Time{}
+		m.lastErr = ""
+		if m.withdrawn {
+			return membershipRejoin
+		}
+		return membershipKeep
+	}
+
+	m.lastErr = err.Error()
+	if m.failingFrom.IsZero() {
+		m.failingFrom = time.Now()
+		logError("本机服务健康检查失败 (%s): %v", check.Target, err)
+	} else {
+		logDebug("本机服务健康检查失败 (%s): %v", check.Target, err)
+	}
+	if !m.withdrawn && time.Since(m.failingFrom) >= check.failAfter() {
+		return membershipWithdraw
+	}
+	return membershipKeep
+}
+
+// isWithdrawn 本机记录是否已撤下
+func (m *membershipState) isWithdrawn() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.withdrawn
+}
+
+// setWithdrawn 更新撤下状态
+func (m *membershipState) setWithdrawn(withdrawn bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.withdrawn = withdrawn
+}
+
+// status 返回成员状态，供管理 API 输出
+func (m *membershipState) status() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := map[string]interface{}{"withdrawn": m.withdrawn}
+	if !m.failingFrom.IsZero() {
+		status["failing_since"] = m.failingFrom
+		status["last_error"] = m.lastErr
+	}
+	return status
+}
+
+// withdrawRecords 从各记录集中删除指向本机IP的记录。
+// 记录集中没有其他机器的记录时保留本机记录：服务不可用时返回旧地址总好过域名无法解析
+func withdrawRecords(provider *CloudflareClient, source string, targets []RecordTarget, ips ...string) error {
+	provider.SetAuditSource(source)
+	var failures []string
+	for _, target := range targets {
+		records, err := provider.GetAllDNSRecords(target.ZoneID, target.Name, target.Type)
+		if err != nil {
+			failures = append(failures, target.Name+": "+err.Error())
+			continue
+		}
+
+		var own []DNSRecord
+		for _, record := range records {
+			for _, ip := range ips {
+				if ip != "" && record.Content == ip {
+					own = append(own, record)
+					break
+				}
+			}
+		}
+		if len(own) == 0 {
+			continue
+		}
+		if len(own) == len(records) {
+			logError("%s 没有其他机器的记录，保留本机记录", target.Name)
+			continue
+		}
+
+		for _, record := range own {
+			if err := provider.DeleteDNSRecord(target.ZoneID, record); err != nil {
+				failures = append(failures, target.Name+": "+err.Error())
+				continue
+			}
+			logInfo("已从 %s 撤下本机记录 (%s)", target.Name, record.Content)
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf(tr("撤下本机记录失败: %s"), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// checkMembership 按健康检查结果撤下或恢复本机记录。
+// 返回本周期是否继续同步记录；rejoin 为 true 时调用方应忽略上次写入的IP，重新创建本机记录
+func checkMembership(config *Config, ip, currentIP string) (proceed, rejoin bool, err error) {
+	if err := config.ServiceCheck.validate(); err != nil {
+		logError("健康检查配置无效，不检查本机服务: %v", err)
+		return true, false, nil
+	}
+
+	switch membership.evaluate(config.ServiceCheck) {
+	case membershipWithdraw:
+		logError("本机服务持续不可用超过 %s，撤下本机记录", config.ServiceCheck.failAfter())
+		if err := withdrawRecords(app.Client(), "service-check", config.ipTargets(), ip, currentIP); err != nil {
+			logError("%v", err)
+			return false, false, err
+		}
+		membership.setWithdrawn(true)
+		app.SetCurrentIP("")
+		notify(NotifyEvent{
+			Type:    EventMembership,
+			Title:   tr("本机记录已撤下"),
+			Message: tr("本机服务健康检查持续失败，已从记录集中撤下本机记录"),
+			Record:  config.RecordName,
+			OldIP:   ip,
+		})
+		return false, false, nil
+	case membershipRejoin:
+		logInfo("本机服务已恢复，重新加入记录集")
+		membership.setWithdrawn(false)
+		notify(NotifyEvent{
+			Type:    EventMembership,
+			Title:   tr("本机记录已恢复"),
+			Message: tr("本机服务健康检查恢复正常，重新加入记录集"),
+			Record:  config.RecordName,
+			NewIP:   ip,
+		})
+		return true, true, nil
+	}
+	return !membership.isWithdrawn(), false, nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// mmdbMetadataMarker MaxMind DB 文件中元数据段之前的标记
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// mmdbReader MaxMind DB（.mmdb，如 GeoLite2-City、GeoLite2-ASN）的只读查询，
+// 只实现按IP查找记录所需的部分：搜索树遍历与数据段解码
+type mmdbReader struct {
+	tree       []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// DatabaseType 元数据中的数据库类型，如 GeoLite2-ASN
+	DatabaseType string
+}
+
+// openMMDB 读取整个数据库文件
+func openMMDB(path string) (*mmdbReader, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseMMDB(raw)
+}
+
+// parseMMDB 解析数据库内容
+func parseMMDB(raw []byte) (*mmdbReader, error) {
+	start := bytes.LastIndex(raw, mmdbMetadataMarker)
+	if start < 0 {
+		return nil, errors.New(tr("不是有效的 MaxMind DB 文件：未找到元数据"))
+	}
+	metaSection := raw[start+len(mmdbMetadataMarker):]
+	value, _, err := (&mmdbDecoder{buf: metaSection}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf(tr("解析 MaxMind DB 元数据失败: %v"), err)
+	}
+	meta, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New(tr("解析 MaxMind DB 元数据失败: 格式无效"))
+	}
+
+	r := &mmdbReader{
+		nodeCount:  mmdbUint(meta["node_count"]),
+		recordSize: mmdbUint(meta["record_size"]),
+		ipVersion:  mmdbUint(meta["ip_version"]),
+	}
+	r.DatabaseType, _ = meta["database_type"].(string)
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf(tr("不支持的 MaxMind DB 记录大小: %d"), r.recordSize)
+	}
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+16 > uint(start) {
+		return nil, errors.New(tr("解析 MaxMind DB 元数据失败: 格式无效"))
+	}
+	r.tree = raw[:treeSize]
+	r.data = raw[treeSize+16 : start]
+	return r, nil
+}
+
+// record 读取节点的左（bit 为 0）或右记录
+func (r *mmdbReader) record(node uint, bit byte) uint {
+	switch r.recordSize {
+	case 24:
+		b := r.tree[node*6+uint(bit)*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := r.tree[node*7:]
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(r.tree[node*8+uint(bit)*4:]))
+	}
+}
+
+// lookup 返回 ip 对应的记录，未收录时返回 nil
+func (r *mmdbReader) lookup(ip net.IP) (map[string]interface{}, error) {
+	addr := ip.To4()
+	if addr == nil {
+		if r.ipVersion == 4 {
+			return nil, nil
+		}
+		addr = ip.To16()
+	} else if r.ipVersion == 6 {
+		// IPv6 数据库中 IPv4 地址位于 ::/96
+		addr = append(make(net.IP, 12), addr...)
+	}
+
+	node := uint(0)
+	for i := 0; i < len(addr)*8 && node < r.nodeCount; i++ {
+		bit := (addr[i/8] >> (7 - uint(i%8))) & 1
+		node = r.record(node, bit)
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+	offset := node - r.nodeCount - 16
+	if offset >= uint(len(r.data)) {
+		return nil, errors.New(tr("MaxMind DB 搜索树指向的数据无效"))
+	}
+	value, _, err := (&mmdbDecoder{buf: r.data}).decode(offset)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := value.(map[string]interface{})
+	return record, nil
+}
+
+// mmdbDecoder 数据段解码，指针相对于 buf 的起始位置
+type mmdbDecoder struct {
+	buf []byte
+}
+
+var errMMDBTruncated = errors.New("truncated data")
+
+// decode 解码 offset 处的值，返回值与下一个值的位置
+func (d *mmdbDecoder) decode(offset uint) (interface{}, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, errMMDBTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+	kind := uint(ctrl >> 5)
+
+	if kind == 1 {
+		pointer, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+	if kind == 0 {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errMMDBTruncated
+		}
+		kind = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return nil, 0, errMMDBTruncated
+		}
+		extra := uint(0)
+		for _, b := range d.buf[offset : offset+n] {
+			extra = extra<<8 | uint(b)
+		}
+		offset += n
+		switch size {
+		case 29:
+			size = 29 + extra
+		case 30:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+	}
+
+	switch kind {
+	case 7: // map
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, next, err := d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			if k, ok := key.(string); ok {
+				m[k] = value
+			}
+			offset = next
+		}
+		return m, offset, nil
+	case 11: // array
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+			offset = next
+		}
+		return a, offset, nil
+	case 14: // boolean，值保存在 size 中
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errMMDBTruncated
+	}
+	b := d.buf[offset : offset+size]
+	next := offset + size
+	switch kind {
+	case 2: // UTF-8 字符串
+		return string(b), next, nil
+	case 3: // double
+		if size != 8 {
+			return nil, 0, errMMDBTruncated
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case 15: // float
+		if size != 4 {
+			return nil, 0, errMMDBTruncated
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case 5, 6, 8, 9: // uint16 / uint32 / int32 / uint64
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, next, nil
+	default: // bytes、uint128 等不需要的类型原样返回
+		return b, next, nil
+	}
+}
+
+// pointer 解码指针，返回指向的位置与指针之后的位置
+func (d *mmdbDecoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint((ctrl>>3)&0x3) + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errMMDBTruncated
+	}
+	v := uint(0)
+	if n < 4 {
+		v = uint(ctrl & 0x7)
+	}
+	for _, b := range d.buf[offset : offset+n] {
+		v = v<<8 | uint(b)
+	}
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+	return v, offset + n, nil
+}
+
+// mmdbUint 把解码后的整数转换为 uint
+func mmdbUint(v interface{}) uint {
+	n, _ := v.(uint64)
+	return uint(n)
+}
+
+// mmdbPath 按路径读取嵌套的值，如 mmdbPath(record, "country", "iso_code")
+func mmdbPath(record map[string]interface{}, keys ...interface{}) interface{} {
+	var value interface{} = record
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = m[k]
+		case int:
+			a, ok := value.([]interface{})
+			if !ok || k >= len(a) {
+				return nil
+			}
+			value = a[k]
+		}
+	}
+	return value
+}
@@ -0,0 +1,566 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// 通知事件类型
+const (
+	EventDNSUpdated    = "dns_updated"
+	EventError         = "error"
+	EventRecovered     = "recovered"
+	EventFlapping      = "flapping"
+	EventMembership    = "membership"
+	EventDrift         = "drift"
+	EventStartupReport = "startup_report"
+	EventDegraded      = "degraded"
+	EventAnomaly       = "anomaly"
+	EventMismatch      = "mismatch"
+	EventTest          = "test"
+)
+
+// NotifyEvent 通知事件
+type NotifyEvent struct {
+	Type    string
+	Title   string
+	Message string
+	Record  string
+	OldIP   string
+	NewIP   string
+	Time    time.Time
+	// CycleID 触发通知的检测周期的关联ID
+	CycleID string
+	// Geo 新IP的归属信息（配置 geo 时）
+	Geo *GeoInfo
+
+	// Channels 指定接收的渠道名称，为空时发送到所有普通渠道
+	Channels []string
+
+	// Rendered 渠道模板渲染后的内容，非空时替代默认格式
+	Rendered string
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Name() string
+	Send(event NotifyEvent) error
+}
+
+// NotificationConfig 通知渠道配置
+type NotificationConfig struct {
+	Type    string `json:"type"` // dingtalk / wecom / slack / discord
+	Name    string `json:"name,omitempty"`
+	Webhook string `json:"webhook,omitempty"`
+	Secret  string `json:"secret,omitempty"` // 钉钉加签密钥
+	URL     string `json:"url,omitempty"`    // ntfy 主题地址
+	Server  string `json:"server,omitempty"` // Gotify / Bark 服务器地址
+	Token   string `json:"token,omitempty"`  // ntfy 访问令牌 / Gotify 应用令牌
+	Key     string `json:"key,omitempty"`    // Bark 设备密钥
+	// Events 事件过滤: all（默认）/ change（仅记录变更）/ error（仅错误与IP频繁变化）
+	Events string `json:"events,omitempty"`
+	// Template 消息模板（Go text/template），可用字段见 NotifyTemplateData
+	Template string `json:"template,omitempty"`
+	// MinInterval 两条通知的最小间隔（如 "10m"），期间的通知被合并计数
+	MinInterval string `json:"min_interval,omitempty"`
+	// DedupWindow 相同内容通知的去重时间窗口，默认 10m，"0" 表示不去重
+	DedupWindow string `json:"dedup_window,omitempty"`
+}
+
+// defaultDedupWindow 默认去重时间窗口
+const defaultDedupWindow = 10 * time.Minute
+
+// notifyChannel 通知渠道及其事件过滤、模板和限流状态
+type notifyChannel struct {
+	notifier Notifier
+	events   string
+	tmpl     *template.Template
+
+	// escalationOnly 仅接收升级通知的渠道（notify_policy.escalate_to）
+	escalationOnly bool
+
+	minInterval time.Duration
+	dedupWindow time.Duration
+
+	mu         sync.Mutex
+	lastSent   time.Time
+	recent     map[string]time.Time // 去重键 -> 最近发送时间
+	suppressed int                  // 因限流被省略的通知数量
+}
+
+// NotifyTemplateData 通知模板可用的字段
+type NotifyTemplateData struct {
+	Type     string
+	Title    string
+	Message  string
+	Record   string
+	OldIP    string
+	NewIP    string
+	Hostname string
+	Time     string
+	CycleID  string
+	// Geo 新IP的归属信息，如 "AS4134 CHINANET-BACKBONE, Shanghai, Shanghai, CN"
+	Geo string
+}
+
+// allow 判断通知是否可以发送（去重与限流），返回此前被省略的通知数量
+func (c *notifyChannel) allow(event NotifyEvent) (bool, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := event.Time
+	key := event.Type + "|" + event.Record + "|" + event.NewIP + "|" + event.Message
+
+	if c.dedupWindow > 0 {
+		for k, t := range c.recent {
+			if now.Sub(t) >= c.dedupWindow {
+				delete(c.recent, k)
+			}
+		}
+		if _, ok := c.recent[key]; ok {
+			c.suppressed++
+			return false, 0
+		}
+	}
+
+	if c.minInterval > 0 && !c.lastSent.IsZero() && now.Sub(c.lastSent) < c.minInterval {
+		c.suppressed++
+		return false, 0
+	}
+
+	if c.dedupWindow > 0 {
+		c.recent[key] = now
+	}
+	c.lastSent = now
+	suppressed := c.suppressed
+	c.suppressed = 0
+	return true, suppressed
+}
+
+// render 按渠道模板渲染通知内容
+func (c *notifyChannel) render(event NotifyEvent) (string, error) {
+	hostname, _ := os.Hostname()
+	data := NotifyTemplateData{
+		Type:     event.Type,
+		Title:    event.Title,
+		Message:  event.Message,
+		Record:   event.Record,
+		OldIP:    event.OldIP,
+		NewIP:    event.NewIP,
+		Hostname: hostname,
+		Time:     event.Time.In(logLocation).Format(logTimeFormat),
+		CycleID:  event.CycleID,
+		Geo:      event.Geo.String(),
+	}
+
+	var b strings.Builder
+	if err := c.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// accepts 判断渠道是否接收该事件
+func (c *notifyChannel) accepts(event NotifyEvent) bool {
+	if len(event.Channels) > 0 {
+		found := false
+		for _, name := range event.Channels {
+			if name == c.notifier.Name() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	} else if c.escalationOnly {
+		return false
+	}
+
+	switch c.events {
+	case "change":
+		return event.Type == EventDNSUpdated
+	case "error":
+		return event.Type == EventError || event.Type == EventRecovered || event.Type == EventFlapping || event.Type == EventMembership || event.Type == EventDrift || event.Type == EventStartupReport || event.Type == EventDegraded || event.Type == EventAnomaly || event.Type == EventMismatch
+	default:
+		return true
+	}
+}
+
+var (
+	notifiers   []*notifyChannel
+	notifiersMu sync.RWMutex
+	notifyWG    sync.WaitGroup
+
+	// notifyHTTPClient 发送通知的 HTTP 客户端，在 initNotifiers 中按 http 配置与 --debug-http 创建
+	notifyHTTPClient   *http.Client
+	notifyHTTPClientMu sync.Mutex
+)
+
+// notifyTimeout 单个通知请求的超时
+const notifyTimeout = 10 * time.Second
+
+// notifyClient 返回发送通知的 HTTP 客户端；未初始化时（如测试中）使用默认设置
+func notifyClient() *http.Client {
+	notifyHTTPClientMu.Lock()
+	defer notifyHTTPClientMu.Unlock()
+	if notifyHTTPClient == nil {
+		notifyHTTPClient = &http.Client{Timeout: notifyTimeout, Transport: newHTTPTransport("notify")}
+	}
+	return notifyHTTPClient
+}
+
+// initNotifiers 根据配置创建通知渠道，escalateTo 中的渠道只接收升级通知；
+// 通知请求使用 httpConfig 中的超时、CA 证书与解析器设置
+func initNotifiers(cfgs []NotificationConfig, escalateTo []string, httpConfig *HTTPConfig) {
+	notifyHTTPClientMu.Lock()
+	notifyHTTPClient = &http.Client{Timeout: notifyTimeout, Transport: wrapTransport("notify", httpConfig.transport())}
+	notifyHTTPClientMu.Unlock()
+
+	var list []*notifyChannel
+	for _, cfg := range cfgs {
+		n, err := newNotifier(cfg)
+		if err != nil {
+			logError("通知渠道配置无效: %v", err)
+			continue
+		}
+
+		events := strings.ToLower(cfg.Events)
+		switch events {
+		case "", "all":
+			events = "all"
+		case "change", "error":
+		default:
+			logError("通知渠道 %s 的事件过滤无效: %s，将接收所有事件", n.Name(), cfg.Events)
+			events = "all"
+		}
+		channel := &notifyChannel{
+			notifier:    n,
+			events:      events,
+			dedupWindow: defaultDedupWindow,
+			recent:      make(map[string]time.Time),
+		}
+		for _, name := range escalateTo {
+			if name == n.Name() {
+				channel.escalationOnly = true
+			}
+		}
+
+		if cfg.Template != "" {
+			tmpl, err := template.New(n.Name()).Parse(cfg.Template)
+			if err != nil {
+				logError("通知渠道 %s 的模板无效: %v，将使用默认格式", n.Name(), err)
+			} else {
+				channel.tmpl = tmpl
+			}
+		}
+		if cfg.MinInterval != "" {
+			if d, err := time.ParseDuration(cfg.MinInterval); err != nil {
+				logError("通知渠道 %s 的 min_interval 无效: %v", n.Name(), err)
+			} else {
+				channel.minInterval = d
+			}
+		}
+		if cfg.DedupWindow != "" {
+			if d, err := parseDurationOrZero(cfg.DedupWindow); err != nil {
+				logError("通知渠道 %s 的 dedup_window 无效: %v", n.Name(), err)
+			} else {
+				channel.dedupWindow = d
+			}
+		}
+
+		list = append(list, channel)
+	}
+
+	notifiersMu.Lock()
+	notifiers = list
+	notifiersMu.Unlock()
+
+	if len(list) > 0 {
+		logInfo("已启用 %d 个通知渠道", len(list))
+	}
+}
+
+// newNotifier 根据类型创建通知渠道
+func newNotifier(cfg NotificationConfig) (Notifier, error) {
+	registerWebhookSecrets(cfg.Webhook)
+	registerSecret(cfg.Secret)
+	registerSecret(cfg.Token)
+	registerSecret(cfg.Key)
+
+	switch strings.ToLower(cfg.Type) {
+	case "dingtalk":
+		if cfg.Webhook == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 webhook"), cfg.Type)
+		}
+		return &DingTalkNotifier{name: notifierName(cfg), webhook: cfg.Webhook, secret: cfg.Secret}, nil
+	case "wecom":
+		if cfg.Webhook == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 webhook"), cfg.Type)
+		}
+		return &WeComNotifier{name: notifierName(cfg), webhook: cfg.Webhook}, nil
+	case "slack":
+		if cfg.Webhook == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 webhook"), cfg.Type)
+		}
+		return &SlackNotifier{name: notifierName(cfg), webhook: cfg.Webhook}, nil
+	case "discord":
+		if cfg.Webhook == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 webhook"), cfg.Type)
+		}
+		return &DiscordNotifier{name: notifierName(cfg), webhook: cfg.Webhook}, nil
+	case "ntfy":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 %s"), cfg.Type, "url")
+		}
+		return &NtfyNotifier{name: notifierName(cfg), topicURL: cfg.URL, token: cfg.Token}, nil
+	case "gotify":
+		if cfg.Server == "" || cfg.Token == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 %s"), cfg.Type, "server/token")
+		}
+		return &GotifyNotifier{name: notifierName(cfg), server: strings.TrimRight(cfg.Server, "/"), token: cfg.Token}, nil
+	case "bark":
+		if cfg.Key == "" {
+			return nil, fmt.Errorf(tr("%s 通知缺少 %s"), cfg.Type, "key")
+		}
+		server := strings.TrimRight(cfg.Server, "/")
+		if server == "" {
+			server = "https://api.day.app"
+		}
+		return &BarkNotifier{name: notifierName(cfg), server: server, key: cfg.Key}, nil
+	case "desktop":
+		return newDesktopNotifier(notifierName(cfg))
+	default:
+		return nil, fmt.Errorf(tr("不支持的通知类型: %s"), cfg.Type)
+	}
+}
+
+func notifierName(cfg NotificationConfig) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return cfg.Type
+}
+
+// registerWebhookSecrets 将 webhook 地址中的令牌注册为敏感值
+// 包括查询参数中的令牌（钉钉、企业微信）和路径末段的令牌（Slack、Discord）
+func registerWebhookSecrets(webhook string) {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return
+	}
+	for _, key := range []string{"access_token", "key", "token"} {
+		if value := u.Query().Get(key); value != "" {
+			registerSecret(value)
+		}
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if last := segments[len(segments)-1]; len(last) >= 16 {
+		registerSecret(last)
+	}
+}
+
+// notify 异步发送通知到所有渠道，发送失败只记录日志
+func notify(event NotifyEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.CycleID == "" {
+		event.CycleID = currentCycleID()
+	}
+
+	notifiersMu.RLock()
+	list := notifiers
+	notifiersMu.RUnlock()
+
+	for _, c := range list {
+		if !c.accepts(event) {
+			continue
+		}
+
+		ok, suppressed := c.allow(event)
+		if !ok {
+			logInfo("通知已被限流或去重 (%s): %s", c.notifier.Name(), event.Title)
+			continue
+		}
+
+		channelEvent := event
+		if suppressed > 0 {
+			channelEvent.Message = strings.TrimSpace(channelEvent.Message + "\n" +
+				fmt.Sprintf(tr("（此前有 %d 条通知因限流被省略）"), suppressed))
+		}
+		if c.tmpl != nil {
+			rendered, err := c.render(channelEvent)
+			if err != nil {
+				logError("渲染通知模板失败 (%s): %v", c.notifier.Name(), err)
+			} else {
+				channelEvent.Rendered = rendered
+			}
+		}
+
+		notifyWG.Add(1)
+		go func(n Notifier, ev NotifyEvent) {
+			defer notifyWG.Done()
+			if err := n.Send(ev); err != nil {
+				logError("发送通知失败 (%s): %v", n.Name(), err)
+			}
+		}(c.notifier, channelEvent)
+	}
+}
+
+// waitNotifications 等待正在发送的通知完成（最多等待 timeout）
+func waitNotifications(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		notifyWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// notifyTestResult 测试通知的发送结果
+type notifyTestResult struct {
+	Channel string `json:"channel"`
+	Type    string `json:"type"`
+	Error   string `json:"error,omitempty"`
+}
+
+// testNotifications 按配置逐个创建渠道并同步发送一条测试通知，不受事件过滤、限流与去重影响；
+// 配置无效的渠道同样返回结果
+func testNotifications(cfgs []NotificationConfig) []notifyTestResult {
+	hostname, _ := os.Hostname()
+	event := NotifyEvent{
+		Type:    EventTest,
+		Title:   tr("测试通知"),
+		Message: fmt.Sprintf(tr("这是一条来自 %s 的测试通知，收到说明该渠道配置正确"), hostname),
+		Record:  app.Config().RecordName,
+		Time:    time.Now(),
+	}
+
+	results := make([]notifyTestResult, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		result := notifyTestResult{Channel: notifierName(cfg), Type: cfg.Type}
+		n, err := newNotifier(cfg)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		channelEvent := event
+		if cfg.Template != "" {
+			tmpl, err := template.New(n.Name()).Parse(cfg.Template)
+			if err == nil {
+				channelEvent.Rendered, err = (&notifyChannel{tmpl: tmpl}).render(event)
+			}
+			if err != nil {
+				result.Error = fmt.Sprintf(tr("模板无效: %v"), err)
+				results = append(results, result)
+				continue
+			}
+		}
+		if err := n.Send(channelEvent); err != nil {
+			result.Error = redactSecrets(err.Error())
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// formatNotifyText 生成纯文本通知内容（标题 + 正文）
+func formatNotifyText(event NotifyEvent) string {
+	if event.Rendered != "" {
+		return event.Rendered
+	}
+	return fmt.Sprintf("[DNS Manager] %s\n%s", event.Title, formatNotifyBody(event))
+}
+
+// formatNotifyBody 生成通知正文（不含标题），用于标题与正文分开发送的渠道
+func formatNotifyBody(event NotifyEvent) string {
+	if event.Rendered != "" {
+		return event.Rendered
+	}
+	hostname, _ := os.Hostname()
+
+	var b strings.Builder
+	if event.Message != "" {
+		fmt.Fprintf(&b, "%s\n", event.Message)
+	}
+	if event.Record != "" {
+		fmt.Fprintf(&b, tr("记录: %s\n"), event.Record)
+	}
+	if event.OldIP != "" || event.NewIP != "" {
+		fmt.Fprintf(&b, "IP: %s -> %s\n", displayIP(event.OldIP), event.NewIP)
+	}
+	if location := event.Geo.String(); location != "" {
+		fmt.Fprintf(&b, tr("归属: %s\n"), location)
+	}
+	fmt.Fprintf(&b, tr("主机: %s\n"), hostname)
+	fmt.Fprintf(&b, tr("时间: %s"), event.Time.In(logLocation).Format(logTimeFormat))
+	if event.CycleID != "" {
+		fmt.Fprintf(&b, tr("\n周期: %s"), event.CycleID)
+	}
+	return b.String()
+}
+
+func displayIP(ip string) string {
+	if ip == "" {
+		return tr("(无)")
+	}
+	return ip
+}
+
+// parseDurationOrZero 解析时长，"0" 表示禁用
+func parseDurationOrZero(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// postJSON 发送 JSON 请求并返回响应内容
+func postJSON(endpoint string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	resp, err := notifyClient().Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, redactError(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
+	}
+	return body, nil
+}
+
+// checkErrcode 检查钉钉/企业微信机器人的 errcode 响应
+func checkErrcode(body []byte) error {
+	var result struct {
+		Errcode int    `json:"errcode"`
+		Errmsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if result.Errcode != 0 {
+		return fmt.Errorf(tr("机器人返回错误 (errcode: %d): %s"), result.Errcode, result.Errmsg)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// desktopNotifyTimeout 调用桌面通知命令的超时
+const desktopNotifyTimeout = 10 * time.Second
+
+// desktopScript macOS 通过参数传入标题与内容，避免在 AppleScript 中转义
+var desktopScript = []string{
+	"-e", "on run argv",
+	"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+	"-e", "end run",
+}
+
+// DesktopNotifier 桌面通知：Linux 调用 notify-send，macOS 调用 osascript；
+// 需要运行在已登录的桌面会话中（前台运行或从交互式菜单启动），作为系统服务运行时通常无法显示
+type DesktopNotifier struct {
+	name    string
+	command string
+}
+
+// newDesktopNotifier 查找当前系统的桌面通知命令
+func newDesktopNotifier(name string) (*DesktopNotifier, error) {
+	command := "notify-send"
+	if runtime.GOOS == "darwin" {
+		command = "osascript"
+	} else if runtime.GOOS == "windows" {
+		return nil, errors.New(tr("desktop 通知目前只支持 Linux（notify-send）与 macOS"))
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, fmt.Errorf(tr("desktop 通知需要 %s: %v"), command, err)
+	}
+	return &DesktopNotifier{name: name, command: path}, nil
+}
+
+func (n *DesktopNotifier) Name() string {
+	return n.name
+}
+
+func (n *DesktopNotifier) Send(event NotifyEvent) error {
+	title := "DNS Manager: " + event.Title
+	body := formatNotifyBody(event)
+
+	var args []string
+	if runtime.GOOS == "darwin" {
+		args = append(append(args, desktopScript...), title, body)
+	} else {
+		urgency := "normal"
+		if event.Type == EventError || event.Type == EventDegraded {
+			urgency = "critical"
+		}
+		args = []string{"--app-name", "DNS Manager", "--urgency", urgency, title, body}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), desktopNotifyTimeout)
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, n.command, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NtfyNotifier ntfy 主题推送（ntfy.sh 或自建服务）
+type NtfyNotifier struct {
+	name     string
+	topicURL string
+	token    string
+}
+
+func (n *NtfyNotifier) Name() string {
+	return n.name
+}
+
+func (n *NtfyNotifier) Send(event NotifyEvent) error {
+	req, err := http.NewRequest("POST", n.topicURL, strings.NewReader(formatNotifyBody(event)))
+	if err != nil {
+		return err
+	}
+	// 标题默认为中文，HTTP 头按 RFC 2047 编码（ntfy 会解码），纯 ASCII 时保持原样
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", "DNS Manager: "+event.Title))
+	if event.Type == EventError {
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", "warning")
+	} else {
+		req.Header.Set("Tags", "globe_with_meridians")
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := notifyClient().Do(req)
+	if err != nil {
+		return redactError(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf(tr("服务返回状态码: %d"), resp.StatusCode)
+	}
+	return nil
+}
+
+// GotifyNotifier Gotify 消息推送
+type GotifyNotifier struct {
+	name   string
+	server string
+	token  string
+}
+
+func (n *GotifyNotifier) Name() string {
+	return n.name
+}
+
+func (n *GotifyNotifier) Send(event NotifyEvent) error {
+	priority := 5
+	if event.Type == EventError {
+		priority = 8
+	}
+
+	_, err := postJSON(n.server+"/message?token="+n.token, map[string]interface{}{
+		"title":    "DNS Manager: " + event.Title,
+		"message":  formatNotifyBody(event),
+		"priority": priority,
+	})
+	return err
+}
+
+// BarkNotifier Bark（iOS）推送
+type BarkNotifier struct {
+	name   string
+	server string
+	key    string
+}
+
+func (n *BarkNotifier) Name() string {
+	return n.name
+}
+
+func (n *BarkNotifier) Send(event NotifyEvent) error {
+	body, err := postJSON(n.server+"/push", map[string]interface{}{
+		"device_key": n.key,
+		"title":      "DNS Manager: " + event.Title,
+		"body":       formatNotifyBody(event),
+		"group":      "dns_manager",
+	})
+	if err != nil {
+		return err
+	}
+	return checkBarkResponse(body)
+}
+
+// checkBarkResponse 检查 Bark 响应中的 code 字段
+func checkBarkResponse(body []byte) error {
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if result.Code != 200 {
+		return fmt.Errorf(tr("Bark 返回错误 (code: %d): %s"), result.Code, result.Message)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DingTalkNotifier 钉钉群机器人
+type DingTalkNotifier struct {
+	name    string
+	webhook string
+	secret  string
+}
+
+func (n *DingTalkNotifier) Name() string {
+	return n.name
+}
+
+func (n *DingTalkNotifier) Send(event NotifyEvent) error {
+	endpoint := n.webhook
+	if n.secret != "" {
+		signed, err := dingTalkSign(endpoint, n.secret, time.Now())
+		if err != nil {
+			return err
+		}
+		endpoint = signed
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]string{
+			"content": formatNotifyText(event),
+		},
+	}
+
+	body, err := postJSON(endpoint, payload)
+	if err != nil {
+		return err
+	}
+	return checkErrcode(body)
+}
+
+// dingTalkSign 按钉钉加签规则在 webhook 上附加 timestamp 与 sign 参数
+func dingTalkSign(webhook, secret string, now time.Time) (string, error) {
+	timestamp := fmt.Sprintf("%d", now.UnixMilli())
+	stringToSign := timestamp + "\n" + secret
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return "", fmt.Errorf(tr("无效的 webhook 地址: %v"), err)
+	}
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", sign)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// WeComNotifier 企业微信群机器人
+type WeComNotifier struct {
+	name    string
+	webhook string
+}
+
+func (n *WeComNotifier) Name() string {
+	return n.name
+}
+
+func (n *WeComNotifier) Send(event NotifyEvent) error {
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]string{
+			"content": strings.TrimSpace(formatNotifyText(event)),
+		},
+	}
+
+	body, err := postJSON(n.webhook, payload)
+	if err != nil {
+		return err
+	}
+	return checkErrcode(body)
+}
@@ -0,0 +1,35 @@
+package main
+
+// SlackNotifier Slack Incoming Webhook
+type SlackNotifier struct {
+	name    string
+	webhook string
+}
+
+func (n *SlackNotifier) Name() string {
+	return n.name
+}
+
+func (n *SlackNotifier) Send(event NotifyEvent) error {
+	_, err := postJSON(n.webhook, map[string]string{
+		"text": formatNotifyText(event),
+	})
+	return err
+}
+
+// DiscordNotifier Discord Webhook
+type DiscordNotifier struct {
+	name    string
+	webhook string
+}
+
+func (n *DiscordNotifier) Name() string {
+	return n.name
+}
+
+func (n *DiscordNotifier) Send(event NotifyEvent) error {
+	_, err := postJSON(n.webhook, map[string]string{
+		"content": formatNotifyText(event),
+	})
+	return err
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// observerState 观察模式（observe_only）：检测IP并与DNS记录比较，不一致时只告警，不修改任何记录。
+// 适合由人工或其他系统修改记录、只需要监控的场景，API 令牌只需要 Zone - DNS - Read 权限
+type observerState struct {
+	mu sync.Mutex
+	// alerted 最近一次告警的内容，不一致的情况没有变化时不重复通知
+	alerted string
+}
+
+var observer = &observerState{}
+
+// report 比较本周期的计划结果：存在需要修改的记录时告警，返回记录是否都已指向 ip
+func (o *observerState) report(config *Config, ip string, results []SyncResult) bool {
+	var mismatches []string
+	failed := false
+	for _, result := range results {
+		if result.Err != nil {
+			failed = true
+			continue
+		}
+		if result.Action == planNone {
+			continue
+		}
+		current := tr("无记录")
+		if len(result.Before) > 0 {
+			current = strings.Join(result.Before, ", ")
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%s: %s", result.Target.Name, current))
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(mismatches) == 0 {
+		if failed {
+			return false
+		}
+		if o.alerted != "" {
+			logInfo("观察模式: DNS记录已指向公网IP %s", ip)
+			o.alerted = ""
+		}
+		return true
+	}
+
+	summary := strings.Join(mismatches, "; ")
+	logError("观察模式: %d 个记录未指向公网IP %s: %s", len(mismatches), ip, summary)
+	if key := ip + "|" + summary; key != o.alerted {
+		o.alerted = key
+		notify(NotifyEvent{
+			Type:    EventMismatch,
+			Title:   tr("DNS记录与公网IP不一致"),
+			Message: fmt.Sprintf(tr("%d 个记录未指向公网IP %s（观察模式，未修改）: %s"), len(mismatches), ip, summary),
+			Record:  config.RecordName,
+			NewIP:   ip,
+			Geo:     geo.cached(ip),
+		})
+	}
+	return false
+}
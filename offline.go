@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// 离线探测：检测周期失败且 Cloudflare API 无法连接时，暂停完整的检测周期，
+// 改为每隔几秒做一次 TCP 连接探测，连接恢复后立即执行检测并同步期间变化的IP
+const (
+	offlineProbeTimeout  = 3 * time.Second
+	offlineProbeInterval = 3 * time.Second
+)
+
+// offlineQueue 离线状态与待同步的IP
+type offlineQueue struct {
+	mu           sync.Mutex
+	offline      bool
+	since        time.Time
+	lastDetected string
+}
+
+var offline = &offlineQueue{}
+
+// probeConnectivity 探测能否连接 Cloudflare API（只建立 TCP 连接，不发送请求）
+func probeConnectivity() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), offlineProbeTimeout)
+	defer cancel()
+	// 与 API 请求使用同一解析方式，系统解析器被污染时不会误判为离线
+	dial := app.Config().HTTP.dialContext(&net.Dialer{Timeout: offlineProbeTimeout})
+	conn, err := dial(ctx, "tcp", apiProbeAddr())
+	if err != nil {
+		logDebug("连接探测失败: %v", err)
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// observe 记录最近检测到的公网IP
+func (q *offlineQueue) observe(ip string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastDetected = ip
+}
+
+// pendingIP 返回检测到但尚未同步的IP
+func (q *offlineQueue) pendingIP() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.lastDetected == app.CurrentIP() {
+		return ""
+	}
+	return q.lastDetected
+}
+
+// enter 周期失败后探测连通性，无法连接时进入离线状态；返回是否处于离线状态
+func (q *offlineQueue) enter() bool {
+	if probeConnectivity() {
+		return false
+	}
+
+	pending := q.pendingIP()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.offline {
+		q.offline = true
+		q.since = time.Now()
+		if pending != "" {
+			logError("网络不可用，暂停检测，每 %s 探测一次连接；待同步的IP: %s", offlineProbeInterval, pending)
+		} else {
+			logError("网络不可用，暂停检测，每 %s 探测一次连接", offlineProbeInterval)
+		}
+	}
+	return true
+}
+
+// ready 定时器到期时判断是否执行检测周期：离线期间只探测连接，恢复后立即返回 true
+func (q *offlineQueue) ready() bool {
+	q.mu.Lock()
+	isOffline, since := q.offline, q.since
+	q.mu.Unlock()
+
+	if !isOffline {
+		return true
+	}
+	if !probeConnectivity() {
+		return false
+	}
+
+	q.mu.Lock()
+	q.offline = false
+	q.mu.Unlock()
+	logInfo("网络已恢复（离线 %s），立即执行检测", time.Since(since).Round(time.Second))
+	return true
+}
+
+// status 返回离线状态，供管理 API 输出
+func (q *offlineQueue) status() map[string]interface{} {
+	pending := q.pendingIP()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.offline {
+		return nil
+	}
+	status := map[string]interface{}{"since": q.since}
+	if pending != "" {
+		status["pending_ip"] = pending
+	}
+	return status
+}
+
+// scheduleNext 根据周期结果计算下次唤醒前的等待时间：网络不可用时转入离线探测
+func scheduleNext(interval *adaptiveInterval, changed bool, err error) time.Duration {
+	if err != nil && offline.enter() {
+		return offlineProbeInterval
+	}
+	next := interval.next(changed, err)
+	if degraded, ok := degradation.observe(app.Config(), err); ok {
+		return degraded
+	}
+	return next
+}
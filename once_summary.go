@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// OnceSummary once 模式结束时输出的结构化结果，cron 等包装脚本无需解析日志文本即可判断结果
+type OnceSummary struct {
+	Success bool   `json:"success"`
+	Record  string `json:"record"`
+	Type    string `json:"type"`
+	IP      string `json:"ip,omitempty"`
+	Source  string `json:"source,omitempty"`
+	// Action 所有记录中最主要的操作：update、create 或 none
+	Action     string              `json:"action"`
+	Records    []OnceRecordSummary `json:"records,omitempty"`
+	DryRun     bool                `json:"dry_run,omitempty"`
+	StartedAt  time.Time           `json:"started_at"`
+	DurationMS int64               `json:"duration_ms"`
+	Error      string              `json:"error,omitempty"`
+	Category   string              `json:"category,omitempty"`
+	ExitCode   int                 `json:"exit_code"`
+}
+
+// OnceRecordSummary 一个记录的同步结果与前后的记录值
+type OnceRecordSummary struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	IP     string   `json:"ip"`
+	Action string   `json:"action"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// cycleOutcome 收集当前检测周期检测到的IP与各记录的同步结果
+type cycleOutcome struct {
+	mu      sync.Mutex
+	ip      string
+	source  string
+	dryRun  bool
+	records []OnceRecordSummary
+}
+
+var lastCycle = &cycleOutcome{}
+
+// begin 开始一个检测周期
+func (o *cycleOutcome) begin() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ip, o.source, o.dryRun, o.records = "", "", false, nil
+}
+
+// detected 记录主IP的检测结果
+func (o *cycleOutcome) detected(ip, source string, dryRun bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ip, o.source, o.dryRun = ip, source, dryRun
+}
+
+// synced 记录同步到 ip 的结果
+func (o *cycleOutcome) synced(ip string, results []SyncResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, result := range results {
+		record := OnceRecordSummary{
+			Name:   result.Target.Name,
+			Type:   result.Target.Type,
+			IP:     ip,
+			Action: result.Action,
+			Before: result.Before,
+			After:  result.After,
+		}
+		if result.Err != nil {
+			record.Error = redactSecrets(result.Err.Error())
+		}
+		o.records = append(o.records, record)
+	}
+}
+
+// summary 生成 once 模式的结构化结果
+func (o *cycleOutcome) summary(config *Config, start time.Time, err error) OnceSummary {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := OnceSummary{
+		Success:    err == nil,
+		IP:         o.ip,
+		Source:     o.source,
+		Action:     planNone,
+		Records:    o.records,
+		DryRun:     o.dryRun,
+		StartedAt:  start,
+		DurationMS: time.Since(start).Milliseconds(),
+		ExitCode:   exitCodeFor(err),
+	}
+	if config != nil {
+		s.Record = config.RecordName
+		s.Type = config.RecordType
+	}
+	for _, record := range o.records {
+		// 写入失败的记录没有改变，不计入操作
+		if sameAnswers(record.Before, record.After) {
+			continue
+		}
+		if record.Action == planUpdate || (record.Action == planCreate && s.Action == planNone) {
+			s.Action = record.Action
+		}
+	}
+	if err != nil {
+		s.Error = redactSecrets(err.Error())
+		s.Category = errorCategory(err)
+	}
+	return s
+}
+
+// marshalOnceSummary 以缩进格式编码结构化结果
+func marshalOnceSummary(s OnceSummary) []byte {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return append(data, '\n')
+}
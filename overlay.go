@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net"
+)
+
+// Tailscale 为节点分配的地址范围（CGNAT 段与 Tailscale 的 ULA 前缀）
+var (
+	_, tailscaleIPv4Range, _ = net.ParseCIDR("100.64.0.0/10")
+	_, tailscaleIPv6Range, _ = net.ParseCIDR("fd7a:115c:a1e0::/48")
+)
+
+// isTailscaleIP 判断地址是否属于 Tailscale
+func isTailscaleIP(ip net.IP) bool {
+	return tailscaleIPv4Range.Contains(ip) || tailscaleIPv6Range.Contains(ip)
+}
+
+// overlayAddress 从接口地址中选出第一个与记录类型一致且满足 match 的地址（match 为空时不限制），
+// 跳过链路本地地址
+func overlayAddress(addrs []net.Addr, recordType string, match func(net.IP) bool) net.IP {
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() || !ipMatchesType(ipNet.IP.String(), recordType) {
+			continue
+		}
+		if match == nil || match(ipNet.IP) {
+			return ipNet.IP
+		}
+	}
+	return nil
+}
+
+// tailscaleAddress 读取本机的 Tailscale 地址。各平台的接口名称不同
+// （Linux 为 tailscale0，macOS 为 utunN），因此按地址范围在全部接口中查找
+func tailscaleAddress(recordType string) (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口失败: %v"), err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		if ip := overlayAddress(addrs, recordType, isTailscaleIP); ip != nil {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf(tr("没有找到 Tailscale 的 %s 地址（tailscaled 是否已登录？）"), recordType)
+}
+
+// wireguardAddress 读取 WireGuard 接口上的地址（通常是私有地址）
+func wireguardAddress(name, recordType string) (string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), name, err)
+	}
+	if ip := overlayAddress(addrs, recordType, nil); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf(tr("网络接口 %s 上没有 %s 地址"), name, recordType)
+}
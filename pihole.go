@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PiHoleConfig 通过 Pi-hole 的本地DNS记录（Local DNS → DNS Records）维护内部记录。
+// Pi-hole v6 使用 Password（网页密码或应用密码），v5 使用 APIToken
+type PiHoleConfig struct {
+	// URL Pi-hole 地址，如 http://192.168.1.2
+	URL      string `json:"url"`
+	Password string `json:"password,omitempty"`
+	// APIToken v5 的 API 令牌（设置 → API），设置后使用 v5 的 /admin/api.php
+	APIToken string `json:"api_token,omitempty"`
+}
+
+// piholeHost 一条本地DNS记录
+type piholeHost struct {
+	IP     string
+	Domain string
+}
+
+func (c *PiHoleConfig) String() string {
+	return "pihole " + c.URL
+}
+
+func (c *PiHoleConfig) client() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second, Transport: newHTTPTransport("pihole")}
+}
+
+// setAddress 删除各名称指向其他同类型地址的记录，再添加指向 ip 的记录
+func (c *PiHoleConfig) setAddress(names []string, recordType, ip string, ttl int) error {
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer api.close()
+
+	hosts, err := api.list()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		exists := false
+		for _, host := range hosts {
+			if !strings.EqualFold(host.Domain, name) {
+				continue
+			}
+			if host.IP == ip {
+				exists = true
+				continue
+			}
+			if addr := net.ParseIP(host.IP); addr != nil && (addr.To4() == nil) == (recordType == "AAAA") {
+				if err := api.remove(host); err != nil {
+					return err
+				}
+			}
+		}
+		if !exists {
+			if err := api.add(piholeHost{IP: ip, Domain: name}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// piholeAPI Pi-hole 本地DNS记录的读写接口，v5 与 v6 各有实现
+type piholeAPI interface {
+	list() ([]piholeHost, error)
+	add(host piholeHost) error
+	remove(host piholeHost) error
+	close()
+}
+
+// connect 按配置选择 API 版本；v6 先登录取得会话
+func (c *PiHoleConfig) connect() (piholeAPI, error) {
+	if c.APIToken != "" {
+		registerSecret(c.APIToken)
+		return &piholeV5{config: c}, nil
+	}
+	api := &piholeV6{config: c}
+	if c.Password == "" {
+		// 未设置密码的 Pi-hole 不需要登录
+		return api, nil
+	}
+	registerSecret(c.Password)
+	var result struct {
+		Session struct {
+			Valid bool   `json:"valid"`
+			SID   string `json:"sid"`
+		} `json:"session"`
+	}
+	if err := api.do("POST", "/api/auth", map[string]string{"password": c.Password}, &result); err != nil {
+		return nil, err
+	}
+	if !result.Session.Valid {
+		return nil, classify(ErrAuth, errors.New(tr("Pi-hole 登录失败：密码错误")))
+	}
+	api.sid = result.Session.SID
+	registerSecret(api.sid)
+	return api, nil
+}
+
+// piholeError 把响应状态码转换为错误
+func piholeError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return classify(ErrAuth, fmt.Errorf(tr("Pi-hole 认证失败 (状态码: %d)"), resp.StatusCode))
+	}
+	return fmt.Errorf(tr("Pi-hole 返回错误 (状态码: %d)"), resp.StatusCode)
+}
+
+// piholeV6 Pi-hole v6 的 REST API（/api/config/dns/hosts）
+type piholeV6 struct {
+	config *PiHoleConfig
+	sid    string
+}
+
+func (p *piholeV6) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf(tr("序列化请求失败: %v"), err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(p.config.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.sid != "" {
+		req.Header.Set("X-FTL-SID", p.sid)
+	}
+
+	resp, err := p.config.client().Do(req)
+	if err != nil {
+		return fmt.Errorf(tr("连接 Pi-hole 失败: %v"), redactError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return piholeError(resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf(tr("解析响应失败: %v"), err)
+		}
+	}
+	return nil
+}
+
+func (p *piholeV6) list() ([]piholeHost, error) {
+	var result struct {
+		Config struct {
+			DNS struct {
+				Hosts []string `json:"hosts"`
+			} `json:"dns"`
+		} `json:"config"`
+	}
+	if err := p.do("GET", "/api/config/dns/hosts", nil, &result); err != nil {
+		return nil, err
+	}
+	var hosts []piholeHost
+	for _, line := range result.Config.DNS.Hosts {
+		// 每项为 "IP 域名 [域名...]"
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			hosts = append(hosts, piholeHost{IP: fields[0], Domain: domain})
+		}
+	}
+	return hosts, nil
+}
+
+func (p *piholeV6) path(host piholeHost) string {
+	return "/api/config/dns/hosts/" + url.PathEscape(host.IP+" "+host.Domain)
+}
+
+func (p *piholeV6) add(host piholeHost) error {
+	return p.do("PUT", p.path(host), nil, nil)
+}
+
+func (p *piholeV6) remove(host piholeHost) error {
+	return p.do("DELETE", p.path(host), nil, nil)
+}
+
+// close 退出登录，避免占满 Pi-hole 的会话数量
+func (p *piholeV6) close() {
+	if p.sid != "" {
+		p.do("DELETE", "/api/auth", nil, nil)
+	}
+}
+
+// piholeV5 Pi-hole v5 的 /admin/api.php?customdns
+type piholeV5 struct {
+	config *PiHoleConfig
+}
+
+func (p *piholeV5) call(params url.Values, out interface{}) error {
+	params.Set("customdns", "")
+	params.Set("auth", p.config.APIToken)
+	resp, err := p.config.client().Get(strings.TrimSuffix(p.config.URL, "/") + "/admin/api.php?" + params.Encode())
+	if err != nil {
+		return fmt.Errorf(tr("连接 Pi-hole 失败: %v"), redactError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return piholeError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// 令牌错误时返回空数组或空对象
+	if trimmed := strings.TrimSpace(string(data)); trimmed == "[]" || trimmed == "{}" {
+		return classify(ErrAuth, errors.New(tr("Pi-hole 拒绝了请求：请检查 api_token")))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	return nil
+}
+
+func (p *piholeV5) list() ([]piholeHost, error) {
+	var result struct {
+		Data [][]string `json:"data"`
+	}
+	if err := p.call(url.Values{"action": {"get"}}, &result); err != nil {
+		return nil, err
+	}
+	var hosts []piholeHost
+	for _, item := range result.Data {
+		if len(item) == 2 {
+			hosts = append(hosts, piholeHost{Domain: item[0], IP: item[1]})
+		}
+	}
+	return hosts, nil
+}
+
+func (p *piholeV5) change(action string, host piholeHost) error {
+	var result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := p.call(url.Values{"action": {action}, "ip": {host.IP}, "domain": {host.Domain}}, &result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf(tr("Pi-hole 返回错误: %s"), result.Message)
+	}
+	return nil
+}
+
+func (p *piholeV5) add(host piholeHost) error {
+	return p.change("add", host)
+}
+
+func (p *piholeV5) remove(host piholeHost) error {
+	return p.change("delete", host)
+}
+
+func (p *piholeV5) close() {}
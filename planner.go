@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// zoneLister 支持一次读取区域内某类型全部记录的DNS服务，由 CloudflareClient 实现
+type zoneLister interface {
+	ListZoneDNSRecordsByType(zoneID, recordType string) ([]DNSRecord, error)
+	recordName(zoneID, name string) (string, error)
+}
+
+// zoneSnapshot 本周期预读的记录：同一区域的多个记录共用一次列表请求，
+// 避免记录较多时每个记录各查询一次（Cloudflare API 限制每5分钟1200次请求）
+type zoneSnapshot struct {
+	mu      sync.Mutex
+	records map[string][]DNSRecord
+}
+
+func snapshotKey(zoneID, name, recordType string) string {
+	return zoneID + "/" + recordType + "/" + strings.ToLower(name)
+}
+
+// take 返回预读的记录，每个记录只使用一次：执行失败后的重新计划与写入后的验证需要读取最新状态
+func (s *zoneSnapshot) take(zoneID, name, recordType string) ([]DNSRecord, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := snapshotKey(zoneID, name, recordType)
+	records, ok := s.records[key]
+	delete(s.records, key)
+	return records, ok
+}
+
+// Prefetch 同一区域、同一类型的多个记录只读取一次记录列表，之后各记录生成计划时直接使用；
+// 区域内只有一个记录时仍按名称查询，DNS服务不支持或读取失败时各记录照常单独查询
+func (r *Reconciler) Prefetch(targets []RecordTarget) {
+	lister, ok := r.Provider.(zoneLister)
+	if !ok {
+		return
+	}
+
+	var keys []string
+	groups := make(map[string][]RecordTarget)
+	for _, target := range targets {
+		key := target.ZoneID + "/" + target.Type
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], target)
+	}
+
+	snapshot := &zoneSnapshot{records: make(map[string][]DNSRecord)}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		zoneID, recordType := group[0].ZoneID, group[0].Type
+		stop := cycleTiming.phase("dns.list")
+		records, err := lister.ListZoneDNSRecordsByType(zoneID, recordType)
+		stop()
+		if err != nil {
+			logDebug("预读区域 %s 的记录失败，改为逐个查询: %v", zoneID, err)
+			continue
+		}
+		for _, target := range group {
+			name, err := lister.recordName(zoneID, target.Name)
+			if err != nil {
+				continue
+			}
+			// 不存在的记录同样登记为空列表，生成创建计划时无需再查询
+			matched := []DNSRecord{}
+			for _, record := range records {
+				if strings.EqualFold(record.Name, name) {
+					matched = append(matched, record)
+				}
+			}
+			snapshot.records[snapshotKey(zoneID, target.Name, recordType)] = matched
+		}
+		logDebug("已预读区域 %s 的 %d 条 %s 记录，用于 %d 个记录的计划", zoneID, len(records), recordType, len(group))
+	}
+	if len(snapshot.records) > 0 {
+		r.Snapshot = snapshot
+	}
+}
+
+// ListZoneDNSRecordsByType 获取区域内指定类型的全部DNS记录（自动翻页）
+func (c *CloudflareClient) ListZoneDNSRecordsByType(zoneID, recordType string) ([]DNSRecord, error) {
+	records, err := c.listDNSRecordPages(fmt.Sprintf("/zones/%s/dns_records?type=%s", zoneID, recordType))
+	if err != nil {
+		return nil, fmt.Errorf(tr("查找DNS记录失败: %w"), err)
+	}
+	return records, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NotifyPolicy 失败通知策略
+type NotifyPolicy struct {
+	// FailureThreshold 连续失败多少次后发送错误通知，默认 1
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+	// EscalateAfter 持续失败超过该时长后升级通知（如 "30m"），为空则不升级
+	EscalateAfter string `json:"escalate_after,omitempty"`
+	// EscalateTo 升级通知发送的渠道名称（这些渠道只接收升级和恢复通知）
+	EscalateTo []string `json:"escalate_to,omitempty"`
+	// Recovery 失败恢复后是否发送恢复通知，默认 true
+	Recovery *bool `json:"recovery,omitempty"`
+}
+
+// failurePolicy 根据检测周期结果决定何时发送错误、升级和恢复通知
+type failurePolicy struct {
+	mu sync.Mutex
+
+	threshold     int
+	escalateAfter time.Duration
+	escalateTo    []string
+	recovery      bool
+
+	failures     int
+	firstFailure time.Time
+	alerted      bool // 本轮失败已发送错误通知
+	escalated    bool // 本轮失败已发送升级通知
+}
+
+var policy = newFailurePolicy(NotifyPolicy{})
+
+// newFailurePolicy 根据配置创建策略
+func newFailurePolicy(cfg NotifyPolicy) *failurePolicy {
+	p := &failurePolicy{
+		threshold:  cfg.FailureThreshold,
+		escalateTo: cfg.EscalateTo,
+		recovery:   cfg.Recovery == nil || *cfg.Recovery,
+	}
+	if p.threshold <= 0 {
+		p.threshold = 1
+	}
+	if cfg.EscalateAfter != "" {
+		d, err := time.ParseDuration(cfg.EscalateAfter)
+		if err != nil {
+			logError("notify_policy.escalate_after 无效: %v", err)
+		} else {
+			p.escalateAfter = d
+		}
+	}
+	return p
+}
+
+// initNotifyPolicy 应用配置中的通知策略（重新加载时保留当前失败计数）
+func initNotifyPolicy(cfg NotifyPolicy) {
+	next := newFailurePolicy(cfg)
+
+	policy.mu.Lock()
+	next.failures = policy.failures
+	next.firstFailure = policy.firstFailure
+	next.alerted = policy.alerted
+	next.escalated = policy.escalated
+	policy.mu.Unlock()
+
+	policy = next
+}
+
+// observe 记录一次检测周期结果并按策略发送通知
+func (p *failurePolicy) observe(err error) {
+	config := app.Config()
+	currentIP := app.CurrentIP()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	if err == nil {
+		if p.alerted && p.recovery {
+			event := NotifyEvent{
+				Type:    EventRecovered,
+				Title:   tr("DNS更新已恢复"),
+				Message: fmt.Sprintf(tr("连续失败 %d 次（持续 %s）后恢复正常"), p.failures, now.Sub(p.firstFailure).Round(time.Second)),
+				Record:  config.RecordName,
+				NewIP:   currentIP,
+			}
+			notify(event)
+			if p.escalated {
+				event.Channels = p.escalateTo
+				notify(event)
+			}
+		}
+		p.failures = 0
+		p.alerted = false
+		p.escalated = false
+		return
+	}
+
+	if p.failures == 0 {
+		p.firstFailure = now
+	}
+	p.failures++
+
+	if !p.alerted && p.failures >= p.threshold {
+		p.alerted = true
+		notify(NotifyEvent{
+			Type:    EventError,
+			Title:   tr("DNS更新失败"),
+			Message: fmt.Sprintf(tr("连续失败 %d 次: %v"), p.failures, err),
+			Record:  config.RecordName,
+			OldIP:   currentIP,
+		})
+	}
+
+	if p.alerted && !p.escalated && p.escalateAfter > 0 && len(p.escalateTo) > 0 &&
+		now.Sub(p.firstFailure) >= p.escalateAfter {
+		p.escalated = true
+		notify(NotifyEvent{
+			Type:     EventError,
+			Title:    tr("DNS更新持续失败（升级通知）"),
+			Message:  fmt.Sprintf(tr("已持续失败 %s（%d 次）: %v"), now.Sub(p.firstFailure).Round(time.Second), p.failures, err),
+			Record:   config.RecordName,
+			OldIP:    currentIP,
+			Channels: p.escalateTo,
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// dropPrivileges 以 root 启动时切换到指定的用户/用户组运行
+// 应在打开日志文件和写入PID文件之后调用；切换前会将数据目录的所有权交给目标用户，
+// 保证降权后仍可写入状态文件、重新加载配置
+func dropPrivileges(userName, groupName string) error {
+	if userName == "" && groupName == "" {
+		return nil
+	}
+
+	if os.Getuid() != 0 {
+		return fmt.Errorf(tr("切换运行用户需要以 root 身份启动（当前 UID: %d）"), os.Getuid())
+	}
+
+	uid, gid := -1, -1
+
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return fmt.Errorf(tr("查找用户 %s 失败: %v"), userName, err)
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+		gid, _ = strconv.Atoi(u.Gid)
+	}
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return fmt.Errorf(tr("查找用户组 %s 失败: %v"), groupName, err)
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+
+	// 数据目录交给目标用户
+	if err := chownDataDir(uid, gid); err != nil {
+		return fmt.Errorf(tr("修改数据目录所有权失败: %v"), err)
+	}
+	// 降权前（仍可写入 root 的主目录）记录数据目录，之后不带 --user 的命令同样能找到守护进程
+	if err := markDataDir(getDataDir()); err != nil {
+		logError("记录数据目录失败: %v", err)
+	}
+
+	if err := setIDs(uid, gid); err != nil {
+		return err
+	}
+
+	logInfo("已切换运行身份: UID=%d, GID=%d", os.Getuid(), os.Getgid())
+	return nil
+}
+
+// chownDataDir 递归修改数据目录的所有者
+func chownDataDir(uid, gid int) error {
+	dir := getDataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
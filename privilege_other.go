@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+// setIDs 切换运行用户只在类 Unix 系统上可用
+func setIDs(uid, gid int) error {
+	return errors.New(tr("切换运行用户仅在类 Unix 系统上可用"))
+}
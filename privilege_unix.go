@@ -0,0 +1,27 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// setIDs 先切换用户组，再切换用户（切换用户后将无权再修改用户组）；-1 表示不切换
+func setIDs(uid, gid int) error {
+	if gid >= 0 {
+		if err := syscall.Setgroups([]int{gid}); err != nil {
+			return fmt.Errorf(tr("设置附加用户组失败: %v"), err)
+		}
+		if err := syscall.Setgid(gid); err != nil {
+			return fmt.Errorf(tr("切换用户组失败: %v"), err)
+		}
+	}
+
+	if uid >= 0 {
+		if err := syscall.Setuid(uid); err != nil {
+			return fmt.Errorf(tr("切换用户失败: %v"), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// 一致性测试中注入错误的操作
+const (
+	opList   = "list"
+	opCreate = "create"
+	opUpdate = "update"
+)
+
+// providerHarness DNSProvider 实现的一致性测试环境。新的DNS服务实现需要提供该环境，
+// 并在测试中调用 runProviderConformance，保证与调和引擎配合时的行为一致
+type providerHarness struct {
+	Provider DNSProvider
+	ZoneID   string
+	// Seed 绕过 Provider 直接写入一条记录（模拟其他机器或手动创建的记录）
+	Seed func(record DNSRecord)
+	// SetPageSize 限制每页返回的记录数，为 nil 时跳过分页场景
+	SetPageSize func(n int)
+	// FailNext 让下一次 op（opList、opCreate、opUpdate）失败
+	FailNext func(op string)
+}
+
+// providerScenario 一致性测试场景
+type providerScenario struct {
+	name string
+	run  func(t *testing.T, h *providerHarness)
+}
+
+// providerScenarios 所有 DNSProvider 实现都必须通过的场景
+var providerScenarios = []providerScenario{
+	{"create returns the stored record", func(t *testing.T, h *providerHarness) {
+		created, err := h.Provider.CreateDNSRecordWithOptions(h.ZoneID, DNSRecordCreateRequest{
+			Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300, Comment: "conformance",
+		})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if created.ID == "" || created.Content != "198.51.100.1" {
+			t.Fatalf("created = %+v; want ID and content", created)
+		}
+		records := mustList(t, h, testRecord, "A")
+		if len(records) != 1 || records[0].ID != created.ID || records[0].TTL != 300 || records[0].Comment != "conformance" {
+			t.Fatalf("listed = %+v; want the created record with TTL and comment", records)
+		}
+	}},
+	{"update keeps the record ID", func(t *testing.T, h *providerHarness) {
+		h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+		old := mustList(t, h, testRecord, "A")[0]
+		updated, err := h.Provider.EditDNSRecord(h.ZoneID, old, DNSRecordCreateRequest{
+			Type: "A", Name: old.Name, Content: "198.51.100.2", TTL: old.TTL,
+		})
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		records := mustList(t, h, testRecord, "A")
+		if updated.ID != old.ID || len(records) != 1 || records[0].Content != "198.51.100.2" {
+			t.Fatalf("after update: returned %+v, listed %+v", updated, records)
+		}
+	}},
+	{"sync is idempotent", func(t *testing.T, h *providerHarness) {
+		r := conformanceReconciler(h)
+		if result := r.Sync("198.51.100.1", "", "conformance"); result.Err != nil || result.Action != planCreate {
+			t.Fatalf("first sync = %+v; want create", result)
+		}
+		if result := r.Sync("198.51.100.2", "198.51.100.1", "conformance"); result.Err != nil || result.Action != planUpdate {
+			t.Fatalf("second sync = %+v; want update", result)
+		}
+		if result := r.Sync("198.51.100.2", "198.51.100.1", "conformance"); result.Err != nil || result.Action != planNone {
+			t.Fatalf("repeated sync = %+v; want no-op", result)
+		}
+		if records := mustList(t, h, testRecord, "A"); len(records) != 1 {
+			t.Fatalf("records = %+v; want exactly one", records)
+		}
+	}},
+	{"multi-record sets keep other machines", func(t *testing.T, h *providerHarness) {
+		h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: "203.0.113.7", TTL: 120})
+		h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 120})
+		r := conformanceReconciler(h)
+		if result := r.Sync("198.51.100.2", "198.51.100.1", "conformance"); result.Err != nil || result.Action != planUpdate {
+			t.Fatalf("sync = %+v; want update of own record", result)
+		}
+		got := map[string]bool{}
+		for _, record := range mustList(t, h, testRecord, "A") {
+			got[record.Content] = true
+		}
+		if len(got) != 2 || !got["203.0.113.7"] || !got["198.51.100.2"] {
+			t.Fatalf("records = %v; want 203.0.113.7 and 198.51.100.2", got)
+		}
+	}},
+	{"list filters by type", func(t *testing.T, h *providerHarness) {
+		h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+		h.Seed(DNSRecord{Type: "AAAA", Name: testRecord, Content: "2001:db8::1", TTL: 300})
+		h.Seed(DNSRecord{Type: "A", Name: "other." + testZoneName, Content: "198.51.100.9", TTL: 300})
+		if records := mustList(t, h, testRecord, "AAAA"); len(records) != 1 || records[0].Content != "2001:db8::1" {
+			t.Fatalf("AAAA records = %+v", records)
+		}
+		if records := mustList(t, h, testRecord, "A"); len(records) != 1 || records[0].Content != "198.51.100.1" {
+			t.Fatalf("A records = %+v", records)
+		}
+	}},
+	{"list returns every page", func(t *testing.T, h *providerHarness) {
+		if h.SetPageSize == nil {
+			t.Skip("provider has no pagination")
+		}
+		h.SetPageSize(2)
+		for i := 1; i <= 5; i++ {
+			h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: fmt.Sprintf("198.51.100.%d", i), TTL: 300})
+		}
+		if records := mustList(t, h, testRecord, "A"); len(records) != 5 {
+			t.Fatalf("got %d records; want 5", len(records))
+		}
+	}},
+	{"list errors are returned", func(t *testing.T, h *providerHarness) {
+		h.FailNext(opList)
+		if _, err := h.Provider.GetAllDNSRecords(h.ZoneID, testRecord, "A"); err == nil {
+			t.Fatal("list succeeded; want error")
+		}
+	}},
+	{"failed create leaves no record", func(t *testing.T, h *providerHarness) {
+		h.FailNext(opCreate)
+		_, err := h.Provider.CreateDNSRecordWithOptions(h.ZoneID, DNSRecordCreateRequest{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+		if err == nil {
+			t.Fatal("create succeeded; want error")
+		}
+		if records := mustList(t, h, testRecord, "A"); len(records) != 0 {
+			t.Fatalf("records = %+v; want none", records)
+		}
+	}},
+	{"failed update keeps the old content", func(t *testing.T, h *providerHarness) {
+		h.Seed(DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300})
+		old := mustList(t, h, testRecord, "A")[0]
+		h.FailNext(opUpdate)
+		if _, err := h.Provider.EditDNSRecord(h.ZoneID, old, DNSRecordCreateRequest{Type: "A", Name: old.Name, Content: "198.51.100.2", TTL: 300}); err == nil {
+			t.Fatal("update succeeded; want error")
+		}
+		if records := mustList(t, h, testRecord, "A"); len(records) != 1 || records[0].Content != "198.51.100.1" {
+			t.Fatalf("records = %+v; want unchanged", records)
+		}
+	}},
+	{"update of a missing record fails", func(t *testing.T, h *providerHarness) {
+		missing := DNSRecord{ID: "missing", Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300}
+		if _, err := h.Provider.EditDNSRecord(h.ZoneID, missing, DNSRecordCreateRequest{Type: "A", Name: testRecord, Content: "198.51.100.2", TTL: 300}); err == nil {
+			t.Fatal("update of a missing record succeeded; want error")
+		}
+	}},
+}
+
+// runProviderConformance 对每个场景创建新的测试环境并运行
+func runProviderConformance(t *testing.T, newHarness func(t *testing.T) *providerHarness) {
+	for _, scenario := range providerScenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			scenario.run(t, newHarness(t))
+		})
+	}
+}
+
+func mustList(t *testing.T, h *providerHarness, name, recordType string) []DNSRecord {
+	t.Helper()
+	records, err := h.Provider.GetAllDNSRecords(h.ZoneID, name, recordType)
+	if err != nil {
+		t.Fatalf("list %s %s: %v", name, recordType, err)
+	}
+	return records
+}
+
+func conformanceReconciler(h *providerHarness) *Reconciler {
+	return &Reconciler{Provider: h.Provider, ZoneID: h.ZoneID, Name: testRecord, Type: "A", Retries: 1}
+}
+
+func TestCloudflareProviderConformance(t *testing.T) {
+	runProviderConformance(t, func(t *testing.T) *providerHarness {
+		cf := newFakeCloudflare(t)
+		cf.addZone(testZoneID, testZoneName)
+		return &providerHarness{
+			Provider: cf.client(),
+			ZoneID:   testZoneID,
+			Seed: func(record DNSRecord) {
+				cf.addRecord(testZoneID, record)
+			},
+			SetPageSize: func(n int) {
+				cf.mu.Lock()
+				defer cf.mu.Unlock()
+				cf.pageSize = n
+			},
+			FailNext: func(op string) {
+				switch op {
+				case opList:
+					cf.failNext("GET", "/dns_records", 500, 1)
+				case opCreate:
+					cf.failNext("POST", "/dns_records", 500, 1)
+				case opUpdate:
+					cf.failNext("PUT", "/dns_records/", 500, 1)
+				}
+			},
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PTRConfig 公网IP变化后让新IP的反向解析（PTR）指向主记录。
+// 反向解析由IP所属的服务商维护，需要该服务商的 API 凭据
+type PTRConfig struct {
+	// Provider 服务商：linode、vultr 或 hetzner_robot
+	Provider string `json:"provider"`
+	// Token linode / vultr 的 API 令牌
+	Token string `json:"token,omitempty"`
+	// InstanceID vultr 实例 ID
+	InstanceID string `json:"instance_id,omitempty"`
+	// Username / Password Hetzner Robot Webservice 用户名与密码
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Hostname PTR 指向的名称，默认为主记录的完整名称
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// ptrState 已设置反向解析的IP，未变化时不重复请求
+type ptrState struct {
+	mu      sync.Mutex
+	applied string
+}
+
+var ptr = &ptrState{}
+
+// ptrHTTPClient 访问服务商 API 的客户端
+var ptrHTTPClient = &http.Client{Timeout: 15 * time.Second, Transport: newHTTPTransport("ptr")}
+
+// setPTR 调用服务商 API 设置 ip 的反向解析
+func (p *PTRConfig) setPTR(ip, hostname string) error {
+	registerSecret(p.Token)
+	registerSecret(p.Password)
+
+	var req *http.Request
+	var err error
+	switch p.Provider {
+	case "linode":
+		body, _ := json.Marshal(map[string]string{"rdns": hostname})
+		req, err = http.NewRequest("PUT", "https://api.linode.com/v4/networking/ips/"+url.PathEscape(ip), bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Authorization", "Bearer "+p.Token)
+			req.Header.Set("Content-Type", "application/json")
+		}
+	case "vultr":
+		if p.InstanceID == "" {
+			return fmt.Errorf(tr("反向解析服务商 %s 缺少 instance_id"), p.Provider)
+		}
+		family := "ipv4"
+		if net.ParseIP(ip).To4() == nil {
+			family = "ipv6"
+		}
+		body, _ := json.Marshal(map[string]string{"ip": ip, "reverse": hostname})
+		endpoint := fmt.Sprintf("https://api.vultr.com/v2/instances/%s/%s/reverse", url.PathEscape(p.InstanceID), family)
+		req, err = http.NewRequest("POST", endpoint, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Authorization", "Bearer "+p.Token)
+			req.Header.Set("Content-Type", "application/json")
+		}
+	case "hetzner_robot":
+		form := url.Values{"ptr": {hostname}}
+		req, err = http.NewRequest("POST", "https://robot-ws.your-server.de/rdns/"+url.PathEscape(ip), strings.NewReader(form.Encode()))
+		if err == nil {
+			req.SetBasicAuth(p.Username, p.Password)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	default:
+		return fmt.Errorf(tr("不支持的反向解析服务商: %s"), p.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := ptrHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %v"), redactError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf(tr("%s 返回错误 (状态码: %d): %s"), p.Provider, resp.StatusCode, redactSecrets(strings.TrimSpace(string(body))))
+	}
+	return nil
+}
+
+// updatePTR 公网IP变化后设置新IP的反向解析。与防火墙相同，失败时只记录日志，下个周期重试
+func updatePTR(config *Config, client *CloudflareClient, ip string) {
+	ptr.mu.Lock()
+	defer ptr.mu.Unlock()
+
+	if ptr.applied == ip {
+		return
+	}
+
+	p := config.PTR
+	hostname := p.Hostname
+	if hostname == "" {
+		name, err := client.recordName(config.ZoneID, config.RecordName)
+		if err != nil {
+			logError("设置反向解析失败: %v", err)
+			return
+		}
+		hostname = name
+	}
+
+	if err := p.setPTR(ip, hostname); err != nil {
+		logError("设置反向解析失败: %v", err)
+		return
+	}
+	logInfo("已将 %s 的反向解析指向 %s (%s)", ip, hostname, p.Provider)
+	ptr.applied = ip
+}
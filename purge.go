@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CachePurgeConfig 开启代理的记录指向新源站后清除 Cloudflare 缓存。
+// Files 与 Hosts 都为空时清除整个区域的缓存
+type CachePurgeConfig struct {
+	// Files 只清除这些 URL 的缓存
+	Files []string `json:"files,omitempty"`
+	// Hosts 只清除这些主机名的缓存（Cloudflare 企业版功能）
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// purgeAfterSync 对写入了开启代理记录的区域清除缓存。记录已经更新，清除失败只记录日志
+func purgeAfterSync(config *Config, client *CloudflareClient, results []SyncResult) {
+	purged := map[string]bool{}
+	for _, result := range results {
+		zoneID := result.Target.ZoneID
+		if !result.Applied || !result.Proxied || purged[zoneID] {
+			continue
+		}
+		purged[zoneID] = true
+		if err := client.PurgeCache(zoneID, config.CachePurge); err != nil {
+			logError("清除缓存失败 (%s): %v", result.Target.Name, err)
+			continue
+		}
+		logInfo("源站IP已变化，已清除 %s 所在区域的缓存", result.Target.Name)
+	}
+}
+
+// PurgeCache 清除区域缓存：按配置清除指定 URL / 主机名，未指定时清除全部
+func (c *CloudflareClient) PurgeCache(zoneID string, purge *CachePurgeConfig) error {
+	body := map[string]interface{}{}
+	if len(purge.Files) > 0 {
+		body["files"] = purge.Files
+	}
+	if len(purge.Hosts) > 0 {
+		body["hosts"] = purge.Hosts
+	}
+	if len(body) == 0 {
+		body["purge_everything"] = true
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	resp, err := c.makeRequest("POST", fmt.Sprintf("/zones/%s/purge_cache", zoneID), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiStatusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DNSProvider 调和引擎所需的DNS记录读写接口，由 CloudflareClient 实现
+type DNSProvider interface {
+	GetAllDNSRecords(zoneID, recordName, recordType string) ([]DNSRecord, error)
+	CreateDNSRecordWithOptions(zoneID string, req DNSRecordCreateRequest) (*DNSRecord, error)
+	EditDNSRecord(zoneID string, old DNSRecord, req DNSRecordCreateRequest) (*DNSRecord, error)
+	SetAuditSource(source string)
+}
+
+// IPSource 公网IP检测接口，由 IPChecker 实现
+type IPSource interface {
+	GetPublicIPWithService() (string, string, error)
+}
+
+// defaultRecordTTL 新建记录且没有可参考的现有记录时使用的TTL
+const defaultRecordTTL = 3600
+
+// newReconciler 使用的等待时间，测试中缩短以免等待
+var (
+	defaultConfirmDelay = 3 * time.Second
+	defaultRetryDelay   = 2 * time.Second
+)
+
+// 计划中的操作
+const (
+	planNone   = "none"   // 记录集已包含期望的IP
+	planCreate = "create" // 新建记录
+	planUpdate = "update" // 修改已有记录
+)
+
+// DesiredState 期望状态：记录集中应包含指向 IP 的记录
+type DesiredState struct {
+	ZoneID string
+	Name   string
+	Type   string
+	IP     string
+	// OldIP 本机上次写入的IP，存在指向该IP的记录时修改它而不是新增（多机器共用记录名称时只维护自己的记录）
+	OldIP string
+	// Exclusive 记录集只属于本机：修改第一条记录而不是新增（手动更新）
+	Exclusive bool
+}
+
+// Plan 比较期望状态与实际记录得到的操作
+type Plan struct {
+	Desired  DesiredState
+	Observed []DNSRecord
+	Action   string
+	// Target 要修改的记录（仅 update）
+	Target *DNSRecord
+	// Request 写入的完整记录参数（create / update）
+	Request DNSRecordCreateRequest
+}
+
+// recordContents 返回记录的内容列表
+func recordContents(records []DNSRecord) []string {
+	contents := make([]string, 0, len(records))
+	for _, record := range records {
+		contents = append(contents, record.Content)
+	}
+	return contents
+}
+
+// planReconcile 根据实际记录生成操作计划，不访问网络
+func planReconcile(desired DesiredState, observed []DNSRecord) Plan {
+	plan := Plan{Desired: desired, Observed: observed, Action: planNone}
+
+	for _, record := range observed {
+		if record.Content == desired.IP {
+			return plan
+		}
+	}
+
+	var target *DNSRecord
+	for i := range observed {
+		if desired.OldIP != "" && observed[i].Content == desired.OldIP {
+			target = &observed[i]
+			break
+		}
+	}
+	if target == nil && desired.Exclusive && len(observed) > 0 {
+		target = &observed[0]
+	}
+
+	if target != nil {
+		plan.Action = planUpdate
+		plan.Target = target
+		plan.Request = DNSRecordCreateRequest{
+			Type:     target.Type,
+			Name:     target.Name,
+			Content:  desired.IP,
+			TTL:      target.TTL,
+			Proxied:  target.Proxied,
+			Priority: target.Priority,
+			Comment:  target.Comment,
+		}
+		return plan
+	}
+
+	// 没有可修改的记录时新建（多机器时每台机器维护自己的记录），TTL 参考现有记录
+	ttl := defaultRecordTTL
+	if len(observed) > 0 {
+		ttl = observed[0].TTL
+	}
+	plan.Action = planCreate
+	plan.Request = DNSRecordCreateRequest{
+		Type:    desired.Type,
+		Name:    desired.Name,
+		Content: desired.IP,
+		TTL:     ttl,
+	}
+	return plan
+}
+
+// Result 按计划执行后的记录集
+func (p Plan) Result() []DNSRecord {
+	result := make([]DNSRecord, len(p.Observed))
+	copy(result, p.Observed)
+
+	switch p.Action {
+	case planUpdate:
+		for i := range result {
+			if result[i].ID == p.Target.ID {
+				result[i].Content = p.Request.Content
+			}
+		}
+	case planCreate:
+		result = append(result, DNSRecord{
+			Type:    p.Request.Type,
+			Name:    p.Request.Name,
+			Content: p.Request.Content,
+			TTL:     p.Request.TTL,
+			Proxied: p.Request.Proxied,
+		})
+	}
+	return result
+}
+
+// Reconciler 调和引擎：检测 → 期望状态 → 实际状态 → 计划 → 执行 → 验证。
+// 守护进程、手动更新与代理上报共用同一套流程，各阶段可以单独调用
+type Reconciler struct {
+	Provider DNSProvider
+	IPSource IPSource
+	ZoneID   string
+	Name     string
+	Type     string
+
+	// Retries 检测与执行阶段的最大尝试次数
+	Retries int
+	// ConfirmAttempts IP变化后再次检测确认的次数，0 表示不确认
+	ConfirmAttempts int
+	// ConfirmDelay IP变化后再次检测确认前的等待时间
+	ConfirmDelay time.Duration
+	// RetryDelay 执行失败后第一次重试前的等待时间，之后每次乘以 Backoff，不超过 MaxRetryDelay
+	RetryDelay    time.Duration
+	Backoff       float64
+	MaxRetryDelay time.Duration
+	// DryRun 只生成计划，不修改记录
+	DryRun bool
+	// VerifyPropagation 执行后等待权威名称服务器返回新IP
+	VerifyPropagation  bool
+	PropagationTimeout time.Duration
+	// TTLStrategy 写入前先降低TTL，为空则保持记录原有TTL
+	TTLStrategy *TTLStrategyConfig
+	// Snapshot 本周期预读的区域记录（见 Prefetch），为空时每个记录单独查询
+	Snapshot *zoneSnapshot
+	// Dedupe 同步前删除记录集中名称、类型与内容都相同的重复记录
+	Dedupe bool
+}
+
+// newReconciler 按当前配置创建调和引擎
+func newReconciler() *Reconciler {
+	config := app.Config()
+	cfClient := app.Client()
+	ipChecker := app.IPChecker()
+	r := &Reconciler{
+		Provider:           cfClient,
+		IPSource:           ipChecker,
+		ZoneID:             config.ZoneID,
+		Name:               config.RecordName,
+		Type:               config.RecordType,
+		Retries:            defaultRetries + 1,
+		ConfirmAttempts:    defaultConfirmAttempts,
+		ConfirmDelay:       defaultConfirmDelay,
+		RetryDelay:         defaultRetryDelay,
+		Backoff:            1,
+		DryRun:             dryRun || maintenance.isPaused() || config.ObserveOnly,
+		VerifyPropagation:  config.VerifyDNS,
+		PropagationTimeout: defaultPropagationTimeout,
+		TTLStrategy:        config.TTLStrategy,
+		Dedupe:             config.DedupeRecords,
+	}
+	if d, err := parseDurationOrZero(config.VerifyDNSTimeout); err == nil && d > 0 {
+		r.PropagationTimeout = d
+	}
+	config.RetryPolicy.applyTo(r)
+	return r
+}
+
+// RecordTarget 需要同步的记录
+type RecordTarget struct {
+	ZoneID string
+	Name   string
+	Type   string
+	// Retry 该记录单独设置的重试策略，为空时沿用调和引擎的设置
+	Retry *RetryPolicy
+	// OldIP 该记录上次指向的IP，为空时使用本机上次写入的IP（见 driftTracker.filter）
+	OldIP string
+}
+
+// previousIP 返回生成计划时使用的旧IP
+func (t RecordTarget) previousIP(oldIP string) string {
+	if t.OldIP != "" {
+		return t.OldIP
+	}
+	return oldIP
+}
+
+// forTarget 复制一份调和引擎并指向另一个记录
+func (r *Reconciler) forTarget(target RecordTarget) *Reconciler {
+	copied := *r
+	copied.ZoneID = target.ZoneID
+	copied.Name = target.Name
+	copied.Type = target.Type
+	target.Retry.applyTo(&copied)
+	return &copied
+}
+
+// SyncResult 单个记录的同步结果
+type SyncResult struct {
+	Target RecordTarget
+	Action string
+	// Applied 记录已写入（此时 Err 表示验证失败）
+	Applied bool
+	// Proxied 写入的记录开启了 Cloudflare 代理
+	Proxied bool
+	// Before / After 同步前后记录集的内容（dry-run 时 After 为计划的结果）
+	Before []string
+	After  []string
+	Err    error
+}
+
+// Sync 对当前记录执行 期望状态 → 实际状态 → 计划 → 执行 → 验证
+func (r *Reconciler) Sync(ip, oldIP, source string) SyncResult {
+	result := SyncResult{Target: RecordTarget{ZoneID: r.ZoneID, Name: r.Name, Type: r.Type}, Action: planNone}
+
+	desired := r.Desired(ip, oldIP)
+	plan, err := r.Plan(desired)
+	if err != nil {
+		logError("获取DNS记录失败: %v", err)
+		result.Err = err
+		return result
+	}
+	logInfo("%s: 找到 %d 个DNS记录", r.Name, len(plan.Observed))
+	result.Before = recordContents(plan.Observed)
+	if r.Dedupe {
+		plan = r.collapseDuplicates(plan, source)
+	}
+
+	result.Action = plan.Action
+	result.Proxied = plan.Request.Proxied
+	result.After = recordContents(plan.Observed)
+	if plan.Action == planNone {
+		logInfo("%s 已存在指向本机IP (%s) 的DNS记录，无需更新", r.Name, ip)
+		return result
+	}
+
+	// 多机器时每个机器维护自己的记录：存在指向旧IP的记录时修改它，否则创建新记录
+	logInfo("正在更新或创建DNS记录: %s -> %s", r.Name, ip)
+	if err := r.Apply(plan, source); err != nil {
+		logError("DNS更新/创建失败: %v", err)
+		result.Err = err
+		return result
+	}
+	result.After = recordContents(plan.Result())
+	if r.DryRun {
+		return result
+	}
+	result.Applied = true
+
+	// 确认记录已写入；开启 verify_dns 时通过权威名称服务器确认新值已实际生效
+	if err := r.Verify(desired); err != nil {
+		logError("%v", err)
+		result.Err = err
+		return result
+	}
+	logInfo("DNS记录已成功更新/创建: %s -> %s", r.Name, ip)
+	return result
+}
+
+// SyncAll 用有限数量的协程并行同步多个记录，结果顺序与 targets 一致
+func (r *Reconciler) SyncAll(targets []RecordTarget, ip, oldIP, source string, workers int) []SyncResult {
+	results := make([]SyncResult, len(targets))
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.forTarget(targets[i]).safeSync(ip, targets[i].previousIP(oldIP), source)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// safeSync 在工作协程中执行 Sync，panic 转换为该记录的错误，避免整个进程退出
+func (r *Reconciler) safeSync(ip, oldIP, source string) (result SyncResult) {
+	defer func() {
+		if p := recover(); p != nil {
+			logError("同步记录 %s 时发生异常: %v", r.Name, p)
+			result = SyncResult{
+				Target: RecordTarget{ZoneID: r.ZoneID, Name: r.Name, Type: r.Type},
+				Action: planNone,
+				Err:    fmt.Errorf(tr("同步记录 %s 时发生异常: %v"), r.Name, p),
+			}
+		}
+	}()
+	return r.Sync(ip, oldIP, source)
+}
+
+// Detect 检测公网IP，失败时重试
+func (r *Reconciler) Detect() (ip, source string, err error) {
+	defer cycleTiming.phase("ip.detect")()
+	span := tracing.start("ip.detect", spanKindInternal)
+	defer func() {
+		span.set("ip", ip)
+		span.set("ip.source", source)
+		span.finish(err)
+	}()
+	for i := 0; i < r.Retries; i++ {
+		ip, source, err = r.IPSource.GetPublicIPWithService()
+		if err == nil {
+			return ip, source, nil
+		}
+		if i < r.Retries-1 {
+			logError("获取公网IP失败 (尝试 %d/%d): %v，1秒后重试...", i+1, r.Retries, err)
+			time.Sleep(1 * time.Second)
+		}
+	}
+	return "", "", err
+}
+
+// ConfirmChange 等待片刻后再次检测（共 ConfirmAttempts 次），避免不同服务返回不同IP导致误判
+func (r *Reconciler) ConfirmChange(ip string) (err error) {
+	defer cycleTiming.phase("ip.confirm")()
+	span := tracing.start("ip.confirm", spanKindInternal)
+	span.set("ip", ip)
+	defer func() { span.finish(err) }()
+	for i := 0; i < r.ConfirmAttempts; i++ {
+		time.Sleep(r.ConfirmDelay)
+
+		confirmIP, confirmService, err := r.IPSource.GetPublicIPWithService()
+		if err != nil {
+			logError("确认IP时失败: %v，取消更新", err)
+			return err
+		}
+		if confirmIP != ip {
+			logError("IP确认失败: 第一次检测到 %s，确认时检测到 %s (来源: %s)，可能是服务不稳定，取消更新",
+				ip, confirmIP, confirmService)
+			return fmt.Errorf(tr("IP确认失败: %s != %s"), ip, confirmIP)
+		}
+	}
+	return nil
+}
+
+// Desired 生成期望状态
+func (r *Reconciler) Desired(ip, oldIP string) DesiredState {
+	return DesiredState{ZoneID: r.ZoneID, Name: r.Name, Type: r.Type, IP: ip, OldIP: oldIP}
+}
+
+// Observe 读取当前记录集
+func (r *Reconciler) Observe() ([]DNSRecord, error) {
+	if records, ok := r.Snapshot.take(r.ZoneID, r.Name, r.Type); ok {
+		return records, nil
+	}
+	return r.Provider.GetAllDNSRecords(r.ZoneID, r.Name, r.Type)
+}
+
+// Plan 读取当前记录并生成计划
+func (r *Reconciler) Plan(desired DesiredState) (Plan, error) {
+	stop := cycleTiming.phase("dns.list")
+	observed, err := r.Observe()
+	stop()
+	if err != nil {
+		return Plan{}, err
+	}
+	return planReconcile(desired, observed), nil
+}
+
+// Apply 执行计划；失败时重新读取记录并重新生成计划后重试，避免上次请求实际已生效时重复创建记录
+func (r *Reconciler) Apply(plan Plan, source string) error {
+	if plan.Action == planNone {
+		return nil
+	}
+	if r.DryRun {
+		logInfo("[dry-run] 计划操作 %s: %s -> %s（未执行）", plan.Action, plan.Request.Name, plan.Request.Content)
+		return nil
+	}
+
+	r.Provider.SetAuditSource(source)
+	defer cycleTiming.phase("dns.update")()
+
+	// 重新读取失败时 plan 会被清空，重试始终按最初的期望状态计划
+	desired := plan.Desired
+	var err error
+	for i := 0; i < r.Retries; i++ {
+		if i > 0 {
+			if plan, err = r.Plan(desired); err != nil {
+				// 读取失败多为暂时性错误（如限流），同样等待后再重试
+				if i < r.Retries-1 {
+					delay := r.retryDelay(i)
+					logError("获取DNS记录失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+					time.Sleep(delay)
+				}
+				continue
+			}
+			if plan.Action == planNone {
+				return nil
+			}
+		}
+
+		if err = r.lowerTTL(&plan); err != nil {
+			if i < r.Retries-1 {
+				delay := r.retryDelay(i)
+				logError("降低TTL失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+				time.Sleep(delay)
+			} else {
+				logError("降低TTL失败 (尝试 %d/%d): %v", i+1, r.Retries, err)
+			}
+			continue
+		}
+
+		switch plan.Action {
+		case planCreate:
+			_, err = r.Provider.CreateDNSRecordWithOptions(r.ZoneID, plan.Request)
+		case planUpdate:
+			_, err = r.Provider.EditDNSRecord(r.ZoneID, *plan.Target, plan.Request)
+		}
+		if err == nil {
+			return nil
+		}
+		if i < r.Retries-1 {
+			delay := r.retryDelay(i)
+			logError("DNS更新/创建失败 (尝试 %d/%d): %v，%s后重试...", i+1, r.Retries, err, delay)
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
+
+// Verify 确认记录集已包含新IP；开启传播验证时等待权威名称服务器返回新IP
+func (r *Reconciler) Verify(desired DesiredState) (err error) {
+	if r.DryRun {
+		return nil
+	}
+	defer cycleTiming.phase("dns.verify")()
+	span := tracing.start("dns.verify", spanKindInternal)
+	span.set("dns.record", desired.Name)
+	span.set("ip", desired.IP)
+	span.set("propagation", r.VerifyPropagation)
+	defer func() { span.finish(err) }()
+
+	records, err := r.Observe()
+	if err != nil {
+		logError("验证DNS记录失败: %v，但更新可能已成功", err)
+		return nil
+	}
+
+	found := false
+	proxied := false
+	for _, record := range records {
+		proxied = proxied || record.Proxied
+		if record.Content == desired.IP {
+			found = true
+		}
+	}
+	if !found {
+		logError("DNS记录验证失败: 未找到指向 %s 的记录", desired.IP)
+		return nil
+	}
+	logInfo("DNS记录验证成功: %s 现在包含IP %s (共 %d 个A记录)", desired.Name, desired.IP, len(records))
+
+	if !r.VerifyPropagation {
+		return nil
+	}
+	if proxied {
+		logInfo("记录已开启代理，跳过DNS传播验证")
+		return nil
+	}
+
+	// 使用 Cloudflare 返回的完整名称（配置中可能是 "@"）
+	name := records[0].Name
+	logInfo("正在等待权威名称服务器返回新IP %s（最长 %s）...", desired.IP, r.PropagationTimeout)
+	start := time.Now()
+	if err := waitForPropagation(name, desired.Type, desired.IP, r.PropagationTimeout); err != nil {
+		return err
+	}
+	logInfo("DNS传播已验证: %s -> %s（耗时 %s）", name, desired.IP, time.Since(start).Round(time.Second))
+	return nil
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	mine := DNSRecord{ID: "a", Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 300, Comment: "keep"}
+	other := DNSRecord{ID: "b", Type: "A", Name: testRecord, Content: "203.0.113.7", TTL: 120}
+
+	tests := []struct {
+		name     string
+		desired  DesiredState
+		observed []DNSRecord
+		action   string
+		targetID string
+		ttl      int
+		comment  string
+	}{
+		{
+			name:     "already points to IP",
+			desired:  DesiredState{Name: testRecord, Type: "A", IP: "198.51.100.1"},
+			observed: []DNSRecord{other, mine},
+			action:   planNone,
+		},
+		{
+			name:     "updates the record with the old IP",
+			desired:  DesiredState{Name: testRecord, Type: "A", IP: "198.51.100.2", OldIP: "198.51.100.1"},
+			observed: []DNSRecord{other, mine},
+			action:   planUpdate,
+			targetID: "a",
+			ttl:      300,
+			comment:  "keep",
+		},
+		{
+			name:     "creates beside other machines",
+			desired:  DesiredState{Name: testRecord, Type: "A", IP: "198.51.100.2"},
+			observed: []DNSRecord{other},
+			action:   planCreate,
+			ttl:      120,
+		},
+		{
+			name:     "exclusive updates the first record",
+			desired:  DesiredState{Name: testRecord, Type: "A", IP: "198.51.100.2", Exclusive: true},
+			observed: []DNSRecord{other},
+			action:   planUpdate,
+			targetID: "b",
+			ttl:      120,
+		},
+		{
+			name:    "creates with default TTL",
+			desired: DesiredState{Name: testRecord, Type: "A", IP: "198.51.100.2"},
+			action:  planCreate,
+			ttl:     defaultRecordTTL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planReconcile(tt.desired, tt.observed)
+			if plan.Action != tt.action {
+				t.Fatalf("action = %s; want %s", plan.Action, tt.action)
+			}
+			if tt.action == planNone {
+				return
+			}
+			if tt.targetID != "" && (plan.Target == nil || plan.Target.ID != tt.targetID) {
+				t.Fatalf("target = %+v; want %s", plan.Target, tt.targetID)
+			}
+			if plan.Request.Content != tt.desired.IP || plan.Request.TTL != tt.ttl || plan.Request.Comment != tt.comment {
+				t.Fatalf("request = %+v; want content %s, TTL %d, comment %q", plan.Request, tt.desired.IP, tt.ttl, tt.comment)
+			}
+		})
+	}
+}
+
+// newTestReconciler 返回使用模拟服务器、不等待的调和引擎
+func newTestReconciler(cf *fakeCloudflare) *Reconciler {
+	return &Reconciler{
+		Provider: cf.client(),
+		ZoneID:   testZoneID,
+		Name:     testRecord,
+		Type:     "A",
+		Retries:  3,
+	}
+}
+
+func TestReconcilerSyncIsIdempotent(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	r := newTestReconciler(cf)
+
+	result := r.Sync("198.51.100.1", "", "test")
+	if result.Err != nil || result.Action != planCreate || !result.Applied {
+		t.Fatalf("first Sync = %+v; want applied create", result)
+	}
+	writes := cf.writes()
+
+	result = r.Sync("198.51.100.1", "", "test")
+	if result.Err != nil || result.Action != planNone || result.Applied {
+		t.Fatalf("second Sync = %+v; want no-op", result)
+	}
+	if cf.writes() != writes {
+		t.Fatalf("second Sync sent %d write requests", cf.writes()-writes)
+	}
+}
+
+func TestReconcilerApplyRetriesAfterRateLimit(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	r := newTestReconciler(cf)
+
+	plan, err := r.Plan(r.Desired("198.51.100.2", "198.51.100.1"))
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	cf.rateLimit(1)
+	if err := r.Apply(plan, "test"); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); len(got) != 1 || got[0] != "198.51.100.2" {
+		t.Fatalf("records = %v; want [198.51.100.2]", got)
+	}
+}
+
+func TestReconcilerApplyDoesNotDuplicateAfterLostResponse(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	r := newTestReconciler(cf)
+
+	plan, err := r.Plan(r.Desired("198.51.100.2", ""))
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	// 第一次创建实际成功但返回错误，重试前重新读取记录，不应再次创建
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.2"})
+	cf.failNext("POST", "/dns_records", http.StatusBadGateway, 1)
+
+	if err := r.Apply(plan, "test"); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := cf.find(testZoneID, testRecord, "A"); len(got) != 1 {
+		t.Fatalf("records = %+v; want exactly one", got)
+	}
+}
+
+func TestReconcilerSyncAllReportsEachTarget(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.failNext("GET", "/dns_records", http.StatusInternalServerError, 1)
+	r := newTestReconciler(cf)
+
+	targets := []RecordTarget{
+		{ZoneID: testZoneID, Name: testRecord, Type: "A"},
+		{ZoneID: "missing", Name: "x.example.net", Type: "A"},
+	}
+	results := r.SyncAll(targets, "198.51.100.2", "", "test", 1)
+	if len(results) != 2 {
+		t.Fatalf("got %d results; want 2", len(results))
+	}
+	if results[0].Err == nil {
+		t.Errorf("first target: want injected error")
+	}
+	if results[1].Err == nil {
+		t.Errorf("unknown zone: want error")
+	}
+}
+
+func TestReconcilerRetryPolicy(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	r := newTestReconciler(cf)
+	r.ConfirmAttempts = 1
+	(&RetryPolicy{RetryDelay: "1s", Backoff: 2, MaxRetryDelay: "5s"}).applyTo(r)
+
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := r.retryDelay(attempt); got != want {
+			t.Errorf("retryDelay(%d) = %v; want %v", attempt, got, want)
+		}
+	}
+
+	// 记录的设置只覆盖设置了的项
+	none, ten := 0, 10
+	lab := RecordTarget{ZoneID: testZoneID, Name: testRecord, Type: "A", Retry: &RetryPolicy{Retries: &none, ConfirmAttempts: &none}}
+	critical := RecordTarget{ZoneID: testZoneID, Name: "vpn.example.com", Type: "A", Retry: &RetryPolicy{Retries: &ten, ConfirmAttempts: &ten}}
+	if got := r.forTarget(critical); got.Retries != 11 || got.ConfirmAttempts != 10 || got.RetryDelay != time.Second {
+		t.Errorf("critical policy = retries %d, confirm %d, delay %v", got.Retries, got.ConfirmAttempts, got.RetryDelay)
+	}
+	if got := r.confirmFor([]RecordTarget{lab, critical}).ConfirmAttempts; got != 10 {
+		t.Errorf("confirmFor = %d attempts; want the strictest (10)", got)
+	}
+	if got := r.confirmFor([]RecordTarget{lab}).ConfirmAttempts; got != 1 {
+		t.Errorf("confirmFor(lab) = %d attempts; want the default (1)", got)
+	}
+
+	// 不重试的记录第一次失败即返回
+	r.RetryDelay = 0
+	cf.failNext("PUT", "/dns_records/", http.StatusInternalServerError, 1)
+	if result := r.SyncAll([]RecordTarget{lab}, "198.51.100.2", "198.51.100.1", "test", 1)[0]; result.Err == nil {
+		t.Fatal("Sync succeeded; want the injected failure without retry")
+	}
+	if n := cf.count("PUT"); n != 1 {
+		t.Errorf("PUT requests = %d; want 1", n)
+	}
+}
+
+func TestReconcilerDedupe(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "203.0.113.7"})
+	r := newTestReconciler(cf)
+	r.Dedupe = true
+
+	// dry-run 只报告，不删除
+	r.DryRun = true
+	r.Sync("198.51.100.2", "198.51.100.1", "test")
+	if got := cf.find(testZoneID, testRecord, "A"); len(got) != 3 {
+		t.Fatalf("dry-run left %d records; want 3", len(got))
+	}
+
+	r.DryRun = false
+	result := r.Sync("198.51.100.2", "198.51.100.1", "test")
+	if result.Err != nil || result.Action != planUpdate {
+		t.Fatalf("Sync = %+v; want update after removing the duplicate", result)
+	}
+	got := cf.contents(testZoneID, testRecord, "A")
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"198.51.100.2", "203.0.113.7"}) {
+		t.Fatalf("records = %v; want one record per machine", got)
+	}
+}
+
+// failingBatch 第一次批量请求返回错误，并让随后的重新读取记录也失败
+type failingBatch struct {
+	*CloudflareClient
+	cf     *fakeCloudflare
+	failed bool
+}
+
+func (b *failingBatch) BatchDNSRecords(zoneID string, creates []DNSRecordCreateRequest, updates []batchUpdate) error {
+	if !b.failed {
+		b.failed = true
+		b.cf.failNext("GET", "/dns_records", http.StatusBadRequest, 1)
+		return errors.New("batch failed")
+	}
+	return b.CloudflareClient.BatchDNSRecords(zoneID, creates, updates)
+}
+
+func TestApplyGroupWaitsAfterReplanFailure(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	r := newTestReconciler(cf)
+	r.Provider = &failingBatch{CloudflareClient: cf.client(), cf: cf}
+	r.RetryDelay = 50 * time.Millisecond
+
+	results := []SyncResult{{Target: RecordTarget{ZoneID: testZoneID, Name: testRecord, Type: "A"}}}
+	plans, err := r.planGroup(results, "", "198.51.100.2", "198.51.100.1")
+	if err != nil {
+		t.Fatalf("planGroup: %v", err)
+	}
+
+	// 批量请求失败后重新读取记录也失败：每次重试前都要等待，不能连续用完重试次数
+	start := time.Now()
+	if err := r.applyGroup(plans, results, "", "198.51.100.2", "198.51.100.1", "test"); err != nil {
+		t.Fatalf("applyGroup: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*r.RetryDelay {
+		t.Fatalf("applyGroup retried after %v; want at least %v", elapsed, 2*r.RetryDelay)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); !reflect.DeepEqual(got, []string{"198.51.100.2"}) {
+		t.Fatalf("records = %v; want [198.51.100.2]", got)
+	}
+}
+
+// failingEdit 第一次修改记录返回错误，并让随后的重新读取记录也失败
+type failingEdit struct {
+	*CloudflareClient
+	cf     *fakeCloudflare
+	failed bool
+}
+
+func (e *failingEdit) EditDNSRecord(zoneID string, old DNSRecord, req DNSRecordCreateRequest) (*DNSRecord, error) {
+	if !e.failed {
+		e.failed = true
+		e.cf.failNext("GET", "/dns_records", http.StatusTooManyRequests, 1)
+		return nil, errors.New("edit failed")
+	}
+	return e.CloudflareClient.EditDNSRecord(zoneID, old, req)
+}
+
+func TestApplyWaitsAfterReplanFailure(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	r := newTestReconciler(cf)
+	r.Provider = &failingEdit{CloudflareClient: cf.client(), cf: cf}
+	r.RetryDelay = 50 * time.Millisecond
+
+	plan, err := r.Plan(r.Desired("198.51.100.2", "198.51.100.1"))
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	// 修改失败后重新读取记录被限流：每次重试前都要等待，不能连续用完重试次数
+	start := time.Now()
+	if err := r.Apply(plan, "test"); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*r.RetryDelay {
+		t.Fatalf("Apply retried after %v; want at least %v", elapsed, 2*r.RetryDelay)
+	}
+	if got := cf.contents(testZoneID, testRecord, "A"); !reflect.DeepEqual(got, []string{"198.51.100.2"}) {
+		t.Fatalf("records = %v; want [198.51.100.2]", got)
+	}
+}
+
+func TestApplyWaitsAfterLowerTTLFailure(t *testing.T) {
+	cf := newFakeCloudflare(t)
+	cf.addZone(testZoneID, testZoneName)
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1", TTL: 3600})
+	r := newTestReconciler(cf)
+	r.TTLStrategy = &TTLStrategyConfig{}
+	r.RetryDelay = 50 * time.Millisecond
+
+	plan, err := r.Plan(r.Desired("198.51.100.2", "198.51.100.1"))
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	// 降低TTL的请求失败：等待后再重试，不能连续用完重试次数
+	cf.failNext("PUT", "/dns_records/", http.StatusBadRequest, 1)
+	start := time.Now()
+	if err := r.Apply(plan, "test"); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < r.RetryDelay {
+		t.Fatalf("Apply retried after %v; want at least %v", elapsed, r.RetryDelay)
+	}
+	records := cf.find(testZoneID, testRecord, "A")
+	if len(records) != 1 || records[0].Content != "198.51.100.2" || records[0].TTL != 60 {
+		t.Fatalf("records = %+v; want one record at 198.51.100.2 with TTL 60", records)
+	}
+}
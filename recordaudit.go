@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordAuditConfig 定期审计记录：不依赖IP变化检测，按间隔比较全部记录与期望状态并修复不一致的记录。
+// IP未变化时不会读取记录，记录被删除或改错后要等到下次IP变化才会恢复；审计弥补这一点
+type RecordAuditConfig struct {
+	// Interval 审计间隔，默认 1h
+	Interval string `json:"interval,omitempty"`
+	// ReportOnly 只报告不一致的记录，不修复
+	ReportOnly bool `json:"report_only,omitempty"`
+}
+
+// defaultRecordAuditInterval 默认审计间隔
+const defaultRecordAuditInterval = time.Hour
+
+func (c *RecordAuditConfig) interval() time.Duration {
+	if c.Interval == "" {
+		return defaultRecordAuditInterval
+	}
+	d, err := parseDurationOrZero(c.Interval)
+	if err != nil || d <= 0 {
+		logError("record_audit.interval 格式无效: %s，使用默认值 %v", c.Interval, defaultRecordAuditInterval)
+		return defaultRecordAuditInterval
+	}
+	return d
+}
+
+// recordAuditScheduler 记录上次审计的时间
+type recordAuditScheduler struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+var recordAudit = &recordAuditScheduler{}
+
+// due 是否到了审计时间；第一次调用时只记下时间（启动时已有启动检查）
+func (a *recordAuditScheduler) due(interval time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	if a.last.IsZero() {
+		a.last = now
+		return false
+	}
+	if now.Sub(a.last) < interval {
+		return false
+	}
+	a.last = now
+	return true
+}
+
+// RecordAuditEntry 审计中一个记录集的结果
+type RecordAuditEntry struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Status   string   `json:"status"`
+	Problems []string `json:"problems,omitempty"`
+	// Repaired 已改回期望的内容
+	Repaired bool   `json:"repaired,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RecordAuditReport 审计结果
+type RecordAuditReport struct {
+	Time    time.Time          `json:"time"`
+	IP      string             `json:"ip"`
+	Entries []RecordAuditEntry `json:"entries"`
+	// Errors 读取失败的记录
+	Errors []string `json:"errors,omitempty"`
+}
+
+// counts 返回不一致的记录数与其中已修复的数量
+func (a *RecordAuditReport) counts() (problems, repaired int) {
+	problems = len(a.Errors)
+	for _, entry := range a.Entries {
+		if entry.Status != reportOK {
+			problems++
+		}
+		if entry.Repaired {
+			repaired++
+		}
+	}
+	return problems, repaired
+}
+
+// String 报告的文本形式，每个不一致的记录一行
+func (a *RecordAuditReport) String() string {
+	var b strings.Builder
+	for _, entry := range a.Entries {
+		if entry.Status == reportOK {
+			continue
+		}
+		line := fmt.Sprintf("%s %s: %s", entry.Name, entry.Type, strings.Join(entry.Problems, "; "))
+		switch {
+		case entry.Repaired:
+			line += tr("（已修复）")
+		case entry.Error != "":
+			line += fmt.Sprintf(tr("（修复失败: %s）"), entry.Error)
+		}
+		b.WriteString(line + "\n")
+	}
+	for _, e := range a.Errors {
+		fmt.Fprintf(&b, tr("读取失败: %s")+"\n", e)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// runRecordAudit 比较全部跟随公网IP的记录与子域名 CNAME 的实际状态与期望状态；
+// repair 为 true 时修复缺失或指向其他地址的记录（TTL 与代理设置的差异只报告）
+func runRecordAudit(config *Config, r *Reconciler, ip string, repair bool) *RecordAuditReport {
+	report := buildStartupReport(config, r, ip)
+	result := &RecordAuditReport{Time: time.Now(), IP: ip, Errors: report.Errors}
+	for _, entry := range report.Entries {
+		audited := RecordAuditEntry{Name: entry.Name, Type: entry.Type, Status: entry.Status, Problems: entry.Problems}
+		fixable := entry.Status == reportMissing || entry.Status == reportDrifted
+		if fixable && config.Drift != nil && drift.overridden(entry.target) {
+			// 按 drift 策略停止维护或接受了外部值的记录保持原样
+			audited.Problems = append(audited.Problems, tr("已按 drift 策略停止维护，不修复"))
+			fixable = false
+		}
+		if repair && fixable && entry.want != "" {
+			if err := repairAuditEntry(r, entry, ip); err != nil {
+				audited.Error = redactError(err).Error()
+			} else {
+				audited.Repaired = true
+			}
+		}
+		result.Entries = append(result.Entries, audited)
+	}
+	return result
+}
+
+// repairAuditEntry 按期望状态新建或修改一个记录集
+func repairAuditEntry(r *Reconciler, entry reportEntry, ip string) error {
+	t := r.forTarget(entry.target)
+	desired := t.Desired(entry.want, entry.target.previousIP(ip))
+	if entry.target.Type == "CNAME" {
+		desired = DesiredState{ZoneID: t.ZoneID, Name: t.Name, Type: "CNAME", IP: entry.want, Exclusive: true}
+	}
+	plan, err := t.Plan(desired)
+	if err != nil {
+		return err
+	}
+	return t.Apply(plan, tr("定期审计"))
+}
+
+// runScheduledAudit IP未变化时按间隔执行审计，写入日志与历史，存在不一致时发送 drift 通知
+func runScheduledAudit(config *Config, r *Reconciler, ip string) {
+	repair := !config.RecordAudit.ReportOnly && !r.DryRun
+	report := runRecordAudit(config, r, ip, repair)
+	for _, line := range strings.Split(report.String(), "\n") {
+		if line != "" {
+			logError("定期审计: %s", line)
+		}
+	}
+	problems, repaired := report.counts()
+	message := fmt.Sprintf(tr("共 %d 条记录，%d 条与期望状态不一致，已修复 %d 条"), len(report.Entries)+len(report.Errors), problems, repaired)
+	logInfo("定期审计完成: %s", message)
+	if problems == 0 {
+		return
+	}
+
+	appendHistory(HistoryEntry{Type: "audit", IP: ip, Message: message})
+	notify(NotifyEvent{
+		Type:    EventDrift,
+		Title:   fmt.Sprintf(tr("定期审计: %d 条记录与期望状态不一致"), problems),
+		Message: report.String(),
+		Record:  config.RecordName,
+		NewIP:   ip,
+	})
+}
+
+func cmdAuditMain(args []string) int {
+	fs, common := newFlagSet("audit")
+	repair := fs.Bool("repair", false, tr("修复缺失或指向其他地址的记录（默认只报告）"))
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+
+	r := newReconciler()
+	r.Retries = 1
+	ip, _, err := r.Detect()
+	if err != nil {
+		return failOutput(*output, fmt.Errorf(tr("获取公网IP失败: %v"), err))
+	}
+	report := runRecordAudit(app.Config(), r, ip, *repair)
+	problems, repaired := report.counts()
+
+	if *output == outputJSON {
+		printJSON(report)
+	} else {
+		for _, entry := range report.Entries {
+			if entry.Status == reportOK {
+				fmt.Printf(tr("✓ %s %s 正常\n"), entry.Name, entry.Type)
+			}
+		}
+		if text := report.String(); text != "" {
+			fmt.Println(text)
+		}
+		fmt.Printf(tr("\n共 %d 条记录，%d 条与期望状态不一致，已修复 %d 条\n"), len(report.Entries)+len(report.Errors), problems, repaired)
+		if problems > repaired && !*repair {
+			fmt.Println(tr("提示: 使用 --repair 修复缺失或指向其他地址的记录"))
+		}
+	}
+	// 仍有未修复的不一致时返回非零，便于定时任务发现问题
+	if problems > repaired {
+		return 1
+	}
+	return 0
+}
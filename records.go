@@ -0,0 +1,636 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// proxiableTypes 可以开启 Cloudflare 代理的记录类型
+var proxiableTypes = map[string]bool{"A": true, "AAAA": true, "CNAME": true}
+
+// manageRecordsMenu DNS记录管理菜单（查看、创建、编辑、删除）
+func manageRecordsMenu() {
+	cfClient := app.Client()
+	for {
+		fmt.Println(tr("\n========== DNS记录管理 =========="))
+		fmt.Println(tr("1. 查看当前配置的记录"))
+		fmt.Println(tr("2. 查看区域内所有记录"))
+		fmt.Println(tr("3. 创建记录"))
+		fmt.Println(tr("4. 编辑记录"))
+		fmt.Println(tr("5. 删除记录"))
+		fmt.Println(tr("6. 返回主菜单"))
+		fmt.Println("================================")
+
+		choice := getUserInput(tr("请选择操作 (1-6): "))
+
+		// 菜单中的变更在审计日志中统一记录来源
+		cfClient.SetAuditSource(tr("交互式菜单"))
+
+		switch choice {
+		case "1":
+			viewDNSRecords(recordFilter{})
+		case "2":
+			if records, err := listZoneRecords(); err == nil {
+				printRecordTable(records)
+			}
+		case "3":
+			createRecordInteractive()
+		case "4":
+			editRecordInteractive()
+		case "5":
+			deleteRecordInteractive()
+		case "6":
+			return
+		default:
+			fmt.Println(tr("无效的选择，请重新输入。"))
+		}
+	}
+}
+
+// listZoneRecords 获取区域内所有记录，失败时输出错误
+func listZoneRecords() ([]DNSRecord, error) {
+	config := app.Config()
+	cfClient := app.Client()
+	fmt.Println(tr("\n正在获取DNS记录..."))
+	records, err := cfClient.ListZoneDNSRecords(config.ZoneID)
+	if err != nil {
+		fmt.Printf(tr("❌ 获取失败: %v\n"), err)
+		return nil, err
+	}
+	if len(records) == 0 {
+		fmt.Println(tr("未找到匹配的DNS记录"))
+	}
+	return records, nil
+}
+
+// printRecordTable 输出带序号的记录列表
+func printRecordTable(records []DNSRecord) {
+	if len(records) == 0 {
+		return
+	}
+	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-4s %-30s %-8s %-30s %-8s %-8s\n", "#", tr("名称"), tr("类型"), tr("内容"), "TTL", tr("代理"))
+	fmt.Println(strings.Repeat("-", 100))
+	for i, record := range records {
+		content := record.Content
+		if record.Priority != nil {
+			content = fmt.Sprintf("%d %s", *record.Priority, content)
+		}
+		fmt.Printf("%-4d %-30s %-8s %-30s %-8s %-8s\n",
+			i+1, record.Name, record.Type, content, formatTTL(record.TTL), formatBool(record.Proxied))
+	}
+	fmt.Println(strings.Repeat("-", 100))
+}
+
+// formatTTL TTL 为 1 时表示自动
+func formatTTL(ttl int) string {
+	if ttl == 1 {
+		return tr("自动")
+	}
+	return strconv.Itoa(ttl)
+}
+
+// formatBool 以是/否显示布尔值
+func formatBool(b bool) string {
+	if b {
+		return tr("是")
+	}
+	return tr("否")
+}
+
+// selectRecord 列出区域内记录并让用户按序号选择
+func selectRecord() (*DNSRecord, bool) {
+	records, err := listZoneRecords()
+	if err != nil || len(records) == 0 {
+		return nil, false
+	}
+	printRecordTable(records)
+
+	input := getUserInput(tr("请输入记录序号（直接回车取消）: "))
+	if input == "" {
+		fmt.Println(tr("已取消"))
+		return nil, false
+	}
+	index, err := strconv.Atoi(input)
+	if err != nil || index < 1 || index > len(records) {
+		fmt.Println(tr("无效的序号"))
+		return nil, false
+	}
+	return &records[index-1], true
+}
+
+// confirm 询问用户确认，默认否
+func confirm(prompt string) bool {
+	answer := getUserInput(prompt + " (y/N): ")
+	if answer == "y" || answer == "Y" {
+		return true
+	}
+	fmt.Println(tr("已取消"))
+	return false
+}
+
+// validateRecordContent 根据记录类型校验内容格式
+func validateRecordContent(recordType, content string) error {
+	switch recordType {
+	case "A":
+		if !isValidIPv4(content) {
+			return fmt.Errorf(tr("无效的IP地址格式: %s"), content)
+		}
+	case "AAAA":
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf(tr("无效的IPv6地址格式: %s"), content)
+		}
+	}
+	if content == "" {
+		return errors.New(tr("记录内容不能为空"))
+	}
+	return nil
+}
+
+// parseTTL 解析 TTL 输入：空值使用默认值，1 表示自动，其他取值范围 60-86400
+func parseTTL(input string, def int) (int, error) {
+	if input == "" {
+		return def, nil
+	}
+	ttl, err := strconv.Atoi(input)
+	if err != nil || (ttl != 1 && (ttl < 60 || ttl > 86400)) {
+		return 0, fmt.Errorf(tr("无效的 TTL: %s（1 表示自动，或 60-86400 秒）"), input)
+	}
+	return ttl, nil
+}
+
+// parsePriority 解析 MX 优先级输入：空值使用默认值，取值范围 0-65535
+func parsePriority(input string, def int) (int, error) {
+	if input == "" {
+		return def, nil
+	}
+	priority, err := strconv.Atoi(input)
+	if err != nil || priority < 0 || priority > 65535 {
+		return 0, fmt.Errorf(tr("无效的优先级: %s（0-65535）"), input)
+	}
+	return priority, nil
+}
+
+// parseYesNo 解析 y/n 输入，空值使用默认值
+func parseYesNo(input string, def bool) bool {
+	switch strings.ToLower(input) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// createRecordInteractive 交互式创建记录
+func createRecordInteractive() {
+	config := app.Config()
+	cfClient := app.Client()
+	fmt.Println(tr("\n========== 创建记录 =========="))
+
+	recordType := strings.ToUpper(getUserInput(tr("记录类型（如 A、AAAA、CNAME、TXT、MX）: ")))
+	if recordType == "" {
+		fmt.Println(tr("已取消"))
+		return
+	}
+
+	name := getUserInput(tr("记录名称（完整域名）: "))
+	if name == "" {
+		fmt.Println(tr("记录名称不能为空"))
+		return
+	}
+
+	content := getUserInput(tr("记录内容: "))
+	if err := validateRecordContent(recordType, content); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	ttl, err := parseTTL(getUserInput(tr("TTL（秒，1 表示自动，默认 1）: ")), 1)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	req := DNSRecordCreateRequest{
+		Type:    recordType,
+		Name:    name,
+		Content: content,
+		TTL:     ttl,
+	}
+
+	if proxiableTypes[recordType] {
+		req.Proxied = parseYesNo(getUserInput(tr("是否开启 Cloudflare 代理？(y/N): ")), false)
+	}
+
+	if recordType == "MX" {
+		priority, err := parsePriority(getUserInput(tr("优先级（默认 10）: ")), 10)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		req.Priority = &priority
+	}
+
+	fmt.Printf(tr("\n即将创建: %s %s %s (TTL: %s, 代理: %s)\n"),
+		req.Name, req.Type, req.Content, formatTTL(req.TTL), formatBool(req.Proxied))
+	if !confirm(tr("确认创建？")) {
+		return
+	}
+
+	record, err := cfClient.CreateDNSRecordWithOptions(config.ZoneID, req)
+	if err != nil {
+		fmt.Printf(tr("❌ 创建失败: %v\n"), err)
+		return
+	}
+	fmt.Printf(tr("✓ 记录已创建: %s %s %s (ID: %s)\n"), record.Name, record.Type, record.Content, record.ID)
+}
+
+// editRecordInteractive 交互式编辑记录内容、TTL、代理状态
+func editRecordInteractive() {
+	config := app.Config()
+	cfClient := app.Client()
+	record, ok := selectRecord()
+	if !ok {
+		return
+	}
+
+	fmt.Printf(tr("\n正在编辑: %s %s（直接回车保留原值）\n"), record.Name, record.Type)
+
+	content := getUserInput(fmt.Sprintf(tr("记录内容 [%s]: "), record.Content))
+	if content == "" {
+		content = record.Content
+	}
+	if err := validateRecordContent(record.Type, content); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	ttl, err := parseTTL(getUserInput(fmt.Sprintf(tr("TTL [%s]: "), formatTTL(record.TTL))), record.TTL)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	req := DNSRecordCreateRequest{
+		Type:     record.Type,
+		Name:     record.Name,
+		Content:  content,
+		TTL:      ttl,
+		Priority: record.Priority,
+		Comment:  record.Comment,
+	}
+
+	if proxiableTypes[record.Type] {
+		req.Proxied = parseYesNo(getUserInput(fmt.Sprintf(tr("是否开启 Cloudflare 代理 [%s]: "), formatBool(record.Proxied))), record.Proxied)
+	}
+
+	oldPriority := 0
+	if record.Priority != nil {
+		oldPriority = *record.Priority
+	}
+	if record.Type == "MX" {
+		priority, err := parsePriority(getUserInput(fmt.Sprintf(tr("优先级 [%d]: "), oldPriority)), oldPriority)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		req.Priority = &priority
+	}
+	priorityChanged := req.Priority != nil && *req.Priority != oldPriority
+
+	if req.Content == record.Content && req.TTL == record.TTL && req.Proxied == record.Proxied && !priorityChanged {
+		fmt.Println(tr("记录未修改"))
+		return
+	}
+
+	fmt.Printf(tr("\n内容: %s -> %s\nTTL: %s -> %s\n代理: %s -> %s\n"),
+		record.Content, req.Content, formatTTL(record.TTL), formatTTL(req.TTL), formatBool(record.Proxied), formatBool(req.Proxied))
+	if priorityChanged {
+		fmt.Printf(tr("优先级: %d -> %d\n"), oldPriority, *req.Priority)
+	}
+	if !confirm(tr("确认修改？")) {
+		return
+	}
+
+	updated, err := cfClient.EditDNSRecord(config.ZoneID, *record, req)
+	if err != nil {
+		fmt.Printf(tr("❌ 更新失败: %v\n"), err)
+		return
+	}
+	fmt.Printf(tr("✓ 记录已更新: %s %s %s\n"), updated.Name, updated.Type, updated.Content)
+}
+
+// deleteRecordInteractive 交互式删除记录
+func deleteRecordInteractive() {
+	config := app.Config()
+	cfClient := app.Client()
+	record, ok := selectRecord()
+	if !ok {
+		return
+	}
+
+	fmt.Printf(tr("\n即将删除: %s %s %s\n"), record.Name, record.Type, record.Content)
+	if record.Name == config.RecordName && record.Type == config.RecordType {
+		fmt.Println(tr("警告: 该记录由本程序自动维护，删除后守护进程可能会重新创建"))
+	}
+	if !confirm(tr("确认删除？")) {
+		return
+	}
+
+	if err := cfClient.DeleteDNSRecord(config.ZoneID, *record); err != nil {
+		fmt.Printf(tr("❌ 删除失败: %v\n"), err)
+		return
+	}
+	fmt.Println(tr("✓ 记录已删除"))
+}
+
+// recordArgs 取出写在参数之前的记录名称，如 "records create www --type A"
+func recordArgs(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// resolveRecordArg 补全 "@" 形式的记录名称，并检查记录是否属于配置的区域
+func resolveRecordArg(name string) (string, error) {
+	config := app.Config()
+	client := app.Client()
+	if err := checkRecordZone(client, config.ZoneID, name); err != nil {
+		return "", err
+	}
+	return client.recordName(config.ZoneID, name)
+}
+
+// cmdRecordsCreate 命令行创建记录，使用已配置的令牌与区域
+func cmdRecordsCreate(args []string) int {
+	name, args := recordArgs(args)
+	fs, common := newFlagSet("records")
+	recordType := fs.String("type", "", tr("记录类型（如 A、AAAA、CNAME、TXT、MX）"))
+	content := fs.String("content", "", tr("记录内容"))
+	ttlFlag := fs.String("ttl", "1", tr("TTL（秒，1 表示自动）"))
+	proxied := fs.Bool("proxied", false, tr("开启 Cloudflare 代理（仅 A、AAAA、CNAME）"))
+	priority := fs.Int("priority", 10, tr("MX 记录的优先级"))
+	comment := fs.String("comment", "", tr("记录备注"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	*recordType = strings.ToUpper(*recordType)
+	if name == "" || *recordType == "" || *content == "" {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager records create <name> --type A --content 1.2.3.4 [--ttl 300] [--proxied] [--yes]"))
+		return 2
+	}
+	if err := validateRecordContent(*recordType, *content); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	ttl, err := parseTTL(*ttlFlag, 1)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *proxied && !proxiableTypes[*recordType] {
+		fmt.Fprintf(os.Stderr, tr("%s 记录不能开启 Cloudflare 代理\n"), *recordType)
+		return 2
+	}
+	if !*yes && *output == outputJSON {
+		return failOutput(*output, errors.New(tr("--output json 需要与 --yes 一起使用")))
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+	if name, err = resolveRecordArg(name); err != nil {
+		return failOutput(*output, err)
+	}
+
+	req := DNSRecordCreateRequest{
+		Type:    *recordType,
+		Name:    name,
+		Content: *content,
+		TTL:     ttl,
+		Proxied: *proxied,
+		Comment: *comment,
+	}
+	if req.Type == "MX" {
+		req.Priority = priority
+	}
+
+	if !*yes {
+		fmt.Printf(tr("\n即将创建: %s %s %s (TTL: %s, 代理: %s)\n"),
+			req.Name, req.Type, req.Content, formatTTL(req.TTL), formatBool(req.Proxied))
+		if !confirm(tr("确认创建？")) {
+			return 1
+		}
+	}
+
+	client := app.Client()
+	client.SetAuditSource(tr("命令行"))
+	record, err := client.CreateDNSRecordWithOptions(app.Config().ZoneID, req)
+	if err != nil {
+		return failOutput(*output, fmt.Errorf(tr("创建失败: %v"), err))
+	}
+	if *output == outputJSON {
+		printJSON(record)
+		return 0
+	}
+	fmt.Printf(tr("✓ 记录已创建: %s %s %s (ID: %s)\n"), record.Name, record.Type, record.Content, record.ID)
+	return 0
+}
+
+// cmdRecordsDelete 命令行删除记录；按名称、类型、内容筛选，匹配多条时需要 --all
+func cmdRecordsDelete(args []string) int {
+	name, args := recordArgs(args)
+	fs, common := newFlagSet("records")
+	recordType := fs.String("type", "", tr("只删除该类型的记录"))
+	content := fs.String("content", "", tr("只删除内容为该值的记录"))
+	all := fs.Bool("all", false, tr("删除所有匹配的记录（匹配多条时需要）"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager records delete <name> [--type A] [--content 1.2.3.4] [--all] [--yes]"))
+		return 2
+	}
+	if !*yes && *output == outputJSON {
+		return failOutput(*output, errors.New(tr("--output json 需要与 --yes 一起使用")))
+	}
+
+	opts := runtimeOptions{console: *output != outputJSON, debugHTTP: *common.debugHTTP}
+	if err := initRuntime(opts); err != nil {
+		return failOutput(*output, err)
+	}
+	name, err := resolveRecordArg(name)
+	if err != nil {
+		return failOutput(*output, err)
+	}
+
+	config := app.Config()
+	client := app.Client()
+	records, err := client.ListDNSRecords(config.ZoneID, name)
+	if err != nil {
+		return failOutput(*output, err)
+	}
+	matched := filterRecords(records, strings.ToUpper(*recordType), *content)
+	switch {
+	case len(matched) == 0:
+		return failOutput(*output, fmt.Errorf(tr("未找到匹配的DNS记录: %s"), name))
+	case len(matched) > 1 && !*all:
+		if *output != outputJSON {
+			printRecordTable(matched)
+		}
+		return failOutput(*output, fmt.Errorf(tr("匹配到 %d 条记录，请用 --type、--content 缩小范围，或使用 --all 全部删除"), len(matched)))
+	}
+
+	if !*yes {
+		fmt.Println(tr("\n即将删除以下记录:"))
+		printRecordTable(matched)
+		for _, record := range matched {
+			if record.Name == config.RecordName && record.Type == config.RecordType {
+				fmt.Println(tr("警告: 该记录由本程序自动维护，删除后守护进程可能会重新创建"))
+				break
+			}
+		}
+		if !confirm(tr("确认删除？")) {
+			return 1
+		}
+	}
+
+	client.SetAuditSource(tr("命令行"))
+	for i, record := range matched {
+		if err := client.DeleteDNSRecord(config.ZoneID, record); err != nil {
+			return failOutput(*output, fmt.Errorf(tr("删除失败（已删除 %d/%d 条）: %v"), i, len(matched), err))
+		}
+	}
+	if *output == outputJSON {
+		printJSON(map[string]interface{}{"success": true, "deleted": matched})
+		return 0
+	}
+	fmt.Printf(tr("✓ 已删除 %d 条记录\n"), len(matched))
+	return 0
+}
+
+// filterRecords 按类型与内容筛选记录，参数为空表示不限
+func filterRecords(records []DNSRecord, recordType, content string) []DNSRecord {
+	var matched []DNSRecord
+	for _, record := range records {
+		if (recordType == "" || record.Type == recordType) && (content == "" || record.Content == content) {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// recordFilter 记录列表的筛选与排序条件；设置了任一筛选条件时在整个区域中查找，否则只列出配置的记录
+type recordFilter struct {
+	Type            string
+	ContentContains string
+	Name            string // 名称通配符，如 "*.example.com"
+	Sort            string // name（默认）/ type / content / ttl
+}
+
+// recordSortKeys 可用的排序字段
+var recordSortKeys = map[string]bool{"": true, "name": true, "type": true, "content": true, "ttl": true}
+
+// zoneWide 是否需要在整个区域中查找
+func (f recordFilter) zoneWide() bool {
+	return f.Type != "" || f.ContentContains != "" || f.Name != ""
+}
+
+// validate 检查通配符与排序字段
+func (f recordFilter) validate() error {
+	if _, err := path.Match(f.Name, ""); err != nil {
+		return fmt.Errorf(tr("无效的名称通配符: %s"), f.Name)
+	}
+	if !recordSortKeys[f.Sort] {
+		return fmt.Errorf(tr("无效的排序字段: %s（可选 name、type、content、ttl）"), f.Sort)
+	}
+	return nil
+}
+
+// apply 按条件筛选并排序，相同时依次按名称、类型、内容排序
+func (f recordFilter) apply(records []DNSRecord) []DNSRecord {
+	var matched []DNSRecord
+	for _, record := range records {
+		if f.Type != "" && !strings.EqualFold(record.Type, f.Type) {
+			continue
+		}
+		if f.ContentContains != "" && !strings.Contains(strings.ToLower(record.Content), strings.ToLower(f.ContentContains)) {
+			continue
+		}
+		if f.Name != "" {
+			if ok, _ := path.Match(strings.ToLower(f.Name), strings.ToLower(record.Name)); !ok {
+				continue
+			}
+		}
+		matched = append(matched, record)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		switch f.Sort {
+		case "type":
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+		case "content":
+			if a.Content != b.Content {
+				return a.Content < b.Content
+			}
+		case "ttl":
+			if a.TTL != b.TTL {
+				return a.TTL < b.TTL
+			}
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Content < b.Content
+	})
+	return matched
+}
+
+// listFilteredRecords 读取记录并筛选：有筛选条件时翻页读取整个区域（按类型筛选时由 API 过滤），否则只读取配置的记录
+func listFilteredRecords(filter recordFilter) ([]DNSRecord, error) {
+	config := app.Config()
+	client := app.Client()
+
+	var records []DNSRecord
+	var err error
+	switch {
+	case !filter.zoneWide():
+		records, err = client.ListDNSRecords(config.ZoneID, config.RecordName)
+	case filter.Type != "":
+		records, err = client.ListZoneDNSRecordsByType(config.ZoneID, strings.ToUpper(filter.Type))
+	default:
+		records, err = client.ListZoneDNSRecords(config.ZoneID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filter.apply(records), nil
+}
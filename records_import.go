@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// importedRecord 从 CSV 或区域文件读取的一条记录及其所在行
+type importedRecord struct {
+	Line int
+	ApplyRecord
+}
+
+// importCSVColumns CSV 文件可用的列，第一行为列名
+var importCSVColumns = map[string]bool{
+	"name": true, "type": true, "content": true, "ttl": true, "proxied": true, "priority": true, "comment": true,
+}
+
+// parseRecordCSV 解析 CSV 文件：第一行为列名（name、type、content 必填，ttl、proxied、priority、comment 可选）；
+// 格式错误的行返回到 issues 中，不影响其他行
+func parseRecordCSV(data []byte) (records []importedRecord, issues []string, err error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf(tr("读取 CSV 列名失败: %v"), err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !importCSVColumns[name] {
+			return nil, nil, fmt.Errorf(tr("CSV 中未知的列: %s（可用 name、type、content、ttl、proxied、priority、comment）"), name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"name", "type", "content"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf(tr("CSV 缺少 %s 列"), name)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: %v"), parseErr.StartLine, parseErr.Err))
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := importedRecord{Line: line, ApplyRecord: ApplyRecord{
+			Name:    field("name"),
+			Type:    field("type"),
+			Content: field("content"),
+			Comment: field("comment"),
+		}}
+		if value := field("ttl"); value != "" {
+			if record.TTL, err = strconv.Atoi(value); err != nil {
+				issues = append(issues, fmt.Sprintf(tr("第 %d 行: 无效的 TTL: %s"), line, value))
+				continue
+			}
+		}
+		if value := field("proxied"); value != "" {
+			if record.Proxied, err = strconv.ParseBool(value); err != nil {
+				issues = append(issues, fmt.Sprintf(tr("第 %d 行: 无效的 proxied: %s（true 或 false）"), line, value))
+				continue
+			}
+		}
+		if value := field("priority"); value != "" {
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf(tr("第 %d 行: 无效的 priority: %s"), line, value))
+				continue
+			}
+			record.Priority = &priority
+		}
+		records = append(records, record)
+	}
+	return records, issues, nil
+}
+
+// zoneToken 区域文件中的一个字段，quoted 表示带引号的字符串
+type zoneToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeZoneLine 拆分一行区域文件，去掉注释并返回括号层数的变化
+func tokenizeZoneLine(line string) (tokens []zoneToken, depth int) {
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ';':
+			return tokens, depth
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case c == '"':
+			var text strings.Builder
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				text.WriteByte(line[i])
+			}
+			i++
+			tokens = append(tokens, zoneToken{text: text.String(), quoted: true})
+		default:
+			start := i
+			for i < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[i])) {
+				i++
+			}
+			tokens = append(tokens, zoneToken{text: line[start:i]})
+		}
+	}
+	return tokens, depth
+}
+
+// parseZoneTTL 解析 TTL，支持 BIND 的单位（如 1h、1d12h）
+func parseZoneTTL(value string) (int, bool) {
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, n, digits := 0, 0, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c >= '0' && c <= '9':
+			n = n*10 + int(c-'0')
+			digits = true
+		case units[c|0x20] > 0 && digits:
+			total += n * units[c|0x20]
+			n = 0
+		default:
+			return 0, false
+		}
+	}
+	return total + n, digits
+}
+
+// parseZoneFile 解析 BIND 格式的区域文件，origin 为区域名称（文件中的 $ORIGIN 优先）。
+// 支持 A、AAAA、CNAME、MX、TXT 与子域名的 NS 记录；SOA 与根域名的 NS 由 Cloudflare 管理，直接跳过；
+// 其他类型及格式错误的记录返回到 issues 中
+func parseZoneFile(data []byte, origin string) (records []importedRecord, issues []string) {
+	origin = strings.TrimSuffix(strings.ToLower(origin), ".")
+	zone := origin
+	defaultTTL := 0
+	owner := origin
+
+	absolute := func(name string) string {
+		switch {
+		case name == "@":
+			return origin
+		case strings.HasSuffix(name, "."):
+			return strings.TrimSuffix(name, ".")
+		}
+		return name + "." + origin
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		raw := lines[i]
+		tokens, depth := tokenizeZoneLine(raw)
+		for depth > 0 && i+1 < len(lines) {
+			i++
+			more, d := tokenizeZoneLine(lines[i])
+			tokens = append(tokens, more...)
+			depth += d
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(tokens[0].text) {
+		case "$ORIGIN":
+			if len(tokens) > 1 {
+				origin = strings.ToLower(absolute(tokens[1].text))
+			}
+			continue
+		case "$TTL":
+			if len(tokens) > 1 {
+				if ttl, ok := parseZoneTTL(tokens[1].text); ok {
+					defaultTTL = ttl
+				}
+			}
+			continue
+		case "$INCLUDE", "$GENERATE":
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: 不支持 %s，已跳过"), lineNo, tokens[0].text))
+			continue
+		}
+
+		// 行首为空白时沿用上一条记录的名称
+		if raw[0] != ' ' && raw[0] != '\t' {
+			owner = strings.ToLower(absolute(tokens[0].text))
+			tokens = tokens[1:]
+		}
+
+		ttl := defaultTTL
+		for len(tokens) > 0 {
+			if t, ok := parseZoneTTL(tokens[0].text); ok && !tokens[0].quoted {
+				ttl = t
+			} else if class := strings.ToUpper(tokens[0].text); class != "IN" && class != "CH" && class != "HS" {
+				break
+			}
+			tokens = tokens[1:]
+		}
+		if len(tokens) < 2 {
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: 无法解析的记录"), lineNo))
+			continue
+		}
+
+		record := importedRecord{Line: lineNo, ApplyRecord: ApplyRecord{
+			Name: owner,
+			Type: strings.ToUpper(tokens[0].text),
+			TTL:  clampImportTTL(ttl),
+		}}
+		rdata := tokens[1:]
+		switch record.Type {
+		case "A", "AAAA":
+			record.Content = rdata[0].text
+		case "CNAME":
+			record.Content = strings.ToLower(absolute(rdata[0].text))
+		case "NS":
+			if owner == zone {
+				continue
+			}
+			record.Content = strings.ToLower(absolute(rdata[0].text))
+		case "MX":
+			priority, err := strconv.Atoi(rdata[0].text)
+			if err != nil || len(rdata) < 2 {
+				issues = append(issues, fmt.Sprintf(tr("第 %d 行: 无法解析的记录"), lineNo))
+				continue
+			}
+			record.Priority = &priority
+			record.Content = strings.ToLower(absolute(rdata[1].text))
+		case "TXT", "SPF":
+			var text strings.Builder
+			for _, token := range rdata {
+				text.WriteString(token.text)
+			}
+			record.Type = "TXT"
+			record.Content = text.String()
+		case "SOA":
+			continue
+		default:
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: 不支持的记录类型 %s，已跳过"), lineNo, record.Type))
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, issues
+}
+
+// clampImportTTL 将区域文件中的 TTL 限制在 Cloudflare 接受的范围内，0 表示自动
+func clampImportTTL(ttl int) int {
+	switch {
+	case ttl == 0:
+		return 0
+	case ttl < 60:
+		return 60
+	case ttl > 86400:
+		return 86400
+	}
+	return ttl
+}
+
+// planImport 逐条校验导入的记录并与区域内的当前记录比较：无效、重复与本程序维护的记录跳过并返回到 issues 中，
+// 其余生成新建或修改操作（不删除区域内的其他记录）
+func planImport(records []importedRecord, config *Config, client *CloudflareClient, zoneID string) ([]restoreAction, []string, error) {
+	managed, err := managedRecordSets(config, client, zoneID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var issues []string
+	var desired []DNSRecord
+	seen := make(map[string]int)
+	for _, record := range records {
+		valid, err := (&ApplyFile{Records: []ApplyRecord{record.ApplyRecord}}).desiredRecords(client, zoneID)
+		if err != nil {
+			if errors.Is(err, ErrNetwork) || errors.Is(err, ErrAuth) {
+				return nil, nil, err
+			}
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: %v"), record.Line, err))
+			continue
+		}
+		want := valid[0]
+		if isManagedRecord(managed, want) {
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: %s %s 由本程序自动维护，已跳过"), record.Line, want.Name, want.Type))
+			continue
+		}
+		key := want.Name + "/" + want.Type + "/" + want.Content
+		if line, ok := seen[key]; ok {
+			issues = append(issues, fmt.Sprintf(tr("第 %d 行: 与第 %d 行重复，已跳过"), record.Line, line))
+			continue
+		}
+		seen[key] = record.Line
+		desired = append(desired, want)
+	}
+
+	current, err := client.ListZoneDNSRecords(zoneID)
+	if err != nil {
+		return nil, nil, fmt.Errorf(tr("获取DNS记录失败: %w"), err)
+	}
+	return planApply(desired, current, false, managed), issues, nil
+}
+
+// cmdRecordsImport 从 CSV 或区域文件批量创建、修改记录；单条记录失败不影响其他记录
+func cmdRecordsImport(args []string) int {
+	file, args := recordArgs(args)
+	fs, common := newFlagSet("records")
+	format := fs.String("format", "", tr("文件格式: csv 或 zone（默认按扩展名判断，.csv 为 CSV，其他为区域文件）"))
+	dryRunOnly := fs.Bool("dry-run", false, tr("只显示将要执行的更改，不修改DNS记录"))
+	yes := fs.Bool("yes", false, tr("不询问确认，直接执行"))
+	parseFlags(fs, common, args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(os.Stderr, tr("用法: dns_manager records import <file.csv|zonefile> [--format csv|zone] [--dry-run] [--yes]"))
+		return 2
+	}
+	if *format == "" {
+		*format = "zone"
+		if strings.EqualFold(filepath.Ext(file), ".csv") {
+			*format = "csv"
+		}
+	}
+	if *format != "csv" && *format != "zone" {
+		fmt.Fprintf(os.Stderr, tr("无效的文件格式: %s（可选 csv 或 zone）\n"), *format)
+		return 2
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("读取文件失败: %v")+"\n", err)
+		return 1
+	}
+
+	if err := initRuntime(runtimeOptions{console: true, debugHTTP: *common.debugHTTP}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer globalLogger.Close()
+	config := app.Config()
+	client := app.Client()
+
+	var records []importedRecord
+	var issues []string
+	if *format == "csv" {
+		if records, issues, err = parseRecordCSV(data); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+	} else {
+		zoneName, err := client.zoneName(config.ZoneID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitCodeFor(err)
+		}
+		records, issues = parseZoneFile(data, zoneName)
+	}
+
+	actions, skipped, err := planImport(records, config, client, config.ZoneID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitCodeFor(err)
+	}
+	issues = append(issues, skipped...)
+	for _, issue := range issues {
+		fmt.Println("  ! " + issue)
+	}
+	if len(actions) == 0 {
+		fmt.Printf(tr("读取 %d 条记录，区域中的记录已一致，无需修改\n"), len(records))
+		return 0
+	}
+	printRestorePlan(actions)
+	counts := map[string]int{}
+	for _, action := range actions {
+		counts[action.Action]++
+	}
+	fmt.Printf(tr("读取 %d 条记录，跳过 %d 条；计划: 新建 %d 条，修改 %d 条\n"), len(records), len(issues), counts["create"], counts["update"])
+
+	if *dryRunOnly {
+		return 0
+	}
+	if !*yes && !confirm(tr("确认导入？")) {
+		return 1
+	}
+
+	client.SetAuditSource(tr("导入文件: ") + filepath.Base(file))
+	failed := 0
+	for i, action := range actions {
+		record := action.Record
+		fmt.Printf("[%d/%d] %s %s %s %s ", i+1, len(actions), action.Action, record.Name, record.Type, record.Content)
+		if err := applyRestoreAction(config.ZoneID, action); err != nil {
+			failed++
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		fmt.Println("✓")
+	}
+	fmt.Printf(tr("导入完成: 成功 %d 条，失败 %d 条，跳过 %d 条\n"), len(actions)-failed, failed, len(issues))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
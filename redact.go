@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	secrets   []string
+	secretsMu sync.RWMutex
+
+	// 匹配 Authorization 头与 Bearer 令牌
+	authHeaderPattern = regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?)(bearer\s+)?[^\s"',}]+`)
+	bearerPattern     = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_.~+/=]{8,}`)
+	// 匹配 JSON 中的 api_token 字段
+	tokenFieldPattern = regexp.MustCompile(`("api_token"\s*:\s*")[^"]*(")`)
+	// 名称表示敏感值的配置字段与查询参数：包含 token、secret、password 或 key
+	secretFieldPattern = regexp.MustCompile(`(?i)token|secret|password|key`)
+)
+
+// registerSecret 注册需要在日志和错误信息中屏蔽的敏感值
+func registerSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < 4 {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// maskSecret 屏蔽敏感值，仅保留前4位便于识别
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
+
+// redactSecrets 屏蔽字符串中的已注册敏感值、Authorization 头和 Bearer 令牌
+func redactSecrets(s string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, maskSecret(secret))
+	}
+	secretsMu.RUnlock()
+
+	s = authHeaderPattern.ReplaceAllString(s, "${1}${2}****")
+	s = bearerPattern.ReplaceAllString(s, "${1}****")
+	s = tokenFieldPattern.ReplaceAllString(s, "${1}****${2}")
+	return s
+}
+
+// redactError 返回屏蔽敏感信息后的错误
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	redacted := redactSecrets(err.Error())
+	if redacted == err.Error() {
+		return err
+	}
+	return errors.New(redacted)
+}
+
+// jsonLevel redactJSON 正在输出的对象或数组
+type jsonLevel struct {
+	object  bool   // 对象（否则为数组）
+	count   int    // 已输出的成员数
+	needKey bool   // 对象中下一个字符串是成员名称
+	key     string // 对象中当前成员的名称
+	secret  bool   // 成员的值都需要屏蔽（headers 或敏感字段下的对象与数组）
+}
+
+// redactJSON 按字段名屏蔽 JSON 中的敏感值并缩进输出，保留字段顺序：名称包含 token、secret、password、key
+// 的字段与 headers 下的值整体屏蔽，其他字符串中的 URL 屏蔽密码与同名查询参数，webhook 地址另屏蔽路径末段的令牌。
+// 按名称匹配而不是逐个注册字段，之后新增的敏感配置项同样不会泄露
+func redactJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	var stack []*jsonLevel
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var parent *jsonLevel
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteString(delim.String())
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				stack[len(stack)-1].done()
+			}
+			continue
+		}
+		if parent != nil && parent.object && parent.needKey {
+			if parent.count > 0 {
+				out.WriteByte(',')
+			}
+			name, _ := tok.(string)
+			key, _ := json.Marshal(name)
+			out.Write(key)
+			out.WriteByte(':')
+			parent.key = name
+			parent.needKey = false
+			continue
+		}
+		if parent != nil && !parent.object && parent.count > 0 {
+			out.WriteByte(',')
+		}
+
+		secret := parent != nil && (parent.secret || parent.object && secretFieldPattern.MatchString(parent.key))
+		switch v := tok.(type) {
+		case json.Delim:
+			out.WriteString(v.String())
+			headers := parent != nil && parent.object && parent.key == "headers"
+			stack = append(stack, &jsonLevel{object: v == '{', needKey: v == '{', secret: secret || headers})
+			continue
+		case string:
+			if secret && v != "" {
+				v = maskSecret(v)
+			} else {
+				v = redactURL(v, parent != nil && parent.key == "webhook")
+			}
+			value, _ := json.Marshal(v)
+			out.Write(value)
+		case json.Number:
+			out.WriteString(v.String())
+		case bool:
+			if v {
+				out.WriteString("true")
+			} else {
+				out.WriteString("false")
+			}
+		case nil:
+			out.WriteString("null")
+		}
+		if parent != nil {
+			parent.done()
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// done 当前成员已输出
+func (l *jsonLevel) done() {
+	l.count++
+	l.needKey = l.object
+}
+
+// redactURL 屏蔽 URL 中的密码与名称敏感的查询参数（钉钉、企业微信的令牌），webhook 地址另屏蔽
+// 路径末段的令牌（Slack、Discord）；不是 URL 时原样返回
+func redactURL(s string, webhook bool) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return s
+	}
+	var found []string
+	if password, ok := u.User.Password(); ok {
+		found = append(found, password)
+	}
+	for name, values := range u.Query() {
+		if secretFieldPattern.MatchString(name) {
+			found = append(found, values...)
+		}
+	}
+	if webhook {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if last := segments[len(segments)-1]; len(last) >= 16 {
+			found = append(found, last)
+		}
+	}
+	for _, secret := range found {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, maskSecret(secret))
+		}
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// reexecEnv 重新执行时传给新进程的环境变量，值为最近确认的公网IP；
+// 新进程据此跳过守护进程化、降权与启动钩子，并沿用该IP，不会当作首次运行
+const reexecEnv = "DNS_MANAGER_REEXEC"
+
+// reexecCheckTimeout 执行新版本 version 子命令的超时
+const reexecCheckTimeout = 10 * time.Second
+
+// startBinary 守护进程启动时的可执行文件，用于判断磁盘上的文件是否已被升级替换
+var startBinary struct {
+	path string
+	info os.FileInfo
+}
+
+// executablePath 返回可执行文件路径；文件被替换后 Linux 会在路径末尾加上 " (deleted)"
+func executablePath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(path, " (deleted)"), nil
+}
+
+// recordStartBinary 记录启动时的可执行文件
+func recordStartBinary() {
+	path, err := executablePath()
+	if err != nil {
+		logError("获取可执行文件路径失败: %v", err)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		logError("获取可执行文件信息失败: %v", err)
+		return
+	}
+	startBinary.path = path
+	startBinary.info = info
+}
+
+// binaryUpgraded 磁盘上的可执行文件是否已被替换（包管理器升级）或修改，返回其路径
+func binaryUpgraded() (string, bool) {
+	if startBinary.info == nil {
+		return "", false
+	}
+	info, err := os.Stat(startBinary.path)
+	if err != nil {
+		return "", false
+	}
+	changed := !os.SameFile(startBinary.info, info) || !info.ModTime().Equal(startBinary.info.ModTime()) || info.Size() != startBinary.info.Size()
+	return startBinary.path, changed
+}
+
+// reexecDaemon 以相同的参数重新执行磁盘上的新版本。进程号不变，PID文件与 systemd 的跟踪都继续有效；
+// 先确认新版本能够运行，失败时返回错误，由调用方继续运行当前版本
+func reexecDaemon(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reexecCheckTimeout)
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, path, "version").CombinedOutput(); err != nil {
+		return fmt.Errorf(tr("新版本无法运行: %v: %s"), err, strings.TrimSpace(string(output)))
+	}
+
+	logInfo("可执行文件已更新，重新执行: %s", path)
+	waitNotifications(5 * time.Second)
+	waitTracing(5 * time.Second)
+
+	env := []string{reexecEnv + "=" + app.CurrentIP()}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, reexecEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	if err := syscall.Exec(path, os.Args, env); err != nil {
+		return fmt.Errorf(tr("重新执行失败: %v"), err)
+	}
+	return nil
+}
+
+// resumeAfterReexec 当前进程是否由 reexecDaemon 启动；是则恢复最近确认的公网IP，并标记启动钩子已执行
+func resumeAfterReexec() bool {
+	ip, ok := os.LookupEnv(reexecEnv)
+	if !ok {
+		return false
+	}
+	os.Unsetenv(reexecEnv)
+	app.SetCurrentIP(ip)
+	startHookFired = true
+	logInfo("已重新执行为新版本: %s", getBuildInfo())
+	return true
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// publicResolvers 可选的公共递归解析器
+var publicResolvers = []string{"1.1.1.1", "8.8.8.8"}
+
+// dnsQueryTimeout 单次DNS查询超时
+const dnsQueryTimeout = 5 * time.Second
+
+// 传播状态
+const (
+	propagationOK      = "ok"      // 解析结果与期望一致
+	propagationStale   = "stale"   // 解析结果与期望不一致
+	propagationProxied = "proxied" // 记录已开启代理，解析结果为 Cloudflare 地址
+	propagationError   = "error"   // 查询失败
+)
+
+// ResolverResult 单个解析器的查询结果
+type ResolverResult struct {
+	Resolver      string   `json:"resolver"`
+	Authoritative bool     `json:"authoritative"`
+	Answers       []string `json:"answers"`
+	Status        string   `json:"status"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// newServerResolver 创建直接向指定DNS服务器查询的解析器
+func newServerResolver(server string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: dnsQueryTimeout}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// queryRecord 向指定服务器查询记录，返回排序后的地址列表
+func queryRecord(server, name, recordType string) ([]string, error) {
+	network := "ip4"
+	if recordType == "AAAA" {
+		network = "ip6"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsQueryTimeout)
+	defer cancel()
+
+	// 末尾加点，避免套用 resolv.conf 中的搜索域
+	ips, err := newServerResolver(server).LookupIP(ctx, network, strings.TrimSuffix(name, ".")+".")
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		answers = append(answers, ip.String())
+	}
+	sort.Strings(answers)
+	return answers, nil
+}
+
+// authoritativeServers 获取区域的权威名称服务器：优先使用 Cloudflare 分配的服务器，失败时按记录名称逐级查询 NS
+func authoritativeServers(zoneID, recordName string) ([]string, error) {
+	cfClient := app.Client()
+	if cfClient != nil {
+		zone, err := cfClient.GetZone(zoneID)
+		if err == nil && len(zone.NameServers) > 0 {
+			return zone.NameServers, nil
+		}
+		if err != nil {
+			logDebug("获取区域信息失败，改为查询 NS 记录: %v", err)
+		}
+	}
+
+	labels := strings.Split(strings.TrimSuffix(recordName, "."), ".")
+	for i := 0; i < len(labels)-1; i++ {
+		nss, err := net.LookupNS(strings.Join(labels[i:], "."))
+		if err != nil || len(nss) == 0 {
+			continue
+		}
+		servers := make([]string, 0, len(nss))
+		for _, ns := range nss {
+			servers = append(servers, strings.TrimSuffix(ns.Host, "."))
+		}
+		return servers, nil
+	}
+	return nil, fmt.Errorf(tr("未找到 %s 的权威名称服务器"), recordName)
+}
+
+// checkResolvers 查询各解析器并与期望值比较
+func checkResolvers(servers []string, authoritative bool, name, recordType string, expected []string, proxied bool) []ResolverResult {
+	results := make([]ResolverResult, len(servers))
+	done := make(chan struct{}, len(servers))
+	for i, server := range servers {
+		go func(i int, server string) {
+			defer func() { done <- struct{}{} }()
+
+			result := ResolverResult{Resolver: server, Authoritative: authoritative, Answers: []string{}}
+			answers, err := queryRecord(server, name, recordType)
+			switch {
+			case err != nil:
+				result.Status = propagationError
+				result.Error = err.Error()
+			case proxied:
+				result.Answers = answers
+				result.Status = propagationProxied
+			case sameAnswers(answers, expected):
+				result.Answers = answers
+				result.Status = propagationOK
+			default:
+				result.Answers = answers
+				result.Status = propagationStale
+			}
+			results[i] = result
+		}(i, server)
+	}
+	for range servers {
+		<-done
+	}
+	return results
+}
+
+// sameAnswers 比较两个已排序的地址列表
+func sameAnswers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveRecord 检查记录在权威服务器（及可选的公共解析器）上的传播情况
+func resolveRecord(name, recordType string, expected []string, proxied, public bool) ([]ResolverResult, error) {
+	config := app.Config()
+	servers, err := authoritativeServers(config.ZoneID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	results := checkResolvers(servers, true, name, recordType, expected, proxied)
+	if public {
+		results = append(results, checkResolvers(publicResolvers, false, name, recordType, expected, proxied)...)
+	}
+	return results, nil
+}
+
+// expectedAnswers 从 Cloudflare 获取记录的期望值，并返回记录是否开启代理
+func expectedAnswers(name, recordType string) ([]string, bool, error) {
+	config := app.Config()
+	cfClient := app.Client()
+	records, err := cfClient.GetAllDNSRecords(config.ZoneID, name, recordType)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(records) == 0 {
+		return nil, false, errors.New(tr("未找到匹配的DNS记录"))
+	}
+
+	var expected []string
+	proxied := false
+	for _, record := range records {
+		expected = append(expected, record.Content)
+		proxied = proxied || record.Proxied
+	}
+	sort.Strings(expected)
+	return expected, proxied, nil
+}
+
+// printResolverResults 以表格形式输出查询结果
+func printResolverResults(name, recordType string, expected []string, results []ResolverResult) {
+	fmt.Printf(tr("\n记录: %s (%s)\n期望值: %s\n"), name, recordType, strings.Join(expected, ", "))
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-28s %-10s %s\n", tr("解析器"), tr("状态"), tr("解析结果"))
+	fmt.Println(strings.Repeat("-", 80))
+	for _, result := range results {
+		resolver := result.Resolver
+		if result.Authoritative {
+			resolver += " *"
+		}
+		answer := strings.Join(result.Answers, ", ")
+		if result.Error != "" {
+			answer = result.Error
+		}
+		fmt.Printf("%-28s %-10s %s\n", resolver, result.Status, answer)
+	}
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Println(tr("* 权威名称服务器"))
+}
+
+// defaultPropagationTimeout 传播验证的默认超时
+const defaultPropagationTimeout = 60 * time.Second
+
+// waitForPropagation 轮询权威名称服务器，直到全部返回包含 ip 的解析结果或超时
+func waitForPropagation(name, recordType, ip string, timeout time.Duration) error {
+	config := app.Config()
+	servers, err := authoritativeServers(config.ZoneID, name)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var pending []string
+		for _, result := range checkResolvers(servers, true, name, recordType, nil, false) {
+			if !containsString(result.Answers, ip) {
+				pending = append(pending, result.Resolver)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf(tr("DNS传播验证超时（%s）: %s 尚未返回 %s"), timeout, strings.Join(pending, ", "), ip)
+		}
+		logDebug("等待DNS传播: %s 尚未返回 %s", strings.Join(pending, ", "), ip)
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// containsString 判断列表中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+)
+
+// RetryPolicy 记录的重试与确认策略；顶层的 retry_policy 作用于全部记录，
+// records 中的设置只覆盖该记录，未设置的项沿用上一级的值
+type RetryPolicy struct {
+	// Retries 写入失败后的重试次数，默认 2（最多尝试 3 次），0 表示不重试
+	Retries *int `json:"retries,omitempty"`
+	// ConfirmAttempts IP变化后写入前再次检测确认的次数，默认 1，0 表示不确认
+	ConfirmAttempts *int `json:"confirm_attempts,omitempty"`
+	// ConfirmDelay 每次确认前的等待时间，默认 3s
+	ConfirmDelay string `json:"confirm_delay,omitempty"`
+	// RetryDelay 第一次重试前的等待时间，默认 2s
+	RetryDelay string `json:"retry_delay,omitempty"`
+	// Backoff 每次重试后等待时间的倍数，默认 1（固定间隔）
+	Backoff float64 `json:"backoff,omitempty"`
+	// MaxRetryDelay 重试等待时间的上限，为空时不限制
+	MaxRetryDelay string `json:"max_retry_delay,omitempty"`
+}
+
+// 默认策略
+const (
+	defaultRetries         = 2
+	defaultConfirmAttempts = 1
+)
+
+// applyTo 把策略中设置了的项写入调和引擎；格式无效的等待时间记录日志后忽略
+func (p *RetryPolicy) applyTo(r *Reconciler) {
+	if p == nil {
+		return
+	}
+	if p.Retries != nil && *p.Retries >= 0 {
+		r.Retries = *p.Retries + 1
+	}
+	if p.ConfirmAttempts != nil && *p.ConfirmAttempts >= 0 {
+		r.ConfirmAttempts = *p.ConfirmAttempts
+	}
+	if p.Backoff >= 1 {
+		r.Backoff = p.Backoff
+	}
+	for _, item := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"confirm_delay", p.ConfirmDelay, &r.ConfirmDelay},
+		{"retry_delay", p.RetryDelay, &r.RetryDelay},
+		{"max_retry_delay", p.MaxRetryDelay, &r.MaxRetryDelay},
+	} {
+		if item.value == "" {
+			continue
+		}
+		d, err := parseDurationOrZero(item.value)
+		if err != nil || d < 0 {
+			logError("retry_policy.%s 格式无效: %s，使用默认值", item.key, item.value)
+			continue
+		}
+		*item.dst = d
+	}
+}
+
+// retryDelay 第 attempt 次失败（从 0 开始）后重试前的等待时间
+func (r *Reconciler) retryDelay(attempt int) time.Duration {
+	delay := r.RetryDelay
+	for i := 0; i < attempt && r.Backoff > 1; i++ {
+		delay = time.Duration(float64(delay) * r.Backoff)
+		if r.MaxRetryDelay > 0 && delay >= r.MaxRetryDelay {
+			break
+		}
+	}
+	if r.MaxRetryDelay > 0 && delay > r.MaxRetryDelay {
+		delay = r.MaxRetryDelay
+	}
+	return delay
+}
+
+// confirmFor 返回确认本次IP变化用的调和引擎：各记录共用同一次检测，
+// 确认次数与等待时间取 targets 中要求最严格的设置
+func (r *Reconciler) confirmFor(targets []RecordTarget) *Reconciler {
+	confirm := *r
+	for _, target := range targets {
+		if target.Retry == nil {
+			continue
+		}
+		t := r.forTarget(target)
+		if t.ConfirmAttempts > confirm.ConfirmAttempts {
+			confirm.ConfirmAttempts = t.ConfirmAttempts
+		}
+		if t.ConfirmAttempts > 0 && t.ConfirmDelay > confirm.ConfirmDelay {
+			confirm.ConfirmDelay = t.ConfirmDelay
+		}
+	}
+	return &confirm
+}
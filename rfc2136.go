@@ -0,0 +1,271 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// RFC2136Config 通过 DNS UPDATE（RFC 2136）更新内部DNS服务器，使用 TSIG 签名
+type RFC2136Config struct {
+	// Server DNS服务器地址，格式为 host[:port]，默认端口 53
+	Server string `json:"server"`
+	// Zone 要更新的区域，如 home.example.com
+	Zone string `json:"zone"`
+	// TSIGKey TSIG 密钥名称，为空时不签名
+	TSIGKey string `json:"tsig_key,omitempty"`
+	// TSIGSecret base64 编码的密钥
+	TSIGSecret string `json:"tsig_secret,omitempty"`
+	// TSIGAlgorithm hmac-sha256（默认）、hmac-sha512、hmac-sha384、hmac-sha1 或 hmac-md5
+	TSIGAlgorithm string `json:"tsig_algorithm,omitempty"`
+	// TCP 使用 TCP 发送，默认使用 UDP（响应被截断时自动改用 TCP）
+	TCP bool `json:"tcp,omitempty"`
+}
+
+// DNS 报文中使用的常量
+const (
+	dnsTypeA    = 1
+	dnsTypeSOA  = 6
+	dnsTypeAAAA = 28
+	dnsTypeTSIG = 250
+	dnsClassIN  = 1
+	dnsClassANY = 255
+
+	dnsOpcodeUpdate = 5
+	tsigFudge       = 300
+)
+
+// rfc2136Timeout 单次请求的超时时间
+const rfc2136Timeout = 10 * time.Second
+
+// tsigAlgorithms TSIG 算法名称与对应的哈希函数
+var tsigAlgorithms = map[string]struct {
+	name string
+	hash func() hash.Hash
+}{
+	"hmac-sha256": {"hmac-sha256.", sha256.New},
+	"hmac-sha512": {"hmac-sha512.", sha512.New},
+	"hmac-sha384": {"hmac-sha384.", sha512.New384},
+	"hmac-sha1":   {"hmac-sha1.", sha1.New},
+	"hmac-md5":    {"hmac-md5.sig-alg.reg.int.", md5.New},
+}
+
+// dnsRcodes 常见的响应码名称
+var dnsRcodes = map[int]string{
+	1: "FORMERR", 2: "SERVFAIL", 3: "NXDOMAIN", 4: "NOTIMP", 5: "REFUSED",
+	6: "YXDOMAIN", 7: "YXRRSET", 8: "NXRRSET", 9: "NOTAUTH", 10: "NOTZONE",
+}
+
+func (c *RFC2136Config) String() string {
+	return "rfc2136 " + c.Server
+}
+
+func (c *RFC2136Config) server() string {
+	if _, _, err := net.SplitHostPort(c.Server); err != nil {
+		return net.JoinHostPort(c.Server, "53")
+	}
+	return c.Server
+}
+
+// setAddress 在一个 UPDATE 请求中删除各名称原有的同类型记录并写入新地址
+func (c *RFC2136Config) setAddress(names []string, recordType, ip string, ttl int) error {
+	msg, err := c.buildUpdate(names, recordType, ip, ttl, time.Now())
+	if err != nil {
+		return err
+	}
+	resp, err := c.exchange(msg, c.TCP)
+	if err == nil && len(resp) >= 4 && resp[2]&0x02 != 0 {
+		// 响应被截断，改用 TCP
+		resp, err = c.exchange(msg, true)
+	}
+	if err != nil {
+		return fmt.Errorf(tr("连接 %s 失败: %v"), c.server(), err)
+	}
+	return checkUpdateResponse(msg, resp)
+}
+
+// buildUpdate 生成 UPDATE 报文；配置了密钥时附加 TSIG 签名
+func (c *RFC2136Config) buildUpdate(names []string, recordType, ip string, ttl int, now time.Time) ([]byte, error) {
+	zone := strings.ToLower(strings.TrimSuffix(c.Zone, "."))
+	if zone == "" {
+		return nil, errors.New(tr("rfc2136.zone 不能为空"))
+	}
+	rrType, addr := uint16(dnsTypeA), net.ParseIP(ip).To4()
+	if recordType == "AAAA" {
+		rrType, addr = dnsTypeAAAA, net.ParseIP(ip).To16()
+	}
+	if addr == nil {
+		return nil, fmt.Errorf(tr("无效的IP地址: %s"), ip)
+	}
+
+	id := make([]byte, 2)
+	rand.Read(id)
+	msg := append(id, dnsOpcodeUpdate<<3, 0, 0, 1, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(msg[8:], uint16(2*len(names)))
+	msg = appendDNSName(msg, zone)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypeSOA)
+	msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name != zone && !strings.HasSuffix(name, "."+zone) {
+			return nil, fmt.Errorf(tr("%s 不在区域 %s 中"), name, zone)
+		}
+		// 删除该名称的全部同类型记录（CLASS ANY，TTL 0，无数据），再添加新记录
+		msg = appendDNSName(msg, name)
+		msg = binary.BigEndian.AppendUint16(msg, rrType)
+		msg = binary.BigEndian.AppendUint16(msg, dnsClassANY)
+		msg = append(msg, 0, 0, 0, 0, 0, 0)
+
+		msg = appendDNSName(msg, name)
+		msg = binary.BigEndian.AppendUint16(msg, rrType)
+		msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+		msg = binary.BigEndian.AppendUint32(msg, uint32(ttl))
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(addr)))
+		msg = append(msg, addr...)
+	}
+
+	if c.TSIGKey == "" {
+		return msg, nil
+	}
+	return c.sign(msg, now)
+}
+
+// sign 按 RFC 8945 计算 TSIG 并附加到报文末尾
+func (c *RFC2136Config) sign(msg []byte, now time.Time) ([]byte, error) {
+	algorithm := c.TSIGAlgorithm
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	alg, ok := tsigAlgorithms[strings.ToLower(strings.TrimSuffix(algorithm, "."))]
+	if !ok {
+		return nil, fmt.Errorf(tr("不支持的 TSIG 算法: %s"), algorithm)
+	}
+	registerSecret(c.TSIGSecret)
+	secret, err := base64.StdEncoding.DecodeString(c.TSIGSecret)
+	if err != nil {
+		return nil, errors.New(tr("tsig_secret 不是有效的 base64"))
+	}
+
+	keyName := strings.ToLower(strings.TrimSuffix(c.TSIGKey, "."))
+	signed := make([]byte, 6)
+	binary.BigEndian.PutUint16(signed[0:], uint16(now.Unix()>>32))
+	binary.BigEndian.PutUint32(signed[2:], uint32(now.Unix()))
+
+	// 参与签名的 TSIG 变量：密钥名称、CLASS、TTL、算法、签名时间、fudge、error、other len
+	variables := appendDNSName(nil, keyName)
+	variables = binary.BigEndian.AppendUint16(variables, dnsClassANY)
+	variables = append(variables, 0, 0, 0, 0)
+	variables = appendDNSName(variables, alg.name)
+	variables = append(variables, signed...)
+	variables = binary.BigEndian.AppendUint16(variables, tsigFudge)
+	variables = append(variables, 0, 0, 0, 0)
+
+	mac := hmac.New(alg.hash, secret)
+	mac.Write(msg)
+	mac.Write(variables)
+	sum := mac.Sum(nil)
+
+	rdata := appendDNSName(nil, alg.name)
+	rdata = append(rdata, signed...)
+	rdata = binary.BigEndian.AppendUint16(rdata, tsigFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = append(rdata, msg[0], msg[1]) // original ID
+	rdata = append(rdata, 0, 0, 0, 0)
+
+	out := appendDNSName(append([]byte(nil), msg...), keyName)
+	out = binary.BigEndian.AppendUint16(out, dnsTypeTSIG)
+	out = binary.BigEndian.AppendUint16(out, dnsClassANY)
+	out = append(out, 0, 0, 0, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(rdata)))
+	out = append(out, rdata...)
+	binary.BigEndian.PutUint16(out[10:], binary.BigEndian.Uint16(msg[10:])+1)
+	return out, nil
+}
+
+// exchange 发送报文并读取响应
+func (c *RFC2136Config) exchange(msg []byte, useTCP bool) ([]byte, error) {
+	network := "udp"
+	if useTCP {
+		network = "tcp"
+	}
+	conn, err := net.DialTimeout(network, c.server(), rfc2136Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(rfc2136Timeout))
+
+	if !useTCP {
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, 4096)
+		n, err := conn.Read(resp)
+		if err != nil {
+			return nil, err
+		}
+		return resp[:n], nil
+	}
+
+	// TCP 报文前有 2 字节长度
+	if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(msg)))); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	size := make([]byte, 2)
+	if _, err := io.ReadFull(conn, size); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// checkUpdateResponse 检查响应是否对应请求以及响应码
+func checkUpdateResponse(msg, resp []byte) error {
+	if len(resp) < 12 || resp[0] != msg[0] || resp[1] != msg[1] || resp[2]&0x80 == 0 {
+		return errors.New(tr("无效的 DNS 响应"))
+	}
+	rcode := int(resp[3] & 0x0f)
+	if rcode == 0 {
+		return nil
+	}
+	name := dnsRcodes[rcode]
+	if name == "" {
+		name = fmt.Sprintf("RCODE %d", rcode)
+	}
+	if rcode == 9 {
+		return classify(ErrAuth, fmt.Errorf(tr("DNS 服务器拒绝更新: %s（请检查 TSIG 密钥与服务器的 update-policy）"), name))
+	}
+	return fmt.Errorf(tr("DNS 服务器拒绝更新: %s"), name)
+}
+
+// appendDNSName 以 DNS 报文格式（长度前缀的标签序列）追加域名
+func appendDNSName(b []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeUpdateServer 接收 DNS UPDATE 的 UDP 服务器：校验 TSIG 签名，记录更新段中的记录
+type fakeUpdateServer struct {
+	conn   net.PacketConn
+	secret []byte
+
+	mu      sync.Mutex
+	updates [][]string
+}
+
+func newFakeUpdateServer(t *testing.T, secret []byte) *fakeUpdateServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeUpdateServer{conn: conn, secret: secret}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeUpdateServer) serve() {
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		msg := append([]byte(nil), buf[:n]...)
+		rcode := byte(0)
+		rrs, ok := s.parse(msg)
+		if !ok {
+			rcode = 9 // NOTAUTH
+		} else {
+			s.mu.Lock()
+			s.updates = append(s.updates, rrs)
+			s.mu.Unlock()
+		}
+		resp := []byte{msg[0], msg[1], 0x80 | msg[2], rcode, 0, 0, 0, 0, 0, 0, 0, 0}
+		s.conn.WriteTo(resp, addr)
+	}
+}
+
+// parse 读取更新段并校验 TSIG，返回 "名称 类型 CLASS 数据" 形式的记录
+func (s *fakeUpdateServer) parse(msg []byte) ([]string, bool) {
+	readName := func(i int) (string, int) {
+		var labels []string
+		for msg[i] != 0 {
+			labels = append(labels, string(msg[i+1:i+1+int(msg[i])]))
+			i += 1 + int(msg[i])
+		}
+		return strings.Join(labels, "."), i + 1
+	}
+	if msg[2]>>3 != dnsOpcodeUpdate || binary.BigEndian.Uint16(msg[10:]) != 1 {
+		return nil, false
+	}
+	_, i := readName(12)
+	i += 4
+
+	var rrs []string
+	for n := binary.BigEndian.Uint16(msg[8:]); n > 0; n-- {
+		name, next := readName(i)
+		rrType, class := binary.BigEndian.Uint16(msg[next:]), binary.BigEndian.Uint16(msg[next+2:])
+		rdlen := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := ""
+		if rdlen > 0 {
+			data = net.IP(msg[next+10 : next+10+rdlen]).String()
+		}
+		rrs = append(rrs, strings.TrimSpace(strings.Join([]string{name, map[uint16]string{dnsTypeA: "A", dnsTypeAAAA: "AAAA"}[rrType],
+			map[uint16]string{dnsClassIN: "IN", dnsClassANY: "ANY"}[class], data}, " ")))
+		i = next + 10 + rdlen
+	}
+
+	// TSIG：按相同方式计算签名并比较
+	tsigStart := i
+	keyName, j := readName(i)
+	alg, k := readName(j + 10) // 跳过 TYPE、CLASS、TTL 与 RDLENGTH
+	timeAndFudge := msg[k : k+8]
+	macSize := int(binary.BigEndian.Uint16(msg[k+8:]))
+	got := msg[k+10 : k+10+macSize]
+
+	unsigned := append([]byte(nil), msg[:tsigStart]...)
+	binary.BigEndian.PutUint16(unsigned[10:], 0)
+	variables := appendDNSName(nil, keyName)
+	variables = append(variables, 0, dnsClassANY, 0, 0, 0, 0)
+	variables = appendDNSName(variables, alg)
+	variables = append(variables, timeAndFudge...)
+	variables = append(variables, 0, 0, 0, 0)
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(unsigned)
+	mac.Write(variables)
+	return rrs, hmac.Equal(got, mac.Sum(nil))
+}
+
+func (s *fakeUpdateServer) received() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.updates...)
+}
+
+func TestSplitHorizonRFC2136(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	server := newFakeUpdateServer(t, secret)
+
+	cf := newFakeCloudflare(t)
+	config := setupApp(t, cf, newFakeIPService(t, "198.51.100.2"), "198.51.100.1")
+	config.Records = []RecordConfig{{Name: "vpn.example.com"}}
+	config.SplitHorizon = &SplitHorizonConfig{RFC2136: &RFC2136Config{
+		Server:     server.conn.LocalAddr().String(),
+		Zone:       "example.com.",
+		TSIGKey:    "ddns-key",
+		TSIGSecret: base64.StdEncoding.EncodeToString(secret),
+	}}
+	cf.addRecord(testZoneID, DNSRecord{Type: "A", Name: testRecord, Content: "198.51.100.1"})
+	t.Cleanup(func() { splitHorizon = &splitHorizonState{} })
+
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("checkAndUpdate: %v", err)
+	}
+	want := []string{
+		testRecord + " A ANY", testRecord + " A IN 198.51.100.2",
+		"vpn.example.com A ANY", "vpn.example.com A IN 198.51.100.2",
+	}
+	got := server.received()
+	if len(got) != 1 || strings.Join(got[0], "; ") != strings.Join(want, "; ") {
+		t.Fatalf("updates = %v; want [%v]", got, want)
+	}
+
+	// 地址未变化时不重复发送
+	if _, err := checkAndUpdate(); err != nil {
+		t.Fatalf("second checkAndUpdate: %v", err)
+	}
+	if got := server.received(); len(got) != 1 {
+		t.Errorf("updates after unchanged cycle = %d; want 1", len(got))
+	}
+
+	// 签名错误时返回 NOTAUTH
+	bad := *config.SplitHorizon.RFC2136
+	bad.TSIGSecret = base64.StdEncoding.EncodeToString([]byte("wrong"))
+	if err := bad.setAddress([]string{testRecord}, "A", "198.51.100.3", 60); exitCodeFor(err) != exitAuth {
+		t.Errorf("setAddress with a wrong key = %v; want an auth error", err)
+	}
+	if err := bad.setAddress([]string{"host.example.org"}, "A", "198.51.100.3", 60); err == nil {
+		t.Error("setAddress accepted a name outside the zone")
+	}
+}
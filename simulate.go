@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// simulateFail 模拟脚本中表示本次检测失败的值
+const simulateFail = "fail"
+
+// simulatedSource 模拟IP的来源名称，显示在日志与通知中
+const simulatedSource = "simulate"
+
+// ipSimulation 按脚本依次返回IP，代替真实检测；用完后一直返回最后一个值
+type ipSimulation struct {
+	mu     sync.Mutex
+	values []string
+	pos    int
+}
+
+// ipSimulator 不为空时 IPChecker 从脚本读取IP
+var ipSimulator *ipSimulation
+
+// parseIPSimulation 解析 --simulate-ip：已存在的文件按行读取（# 开头为注释），否则按逗号分隔
+func parseIPSimulation(spec string) (*ipSimulation, error) {
+	var fields []string
+	if data, err := os.ReadFile(spec); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if i := strings.Index(line, "#"); i >= 0 {
+				line = line[:i]
+			}
+			fields = append(fields, line)
+		}
+	} else {
+		fields = strings.Split(spec, ",")
+	}
+
+	s := &ipSimulation{}
+	for _, field := range fields {
+		value := strings.TrimSpace(field)
+		if value == "" {
+			continue
+		}
+		if value != simulateFail && net.ParseIP(value) == nil {
+			return nil, fmt.Errorf(tr("模拟IP序列中的值无效: %s"), value)
+		}
+		s.values = append(s.values, value)
+	}
+	if len(s.values) == 0 {
+		return nil, errors.New(tr("模拟IP序列为空"))
+	}
+	return s, nil
+}
+
+// next 返回序列中的下一个IP，值为 fail 时返回检测失败
+func (s *ipSimulation) next() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := s.pos
+	if s.pos < len(s.values)-1 {
+		s.pos++
+	}
+	value := s.values[index]
+	logDebug("模拟IP: 第 %d/%d 个值 %s", index+1, len(s.values), value)
+	if value == simulateFail {
+		return "", errors.New(tr("模拟的IP检测失败"))
+	}
+	return value, nil
+}
+
+// addSimulateFlag 为子命令添加 --simulate-ip 参数
+func addSimulateFlag(fs *flag.FlagSet) *string {
+	return fs.String("simulate-ip", "", tr("用脚本中的IP代替真实检测（逗号分隔的序列或每行一个IP的文件，fail 表示检测失败），用于测试确认、防抖与通知"))
+}
+
+// applySimulateFlag 解析 --simulate-ip 并启用模拟
+func applySimulateFlag(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	s, err := parseIPSimulation(spec)
+	if err != nil {
+		return err
+	}
+	ipSimulator = s
+	if !dryRun {
+		fmt.Fprintln(os.Stderr, tr("⚠️  已启用IP模拟，模拟的IP会写入DNS记录；只想观察行为时请同时使用 --dry-run"))
+	}
+	return nil
+}
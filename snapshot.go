@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot 某个记录名称在首次修改前的全部记录
+type Snapshot struct {
+	Time    time.Time   `json:"time"`
+	ZoneID  string      `json:"zone_id"`
+	Name    string      `json:"name"`
+	Source  string      `json:"source,omitempty"`
+	Records []DNSRecord `json:"records"`
+}
+
+// getSnapshotDir 返回快照目录
+func getSnapshotDir() string {
+	return filepath.Join(getDataDir(), "snapshots")
+}
+
+// snapshotBeforeMutation 在本进程首次修改某个记录名称前保存该名称下的全部记录；保存失败时中止修改
+func (c *CloudflareClient) snapshotBeforeMutation(zoneID, name string) error {
+	key := zoneID + "/" + strings.ToLower(name)
+
+	c.snapshotMu.Lock()
+	defer c.snapshotMu.Unlock()
+	if c.snapshotted[key] {
+		return nil
+	}
+
+	records, err := c.ListDNSRecords(zoneID, name)
+	if err != nil {
+		return fmt.Errorf(tr("保存修改前快照失败: %v"), err)
+	}
+
+	path, err := saveSnapshot(Snapshot{
+		Time:    time.Now(),
+		ZoneID:  zoneID,
+		Name:    name,
+		Source:  c.getAuditSource(),
+		Records: records,
+	})
+	if err != nil {
+		return fmt.Errorf(tr("保存修改前快照失败: %v"), err)
+	}
+
+	if c.snapshotted == nil {
+		c.snapshotted = make(map[string]bool)
+	}
+	c.snapshotted[key] = true
+	logInfo("已保存 %s 的修改前快照（%d 条记录）: %s", name, len(records), path)
+	return nil
+}
+
+// saveSnapshot 写入快照文件，返回文件路径
+func saveSnapshot(snapshot Snapshot) (string, error) {
+	dir := getSnapshotDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	if snapshot.Records == nil {
+		snapshot.Records = []DNSRecord{}
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	base := strings.NewReplacer("*", "_wildcard_", "/", "_").Replace(strings.ToLower(snapshot.Name))
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.json", base, snapshot.Time.Format("20060102_150405")))
+	if err := writeFileAtomic(path, data, 0600, true); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// loadSnapshot 读取快照文件；只给出文件名时在快照目录中查找
+func loadSnapshot(path string) (*Snapshot, error) {
+	if !strings.Contains(path, string(os.PathSeparator)) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join(getSnapshotDir(), path)
+		}
+	}
+
+	data, err := readFileChecked(path)
+	if errors.Is(err, errChecksumMismatch) {
+		return nil, fmt.Errorf(tr("快照文件校验失败，文件可能已损坏: %s"), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(tr("读取快照失败: %v"), err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf(tr("解析快照失败: %v"), err)
+	}
+	if snapshot.ZoneID == "" || snapshot.Name == "" {
+		return nil, errors.New(tr("快照文件缺少 zone_id 或 name"))
+	}
+	return &snapshot, nil
+}
+
+// listSnapshots 返回快照文件列表（新的在前）
+func listSnapshots() ([]string, error) {
+	entries, err := os.ReadDir(getSnapshotDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+// restoreAction 恢复快照时对单条记录的操作
+type restoreAction struct {
+	Action string     `json:"action"` // create / update / delete
+	Record DNSRecord  `json:"record"`
+	Old    *DNSRecord `json:"old,omitempty"`
+}
+
+// planRestore 比较快照与当前记录，生成恢复操作：先按记录ID匹配，再按类型和内容匹配
+func planRestore(snapshot, current []DNSRecord) []restoreAction {
+	remaining := make(map[string]DNSRecord, len(current))
+	for _, record := range current {
+		remaining[record.ID] = record
+	}
+
+	var actions []restoreAction
+	var unmatched []DNSRecord
+	for _, want := range snapshot {
+		if have, ok := remaining[want.ID]; ok {
+			delete(remaining, want.ID)
+			if !sameRecord(have, want) {
+				old := have
+				actions = append(actions, restoreAction{Action: "update", Record: want, Old: &old})
+			}
+			continue
+		}
+		unmatched = append(unmatched, want)
+	}
+
+	for _, want := range unmatched {
+		matched := false
+		for id, have := range remaining {
+			if have.Type == want.Type && have.Content == want.Content {
+				delete(remaining, id)
+				matched = true
+				if !sameRecord(have, want) {
+					old := have
+					want.ID = have.ID
+					actions = append(actions, restoreAction{Action: "update", Record: want, Old: &old})
+				}
+				break
+			}
+		}
+		if !matched {
+			actions = append(actions, restoreAction{Action: "create", Record: want})
+		}
+	}
+
+	for _, extra := range current {
+		if _, ok := remaining[extra.ID]; ok {
+			actions = append(actions, restoreAction{Action: "delete", Record: extra})
+		}
+	}
+	return actions
+}
+
+// sameRecord 比较记录的可修改字段
+func sameRecord(a, b DNSRecord) bool {
+	samePriority := (a.Priority == nil && b.Priority == nil) ||
+		(a.Priority != nil && b.Priority != nil && *a.Priority == *b.Priority)
+	return a.Type == b.Type && a.Content == b.Content && a.TTL == b.TTL && a.Proxied == b.Proxied && samePriority
+}
+
+// applyRestore 执行恢复操作
+func applyRestore(zoneID string, actions []restoreAction) error {
+	for _, action := range actions {
+		if err := applyRestoreAction(zoneID, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRestoreAction 执行单条记录的操作
+func applyRestoreAction(zoneID string, action restoreAction) error {
+	cfClient := app.Client()
+	record := action.Record
+	req := DNSRecordCreateRequest{
+		Type:     record.Type,
+		Name:     record.Name,
+		Content:  record.Content,
+		TTL:      record.TTL,
+		Proxied:  record.Proxied,
+		Priority: record.Priority,
+		Comment:  record.Comment,
+	}
+
+	var err error
+	switch action.Action {
+	case "create":
+		_, err = cfClient.CreateDNSRecordWithOptions(zoneID, req)
+	case "update":
+		_, err = cfClient.EditDNSRecord(zoneID, *action.Old, req)
+	case "delete":
+		err = cfClient.DeleteDNSRecord(zoneID, record)
+	}
+	if err != nil {
+		return fmt.Errorf(tr("%s %s %s 失败: %v"), action.Action, record.Type, record.Content, err)
+	}
+	return nil
+}
+
+// printRestorePlan 输出恢复操作
+func printRestorePlan(actions []restoreAction) {
+	for _, action := range actions {
+		record := action.Record
+		switch action.Action {
+		case "create":
+			fmt.Printf("  + %s %s %s (TTL: %s, %s: %s)\n", record.Name, record.Type, record.Content,
+				formatTTL(record.TTL), tr("代理"), formatBool(record.Proxied))
+		case "delete":
+			fmt.Printf("  - %s %s %s\n", record.Name, record.Type, record.Content)
+		case "update":
+			fmt.Printf("  ~ %s %s %s -> %s (TTL: %s -> %s, %s: %s -> %s)\n", record.Name, record.Type,
+				action.Old.Content, record.Content, formatTTL(action.Old.TTL), formatTTL(record.TTL),
+				tr("代理"), formatBool(action.Old.Proxied), formatBool(record.Proxied))
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// SplitHorizonConfig 内部DNS（split-horizon）：公网IP变化后把同一批名称写入局域网内的DNS服务器，
+// 使内部与外部解析保持一致
+type SplitHorizonConfig struct {
+	// Address 内部记录指向的地址：public（默认，公网IP）、lan（本机局域网地址）、
+	// tailscale、wireguard（本机在组网中的地址）或固定IP
+	Address string `json:"address,omitempty"`
+	// Interface address 为 lan 时读取该网络接口的地址，为空时使用默认路由的出口地址；
+	// address 为 wireguard 时为 WireGuard 接口名称（默认 wg0）
+	Interface string `json:"interface,omitempty"`
+	// Names 内部维护的完整域名，为空时使用跟随公网IP的全部记录
+	Names []string `json:"names,omitempty"`
+	// TTL 内部记录的TTL（秒），默认 300
+	TTL int `json:"ttl,omitempty"`
+
+	// RFC2136 通过 DNS UPDATE 更新 BIND、Knot、PowerDNS 等服务器
+	RFC2136 *RFC2136Config `json:"rfc2136,omitempty"`
+	// AdGuard 通过 AdGuard Home 的 DNS 重写维护
+	AdGuard *AdGuardConfig `json:"adguard,omitempty"`
+	// PiHole 通过 Pi-hole 的本地DNS记录维护
+	PiHole *PiHoleConfig `json:"pihole,omitempty"`
+}
+
+// internalDNSServer 内部DNS服务器
+type internalDNSServer interface {
+	String() string
+	// setAddress 让 names 只指向 ip（替换这些名称原有的同类型记录）
+	setAddress(names []string, recordType, ip string, ttl int) error
+}
+
+// defaultSplitHorizonTTL 内部记录的默认TTL
+const defaultSplitHorizonTTL = 300
+
+// splitHorizonState 各内部DNS服务器已写入的地址与名称，未变化时不重复写入
+type splitHorizonState struct {
+	mu      sync.Mutex
+	applied map[string]string
+}
+
+var splitHorizon = &splitHorizonState{}
+
+func (c *SplitHorizonConfig) ttl() int {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return defaultSplitHorizonTTL
+}
+
+// servers 返回配置的内部DNS服务器
+func (c *SplitHorizonConfig) servers() []internalDNSServer {
+	var servers []internalDNSServer
+	if c.RFC2136 != nil {
+		servers = append(servers, c.RFC2136)
+	}
+	if c.AdGuard != nil {
+		servers = append(servers, c.AdGuard)
+	}
+	if c.PiHole != nil {
+		servers = append(servers, c.PiHole)
+	}
+	return servers
+}
+
+// address 返回内部记录指向的地址
+func (c *SplitHorizonConfig) address(publicIP, recordType string) (string, error) {
+	switch c.Address {
+	case "", "public":
+		return publicIP, nil
+	case "lan":
+		return lanAddress(c.Interface, recordType)
+	case "tailscale":
+		return tailscaleAddress(recordType)
+	case "wireguard":
+		iface := c.Interface
+		if iface == "" {
+			iface = "wg0"
+		}
+		return wireguardAddress(iface, recordType)
+	}
+	if ip := net.ParseIP(c.Address); ip != nil {
+		return ip.String(), nil
+	}
+	return "", fmt.Errorf(tr("无效的 split_horizon.address: %s（应为 public、lan、tailscale、wireguard 或IP地址）"), c.Address)
+}
+
+// names 返回内部维护的完整域名
+func (c *SplitHorizonConfig) names(config *Config) ([]string, error) {
+	if len(c.Names) > 0 {
+		return c.Names, nil
+	}
+	var names []string
+	for _, target := range config.ipTargets() {
+		name, err := app.Client().recordName(target.ZoneID, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.ToLower(name))
+	}
+	return names, nil
+}
+
+// lanAddress 返回本机的局域网地址：指定接口时取该接口上的第一个私有地址，
+// 否则取访问公网时使用的出口地址（UDP 连接不会发送数据）
+func lanAddress(iface, recordType string) (string, error) {
+	v6 := recordType == "AAAA"
+	if iface == "" {
+		network, probe := "udp4", "198.51.100.1:53"
+		if v6 {
+			network, probe = "udp6", "[2001:db8::1]:53"
+		}
+		conn, err := net.Dial(network, probe)
+		if err != nil {
+			return "", fmt.Errorf(tr("获取局域网地址失败: %v"), err)
+		}
+		defer conn.Close()
+		return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+	}
+
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), iface, err)
+	}
+	addrs, err := i.Addrs()
+	if err != nil {
+		return "", fmt.Errorf(tr("读取网络接口 %s 失败: %v"), iface, err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || (ipNet.IP.To4() == nil) != v6 || ipNet.IP.IsLinkLocalUnicast() || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ipNet.IP.IsPrivate() || v6 {
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf(tr("网络接口 %s 上没有局域网地址"), iface)
+}
+
+// updateSplitHorizon 把内部名称指向公网IP或本机局域网地址。
+// 与 Consul 相同，失败时只记录日志，下个周期重试
+func updateSplitHorizon(config *Config, publicIP string) {
+	splitHorizon.mu.Lock()
+	defer splitHorizon.mu.Unlock()
+
+	c := config.SplitHorizon
+	ip, err := c.address(publicIP, config.RecordType)
+	if err != nil {
+		logError("内部DNS: %v", err)
+		return
+	}
+	recordType := "A"
+	if net.ParseIP(ip).To4() == nil {
+		recordType = "AAAA"
+	}
+	names, err := c.names(config)
+	if err != nil {
+		logError("内部DNS: %v", err)
+		return
+	}
+	if len(names) == 0 {
+		logError("内部DNS: 没有需要维护的名称")
+		return
+	}
+
+	state := ip + " " + strings.Join(names, ",")
+	for _, server := range c.servers() {
+		key := server.String()
+		if splitHorizon.applied[key] == state {
+			continue
+		}
+		if err := server.setAddress(names, recordType, ip, c.ttl()); err != nil {
+			logError("更新内部DNS %s 失败: %v", key, err)
+			continue
+		}
+		logInfo("内部DNS %s: %s 已指向 %s", key, strings.Join(names, ", "), ip)
+		if splitHorizon.applied == nil {
+			splitHorizon.applied = make(map[string]string)
+		}
+		splitHorizon.applied[key] = state
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSplitHorizonAdGuard(t *testing.T) {
+	var mu sync.Mutex
+	rewrites := []adguardRewrite{
+		{Domain: "nas.example.com", Answer: "192.168.1.5"},
+		{Domain: "nas.example.com", Answer: "fd00::5"},
+		{Domain: "www.example.com", Answer: "nas.example.com"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var rewrite adguardRewrite
+		switch r.URL.Path {
+		case "/control/rewrite/list":
+			json.NewEncoder(w).Encode(rewrites)
+		case "/control/rewrite/add":
+			json.NewDecoder(r.Body).Decode(&rewrite)
+			rewrites = append(rewrites, rewrite)
+		case "/control/rewrite/delete":
+			json.NewDecoder(r.Body).Decode(&rewrite)
+			for i := range rewrites {
+				if rewrites[i] == rewrite {
+					rewrites = append(rewrites[:i], rewrites[i+1:]...)
+					break
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := &AdGuardConfig{URL: server.URL + "/", Username: "admin", Password: "secret"}
+	if err := c.setAddress([]string{"NAS.example.com.", "git.example.com"}, "A", "192.168.1.9", 300); err != nil {
+		t.Fatalf("setAddress: %v", err)
+	}
+	want := []adguardRewrite{
+		{Domain: "git.example.com", Answer: "192.168.1.9"},
+		{Domain: "nas.example.com", Answer: "192.168.1.9"},
+		{Domain: "nas.example.com", Answer: "fd00::5"},
+		{Domain: "www.example.com", Answer: "nas.example.com"},
+	}
+	sort.Slice(rewrites, func(i, j int) bool {
+		return rewrites[i].Domain+rewrites[i].Answer < rewrites[j].Domain+rewrites[j].Answer
+	})
+	if !reflect.DeepEqual(rewrites, want) {
+		t.Errorf("rewrites = %+v; want %+v", rewrites, want)
+	}
+
+	c.Password = "wrong"
+	if err := c.setAddress([]string{"nas.example.com"}, "A", "192.168.1.9", 300); exitCodeFor(err) != exitAuth {
+		t.Errorf("setAddress with a wrong password = %v; want an auth error", err)
+	}
+}
+
+func TestSplitHorizonPiHole(t *testing.T) {
+	var mu sync.Mutex
+	hosts := []string{"192.168.1.5 nas.example.com", "192.168.1.7 printer.lan"}
+	loggedOut := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/api/auth" {
+			if r.Method == "DELETE" {
+				loggedOut = r.Header.Get("X-FTL-SID") == "sid-1"
+				return
+			}
+			var body struct{ Password string }
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(map[string]interface{}{"session": map[string]interface{}{"valid": body.Password == "secret", "sid": "sid-1"}})
+			return
+		}
+		if r.Header.Get("X-FTL-SID") != "sid-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		entry, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/api/config/dns/hosts/"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/config/dns/hosts":
+			json.NewEncoder(w).Encode(map[string]interface{}{"config": map[string]interface{}{"dns": map[string]interface{}{"hosts": hosts}}})
+		case r.Method == "PUT":
+			hosts = append(hosts, entry)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "DELETE":
+			for i := range hosts {
+				if hosts[i] == entry {
+					hosts = append(hosts[:i], hosts[i+1:]...)
+					break
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := &PiHoleConfig{URL: server.URL, Password: "secret"}
+	if err := c.setAddress([]string{"nas.example.com"}, "A", "192.168.1.9", 300); err != nil {
+		t.Fatalf("setAddress: %v", err)
+	}
+	want := []string{"192.168.1.7 printer.lan", "192.168.1.9 nas.example.com"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v; want %v", hosts, want)
+	}
+	if !loggedOut {
+		t.Error("session was not closed")
+	}
+
+	c.Password = "wrong"
+	if err := c.setAddress([]string{"nas.example.com"}, "A", "192.168.1.9", 300); exitCodeFor(err) != exitAuth {
+		t.Errorf("setAddress with a wrong password = %v; want an auth error", err)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StartupReportConfig 守护进程启动时比较期望状态与实际记录的报告
+type StartupReportConfig struct {
+	// Notify 何时发送报告通知：problems（默认，存在问题时）、always、never
+	Notify string `json:"notify,omitempty"`
+	// TTL 期望的TTL（秒，1 表示自动），为 0 时使用 ttl_strategy 的 ttl，都未设置时不检查
+	TTL int `json:"ttl,omitempty"`
+	// Proxied 期望是否开启代理，为空时不检查
+	Proxied *bool `json:"proxied,omitempty"`
+}
+
+// 记录在启动报告中的状态
+const (
+	reportOK       = "ok"
+	reportMissing  = "missing"
+	reportDrifted  = "drifted"
+	reportMismatch = "mismatch"
+)
+
+// reportEntry 单个记录集的检查结果
+type reportEntry struct {
+	Name   string
+	Type   string
+	Status string
+	// Problems 不一致之处的说明
+	Problems []string
+
+	// target 对应的记录与期望的内容（IP 或 CNAME 目标），修复时使用
+	target RecordTarget
+	want   string
+}
+
+// startupReport 启动报告：区域记录与本机IP及期望的 TTL、代理设置的比较结果
+type startupReport struct {
+	IP      string
+	Entries []reportEntry
+	// Errors 读取失败的记录
+	Errors []string
+}
+
+// problems 需要注意的记录数
+func (s *startupReport) problems() int {
+	n := len(s.Errors)
+	for _, entry := range s.Entries {
+		if entry.Status != reportOK {
+			n++
+		}
+	}
+	return n
+}
+
+// expectedTTL 返回期望的TTL，0 表示不检查
+func (c *StartupReportConfig) expectedTTL(config *Config) int {
+	if c != nil && c.TTL > 0 {
+		return c.TTL
+	}
+	if config.TTLStrategy != nil {
+		return config.TTLStrategy.ttl()
+	}
+	return 0
+}
+
+// buildStartupReport 读取跟随公网IP的全部记录与子域名 CNAME，与 ip 比较；ip 为空时只检查记录是否存在
+func buildStartupReport(config *Config, r *Reconciler, ip string) *startupReport {
+	report := &startupReport{IP: ip}
+	expectedTTL := config.StartupReport.expectedTTL(config)
+	var expectedProxied *bool
+	if config.StartupReport != nil {
+		expectedProxied = config.StartupReport.Proxied
+	}
+
+	targets := config.ipTargets()
+	_, aliases := config.subdomainTargets()
+	aliasTarget := ""
+	if len(aliases) > 0 {
+		name, err := app.Client().recordName(config.ZoneID, config.RecordName)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", config.RecordName, err))
+			aliases = nil
+		}
+		aliasTarget = name
+	}
+	for _, alias := range aliases {
+		targets = append(targets, RecordTarget{ZoneID: config.ZoneID, Name: alias, Type: "CNAME"})
+	}
+
+	r.Prefetch(targets)
+	for _, target := range targets {
+		name := target.Name
+		if full, err := app.Client().recordName(target.ZoneID, target.Name); err == nil {
+			name = full
+		}
+		records, err := r.forTarget(target).Observe()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", name, redactError(err)))
+			continue
+		}
+		want := ip
+		if target.Type == "CNAME" {
+			want = aliasTarget
+		}
+		entry := reportEntry{Name: name, Type: target.Type, Status: reportOK, target: target, want: want}
+
+		var contents []string
+		var current *DNSRecord
+		for i := range records {
+			contents = append(contents, records[i].Content)
+			if strings.EqualFold(strings.TrimSuffix(records[i].Content, "."), want) {
+				current = &records[i]
+			}
+		}
+		switch {
+		case len(records) == 0:
+			entry.Status = reportMissing
+			entry.Problems = append(entry.Problems, tr("记录不存在"))
+		case want != "" && current == nil:
+			entry.Status = reportDrifted
+			entry.Problems = append(entry.Problems, fmt.Sprintf(tr("指向 %s，期望 %s"), strings.Join(contents, ", "), want))
+		}
+
+		// TTL 与代理只检查本机维护的记录（IP未知时检查第一条）
+		if current == nil && want == "" && len(records) > 0 {
+			current = &records[0]
+		}
+		if current != nil {
+			// 开启代理的记录TTL固定为自动，不比较
+			if expectedTTL > 0 && !current.Proxied && current.TTL != expectedTTL {
+				entry.Problems = append(entry.Problems, fmt.Sprintf(tr("TTL 为 %s，期望 %s"), formatTTL(current.TTL), formatTTL(expectedTTL)))
+			}
+			if expectedProxied != nil && current.Proxied != *expectedProxied {
+				entry.Problems = append(entry.Problems, fmt.Sprintf(tr("代理为 %s，期望 %s"), formatBool(current.Proxied), formatBool(*expectedProxied)))
+			}
+			if entry.Status == reportOK && len(entry.Problems) > 0 {
+				entry.Status = reportMismatch
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return report
+}
+
+// String 报告的文本形式，每个记录一行
+func (s *startupReport) String() string {
+	var b strings.Builder
+	for _, entry := range s.Entries {
+		status := tr("正常")
+		if entry.Status != reportOK {
+			status = strings.Join(entry.Problems, "; ")
+		}
+		fmt.Fprintf(&b, "%s %s: %s\n", entry.Name, entry.Type, status)
+	}
+	for _, e := range s.Errors {
+		fmt.Fprintf(&b, tr("读取失败: %s")+"\n", e)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// reportStartup 守护进程启动时执行一次：检测公网IP，比较全部记录的实际状态，写入日志并按配置发送通知。
+// 检测失败时与上次写入的IP比较
+func reportStartup(config *Config) {
+	r := newReconciler()
+	ip, _, err := r.Detect()
+	if err != nil {
+		logError("启动检查: 获取公网IP失败: %v", err)
+		ip = app.CurrentIP()
+	}
+
+	report := buildStartupReport(config, r, ip)
+	for _, entry := range report.Entries {
+		if entry.Status == reportOK {
+			logInfo("启动检查: %s %s 正常", entry.Name, entry.Type)
+		} else {
+			logError("启动检查: %s %s %s", entry.Name, entry.Type, strings.Join(entry.Problems, "; "))
+		}
+	}
+	for _, e := range report.Errors {
+		logError("启动检查: 读取失败: %s", e)
+	}
+	problems := report.problems()
+	logInfo("启动检查完成: 共 %d 条记录，%d 条需要注意", len(report.Entries)+len(report.Errors), problems)
+
+	notifyMode := ""
+	if config.StartupReport != nil {
+		notifyMode = config.StartupReport.Notify
+	}
+	if notifyMode == "never" || (notifyMode != "always" && problems == 0) {
+		return
+	}
+	title := tr("启动检查: 全部记录正常")
+	if problems > 0 {
+		title = fmt.Sprintf(tr("启动检查: %d 条记录需要注意"), problems)
+	}
+	notify(NotifyEvent{
+		Type:    EventStartupReport,
+		Title:   title,
+		Message: report.String(),
+		Record:  config.RecordName,
+		NewIP:   ip,
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DaemonState 守护进程运行统计，持久化到状态文件供 --info 查看
+type DaemonState struct {
+	PID                 int       `json:"pid"`
+	StartTime           time.Time `json:"start_time"`
+	Cycles              int64     `json:"cycles"`
+	Updates             int64     `json:"updates"`
+	CurrentIP           string    `json:"current_ip,omitempty"`
+	LastCycle           time.Time `json:"last_cycle,omitempty"`
+	LastIPChange        time.Time `json:"last_ip_change,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	FailingSince        time.Time `json:"failing_since,omitempty"`
+	DegradedSince       time.Time `json:"degraded_since,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	Version             string    `json:"version,omitempty"`
+}
+
+var (
+	daemonState   *DaemonState
+	daemonStateMu sync.Mutex
+)
+
+// getStatePath 返回状态文件路径
+func getStatePath() string {
+	return filepath.Join(getDataDir(), "state.json")
+}
+
+// initDaemonState 初始化本次运行的状态并写入状态文件
+func initDaemonState() {
+	daemonStateMu.Lock()
+	defer daemonStateMu.Unlock()
+
+	daemonState = &DaemonState{
+		PID:       os.Getpid(),
+		StartTime: time.Now(),
+		Version:   getBuildInfo().String(),
+	}
+	if err := saveDaemonState(daemonState); err != nil {
+		logError("写入状态文件失败: %v", err)
+	}
+}
+
+// recordCycle 记录一次检测周期的结果
+func recordCycle(updated bool, err error) {
+	currentIP := app.CurrentIP()
+	daemonStateMu.Lock()
+	defer daemonStateMu.Unlock()
+
+	if daemonState == nil {
+		return
+	}
+
+	now := time.Now()
+	daemonState.Cycles++
+	daemonState.LastCycle = now
+
+	if updated {
+		daemonState.Updates++
+	}
+
+	if currentIP != "" && currentIP != daemonState.CurrentIP {
+		if daemonState.CurrentIP != "" {
+			daemonState.LastIPChange = now
+		}
+		daemonState.CurrentIP = currentIP
+	}
+
+	if err != nil {
+		if daemonState.ConsecutiveFailures == 0 {
+			daemonState.FailingSince = now
+		}
+		daemonState.ConsecutiveFailures++
+		daemonState.LastError = err.Error()
+	} else {
+		daemonState.ConsecutiveFailures = 0
+		daemonState.FailingSince = time.Time{}
+		daemonState.LastError = ""
+	}
+
+	if err := saveDaemonState(daemonState); err != nil {
+		logError("写入状态文件失败: %v", err)
+	}
+}
+
+// markDegraded 记录进入降级模式的时间，零值表示已退出
+func markDegraded(since time.Time) {
+	daemonStateMu.Lock()
+	defer daemonStateMu.Unlock()
+
+	if daemonState == nil {
+		return
+	}
+	daemonState.DegradedSince = since
+	if err := saveDaemonState(daemonState); err != nil {
+		logError("写入状态文件失败: %v", err)
+	}
+}
+
+// saveDaemonState 保存状态到文件
+func saveDaemonState(state *DaemonState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(getStatePath(), data, 0644, true)
+}
+
+// loadDaemonState 从文件读取状态
+func loadDaemonState() (*DaemonState, error) {
+	data, err := readFileChecked(getStatePath())
+	if err != nil {
+		return nil, err
+	}
+
+	var state DaemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
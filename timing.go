@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cycleTimer 记录检测周期各阶段（检测IP、确认、读取记录、更新、验证）的耗时，
+// 周期结束时在调试日志中输出汇总，用于定位"更新很慢"的原因。
+// 多个记录同步时同一阶段汇总为次数与平均耗时；更新重试时重新读取记录的时间同时计入读取与更新
+type cycleTimer struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases map[string]*phaseTiming
+	order  []string
+}
+
+// phaseTiming 一个阶段的次数与累计耗时
+type phaseTiming struct {
+	count int
+	total time.Duration
+}
+
+var cycleTiming = &cycleTimer{}
+
+// begin 开始一个检测周期
+func (t *cycleTimer) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+	t.phases = make(map[string]*phaseTiming)
+	t.order = nil
+}
+
+// phase 开始计时一个阶段，返回结束计时的函数：defer cycleTiming.phase("ip.detect")()
+func (t *cycleTimer) phase(name string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		logDebug("%s 耗时 %v", name, elapsed.Round(time.Millisecond))
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.phases == nil {
+			return
+		}
+		p, ok := t.phases[name]
+		if !ok {
+			p = &phaseTiming{}
+			t.phases[name] = p
+			t.order = append(t.order, name)
+		}
+		p.count++
+		p.total += elapsed
+	}
+}
+
+// summary 按阶段开始的顺序返回汇总，如 "ip.detect 120ms, dns.list 2×80ms, dns.update 310ms"
+func (t *cycleTimer) summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var parts []string
+	for _, name := range t.order {
+		p := t.phases[name]
+		if p.count > 1 {
+			parts = append(parts, fmt.Sprintf("%s %d×%v", name, p.count, (p.total/time.Duration(p.count)).Round(time.Millisecond)))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %v", name, p.total.Round(time.Millisecond)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// end 结束检测周期并输出汇总
+func (t *cycleTimer) end() {
+	summary := t.summary()
+	t.mu.Lock()
+	elapsed := time.Since(t.start)
+	t.phases = nil
+	t.order = nil
+	t.mu.Unlock()
+	if summary == "" {
+		summary = "-"
+	}
+	logDebug("检测周期耗时 %v: %s", elapsed.Round(time.Millisecond), summary)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// authDiagnosisTTL 诊断结果的有效期：认证错误持续出现时不必每个周期重复诊断
+const authDiagnosisTTL = 10 * time.Minute
+
+// tokenPageURL 创建与编辑 API 令牌的页面
+const tokenPageURL = "https://dash.cloudflare.com/profile/api-tokens"
+
+// authDiagnosis 最近一次诊断的结果
+var authDiagnosis struct {
+	mu     sync.Mutex
+	at     time.Time
+	zoneID string
+	advice string
+}
+
+// diagnosedError 附带诊断建议的认证错误：Error() 返回建议而不是 API 响应内容，
+// errors.Is 与 apiResponseMetas 仍可使用原错误
+type diagnosedError struct {
+	advice string
+	err    error
+}
+
+func (e *diagnosedError) Error() string {
+	if metas := apiResponseMetas(e.err); len(metas) > 0 && metas[0].String() != "" {
+		return e.advice + " (" + metas[0].String() + ")"
+	}
+	return e.advice
+}
+
+func (e *diagnosedError) Unwrap() error {
+	return e.err
+}
+
+// onlyAuthErrors 判断错误（多个记录失败时的每一个）是否都是认证错误；混有其他错误时不替换错误信息
+func onlyAuthErrors(err error) bool {
+	if joined, ok := err.(*joinedError); ok {
+		for _, e := range joined.errs {
+			if !errors.Is(e, ErrAuth) {
+				return false
+			}
+		}
+		return len(joined.errs) > 0
+	}
+	return errors.Is(err, ErrAuth)
+}
+
+// diagnoseAuthError Cloudflare 返回 401/403（或 9109 等错误码）时诊断令牌的问题，
+// 返回带有处理建议的错误；无法诊断（如网络错误）时返回原错误
+func diagnoseAuthError(client *CloudflareClient, zoneID string, err error) error {
+	if client == nil || !onlyAuthErrors(err) {
+		return err
+	}
+
+	authDiagnosis.mu.Lock()
+	defer authDiagnosis.mu.Unlock()
+	if authDiagnosis.zoneID != zoneID || time.Since(authDiagnosis.at) >= authDiagnosisTTL {
+		authDiagnosis.advice = diagnoseToken(client, zoneID)
+		authDiagnosis.zoneID = zoneID
+		authDiagnosis.at = time.Now()
+	}
+	if authDiagnosis.advice == "" {
+		return err
+	}
+	logDebug("认证错误的原始信息: %v", err)
+	return &diagnosedError{advice: authDiagnosis.advice, err: err}
+}
+
+// diagnoseToken 依次检查令牌是否有效、能否访问区域、能否读取记录，定位认证失败的原因
+func diagnoseToken(client *CloudflareClient, zoneID string) string {
+	token, err := client.VerifyToken()
+	if err != nil {
+		if errors.Is(err, ErrAuth) {
+			return fmt.Sprintf(tr("API 令牌无效（已删除、已轮换或填写错误）：请在 %s 重新创建令牌并更新配置中的 api_token"), tokenPageURL)
+		}
+		return ""
+	}
+	expired := token.Status == "expired"
+	if expires, err := time.Parse(time.RFC3339, token.ExpiresOn); err == nil && expires.Before(time.Now()) {
+		expired = true
+	}
+	switch {
+	case expired:
+		return fmt.Sprintf(tr("API 令牌已于 %s 过期：请在 %s 延长有效期或重新创建令牌"), token.ExpiresOn, tokenPageURL)
+	case token.Status != "active":
+		return fmt.Sprintf(tr("API 令牌状态为 %s，不能使用：请在 %s 启用令牌"), token.Status, tokenPageURL)
+	}
+
+	if _, err := client.GetZone(zoneID); err != nil {
+		if errors.Is(err, ErrAuth) || errors.Is(err, ErrRecordNotFound) {
+			return fmt.Sprintf(tr("API 令牌无权访问区域 %s：令牌的区域资源（Zone Resources）未包含该区域，或 zone_id 填写错误"), zoneID)
+		}
+		return ""
+	}
+	if _, err := client.ListZoneDNSRecordsByType(zoneID, "A"); err != nil {
+		if errors.Is(err, ErrAuth) {
+			return fmt.Sprintf(tr("API 令牌可以访问区域 %s，但没有读取DNS记录的权限：请在 %s 编辑令牌，添加 Zone - DNS - Edit 权限"), zoneID, tokenPageURL)
+		}
+		return ""
+	}
+	return fmt.Sprintf(tr("API 令牌可以读取区域 %s 的DNS记录，但没有修改权限：请在 %s 编辑令牌，添加 Zone - DNS - Edit 权限"), zoneID, tokenPageURL)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TracingConfig 检测周期的链路追踪，以 OTLP/HTTP（JSON）格式导出到 OpenTelemetry Collector 等后端
+type TracingConfig struct {
+	// Endpoint OTLP/HTTP 地址（如 http://127.0.0.1:4318），未包含路径时追加 /v1/traces；
+	// 为空时使用环境变量 OTEL_EXPORTER_OTLP_TRACES_ENDPOINT 或 OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string `json:"endpoint,omitempty"`
+	// Headers 导出请求附带的请求头（如认证信息）
+	Headers map[string]string `json:"headers,omitempty"`
+	// ServiceName 上报的 service.name（默认 dns_manager）
+	ServiceName string `json:"service_name,omitempty"`
+}
+
+// OTLP span 类型与状态码
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+
+	spanStatusError = 2
+)
+
+// traceSpan 一次操作的耗时记录；为 nil 时所有方法都不做任何事，调用方无需判断是否启用追踪
+type traceSpan struct {
+	tracer   *cycleTracer
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	err      error
+}
+
+// cycleTracer 收集当前检测周期内的 span，周期结束时一并导出
+type cycleTracer struct {
+	mu          sync.Mutex
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+	root        *traceSpan
+	spans       []*traceSpan
+	exports     sync.WaitGroup
+}
+
+// tracing 全局追踪器，未配置时 endpoint 为空
+var tracing = &cycleTracer{}
+
+// initTracing 按配置启用或关闭追踪
+func initTracing(config *TracingConfig) {
+	endpoint := ""
+	var headers map[string]string
+	serviceName := "dns_manager"
+	if config != nil {
+		endpoint = config.Endpoint
+		headers = config.Headers
+		if config.ServiceName != "" {
+			serviceName = config.ServiceName
+		}
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint != "" {
+		endpoint = otlpTracesURL(endpoint)
+	}
+	for _, value := range headers {
+		registerSecret(value)
+	}
+
+	tracing.mu.Lock()
+	defer tracing.mu.Unlock()
+	if endpoint != "" && endpoint != tracing.endpoint {
+		logInfo("链路追踪已启用，导出到 %s", endpoint)
+	}
+	tracing.endpoint = endpoint
+	tracing.headers = headers
+	tracing.serviceName = serviceName
+	if tracing.client == nil {
+		// 导出请求不经过追踪层，避免导出本身产生 span
+		tracing.client = &http.Client{Timeout: 10 * time.Second}
+	}
+}
+
+// otlpTracesURL 只给出主机地址时补全 /v1/traces
+func otlpTracesURL(endpoint string) string {
+	trimmed := strings.TrimRight(endpoint, "/")
+	rest := trimmed
+	if i := strings.Index(rest, "://"); i >= 0 {
+		rest = rest[i+3:]
+	}
+	if !strings.Contains(rest, "/") {
+		return trimmed + "/v1/traces"
+	}
+	return trimmed
+}
+
+// startCycle 开始一个检测周期的根 span，未启用追踪时返回 nil
+func (t *cycleTracer) startCycle() *traceSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.endpoint == "" {
+		return nil
+	}
+	t.root = &traceSpan{
+		tracer:  t,
+		traceID: randomHex(16),
+		spanID:  randomHex(8),
+		name:    "dns_manager.cycle",
+		kind:    spanKindInternal,
+		start:   time.Now(),
+		attrs:   map[string]interface{}{},
+	}
+	t.spans = []*traceSpan{t.root}
+	return t.root
+}
+
+// start 在当前检测周期下开始一个子 span，不在检测周期内时返回 nil
+func (t *cycleTracer) start(name string, kind int) *traceSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.root == nil {
+		return nil
+	}
+	span := &traceSpan{
+		tracer:   t,
+		traceID:  t.root.traceID,
+		spanID:   randomHex(8),
+		parentID: t.root.spanID,
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		attrs:    map[string]interface{}{},
+	}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+// set 设置属性
+func (s *traceSpan) set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.attrs[key] = value
+}
+
+// finish 结束 span；根 span 结束时导出整个周期
+func (s *traceSpan) finish(err error) {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.mu.Lock()
+	s.end = time.Now()
+	s.err = err
+	if s != t.root {
+		t.mu.Unlock()
+		return
+	}
+	// 在锁内编码，此后仍在进行的请求结束时不会再修改已导出的 span
+	payload := otlpPayload(t.serviceName, t.spans)
+	endpoint, headers := t.endpoint, t.headers
+	t.root, t.spans = nil, nil
+	t.mu.Unlock()
+
+	t.exports.Add(1)
+	go func() {
+		defer t.exports.Done()
+		if err := t.export(endpoint, headers, payload); err != nil {
+			logDebug("导出链路追踪失败: %v", err)
+		}
+	}()
+}
+
+// waitTracing 等待正在进行的导出完成（最多等待 timeout），供退出前调用
+func waitTracing(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		tracing.exports.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// export 发送 OTLP/HTTP JSON 请求
+func (t *cycleTracer) export(endpoint string, headers map[string]string, payload []byte) error {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf(tr("状态码: %d"), resp.StatusCode)
+	}
+	return nil
+}
+
+// otlpPayload 按 OTLP JSON 编码（ID 使用十六进制，时间为纳秒字符串）
+func otlpPayload(serviceName string, spans []*traceSpan) []byte {
+	resource := []map[string]interface{}{
+		otlpAttr("service.name", serviceName),
+		otlpAttr("service.version", getBuildInfo().Version),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		resource = append(resource, otlpAttr("host.name", hostname))
+	}
+
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		end := span.end
+		if end.IsZero() {
+			// 周期结束时仍未结束的 span（如超时的后台请求）按周期结束时间截断
+			end = spans[0].end
+		}
+		attrs := make([]map[string]interface{}, 0, len(span.attrs))
+		for key, value := range span.attrs {
+			attrs = append(attrs, otlpAttr(key, value))
+		}
+		item := map[string]interface{}{
+			"traceId":           span.traceID,
+			"spanId":            span.spanID,
+			"name":              span.name,
+			"kind":              span.kind,
+			"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+			"attributes":        attrs,
+		}
+		if span.parentID != "" {
+			item["parentSpanId"] = span.parentID
+		}
+		if span.err != nil {
+			item["status"] = map[string]interface{}{"code": spanStatusError, "message": redactSecrets(span.err.Error())}
+		}
+		encoded = append(encoded, item)
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": resource},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": serviceName, "version": getBuildInfo().Version},
+				"spans": encoded,
+			}},
+		}},
+	})
+	return data
+}
+
+// otlpAttr 编码一个 OTLP 属性
+func otlpAttr(key string, value interface{}) map[string]interface{} {
+	var v map[string]interface{}
+	switch value := value.(type) {
+	case bool:
+		v = map[string]interface{}{"boolValue": value}
+	case int:
+		v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+	case int64:
+		v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+	default:
+		v = map[string]interface{}{"stringValue": redactSecrets(fmt.Sprint(value))}
+	}
+	return map[string]interface{}{"key": key, "value": v}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// traceTransport 为检测周期内的每个 HTTP 请求记录 span（IP检测服务、Cloudflare API 等），
+// 并附带周期的关联ID请求头
+type traceTransport struct {
+	name string
+	base http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := currentCycleID(); id != "" {
+		// RoundTripper 不能修改调用方的请求
+		req = req.Clone(req.Context())
+		req.Header.Set(correlationHeader, id)
+	}
+	span := tracing.start(t.name+" "+req.Method, spanKindClient)
+	if span == nil {
+		return t.base.RoundTrip(req)
+	}
+	span.set("http.method", req.Method)
+	span.set("server.address", req.URL.Host)
+	span.set("url.path", req.URL.Path)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.finish(redactError(err))
+		return nil, err
+	}
+	span.set("http.status_code", resp.StatusCode)
+	if ray := resp.Header.Get("Cf-Ray"); ray != "" {
+		span.set("cloudflare.ray_id", ray)
+	}
+	if resp.StatusCode >= 400 {
+		span.finish(fmt.Errorf(tr("状态码: %d"), resp.StatusCode))
+	} else {
+		span.finish(nil)
+	}
+	return resp, nil
+}
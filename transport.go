@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// HTTPConfig Cloudflare API 与IP检测服务的 HTTP 设置：超时、连接复用、域名解析与 TLS；时长为 Go 格式（如 "5s"），"0" 表示不限制
+type HTTPConfig struct {
+	// CloudflareTimeout 单个 Cloudflare API 请求的超时（默认 30s）
+	CloudflareTimeout string `json:"cloudflare_timeout,omitempty"`
+	// IPCheckTimeout 单个IP检测服务请求的超时（默认 10s）
+	IPCheckTimeout string `json:"ip_check_timeout,omitempty"`
+	// DialTimeout 建立 TCP 连接的超时（默认 30s）
+	DialTimeout string `json:"dial_timeout,omitempty"`
+	// KeepAlive TCP keep-alive 探测间隔（默认 30s），"0" 表示关闭 keep-alive，每个请求使用新连接
+	KeepAlive string `json:"keep_alive,omitempty"`
+	// IdleConnTimeout 空闲连接保留的时间（默认 90s）
+	IdleConnTimeout string `json:"idle_conn_timeout,omitempty"`
+	// MaxIdleConns 保留的空闲连接总数（默认 100）
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+	// MaxIdleConnsPerHost 每个主机保留的空闲连接数（默认 2）
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+	// Resolvers 解析 Cloudflare API 与IP检测服务域名使用的DNS服务器（如 "1.1.1.1"、"9.9.9.9:53"），
+	// 依次尝试，为空时使用系统解析器；用于系统解析器会过滤或污染这些域名的网络
+	Resolvers []string `json:"resolvers,omitempty"`
+	// DoH 通过 DNS-over-HTTPS 解析这些域名（如 "https://1.1.1.1/dns-query"），优先于 resolvers；
+	// 地址应使用IP，否则 DoH 服务本身的域名仍由系统解析器解析
+	DoH string `json:"doh,omitempty"`
+	// CAFile 额外信任的 CA 证书（PEM），与系统证书一起使用；用于会解密检查 TLS 流量的出口代理
+	CAFile string `json:"ca_file,omitempty"`
+	// ClientCert、ClientKey 客户端证书与私钥（PEM），出口要求双向 TLS 认证时设置；每次建立连接时重新读取，证书轮换后无需重启
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+}
+
+// HTTP 超时与连接池的默认值
+const (
+	defaultCloudflareTimeout = 30 * time.Second
+	defaultIPCheckTimeout    = 10 * time.Second
+	defaultDialTimeout       = 30 * time.Second
+	defaultKeepAlive         = 30 * time.Second
+	defaultIdleConnTimeout   = 90 * time.Second
+)
+
+// httpDuration 解析时长配置，为空或无效时使用默认值
+func httpDuration(name, value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := parseDurationOrZero(value)
+	if err != nil || d < 0 {
+		logError("http.%s 格式无效: %s，使用默认值 %v", name, value, def)
+		return def
+	}
+	return d
+}
+
+// cloudflareTimeout 返回 Cloudflare API 请求的超时
+func (c *HTTPConfig) cloudflareTimeout() time.Duration {
+	if c == nil {
+		return defaultCloudflareTimeout
+	}
+	return httpDuration("cloudflare_timeout", c.CloudflareTimeout, defaultCloudflareTimeout)
+}
+
+// ipCheckTimeout 返回IP检测服务请求的超时
+func (c *HTTPConfig) ipCheckTimeout() time.Duration {
+	if c == nil {
+		return defaultIPCheckTimeout
+	}
+	return httpDuration("ip_check_timeout", c.IPCheckTimeout, defaultIPCheckTimeout)
+}
+
+// transport 按配置创建底层 Transport；未配置时使用 http.DefaultTransport（与其他客户端共享连接池）
+func (c *HTTPConfig) transport() http.RoundTripper {
+	if c == nil {
+		return http.DefaultTransport
+	}
+	keepAlive := httpDuration("keep_alive", c.KeepAlive, defaultKeepAlive)
+	dialer := &net.Dialer{Timeout: httpDuration("dial_timeout", c.DialTimeout, defaultDialTimeout), KeepAlive: keepAlive}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = c.dialContext(dialer)
+	transport.IdleConnTimeout = httpDuration("idle_conn_timeout", c.IdleConnTimeout, defaultIdleConnTimeout)
+	if keepAlive == 0 {
+		dialer.KeepAlive = -1
+		transport.DisableKeepAlives = true
+	}
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	// 证书在应用配置时已检查（见 App.ApplyConfig），这里出错只可能是文件在此期间被改动
+	if tlsConfig, err := c.tlsConfig(); err != nil {
+		logError("%v", err)
+	} else if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport
+}
+
+// tlsConfig 按 ca_file、client_cert、client_key 创建 TLS 设置，都未设置时返回 nil
+func (c *HTTPConfig) tlsConfig() (*tls.Config, error) {
+	if c == nil || (c.CAFile == "" && c.ClientCert == "" && c.ClientKey == "") {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf(tr("读取 http.ca_file 失败: %v"), err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(tr("http.ca_file 中没有有效的证书: %s"), c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New(tr("http.client_cert 与 http.client_key 需要同时设置"))
+		}
+		if _, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey); err != nil {
+			return nil, fmt.Errorf(tr("加载客户端证书失败: %v"), err)
+		}
+		certFile, keyFile := c.ClientCert, c.ClientKey
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf(tr("加载客户端证书失败: %v"), err)
+			}
+			return &cert, nil
+		}
+	}
+	return tlsConfig, nil
+}
+
+// newCloudflareHTTPClient 创建访问 Cloudflare API 的 HTTP 客户端
+func newCloudflareHTTPClient(cfg *HTTPConfig) *http.Client {
+	return &http.Client{
+		Timeout:   cfg.cloudflareTimeout(),
+		Transport: wrapTransport("cloudflare", cfg.transport()),
+	}
+}
+
+// newIPCheckerFor 创建按配置设置超时与连接池的IP检测器
+func newIPCheckerFor(cfg *Config) *IPChecker {
+	checker := NewIPChecker()
+	checker.client = &http.Client{
+		Timeout:   cfg.HTTP.ipCheckTimeout(),
+		Transport: wrapTransport("ip-checker", cfg.HTTP.transport()),
+	}
+	return checker
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLStrategyConfig 写入变化前先降低TTL：修改记录内容前先把TTL降为 LowTTL，
+// 新内容同样以 LowTTL 写入，经过 RestoreAfter 后再恢复为 TTL，缩短IP变化前后的缓存时间
+type TTLStrategyConfig struct {
+	// TTL 恢复后的TTL（秒），默认 3600
+	TTL int `json:"ttl,omitempty"`
+	// LowTTL 变化期间使用的TTL（秒），默认 60（Cloudflare 非企业版的最小值）
+	LowTTL int `json:"low_ttl,omitempty"`
+	// RestoreAfter 写入后多久恢复TTL（如 "10m"），默认 10m
+	RestoreAfter string `json:"restore_after,omitempty"`
+}
+
+// defaultTTLRestoreAfter 默认恢复TTL的延迟
+const defaultTTLRestoreAfter = 10 * time.Minute
+
+func (s *TTLStrategyConfig) ttl() int {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return defaultRecordTTL
+}
+
+func (s *TTLStrategyConfig) lowTTL() int {
+	if s.LowTTL > 0 {
+		return s.LowTTL
+	}
+	return 60
+}
+
+func (s *TTLStrategyConfig) restoreAfter() time.Duration {
+	if d, err := time.ParseDuration(s.RestoreAfter); err == nil && d > 0 {
+		return d
+	}
+	return defaultTTLRestoreAfter
+}
+
+// lowerTTL 执行计划前降低TTL：修改记录时先以原内容写入 LowTTL，新建记录直接使用 LowTTL。
+// 开启代理的记录TTL由 Cloudflare 管理，不处理
+func (r *Reconciler) lowerTTL(plan *Plan) error {
+	if r.TTLStrategy == nil || plan.Request.Proxied {
+		return nil
+	}
+	low := r.TTLStrategy.lowTTL()
+	if plan.Action == planUpdate && plan.Target.TTL != low {
+		logInfo("正在降低 %s 的TTL: %d -> %d", plan.Target.Name, plan.Target.TTL, low)
+		lowered, err := r.Provider.EditDNSRecord(r.ZoneID, *plan.Target, DNSRecordCreateRequest{
+			Type:     plan.Target.Type,
+			Name:     plan.Target.Name,
+			Content:  plan.Target.Content,
+			TTL:      low,
+			Proxied:  plan.Target.Proxied,
+			Priority: plan.Target.Priority,
+			Comment:  plan.Target.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		plan.Target = lowered
+	}
+	plan.Request.TTL = low
+	ttlRestore.schedule(r.TTLStrategy.restoreAfter())
+	return nil
+}
+
+// ttlRestoreState 恢复TTL的时间
+type ttlRestoreState struct {
+	mu      sync.Mutex
+	checked bool // 本次运行是否已检查过（上次运行可能在恢复前退出）
+	due     time.Time
+}
+
+var ttlRestore = &ttlRestoreState{}
+
+// schedule 在 d 之后恢复TTL；再次写入时重新计时
+func (t *ttlRestoreState) schedule(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checked = true
+	t.due = time.Now().Add(d)
+}
+
+// restoreTTLs 到期后把指向当前IP、TTL仍为 LowTTL 的记录恢复为配置的TTL。
+// 启动后的第一次调用同样检查一次，恢复上次运行（或 once 模式）降低后未恢复的记录；
+// 只处理已指向当前IP的记录，IP再次变化时会在写入前重新降低
+func restoreTTLs(config *Config, client *CloudflareClient, ip string) {
+	t := ttlRestore
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.checked && (t.due.IsZero() || time.Now().Before(t.due)) {
+		return
+	}
+
+	strategy := config.TTLStrategy
+	targets := config.ipTargets()
+	_, aliases := config.subdomainTargets()
+	for _, alias := range aliases {
+		targets = append(targets, RecordTarget{ZoneID: config.ZoneID, Name: alias, Type: "CNAME"})
+	}
+
+	client.SetAuditSource("ttl-strategy")
+	ok := true
+	for _, target := range targets {
+		records, err := client.GetAllDNSRecords(target.ZoneID, target.Name, target.Type)
+		if err != nil {
+			logError("恢复TTL时读取记录失败: %v", err)
+			ok = false
+			continue
+		}
+		for _, record := range records {
+			if record.TTL != strategy.lowTTL() || record.Proxied {
+				continue
+			}
+			if target.Type != "CNAME" && record.Content != ip {
+				continue
+			}
+			_, err := client.EditDNSRecord(target.ZoneID, record, DNSRecordCreateRequest{
+				Type:     record.Type,
+				Name:     record.Name,
+				Content:  record.Content,
+				TTL:      strategy.ttl(),
+				Proxied:  record.Proxied,
+				Priority: record.Priority,
+				Comment:  record.Comment,
+			})
+			if err != nil {
+				logError("恢复 %s 的TTL失败: %v", record.Name, err)
+				ok = false
+				continue
+			}
+			logInfo("已恢复 %s 的TTL: %d -> %d", record.Name, record.TTL, strategy.ttl())
+		}
+	}
+
+	t.checked = true
+	if ok {
+		t.due = time.Time{}
+	}
+}
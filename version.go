@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// 构建信息，发布时通过 ldflags 注入：
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// 未注入时从 Go 工具链记录的 VCS 信息中读取
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo 程序版本与构建信息
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	// Defaults 构建时注入的默认值
+	Defaults map[string]string `json:"defaults,omitempty"`
+}
+
+// getBuildInfo 返回当前程序的构建信息，ldflags 注入的值优先
+func getBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Defaults:  embeddedDefaults(),
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// go install 安装的正式版本带有模块版本号；本地构建的伪版本不如 dev 直观
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" && !strings.HasPrefix(bi.Main.Version, "v0.0.0-") {
+		info.Version = strings.TrimPrefix(bi.Main.Version, "v")
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String 单行形式，用于日志与状态文件
+func (b BuildInfo) String() string {
+	parts := []string{}
+	if b.Commit != "" {
+		c := b.Commit
+		if len(c) > 12 {
+			c = c[:12]
+		}
+		if b.Modified {
+			c += "-dirty"
+		}
+		parts = append(parts, "commit "+c)
+	}
+	if b.BuildDate != "" {
+		parts = append(parts, "built "+b.BuildDate)
+	}
+	parts = append(parts, b.GoVersion, b.Platform)
+	return fmt.Sprintf("dns_manager %s (%s)", b.Version, strings.Join(parts, ", "))
+}
+
+func cmdVersionMain(args []string) int {
+	fs, common := newFlagSet("version")
+	output := addOutputFlag(fs)
+	parseFlags(fs, common, args)
+	if !validOutput(*output) {
+		return 2
+	}
+	return cmdVersion(*output)
+}
+
+// cmdVersion 打印版本与构建信息
+func cmdVersion(output string) int {
+	info := getBuildInfo()
+	if output == outputJSON {
+		printJSON(info)
+		return 0
+	}
+	commit := info.Commit
+	if commit == "" {
+		commit = tr("未知")
+	} else if info.Modified {
+		commit += " (modified)"
+	}
+	buildDate := info.BuildDate
+	if buildDate == "" {
+		buildDate = tr("未知")
+	}
+	fmt.Printf("dns_manager %s\n", info.Version)
+	fmt.Printf(tr("提交: %s\n"), commit)
+	fmt.Printf(tr("构建时间: %s\n"), buildDate)
+	fmt.Printf(tr("Go 版本: %s (%s)\n"), info.GoVersion, info.Platform)
+	if len(info.Defaults) > 0 {
+		fmt.Println(tr("构建时注入的默认值:"))
+		var names []string
+		for name := range info.Defaults {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, info.Defaults[name])
+		}
+	}
+	return 0
+}
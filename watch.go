@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultWatchInterval watch 的默认刷新间隔
+const defaultWatchInterval = 10 * time.Second
+
+// watchHistoryLimit watch 保留的变化记录条数
+const watchHistoryLimit = 10
+
+// watchSnapshot watch 一次刷新的结果
+type watchSnapshot struct {
+	Time      time.Time
+	LocalIP   string
+	LocalErr  string
+	Values    []string // Cloudflare 中的记录值
+	Proxied   bool
+	RecordErr string
+	Resolvers []ResolverResult
+}
+
+// takeWatchSnapshot 读取本机检测到的IP、Cloudflare 中的记录值以及权威与公共解析器的解析结果
+func takeWatchSnapshot(name, recordType string) watchSnapshot {
+	snap := watchSnapshot{Time: time.Now()}
+	if ip, _, err := app.IPChecker().GetPublicIPWithService(); err != nil {
+		snap.LocalErr = err.Error()
+	} else {
+		snap.LocalIP = ip
+	}
+
+	values, proxied, err := expectedAnswers(name, recordType)
+	if err != nil {
+		snap.RecordErr = err.Error()
+		return snap
+	}
+	snap.Values, snap.Proxied = values, proxied
+
+	results, err := resolveRecord(name, recordType, values, proxied, true)
+	if err != nil {
+		snap.RecordErr = err.Error()
+		return snap
+	}
+	snap.Resolvers = results
+	return snap
+}
+
+// watchChanges 比较前后两次刷新，返回本机IP、记录值与各解析器结果的变化
+func watchChanges(prev, cur watchSnapshot) []string {
+	stamp := cur.Time.Format("15:04:05")
+	var changes []string
+	if prev.LocalIP != cur.LocalIP && cur.LocalIP != "" {
+		changes = append(changes, fmt.Sprintf(tr("%s 本机IP: %s -> %s"), stamp, valueOrDash(prev.LocalIP), cur.LocalIP))
+	}
+	if before, after := strings.Join(prev.Values, ", "), strings.Join(cur.Values, ", "); before != after && cur.RecordErr == "" {
+		changes = append(changes, fmt.Sprintf(tr("%s 记录值: %s -> %s"), stamp, valueOrDash(before), valueOrDash(after)))
+	}
+	previous := make(map[string]ResolverResult)
+	for _, result := range prev.Resolvers {
+		previous[result.Resolver] = result
+	}
+	for _, result := range cur.Resolvers {
+		if old, ok := previous[result.Resolver]; ok && old.Status != result.Status {
+			changes = append(changes, fmt.Sprintf("%s %s: %s -> %s", stamp, result.Resolver, old.Status, result.Status))
+		}
+	}
+	return changes
+}
+
+// valueOrDash 空值显示为 -
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// renderWatch 输出一次刷新的视图
+func renderWatch(w io.Writer, name, recordType string, snap watchSnapshot, history []string) {
+	fmt.Fprintf(w, tr("记录: %s (%s)    %s\n"), name, recordType, snap.Time.Format(time.RFC3339))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	local := snap.LocalIP
+	if snap.LocalErr != "" {
+		local = colorize(ansiRed, snap.LocalErr)
+	}
+	fmt.Fprintf(w, tr("本机检测到的IP: %s\n"), local)
+	if snap.RecordErr != "" {
+		fmt.Fprintf(w, tr("Cloudflare 记录: %s\n"), colorize(ansiRed, snap.RecordErr))
+	} else {
+		values := strings.Join(snap.Values, ", ")
+		if snap.LocalIP != "" && !containsString(snap.Values, snap.LocalIP) && !snap.Proxied {
+			values = colorize(ansiRed, values) + tr("（与本机IP不一致）")
+		}
+		if snap.Proxied {
+			values += tr("（已开启代理）")
+		}
+		fmt.Fprintf(w, tr("Cloudflare 记录: %s\n"), values)
+	}
+
+	if len(snap.Resolvers) > 0 {
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		fmt.Fprintf(w, "%-28s %-10s %s\n", tr("解析器"), tr("状态"), tr("解析结果"))
+		for _, result := range snap.Resolvers {
+			resolver := result.Resolver
+			if result.Authoritative {
+				resolver += " *"
+			}
+			answer := strings.Join(result.Answers, ", ")
+			if result.Error != "" {
+				answer = result.Error
+			}
+			status := result.Status
+			if status == propagationOK || status == propagationProxied {
+				status = colorize(ansiGreen, fmt.Sprintf("%-10s", status))
+			} else {
+				status = colorize(ansiRed, fmt.Sprintf("%-10s", status))
+			}
+			fmt.Fprintf(w, "%-28s %s %s\n", resolver, status, answer)
+		}
+		fmt.Fprintln(w, tr("* 权威名称服务器"))
+	}
+
+	if len(history) > 0 {
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		fmt.Fprintln(w, tr("最近的变化:"))
+		for _, change := range history {
+			fmt.Fprintln(w, "  "+change)
+		}
+	}
+}
+
+func cmdWatchMain(args []string) int {
+	name, args := recordArgs(args)
+	fs, common := newFlagSet("watch")
+	recordType := fs.String("type", "", tr("记录类型（默认使用配置中的记录类型）"))
+	interval := fs.Duration("interval", defaultWatchInterval, tr("刷新间隔"))
+	count := fs.Int("count", 0, tr("刷新次数后退出，0 表示一直运行直到 Ctrl+C"))
+	parseFlags(fs, common, args)
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if *interval < time.Second {
+		fmt.Fprintln(os.Stderr, tr("刷新间隔不能小于 1s"))
+		return 2
+	}
+
+	if err := initRuntime(runtimeOptions{console: false, debugHTTP: *common.debugHTTP}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	config := app.Config()
+	if name == "" {
+		name = config.RecordName
+	}
+	resolved, err := app.Client().recordName(config.ZoneID, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitCodeFor(err)
+	}
+	name = resolved
+	if *recordType == "" {
+		*recordType = config.RecordType
+	}
+	*recordType = strings.ToUpper(*recordType)
+
+	// 终端中原地刷新，输出到文件或管道时逐次追加
+	live := stdoutIsTerminal()
+	var prev watchSnapshot
+	var history []string
+	for i := 0; *count == 0 || i < *count; i++ {
+		if i > 0 {
+			time.Sleep(*interval)
+		}
+		snap := takeWatchSnapshot(name, *recordType)
+		if i > 0 {
+			history = append(history, watchChanges(prev, snap)...)
+			if len(history) > watchHistoryLimit {
+				history = history[len(history)-watchHistoryLimit:]
+			}
+		}
+		prev = snap
+
+		if live {
+			fmt.Print("\033[H\033[2J")
+		} else if i > 0 {
+			fmt.Println()
+		}
+		renderWatch(os.Stdout, name, *recordType, snap, history)
+		if live {
+			fmt.Printf(tr("\n每 %s 刷新一次，按 Ctrl+C 退出\n"), *interval)
+		}
+	}
+	return 0
+}
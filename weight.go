@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// defaultNodeWeight 未配置 weight 时本机的权重
+const defaultNodeWeight = 100
+
+// LoadBalancerPoolConfig Cloudflare 负载均衡源站池：配置后本机作为池中的一个源站，按权重分配流量。
+// Cloudflare 不允许同名记录存在内容完全相同的多条记录，无法用重复记录模拟权重，需要权重时使用源站池
+type LoadBalancerPoolConfig struct {
+	AccountID string `json:"account_id"`
+	PoolID    string `json:"pool_id"`
+	// Origin 本机在池中的源站名称，默认使用主机名（与写入租约的 owner 相同）
+	Origin string `json:"origin,omitempty"`
+}
+
+// nodeWeight 返回本机权重（0–100）
+func (c *Config) nodeWeight() int {
+	if c.Weight == nil {
+		return defaultNodeWeight
+	}
+	w := *c.Weight
+	if w < 0 {
+		return 0
+	}
+	if w > 100 {
+		return 100
+	}
+	return w
+}
+
+// poolOriginName 返回本机在源站池中的名称
+func (c *Config) poolOriginName() string {
+	if c.LoadBalancerPool.Origin != "" {
+		return c.LoadBalancerPool.Origin
+	}
+	return c.leaseOwner()
+}
+
+// weightState 权重相关的运行状态
+type weightState struct {
+	mu      sync.Mutex
+	drained bool // weight 为 0 时本机记录已撤下
+	warned  bool // 已提示权重在普通记录模式下无法生效
+	// 源站池中最近确认的本机地址与权重，未变化时不再请求 API
+	poolIP     string
+	poolWeight int
+}
+
+var weights = &weightState{}
+
+// checkWeight 普通记录模式下处理权重：0 表示排空，撤下本机记录后不再同步；
+// 其他值无法用重复记录表示，只提示一次。返回本周期是否继续同步，rejoin 含义同 checkMembership
+func checkWeight(config *Config, ip, currentIP string) (proceed, rejoin bool, err error) {
+	weights.mu.Lock()
+	defer weights.mu.Unlock()
+
+	weight := config.nodeWeight()
+	if weight > 0 {
+		if weight != defaultNodeWeight && !weights.warned {
+			weights.warned = true
+			logError("Cloudflare 不允许重复的记录，普通记录模式下权重 %d 与其他机器相同；需要按权重分配流量时请配置 load_balancer_pool", weight)
+		}
+		if weights.drained {
+			weights.drained = false
+			logInfo("权重已恢复为 %d，重新加入记录集", weight)
+			return true, true, nil
+		}
+		return true, false, nil
+	}
+
+	if weights.drained {
+		return false, false, nil
+	}
+	logInfo("权重为 0，撤下本机记录")
+	if err := withdrawRecords(app.Client(), "weight", config.ipTargets(), ip, currentIP); err != nil {
+		logError("%v", err)
+		return false, false, err
+	}
+	weights.drained = true
+	app.SetCurrentIP("")
+	return false, false, nil
+}
+
+// checkPoolOrigin 本机地址或权重与上次确认的不同时同步源站池，配置重载后修改的权重同样生效
+func checkPoolOrigin(config *Config, ip string) (bool, error) {
+	weights.mu.Lock()
+	defer weights.mu.Unlock()
+
+	weight := config.nodeWeight()
+	if weights.poolIP == ip && weights.poolWeight == weight {
+		return false, nil
+	}
+	changed, err := syncPoolOrigin(config, app.Client(), ip)
+	if err != nil {
+		logError("%v", err)
+		return false, err
+	}
+	weights.poolIP = ip
+	weights.poolWeight = weight
+	return changed, nil
+}
+
+// poolOrigin 源站池中需要修改的字段，其余字段原样保留
+type poolOrigin map[string]interface{}
+
+// syncPoolOrigin 确认源站池中本机源站的地址与权重，不一致时修改；返回是否修改了源站池
+func syncPoolOrigin(config *Config, client *CloudflareClient, ip string) (bool, error) {
+	pool := config.LoadBalancerPool
+	name := config.poolOriginName()
+	weight := float64(config.nodeWeight()) / 100
+
+	origins, err := client.GetPoolOrigins(pool.AccountID, pool.PoolID)
+	if err != nil {
+		return false, fmt.Errorf(tr("读取源站池失败: %v"), err)
+	}
+
+	oldAddress := ""
+	found := false
+	for _, origin := range origins {
+		if origin["name"] != name {
+			continue
+		}
+		found = true
+		oldAddress, _ = origin["address"].(string)
+		oldWeight, _ := origin["weight"].(float64)
+		enabled, _ := origin["enabled"].(bool)
+		if oldAddress == ip && oldWeight == weight && enabled {
+			return false, nil
+		}
+		origin["address"] = ip
+		origin["weight"] = weight
+		origin["enabled"] = true
+	}
+	if !found {
+		origins = append(origins, poolOrigin{"name": name, "address": ip, "weight": weight, "enabled": true})
+	}
+
+	if err := client.SetPoolOrigins(pool.AccountID, pool.PoolID, origins); err != nil {
+		return false, fmt.Errorf(tr("修改源站池失败: %v"), err)
+	}
+	writeAudit(AuditEntry{
+		Action:   "pool_origin",
+		Record:   name,
+		Type:     "LB",
+		OldValue: oldAddress,
+		NewValue: fmt.Sprintf("%s weight=%.2f", ip, weight),
+		RecordID: pool.PoolID,
+		Source:   client.getAuditSource(),
+	})
+	logInfo("源站池中的 %s 已更新: %s (权重 %.2f)", name, ip, weight)
+	return true, nil
+}
+
+// GetPoolOrigins 读取负载均衡源站池的源站列表
+func (c *CloudflareClient) GetPoolOrigins(accountID, poolID string) ([]poolOrigin, error) {
+	endpoint := fmt.Sprintf("/accounts/%s/load_balancers/pools/%s", accountID, poolID)
+	resp, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+		Result  struct {
+			Origins []poolOrigin `json:"origins"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf(tr("解析响应失败: %v"), err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf(tr("API 错误: %s"), "success=false")
+	}
+	return result.Result.Origins, nil
+}
+
+// SetPoolOrigins 替换负载均衡源站池的源站列表
+func (c *CloudflareClient) SetPoolOrigins(accountID, poolID string, origins []poolOrigin) error {
+	jsonData, err := json.Marshal(map[string]interface{}{"origins": origins})
+	if err != nil {
+		return fmt.Errorf(tr("序列化请求失败: %v"), err)
+	}
+
+	endpoint := fmt.Sprintf("/accounts/%s/load_balancers/pools/%s", accountID, poolID)
+	resp, err := c.makeRequest("PATCH", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf(tr("请求失败: %v"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiStatusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 配置向导检查项的结果
+const (
+	probeOK   = "ok"
+	probeWarn = "warn"
+	probeFail = "fail"
+)
+
+// setupProbe 配置向导中一项检查的结果
+type setupProbe struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// icon 结果前显示的符号
+func (p setupProbe) icon() string {
+	switch p.Status {
+	case probeOK:
+		return "✓"
+	case probeWarn:
+		return "⚠"
+	default:
+		return "❌"
+	}
+}
+
+// formatLatency 以毫秒显示耗时
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+// runSetupProbes 保存配置前检查：能否访问 Cloudflare API、各IP检测服务的延迟、
+// 令牌是否有效、能否访问区域，以及记录名称下已有的记录。只读取，不修改任何记录
+func runSetupProbes(client *CloudflareClient, checker *IPChecker, cfg *Config) []setupProbe {
+	var probes []setupProbe
+
+	// 出站 HTTPS：任何 HTTP 响应都说明网络可达
+	start := time.Now()
+	resp, err := client.client.Get(client.baseURL)
+	if err != nil {
+		probes = append(probes, setupProbe{Name: tr("访问 Cloudflare API"), Status: probeFail, Detail: redactError(err).Error()})
+	} else {
+		resp.Body.Close()
+		probes = append(probes, setupProbe{Name: tr("访问 Cloudflare API"), Status: probeOK, Detail: formatLatency(time.Since(start))})
+	}
+
+	// IP检测服务：单个服务失败时仍可使用其他服务，全部失败才无法运行
+	var detectedIP string
+	failed := 0
+	services := checker.services
+	if cfg.RecordType == "AAAA" {
+		services = checker.services6
+	}
+	for _, service := range services {
+		start := time.Now()
+		ip, err := checker.fetchIP(service, cfg.RecordType)
+		if err != nil {
+			failed++
+			probes = append(probes, setupProbe{Name: service, Status: probeWarn, Detail: redactError(err).Error()})
+			continue
+		}
+		if detectedIP == "" {
+			detectedIP = ip
+		}
+		probes = append(probes, setupProbe{Name: service, Status: probeOK, Detail: fmt.Sprintf("%s (%s)", ip, formatLatency(time.Since(start)))})
+	}
+	if failed == len(services) {
+		probes = append(probes, setupProbe{Name: tr("获取公网IP"), Status: probeFail, Detail: tr("所有IP检测服务均不可用")})
+	}
+
+	token, err := client.VerifyToken()
+	switch {
+	case err != nil:
+		probes = append(probes, setupProbe{Name: "API Token", Status: probeFail, Detail: err.Error()})
+	case token.Status != "active":
+		probes = append(probes, setupProbe{Name: "API Token", Status: probeFail, Detail: fmt.Sprintf(tr("令牌状态: %s"), token.Status)})
+	case token.ExpiresOn != "":
+		probes = append(probes, setupProbe{Name: "API Token", Status: probeOK, Detail: fmt.Sprintf(tr("有效，过期时间 %s"), token.ExpiresOn)})
+	default:
+		probes = append(probes, setupProbe{Name: "API Token", Status: probeOK, Detail: tr("有效")})
+	}
+
+	zone, err := client.GetZone(cfg.ZoneID)
+	if err != nil {
+		probes = append(probes, setupProbe{Name: tr("区域"), Status: probeFail, Detail: err.Error()})
+		return probes
+	}
+	probes = append(probes, setupProbe{Name: tr("区域"), Status: probeOK, Detail: zone.Name})
+	if err := checkRecordZone(client, cfg.ZoneID, cfg.RecordName); err != nil {
+		probes = append(probes, setupProbe{Name: tr("记录名称"), Status: probeFail, Detail: err.Error()})
+		return probes
+	}
+
+	name, err := client.recordName(cfg.ZoneID, cfg.RecordName)
+	if err == nil {
+		var records []DNSRecord
+		if records, err = client.GetAllDNSRecords(cfg.ZoneID, name, cfg.RecordType); err == nil {
+			probes = append(probes, recordProbe(name, cfg.RecordType, records, detectedIP))
+		}
+	}
+	if err != nil {
+		probes = append(probes, setupProbe{Name: tr("现有记录"), Status: probeFail, Detail: err.Error()})
+	}
+	return probes
+}
+
+// recordProbe 描述记录名称下已有的记录
+func recordProbe(name, recordType string, records []DNSRecord, ip string) setupProbe {
+	probe := setupProbe{Name: fmt.Sprintf("%s %s", name, recordType), Status: probeOK}
+	if len(records) == 0 {
+		probe.Detail = tr("不存在，首次运行时创建")
+		return probe
+	}
+	var contents []string
+	for _, record := range records {
+		contents = append(contents, record.Content)
+	}
+	probe.Detail = fmt.Sprintf(tr("已有 %d 条: %s"), len(records), strings.Join(contents, ", "))
+	if ip != "" && !containsString(contents, ip) {
+		probe.Detail += fmt.Sprintf(tr("，首次运行时新增指向 %s 的记录（已有记录保留）"), ip)
+	}
+	return probe
+}
+
+// printSetupProbes 显示检查结果，返回是否有未通过的检查
+func printSetupProbes(probes []setupProbe) bool {
+	fmt.Println(tr("\n========== 配置检查 =========="))
+	failed := false
+	for _, probe := range probes {
+		fmt.Printf("%s %s: %s\n", probe.icon(), probe.Name, probe.Detail)
+		if probe.Status == probeFail {
+			failed = true
+		}
+	}
+	return failed
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 只依赖标准库，这里实现 apply 文件需要的 YAML 子集：块映射、块序列、单行标量（可加引号）与注释。
+// 不支持锚点、多行字符串与非空的流式集合（[a, b]、{a: b}）
+
+// yamlLine 去掉注释后的非空行
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// yamlToJSON 把 YAML 文档转换为 JSON，之后按结构体的 json 标签解析；以 { 开头的文件直接按 JSON 处理
+func yamlToJSON(data []byte) ([]byte, error) {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		return []byte(trimmed), nil
+	}
+	value, err := parseYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// parseYAML 解析 YAML 文档，映射解析为 map[string]interface{}，序列解析为 []interface{}
+func parseYAML(doc string) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(doc, "\r\n", "\n"), "\n") {
+		text := stripYAMLComment(raw)
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.TrimSpace(trimmed) == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf(tr("第 %d 行: YAML 不能使用制表符缩进"), i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: strings.TrimRight(trimmed, " \t")})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf(tr("第 %d 行: 缩进不正确"), p.lines[p.pos].num)
+	}
+	return value, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block 解析从当前行开始、缩进为 indent 的映射或序列
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || !isYAMLSeqItem(line.text) {
+			return nil, fmt.Errorf(tr("第 %d 行: 缩进不正确"), line.num)
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		switch {
+		case rest == "":
+			p.pos++
+			value, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		case isYAMLSeqItem(rest) || yamlKeyEnd(rest) >= 0:
+			// "- key: value" 开始一个映射，后续键与第一个键对齐
+			p.lines[p.pos] = yamlLine{num: line.num, indent: indent + len(line.text) - len(rest), text: rest}
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		default:
+			value, err := yamlScalar(rest, line.num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	values := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || isYAMLSeqItem(line.text) {
+			return nil, fmt.Errorf(tr("第 %d 行: 缩进不正确"), line.num)
+		}
+
+		end := yamlKeyEnd(line.text)
+		if end < 0 {
+			return nil, fmt.Errorf(tr("第 %d 行: 应为 \"键: 值\""), line.num)
+		}
+		key, err := yamlKey(line.text[:end], line.num)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf(tr("第 %d 行: 重复的键 %s"), line.num, key)
+		}
+		rest := strings.TrimSpace(line.text[end+1:])
+		p.pos++
+
+		if rest != "" {
+			if values[key], err = yamlScalar(rest, line.num); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// 序列可以与所属的键对齐（key:\n- a）
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text) {
+			if values[key], err = p.sequence(indent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if values[key], err = p.nested(indent); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// nested 解析比 parent 缩进更深的子块，没有子块时为 null
+func (p *yamlParser) nested(parent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKeyEnd 返回键后冒号的位置（冒号后为空格或行尾），引号内的冒号不计；不是映射项时返回 -1
+func yamlKeyEnd(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if yamlQuoteEscape(text, i, quote) {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+func yamlKey(text string, num int) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf(tr("第 %d 行: 键不能为空"), num)
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		value, err := yamlScalar(text, num)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(value), nil
+	}
+	return text, nil
+}
+
+// yamlScalar 解析单行标量：加引号的值总是字符串，未加引号的 true/false、null 与数字按类型解析
+func yamlScalar(text string, num int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf(tr("第 %d 行: 无效的字符串 %s"), num, text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf(tr("第 %d 行: 无效的字符串 %s"), num, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "[]":
+		return []interface{}{}, nil
+	case text == "{}":
+		return map[string]interface{}{}, nil
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") ||
+		strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") ||
+		text == "|" || text == ">" || strings.HasPrefix(text, "|-") || strings.HasPrefix(text, ">-"):
+		return nil, fmt.Errorf(tr("第 %d 行: 不支持的 YAML 语法: %s"), num, text)
+	}
+
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return json.Number(text), nil
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXnN") {
+		return json.Number(text), nil
+	}
+	return text, nil
+}
+
+// yamlQuoteEscape 引号内的转义占两个字节（单引号中连续两个单引号，双引号中的反斜杠转义），第二个字节不是结束引号
+func yamlQuoteEscape(text string, i int, quote byte) bool {
+	return i+1 < len(text) && ((quote == '\'' && text[i] == '\'' && text[i+1] == '\'') || (quote == '"' && text[i] == '\\'))
+}
+
+// stripYAMLComment 去掉行内注释：# 位于行首或空白之后，且不在引号内
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if yamlQuoteEscape(line, i, quote) {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == ':' || line[i-1] == '-' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// nameInZone 记录名称是否属于区域：等于区域名称或是其子域名（不区分大小写）
+func nameInZone(name, zone string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+// checkRecordZone 确认记录名称属于 zone_id 对应的区域。填错 Zone ID 时记录列表总是为空，
+// 不会报错，这里给出明确的错误，并尽量找出记录实际所属的区域；获取区域失败时返回该错误
+func checkRecordZone(client *CloudflareClient, zoneID, name string) error {
+	// "@" 形式的名称总是相对于该区域
+	if name == apexName || strings.HasSuffix(name, "."+apexName) {
+		return nil
+	}
+	zone, err := client.zoneName(zoneID)
+	if err != nil {
+		return err
+	}
+	if nameInZone(name, zone) {
+		return nil
+	}
+
+	msg := fmt.Sprintf(tr("记录 %s 不属于区域 %s（zone_id %s），zone_id 可能填写错误"), name, zone, zoneID)
+	if suggested := suggestZone(client, name); suggested != nil {
+		msg += fmt.Sprintf(tr("；该记录属于区域 %s，zone_id 应为 %s"), suggested.Name, suggested.ID)
+	}
+	return errors.New(msg)
+}
+
+// suggestZone 从最长的后缀开始查找令牌可以访问的、包含该记录的区域
+func suggestZone(client *CloudflareClient, name string) *Zone {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".")
+	for i := 0; i < len(labels)-1; i++ {
+		if zone, err := client.FindZone(strings.Join(labels[i:], ".")); err == nil {
+			return zone
+		}
+	}
+	return nil
+}
+
+// checkRecordZones 启动时检查主记录与 records 中的记录是否属于各自的区域，只写入日志；
+// 无法获取区域（如网络错误）时跳过，由之后的检测周期报告
+func checkRecordZones(config *Config) {
+	client := app.Client()
+	check := func(zoneID, name string) {
+		err := checkRecordZone(client, zoneID, name)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrNetwork):
+			logDebug("无法确认记录 %s 所属的区域: %v", name, err)
+		default:
+			logError("%v", err)
+		}
+	}
+
+	check(config.ZoneID, config.RecordName)
+	for _, record := range config.Records {
+		zoneID := record.ZoneID
+		if zoneID == "" {
+			zoneID = config.ZoneID
+		}
+		if record.Name != "" {
+			check(zoneID, record.Name)
+		}
+	}
+}